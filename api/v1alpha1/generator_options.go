@@ -19,8 +19,10 @@ package v1alpha1
 import (
 	routev1 "github.com/openshift/api/route/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // GitSource describes the Component source
@@ -29,6 +31,339 @@ type GitSource struct {
 	URL string `json:"url"`
 }
 
+// ComponentStorage describes a persistent volume claim to generate and mount into the component's container
+type ComponentStorage struct {
+	// Name is the name of the persistent volume claim, also used as the name of the volume
+	Name string `json:"name"`
+
+	// Size is the requested storage size, e.g. 1Gi
+	Size string `json:"size"`
+
+	// MountPath is the path within the container to mount the volume at
+	MountPath string `json:"mountPath"`
+
+	// StorageClass is the storage class of the persistent volume claim. If empty, the cluster default is used
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// WorkloadType is the kind of workload generated for the component
+type WorkloadType string
+
+const (
+	// WorkloadTypeDeployment generates a Deployment, the default
+	WorkloadTypeDeployment WorkloadType = "Deployment"
+	// WorkloadTypeStatefulSet generates a StatefulSet, with a volume claim template for each storage entry
+	WorkloadTypeStatefulSet WorkloadType = "StatefulSet"
+	// WorkloadTypeCronJob generates a CronJob that runs the component on a schedule. No service, route or ingress
+	// is generated for it
+	WorkloadTypeCronJob WorkloadType = "CronJob"
+	// WorkloadTypeKnativeService generates a Knative serving Service, which handles its own routing and scaling.
+	// No deployment, service, route or ingress is generated for it
+	WorkloadTypeKnativeService WorkloadType = "KnativeService"
+)
+
+// RouteBackend describes a service the generated route sends a share of its traffic to
+type RouteBackend struct {
+	// ServiceName is the name of the service
+	ServiceName string `json:"serviceName"`
+
+	// Weight is the relative share of the traffic sent to the service, between 0 and 256
+	Weight int32 `json:"weight"`
+}
+
+// ConfigMapOptions describes a ConfigMap generated by kustomize from literals and data files
+type ConfigMapOptions struct {
+	// Name is the name of the ConfigMap, which kustomize suffixes with a hash of its data unless DisableNameSuffixHash is set
+	Name string `json:"name"`
+
+	// Literals are the key value pairs of the ConfigMap
+	Literals map[string]string `json:"literals,omitempty"`
+
+	// Files are the contents of the data files of the ConfigMap, keyed by their file name, which is also their key in
+	// the ConfigMap. They are written next to the kustomization
+	Files map[string]string `json:"files,omitempty"`
+
+	// DisableNameSuffixHash keeps kustomize from suffixing the name with a hash of the data, so that the ConfigMap can be
+	// referenced from outside the kustomization. Default is false
+	DisableNameSuffixHash bool `json:"disableNameSuffixHash,omitempty"`
+
+	// EnvFrom adds the ConfigMap to the envFrom of the component's container. kustomize updates the reference to the
+	// generated name. Default is false
+	EnvFrom bool `json:"envFrom,omitempty"`
+}
+
+// SecretGeneratorOptions describes a Secret generated by kustomize in the overlays. The generator doesn't write the
+// secret data, which must be committed separately or set in Literals
+type SecretGeneratorOptions struct {
+	// Name is the name of the Secret, which kustomize suffixes with a hash of its data unless DisableNameSuffixHash is set
+	Name string `json:"name"`
+
+	// Type is the type of the Secret. If empty, Opaque is used
+	Type corev1.SecretType `json:"type,omitempty"`
+
+	// Literals are the key value pairs of the Secret
+	Literals map[string]string `json:"literals,omitempty"`
+
+	// Files are the paths of the data files of the Secret, relative to the overlays folder. A path can be prefixed with
+	// the key to use followed by =, e.g. tls.crt=certs/server.crt
+	Files []string `json:"files,omitempty"`
+
+	// Envs are the paths of env files of the Secret, relative to the overlays folder, with a key value pair per line
+	Envs []string `json:"envs,omitempty"`
+
+	// DisableNameSuffixHash keeps kustomize from suffixing the name with a hash of the data, so that the Secret can be
+	// referenced from outside the kustomization. Default is false
+	DisableNameSuffixHash bool `json:"disableNameSuffixHash,omitempty"`
+}
+
+// KustomizeGeneratorOptions describes the options kustomize applies to all the ConfigMaps and Secrets generated by a
+// kustomization, set through its generatorOptions
+type KustomizeGeneratorOptions struct {
+	// Labels are added to all the generated ConfigMaps and Secrets
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to all the generated ConfigMaps and Secrets
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// DisableNameSuffixHash keeps kustomize from suffixing the names of all the generated ConfigMaps and Secrets with a
+	// hash of their data. Default is false
+	DisableNameSuffixHash bool `json:"disableNameSuffixHash,omitempty"`
+}
+
+// PatchTarget selects the resources a patch applies to
+type PatchTarget struct {
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+	Name    string `json:"name,omitempty"`
+}
+
+// TargetedPatch describes a patch of the overlays applied to the resources selected by its target, e.g. a JSON 6902
+// patch removing a list item, which strategic merge patches can't
+type TargetedPatch struct {
+	// Target selects the resources the patch applies to
+	Target PatchTarget `json:"target"`
+
+	// Patch is the inline patch, e.g. a JSON 6902 list of operations
+	Patch string `json:"patch,omitempty"`
+
+	// Path is the path of a file with the patch, relative to the overlays folder. Exactly one of Patch or Path must be set
+	Path string `json:"path,omitempty"`
+}
+
+// Replacement copies a field of a source resource into fields of target resources, through the kustomize
+// replacements
+type Replacement struct {
+	// Source selects the resource and field the value is copied from
+	Source *ReplacementSource `json:"source,omitempty"`
+
+	// Targets select the resources and fields the value is copied to
+	Targets []ReplacementTarget `json:"targets,omitempty"`
+
+	// Path is the path of a file with the replacement, relative to the overlays folder. Either Path or Source and
+	// Targets must be set
+	Path string `json:"path,omitempty"`
+}
+
+// ReplacementSource selects the resource and field a replacement value is copied from
+type ReplacementSource struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+
+	// FieldPath is the path of the field, e.g. spec.template.spec.containers.0.image. Default is metadata.name
+	FieldPath string `json:"fieldPath,omitempty"`
+
+	// Options select a part of the field value
+	Options *ReplacementFieldOptions `json:"options,omitempty"`
+}
+
+// ReplacementTarget selects the resources and fields a replacement value is copied to
+type ReplacementTarget struct {
+	// Select selects the resources the value is copied to
+	Select *ReplacementSelector `json:"select,omitempty"`
+
+	// Reject excludes resources from those selected
+	Reject []ReplacementSelector `json:"reject,omitempty"`
+
+	// FieldPaths are the paths of the fields the value is copied to
+	FieldPaths []string `json:"fieldPaths,omitempty"`
+
+	// Options select the part of the fields that is replaced
+	Options *ReplacementFieldOptions `json:"options,omitempty"`
+}
+
+// ReplacementSelector selects the resources of a replacement target
+type ReplacementSelector struct {
+	Group              string `json:"group,omitempty"`
+	Version            string `json:"version,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+	Name               string `json:"name,omitempty"`
+	Namespace          string `json:"namespace,omitempty"`
+	AnnotationSelector string `json:"annotationSelector,omitempty"`
+	LabelSelector      string `json:"labelSelector,omitempty"`
+}
+
+// ReplacementFieldOptions select a part of a field value, split by the delimiter
+type ReplacementFieldOptions struct {
+	// Delimiter splits the field value
+	Delimiter string `json:"delimiter,omitempty"`
+
+	// Index is the part of the split value used
+	Index int `json:"index,omitempty"`
+
+	// Encoding is the encoding of the field value, e.g. base64
+	Encoding string `json:"encoding,omitempty"`
+
+	// Create creates the target field if it doesn't exist
+	Create bool `json:"create,omitempty"`
+}
+
+// ExposeMode is which resources the component is exposed with in the overlays
+type ExposeMode string
+
+const (
+	// ExposeModeAuto generates an ingress for Kubernetes clusters and a route for OpenShift clusters, the default
+	ExposeModeAuto ExposeMode = "auto"
+	// ExposeModeRoute generates a route
+	ExposeModeRoute ExposeMode = "route"
+	// ExposeModeIngress generates an ingress
+	ExposeModeIngress ExposeMode = "ingress"
+	// ExposeModeBoth generates both a route and an ingress, for environments mirrored across OpenShift and Kubernetes clusters
+	ExposeModeBoth ExposeMode = "both"
+	// ExposeModeNone generates neither a route nor an ingress, e.g. for a port only used by health checks. A route or
+	// ingress passed in through KubernetesResources is still written
+	ExposeModeNone ExposeMode = "none"
+)
+
+// SecretKeyMapping describes an env var of the component's container that is sourced from a key of a secret
+type SecretKeyMapping struct {
+	// SecretName is the name of the secret holding the key
+	SecretName string `json:"secretName"`
+
+	// Key is the key within the secret to set the env var to
+	Key string `json:"key"`
+
+	// EnvName is the name of the env var
+	EnvName string `json:"envName"`
+}
+
+// ComponentPort describes a port exposed by the component's container
+type ComponentPort struct {
+	// Name is the name of the port. Required if more than one port is set
+	Name string `json:"name,omitempty"`
+
+	// ContainerPort is the port number the container listens on
+	ContainerPort int `json:"containerPort"`
+
+	// Protocol is the protocol of the port, one of TCP, UDP or SCTP. If empty, TCP is used
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+
+	// AppProtocol is the application protocol of the port, e.g. http, grpc or h2c, set on the generated service port
+	// for protocol detection by service meshes
+	AppProtocol *string `json:"appProtocol,omitempty"`
+
+	// TargetPortName is the name of the container port, which the generated service targets by name rather than by
+	// number. This allows the container port number to change without changing the service. If empty, the
+	// container port is named Name and targeted by number
+	TargetPortName string `json:"targetPortName,omitempty"`
+
+	// Expose marks the port that the generated route or ingress targets. At most one port can be exposed
+	Expose bool `json:"expose,omitempty"`
+}
+
+// ProbeType is the type of handler used by a generated probe
+type ProbeType string
+
+const (
+	// ProbeTypeTCP checks that a TCP connection can be opened to the target port
+	ProbeTypeTCP ProbeType = "tcp"
+	// ProbeTypeHTTP performs an HTTP GET against the target port
+	ProbeTypeHTTP ProbeType = "http"
+	// ProbeTypeExec runs a command inside the container
+	ProbeTypeExec ProbeType = "exec"
+)
+
+// ProbeOptions describes a probe to generate on the component's container. Fields that are left unset use
+// the same defaults as the generated probes: a 10 second initial delay and period, and the path "/" for http probes
+type ProbeOptions struct {
+	// Type is the type of probe, one of tcp, http or exec. If empty, the default type of the probe is used
+	Type ProbeType `json:"type,omitempty"`
+
+	// Path is the path to query for http probes
+	Path string `json:"path,omitempty"`
+
+	// Scheme is the scheme to use for http probes, either HTTP or HTTPS
+	Scheme corev1.URIScheme `json:"scheme,omitempty"`
+
+	// Command is the command to run for exec probes
+	Command []string `json:"command,omitempty"`
+
+	// InitialDelaySeconds is the number of seconds after the container has started before the probe is run
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+
+	// PeriodSeconds is how often, in seconds, to run the probe
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures for the probe to be considered failed
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// PDBOptions describes the pod disruption budget to generate for the component. Exactly one of MinAvailable or
+// MaxUnavailable must be set
+type PDBOptions struct {
+	// MinAvailable is the number or percentage of the component's pods that must stay available during a disruption
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of the component's pods that can be unavailable during a disruption
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// HPAOptions describes the horizontal pod autoscaler to generate for the component
+type HPAOptions struct {
+	// MinReplicas is the lower limit of replicas the autoscaler can scale down to. If unset, the Kubernetes default of 1 is used
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of replicas the autoscaler can scale up to
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// CPUUtilization is the target average CPU utilization, as a percentage of the requested CPU
+	CPUUtilization *int32 `json:"cpuUtilization,omitempty"`
+
+	// MemoryUtilization is the target average memory utilization, as a percentage of the requested memory
+	MemoryUtilization *int32 `json:"memoryUtilization,omitempty"`
+}
+
+// IngressTLSOptions describes the TLS configuration of the generated ingress
+type IngressTLSOptions struct {
+	// SecretName is the name of the secret holding the TLS certificate. If empty, the ingress controller's default
+	// certificate is used, which is only supported in auto mode
+	SecretName string `json:"secretName,omitempty"`
+
+	// Hosts are the host names covered by the certificate. A rule is generated for each host
+	Hosts []string `json:"hosts,omitempty"`
+
+	// Auto enables TLS for the route host, the same as the edge terminated route generated for OpenShift clusters.
+	// Cannot be combined with Hosts
+	Auto bool `json:"auto,omitempty"`
+}
+
+// RouteTLSOptions describes the TLS configuration of the generated route
+type RouteTLSOptions struct {
+	// Termination is where TLS is terminated, one of edge, passthrough or reencrypt. If empty, edge is used
+	Termination routev1.TLSTerminationType `json:"termination,omitempty"`
+
+	// InsecureEdgeTerminationPolicy is how plain HTTP requests are handled, one of Redirect, Allow or None.
+	// If empty, Redirect is used. Allow is not supported with passthrough termination
+	InsecureEdgeTerminationPolicy routev1.InsecureEdgeTerminationPolicyType `json:"insecureEdgeTerminationPolicy,omitempty"`
+
+	// DestinationCACertificate is the PEM encoded CA certificate used to validate the component's certificate.
+	// Only valid with reencrypt termination
+	DestinationCACertificate string `json:"destinationCACertificate,omitempty"`
+}
+
 // KubernetesResources define the list of Kubernetes resources
 type KubernetesResources struct {
 	DaemonSets   []appsv1.DaemonSet
@@ -53,6 +388,34 @@ type GeneratorOptions struct {
 	// K8sLabels is the labels to add to all the generated kubernetes resources
 	K8sLabels map[string]string `json:"K8sLabels,omitempty"`
 
+	// Annotations is the annotations to add to all the generated kubernetes resources
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// UseKustomizeManagedByLabel has kustomize add the app.kubernetes.io/managed-by label, through the managedByLabel build
+	// metadata of the base kustomization, rather than setting it on every generated resource. Default is false
+	UseKustomizeManagedByLabel bool `json:"useKustomizeManagedByLabel,omitempty"`
+
+	// CommonLabels are added to all the resources of the base through its kustomization. They're set as commonLabels,
+	// which adds them to the selectors as well, unless UseKustomizeLabels is set. Any commonLabels or labels already
+	// in the base kustomization are kept
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+
+	// UseKustomizeLabels sets the CommonLabels through the labels of the base kustomization, which adds them to the pod
+	// templates but leaves the immutable selectors untouched. Default is false
+	UseKustomizeLabels bool `json:"useKustomizeLabels,omitempty"`
+
+	// CommonAnnotations are set as the commonAnnotations of the base and overlays kustomizations, which kustomize adds
+	// to all the resources, e.g. for ownership metadata. Any commonAnnotations already in the overlays kustomization are kept
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
+
+	// KustomizeComponents are the paths of the kustomize components the base and overlays kustomizations reference,
+	// e.g. ../../../../components/add-istio-sidecar. Any components already in the kustomizations are kept
+	KustomizeComponents []string `json:"kustomizeComponents,omitempty"`
+
+	// PodAnnotations is the annotations to add to the pod template of the generated deployment,
+	// e.g. prometheus.io/scrape
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
 	// Application to add the component to
 	Application string `json:"application"`
 
@@ -67,12 +430,113 @@ type GeneratorOptions struct {
 	// Compute Resources required by this component
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// OverlayResources are environment specific compute resources, set in the overlays patch. They are merged
+	// with Resources key by key, so that an environment can override a single limit or request
+	OverlayResources corev1.ResourceRequirements `json:"overlayResources,omitempty"`
+
+	// OverlayNamePrefix and OverlayNameSuffix are added to the names of all resources of the overlays, set as the
+	// namePrefix and nameSuffix of the overlays kustomization. This allows the same component to be deployed twice in
+	// one namespace. If empty, any namePrefix and nameSuffix already in the overlays kustomization are kept
+	OverlayNamePrefix string `json:"overlayNamePrefix,omitempty"`
+	OverlayNameSuffix string `json:"overlayNameSuffix,omitempty"`
+
+	// UseKustomizeImages sets the image of the overlays through the images of the overlays kustomization, rather than
+	// in the patch, so that it can be updated with kustomize edit set image. The base image must be set for kustomize
+	// to replace it. Default is false
+	UseKustomizeImages bool `json:"useKustomizeImages,omitempty"`
+
+	// UseKustomizeReplicas sets the replicas of the overlays through the replicas of the overlays kustomization, rather
+	// than in the patch. Default is false
+	UseKustomizeReplicas bool `json:"useKustomizeReplicas,omitempty"`
+
+	// EnableNamespaceTransformer sets the namespace of the overlays through the namespace of the overlays kustomization,
+	// rather than in the patches. Default is false
+	EnableNamespaceTransformer bool `json:"enableNamespaceTransformer,omitempty"`
+
+	// CreateNamespace generates the namespace of the overlays in namespace.yaml, so that applying the overlays creates
+	// it, e.g. when bootstrapping a new environment. The namespace file is removed when regenerating without it.
+	// Default is false
+	CreateNamespace bool `json:"createNamespace,omitempty"`
+
+	// BaseOverlay is the path, relative to the overlays, of a shared overlay the overlays are based on rather than the base,
+	// e.g. ../common. The directory must exist. Default is ../../base
+	BaseOverlay string `json:"baseOverlay,omitempty"`
+
+	// OverlayTargetedPatches are added to the patches of the overlays kustomization, after the generated patch files.
+	// A patch already in the kustomization with the same target is replaced
+	OverlayTargetedPatches []TargetedPatch `json:"overlayTargetedPatches,omitempty"`
+
+	// OverlayReplacements are added to the replacements of the overlays kustomization, e.g. to copy the image of the
+	// deployment into an annotation. A replacement already in the kustomization isn't added again
+	OverlayReplacements []Replacement `json:"overlayReplacements,omitempty"`
+
+	// SingleOtherResourcesFile writes all the extra resources passed in, such as the second deployment onwards, to
+	// other_resources.yaml as in previous versions, rather than to a file per resource named after its kind and name.
+	// Default is false
+	SingleOtherResourcesFile bool `json:"singleOtherResourcesFile,omitempty"`
+
+	// PreserveUserResources keeps the resources added by users to the base, rather than regenerating the whole base
+	// folder. The resources the generator owns are tracked in an annotation of the base kustomization, and only those
+	// are rewritten or removed. Default is false
+	PreserveUserResources bool `json:"preserveUserResources,omitempty"`
+
+	// ResourceOrder lists the resource files of the base kustomization in the order kustomize should apply them, for
+	// resources that depend on each other. The listed files come first in the given order, followed by the other
+	// resources, and files that aren't resources of the kustomization are ignored. By default the resources are sorted
+	// by name
+	ResourceOrder []string `json:"resourceOrder,omitempty"`
+
+	// ValidateKustomize builds the generated kustomizations once they're written, checking that the files they reference
+	// exist and decode, that no resource is added twice and that every patch without a target matches a resource, so
+	// that a broken kustomization fails the generation rather than the sync. Default is false
+	ValidateKustomize bool `json:"validateKustomize,omitempty"`
+
+	// ApplyDefaultResources fills in the default resource requests for any resource that has neither a request nor a
+	// limit set in Resources, for clusters that reject pods without requests. Default is false
+	ApplyDefaultResources bool `json:"applyDefaultResources,omitempty"`
+
 	// The number of replicas to deploy the component with
 	Replicas int `json:"replicas,omitempty"`
 
 	// The port to expose the component over. Referenced in generated service.yaml and route.yaml
 	TargetPort int `json:"targetPort,omitempty"`
 
+	// Ports is the list of ports to expose the component over. Referenced in generated deployment.yaml and service.yaml,
+	// and the port marked as exposed in route.yaml or ingress.yaml. If set, TargetPort is ignored, otherwise TargetPort
+	// is a shorthand for a single exposed port
+	Ports []ComponentPort `json:"ports,omitempty"`
+
+	// NameTargetPort names the port generated from TargetPort "http", as required by service meshes such as Istio
+	// for protocol detection. Default is false, leaving the port unnamed. Ignored if Ports is set
+	NameTargetPort bool `json:"nameTargetPort,omitempty"`
+
+	// ServiceType is the type of the generated service, one of ClusterIP, NodePort or LoadBalancer.
+	// If empty, the Kubernetes default of ClusterIP is used
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	// SkipService skips generating a service even if ports are set, e.g. for a port only used by health checks. No
+	// route or ingress is generated either. A service passed in through KubernetesResources is still written
+	SkipService bool `json:"skipService,omitempty"`
+
+	// ServiceAnnotations are added to the generated service, on top of Annotations, e.g. for cloud load balancer settings
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// NodePort is the fixed node port of the exposed service port, for the NodePort and LoadBalancer service types.
+	// If unset, a node port is allocated by Kubernetes
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// Headless generates a headless service, without a cluster IP, e.g. for StatefulSets. A headless service cannot
+	// back a route or ingress, so none is generated
+	Headless bool `json:"headless,omitempty"`
+
+	// SessionAffinity is the session affinity of the generated service, either None or ClientIP for sticky sessions.
+	// If empty, the Kubernetes default of None is used
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+
+	// ExternalTrafficPolicy is how the generated service routes external traffic, either Cluster or Local.
+	// Only valid for the NodePort and LoadBalancer service types
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+
 	// The route host name to expose the component with. Referenced in generated route.yaml
 	Route string `json:"route,omitempty"`
 
@@ -80,6 +544,28 @@ type GeneratorOptions struct {
 	// Should be under 30 characters, if over, it will be trimmed to ensure compatibility with generated hostnames
 	RouteName string `json:"routeName,omitempty"`
 
+	// RouteTLS configures TLS on the generated route. If unset, edge termination redirecting plain HTTP is used
+	RouteTLS *RouteTLSOptions `json:"routeTLS,omitempty"`
+
+	// RouteAllowInsecure generates a route without TLS, serving plain HTTP only. Cannot be combined with RouteTLS.
+	// Default is false
+	RouteAllowInsecure bool `json:"routeAllowInsecure,omitempty"`
+
+	// RouteSubdomain is the subdomain of the generated route, prefixed to the cluster's ingress domain, e.g. for preview
+	// environments. Cannot be combined with Route
+	RouteSubdomain string `json:"routeSubdomain,omitempty"`
+
+	// RouteWildcardPolicy is the wildcard policy of the generated route, either None or Subdomain. If empty, None is used
+	RouteWildcardPolicy routev1.WildcardPolicyType `json:"routeWildcardPolicy,omitempty"`
+
+	// RouteAnnotations are added to the generated route, on top of Annotations, e.g. for router timeouts and rate limiting
+	RouteAnnotations map[string]string `json:"routeAnnotations,omitempty"`
+
+	// RouteBackends are the services the generated route splits its traffic between, e.g. for blue/green rollouts.
+	// The first is the route's primary backend and the rest are alternate backends. If empty, all traffic is sent
+	// to the component's service
+	RouteBackends []RouteBackend `json:"routeBackends,omitempty"`
+
 	// An array of environment variables to add to the component.  BaseEnvVar describes environment variables to use for the component
 	BaseEnvVar []corev1.EnvVar `json:"env,omitempty"`
 
@@ -88,17 +574,240 @@ type GeneratorOptions struct {
 	// to the base.
 	OverlayEnvVar []corev1.EnvVar `json:"overlayEnvVar"`
 
+	// BaseEnvFrom is an array of ConfigMaps and Secrets whose contents are added to the component as environment variables
+	BaseEnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// OverlayEnvFrom is an array of ConfigMaps and Secrets in addition to the component BaseEnvFrom. These will ONLY be
+	// added to the deployment patches overlays deployment.yaml, replacing any base entries that reference the same source
+	OverlayEnvFrom []corev1.EnvFromSource `json:"overlayEnvFrom,omitempty"`
+
+	// ConfigMaps are generated by kustomize, through the configMapGenerator of the base and overlays kustomizations.
+	// In the overlays, a ConfigMap the base also generates is merged into the base one. Any other configMapGenerator
+	// entries already in the overlays kustomization are kept
+	ConfigMaps []ConfigMapOptions `json:"configMaps,omitempty"`
+
+	// OverlaySecretGenerators are the environment specific Secrets generated by kustomize, through the secretGenerator
+	// of the overlays kustomization. Any other secretGenerator entries already in the overlays kustomization are kept
+	OverlaySecretGenerators []SecretGeneratorOptions `json:"overlaySecretGenerators,omitempty"`
+
+	// KustomizeGeneratorOptions sets the generatorOptions of the base and overlays kustomizations, replacing those already
+	// in them. If unset, the existing generatorOptions are kept
+	KustomizeGeneratorOptions *KustomizeGeneratorOptions `json:"kustomizeGeneratorOptions,omitempty"`
+
+	// SecretEnv is a list of env vars to set on the component's container from keys of secrets, e.g. the
+	// application secret. They are added after BaseEnvVar
+	SecretEnv []SecretKeyMapping `json:"secretEnv,omitempty"`
+
 	// The container image to build or create the component from
 	ContainerImage string `json:"containerImage,omitempty"`
 
+	// ContainerName is the name of the component's container, which must be a valid DNS-1123 label.
+	// If empty, "container-image" is used
+	ContainerName string `json:"containerName,omitempty"`
+
+	// Command overrides the entrypoint of the component's container image
+	Command []string `json:"command,omitempty"`
+
+	// Args overrides the arguments passed to the entrypoint of the component's container image
+	Args []string `json:"args,omitempty"`
+
+	// Lifecycle is the lifecycle hooks of the component's container, e.g. a preStop hook for graceful shutdown
+	Lifecycle *corev1.Lifecycle `json:"lifecycle,omitempty"`
+
+	// TerminationGracePeriodSeconds is the duration in seconds the component's pods are given to terminate gracefully
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// OverlayLifecycle overrides Lifecycle for an environment. It is ONLY added to the overlays deployment patch
+	OverlayLifecycle *corev1.Lifecycle `json:"overlayLifecycle,omitempty"`
+
+	// OverlayTerminationGracePeriodSeconds overrides TerminationGracePeriodSeconds for an environment.
+	// It is ONLY added to the overlays deployment patch
+	OverlayTerminationGracePeriodSeconds *int64 `json:"overlayTerminationGracePeriodSeconds,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the component's container, one of Always, IfNotPresent or Never.
+	// If unset, Always is used
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ServiceAccountName is the name of the service account to run the component's pods as.
+	// If empty and CreateServiceAccount is set, the Component name is used
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// CreateServiceAccount generates a service account for the component in serviceaccount.yaml.
+	// Default is false, hence the service account must already exist if ServiceAccountName is set
+	CreateServiceAccount bool `json:"createServiceAccount,omitempty"`
+
+	// GenerateNetworkPolicy generates a network policy for the component in networkpolicy.yaml, denying all ingress
+	// traffic to its pods except to its ports from within the namespace. Default is false
+	GenerateNetworkPolicy bool `json:"generateNetworkPolicy,omitempty"`
+
+	// NetworkPolicyAllowedNamespaces are the names of other namespaces the network policy allows traffic from
+	NetworkPolicyAllowedNamespaces []string `json:"networkPolicyAllowedNamespaces,omitempty"`
+
+	// NetworkPolicyAllowedNamespaceLabels are the labels of other namespaces the network policy allows traffic from,
+	// e.g. the label of the namespace the ingress controller runs in
+	NetworkPolicyAllowedNamespaceLabels map[string]string `json:"networkPolicyAllowedNamespaceLabels,omitempty"`
+
+	// NodeSelector is the node selector to schedule the component's pods with.
+	// It is also set in the overlays deployment patch, so that each environment can target different nodes
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are the tolerations of the component's pods. Also set in the overlays deployment patch
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity is the scheduling affinity of the component's pods. Also set in the overlays deployment patch
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// WorkloadType is the kind of workload to generate, one of Deployment, StatefulSet, CronJob or KnativeService.
+	// If empty, a Deployment is generated. Ignored if a workload was passed in through KubernetesResources, or for
+	// KnativeService, if a Knative service was passed in through KubernetesResources.Others
+	WorkloadType WorkloadType `json:"workloadType,omitempty"`
+
+	// Schedule is the cron schedule of the generated CronJob, e.g. "*/5 * * * *". Required for the CronJob workload type
+	Schedule string `json:"schedule,omitempty"`
+
+	// ConcurrencyPolicy is how the generated CronJob treats concurrent runs, one of Allow, Forbid or Replace.
+	// If empty, the Kubernetes default is used
+	ConcurrencyPolicy batchv1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// SuccessfulJobsHistoryLimit is the number of successful jobs of the generated CronJob to keep
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit is the number of failed jobs of the generated CronJob to keep
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
+	// DeploymentStrategy is the strategy used to replace the component's pods, either RollingUpdate with optional
+	// maxSurge and maxUnavailable parameters, or Recreate. If unset, the Kubernetes default is used
+	DeploymentStrategy *appsv1.DeploymentStrategy `json:"deploymentStrategy,omitempty"`
+
+	// PriorityClassName is the priority class of the component's pods. Also set in the overlays deployment patch,
+	// so that it can be overridden per environment
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// RuntimeClassName is the runtime class to run the component's pods with, e.g. for sandboxed workloads
+	RuntimeClassName string `json:"runtimeClassName,omitempty"`
+
+	// HostAliases are entries added to the hosts file of the component's pods, e.g. for hosts outside of cluster DNS
+	HostAliases []corev1.HostAlias `json:"hostAliases,omitempty"`
+
+	// DNSPolicy is the DNS policy of the component's pods. If empty, the Kubernetes default is used
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// DNSConfig is the custom DNS configuration of the component's pods, e.g. additional nameservers or search domains
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// TopologySpreadConstraints describes how the component's pods are spread across topology domains, e.g. zones.
+	// Constraints without a label selector select the component's pods. Also set in the overlays deployment patch,
+	// where they add to or replace the base constraints with the same topology key
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
 	// RevisionHistoryLimit specifies the number of allowed revisions for generated deployments
 	// If unset, RevisionHistorylimit in the deployment spec(s) will not be set
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 
+	// OverlayRevisionHistoryLimit is the environment specific number of allowed revisions, set in the overlays deployment patch.
+	// If unset, it is left out of the patch and the base deployment's limit applies
+	OverlayRevisionHistoryLimit *int32 `json:"overlayRevisionHistoryLimit,omitempty"`
+
+	// Paused pauses the rollouts of the deployment in an environment, set in the overlays deployment patch
+	Paused bool `json:"paused,omitempty"`
+
+	// MinReadySeconds is the number of seconds a new pod of the generated deployment must be ready for before it
+	// is considered available. Also set in the overlays deployment patch, so that it can be overridden per environment
+	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+
+	// ProgressDeadlineSeconds is the number of seconds a rollout of the generated deployment may take before it is
+	// considered failed. Also set in the overlays deployment patch, so that it can be overridden per environment
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// PodDisruptionBudget generates a pod disruption budget for the component's pods in pdb.yaml. If set in the
+	// overlays, it is written to a patch, so that the budget can differ per environment
+	PodDisruptionBudget *PDBOptions `json:"podDisruptionBudget,omitempty"`
+
+	// Autoscaling generates a horizontal pod autoscaler for the component's workload in hpa.yaml. If set, Replicas is
+	// ignored and the replicas are left out of the generated workload and the overlays patch, so that they are owned
+	// by the autoscaler. For the KnativeService workload type, the min and max replicas are set as the min and max
+	// scale of the Knative service instead
+	Autoscaling *HPAOptions `json:"autoscaling,omitempty"`
+
 	// KubernetesResources to be used instead of generating the Kubernetes resources from a component
 	KubernetesResources KubernetesResources `json:"kuberntesResources,omitempty"`
 
 	// IsKubernetesCluster tells us whether it is a Kubernetes or an OpenShift cluster
 	// Default is false, hence it is an OpenShift cluster
 	IsKubernetesCluster bool `json:"isKubernetesCluster,omitempty"`
+
+	// ExposeMode is which of a route or an ingress is generated in the overlays. If empty, auto is used, which
+	// picks one based on IsKubernetesCluster
+	ExposeMode ExposeMode `json:"exposeMode,omitempty"`
+
+	// IngressHost is the host name of the generated ingress. If empty, the route host name is used
+	IngressHost string `json:"ingressHost,omitempty"`
+
+	// HostTemplate generates the route and ingress host names in the overlays if neither Route nor IngressHost is set,
+	// e.g. {component}-{environment}.apps.example.com. The {component}, {application}, {environment} and {namespace}
+	// placeholders are supported, where the environment is the name of the overlay folder
+	HostTemplate string `json:"hostTemplate,omitempty"`
+
+	// IngressTLS configures TLS on the ingress generated for Kubernetes clusters. If unset, or if neither a secret
+	// nor auto mode is set, the ingress serves plain HTTP
+	IngressTLS *IngressTLSOptions `json:"ingressTLS,omitempty"`
+
+	// IngressClassName is the class of the ingress generated for Kubernetes clusters, for clusters with multiple ingress controllers
+	IngressClassName string `json:"ingressClassName,omitempty"`
+
+	// LegacyIngressClassAnnotation sets IngressClassName through the legacy kubernetes.io/ingress.class annotation
+	// rather than the spec field, for older ingress controllers. Default is false
+	LegacyIngressClassAnnotation bool `json:"legacyIngressClassAnnotation,omitempty"`
+
+	// IngressAnnotations are added to the generated ingress, on top of Annotations, e.g. for cert-manager
+	IngressAnnotations map[string]string `json:"ingressAnnotations,omitempty"`
+
+	// IngressPath is the path the generated ingress routes to the component, e.g. /api for components sharing a host.
+	// Must start with "/". If empty, "/" is used
+	IngressPath string `json:"ingressPath,omitempty"`
+
+	// IngressPathType is how IngressPath is matched, either Prefix or Exact. If empty, Prefix is used.
+	// ImplementationSpecific is only allowed if AllowImplementationSpecificPathType is set
+	IngressPathType networkingv1.PathType `json:"ingressPathType,omitempty"`
+
+	// AllowImplementationSpecificPathType allows the ImplementationSpecific ingress path type, whose matching
+	// depends on the ingress controller. Default is false
+	AllowImplementationSpecificPathType bool `json:"allowImplementationSpecificPathType,omitempty"`
+
+	// PodSecurityContext is the pod-level security context to set on the generated deployment.
+	// If unset, no pod security context is added
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// ContainerSecurityContext is the security context to set on the component's container.
+	// If unset, no container security context is added
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+
+	// InitContainers is a list of init containers to run before the component's container starts.
+	// They are copied as-is into the generated deployment, and the overlay env vars are applied to them in the deployment patch
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
+	// Sidecars is a list of additional containers to run alongside the component's container.
+	// They are added after the component's container in the generated deployment
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+
+	// Storage is a list of persistent volume claims to generate for the component.
+	// Each one is written to its own file in the base, and mounted into the component's container
+	Storage []ComponentStorage `json:"storage,omitempty"`
+
+	// ReadinessProbe configures the readiness probe of the component's container.
+	// If unset, a tcp probe against the target port is generated
+	ReadinessProbe *ProbeOptions `json:"readinessProbe,omitempty"`
+
+	// LivenessProbe configures the liveness probe of the component's container.
+	// If unset, an http probe against "/" on the target port is generated
+	LivenessProbe *ProbeOptions `json:"livenessProbe,omitempty"`
+
+	// StartupProbe configures the startup probe of the component's container, for components that are slow to start.
+	// If unset, no startup probe is generated. If the type is unset, a tcp probe against the target port is generated
+	StartupProbe *ProbeOptions `json:"startupProbe,omitempty"`
+
+	// DisableProbes suppresses the readiness, liveness and startup probes of the component's container, in both the
+	// generated deployment and the overlays patch, for components that can't be probed over their port, e.g. gRPC services.
+	// The container ports and service are still generated
+	DisableProbes bool `json:"disableProbes,omitempty"`
 }
@@ -16,6 +16,7 @@
 package gitops
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/redhat-developer/gitops-generator/pkg/util"
@@ -31,13 +32,25 @@ const (
 	initializeGit  GitCmd = "initialize git"
 	addComponents  GitCmd = "add components"
 	getCommitID    GitCmd = "retrieve commit id"
+	getCommitInfo  GitCmd = "retrieve commit info"
+	headBranch     GitCmd = "detect the default branch of"
 	switchBranch   GitCmd = "switch to"
 	checkoutBranch GitCmd = "checkout"
 	genOverlays    GitCmd = "overlays dir"
+	unshallowRepo  GitCmd = "unshallow"
+	sparseCheckout GitCmd = "sparse checkout"
+	abortRebase    GitCmd = "abort rebase in"
+	addRemote      GitCmd = "add remote to"
+	fetchRepo      GitCmd = "fetch"
+	resetBranch    GitCmd = "reset to"
+	cleanRepo      GitCmd = "clean"
+	resetRepo      GitCmd = "reset"
+	createTag      GitCmd = "create"
+	pushTag        GitCmd = "push"
 )
 
 // GitCmdError is used to construct custom errors for a number of git commands that follow similar message patterns
-// Used by the following command types:  cloneRepo, checkGitDiff, commitFiles, pushRemote, initializeGit, addComponents, getCommitID
+// Used by the following command types:  cloneRepo, checkGitDiff, commitFiles, pushRemote, initializeGit, addComponents, getCommitID, unshallowRepo, sparseCheckout, abortRebase
 
 type GitCmdError struct {
 	path      string
@@ -54,13 +67,17 @@ func (e *GitCmdError) Error() string {
 		cmdMsg = cmdMsg + " in"
 	} else if e.cmdType == commitFiles || e.cmdType == pushRemote || e.cmdType == addComponents {
 		cmdMsg = cmdMsg + " to"
-	} else if e.cmdType == getCommitID {
+	} else if e.cmdType == getCommitID || e.cmdType == getCommitInfo {
 		cmdMsg = cmdMsg + " for"
 	}
 
 	return util.SanitizeErrorMessage(fmt.Errorf("failed to %s repository %q %q: %s", cmdMsg, e.path, e.cmdResult, e.err)).Error()
 }
 
+func (e *GitCmdError) Unwrap() error {
+	return e.err
+}
+
 // GitBranchError is used to construct custom errors related to git branch failures
 // Used by the following command types: switchBranch, checkoutBranch
 type GitBranchError struct {
@@ -75,6 +92,10 @@ func (e *GitBranchError) Error() string {
 	return util.SanitizeErrorMessage(fmt.Errorf("failed to %s branch %q in repository %q %q: %s", e.cmdType, e.branch, e.repoPath, string(e.cmdResult), e.err)).Error()
 }
 
+func (e *GitBranchError) Unwrap() error {
+	return e.err
+}
+
 // GitPullError is used to construct custom errors related to git pull failures
 type GitPullError struct {
 	remote    string
@@ -86,6 +107,142 @@ func (e *GitPullError) Error() string {
 	return util.SanitizeErrorMessage(fmt.Errorf("failed to pull from remote %q %q: %s", e.remote, string(e.cmdResult), e.err)).Error()
 }
 
+func (e *GitPullError) Unwrap() error {
+	return e.err
+}
+
+// GitSignCommitError is used to construct a custom error if signing a commit fails, so that callers can fall back to
+// unsigned commits
+type GitSignCommitError struct {
+	repoPath  string
+	cmdResult string
+	err       error
+}
+
+func (e *GitSignCommitError) Error() string {
+	return util.SanitizeErrorMessage(fmt.Errorf("failed to sign the commit in repository %q %q: %s", e.repoPath, e.cmdResult, e.err)).Error()
+}
+
+func (e *GitSignCommitError) Unwrap() error {
+	return e.err
+}
+
+// GitRebaseConflictError is used to construct a custom error if rebasing onto the remote branch conflicts
+type GitRebaseConflictError struct {
+	branch    string
+	remote    string
+	cmdResult string
+	err       error
+}
+
+func (e *GitRebaseConflictError) Error() string {
+	return util.SanitizeErrorMessage(fmt.Errorf("failed to rebase onto branch %q of remote %q due to conflicts %q: %s", e.branch, e.remote, e.cmdResult, e.err)).Error()
+}
+
+func (e *GitRebaseConflictError) Unwrap() error {
+	return e.err
+}
+
+// GitTagError is used to construct a custom error if tagging the commit fails, once it's pushed. The commit itself is
+// pushed, so callers may only report the error
+type GitTagError struct {
+	tag       string
+	path      string
+	cmdResult string
+	err       error
+	cmdType   GitCmd
+}
+
+func (e *GitTagError) Error() string {
+	return util.SanitizeErrorMessage(fmt.Errorf("failed to %s tag %q in repository %q %q: %s", e.cmdType, e.tag, e.path, e.cmdResult, e.err)).Error()
+}
+
+func (e *GitTagError) Unwrap() error {
+	return e.err
+}
+
+// GitPushRemoteError is used to construct a custom error if pushing to an additional remote fails, once the push to
+// the primary remote succeeded
+type GitPushRemoteError struct {
+	remoteName string
+	remote     string
+	cmdResult  string
+	err        error
+}
+
+func (e *GitPushRemoteError) Error() string {
+	return util.SanitizeErrorMessage(fmt.Errorf("failed to push to the additional remote %q %q %q: %s", e.remoteName, e.remote, e.cmdResult, e.err)).Error()
+}
+
+func (e *GitPushRemoteError) Unwrap() error {
+	return e.err
+}
+
+// ErrBranchNotFound is the error of a strict branch that doesn't exist on the remote
+var ErrBranchNotFound = errors.New("branch not found")
+
+// BranchNotFoundError is used to construct a custom error if a strict branch doesn't exist on the remote
+type BranchNotFoundError struct {
+	branch string
+	remote string
+}
+
+func (e *BranchNotFoundError) Error() string {
+	return util.SanitizeErrorMessage(fmt.Errorf("failed to switch to branch %q of remote %q: %s", e.branch, e.remote, ErrBranchNotFound)).Error()
+}
+
+func (e *BranchNotFoundError) Unwrap() error {
+	return ErrBranchNotFound
+}
+
+// ErrDestinationExists is the error of a clone into a destination that exists and isn't a checkout of the remote
+var ErrDestinationExists = errors.New("destination already exists")
+
+// DestinationExistsError is used to construct a custom error if the destination of a clone exists and isn't a checkout
+// of the remote
+type DestinationExistsError struct {
+	repoPath string
+	remote   string
+}
+
+func (e *DestinationExistsError) Error() string {
+	return util.SanitizeErrorMessage(fmt.Errorf("failed to clone remote %q, the %s in %q and isn't a checkout of the remote", e.remote, ErrDestinationExists, e.repoPath)).Error()
+}
+
+func (e *DestinationExistsError) Unwrap() error {
+	return ErrDestinationExists
+}
+
+// KustomizeValidationError is used to construct a custom error if building the generated kustomization fails
+type KustomizeValidationError struct {
+	path string
+	err  error
+}
+
+func (e *KustomizeValidationError) Error() string {
+	return fmt.Sprintf("failed to validate the kustomization in %q: %s", e.path, e.err)
+}
+
+func (e *KustomizeValidationError) Unwrap() error {
+	return e.err
+}
+
+// GitPullRequestError is used to construct a custom error if opening a pull request fails
+type GitPullRequestError struct {
+	remote string
+	head   string
+	base   string
+	err    error
+}
+
+func (e *GitPullRequestError) Error() string {
+	return util.SanitizeErrorMessage(fmt.Errorf("failed to open a pull request from branch %q to branch %q in repository %q: %s", e.head, e.base, e.remote, e.err)).Error()
+}
+
+func (e *GitPullRequestError) Unwrap() error {
+	return e.err
+}
+
 // GitLsRemoteError is used to construct custom errors related to git ls-remote failures
 type GitLsRemoteError struct {
 	remote    string
@@ -97,6 +254,10 @@ func (e *GitLsRemoteError) Error() string {
 	return util.SanitizeErrorMessage(fmt.Errorf("failed to list git remotes for remote %q %q: %s", e.remote, string(e.cmdResult), e.err)).Error()
 }
 
+func (e *GitLsRemoteError) Unwrap() error {
+	return e.err
+}
+
 type GitGenResourcesAndOverlaysError struct {
 	path          string
 	componentName string
@@ -124,6 +285,10 @@ func (e *DeleteFolderError) Error() string {
 	return util.SanitizeErrorMessage(fmt.Errorf("failed to delete %q folder in repository in %q %q: %s", e.componentPath, e.repoPath, e.cmdResult, e.err)).Error()
 }
 
+func (e *DeleteFolderError) Unwrap() error {
+	return e.err
+}
+
 // GitCreateRepoError is used to construct a custom error if repo creation fails
 type GitCreateRepoError struct {
 	repoName string
@@ -138,6 +303,7 @@ func (e *GitCreateRepoError) Error() string {
 // GitAddFilesToRemoteError is used to construct a custom error if adding files to remote repo fails
 type GitAddFilesToRemoteError struct {
 	componentName string
+	remoteName    string
 	remoteURL     string
 	repoPath      string
 	cmdResult     string
@@ -145,7 +311,11 @@ type GitAddFilesToRemoteError struct {
 }
 
 func (e *GitAddFilesToRemoteError) Error() string {
-	return util.SanitizeErrorMessage(fmt.Errorf("failed to add files for component %q, to remote 'origin' %q to repository in %q %q: %s", e.componentName, e.remoteURL, e.repoPath, e.cmdResult, e.err)).Error()
+	return util.SanitizeErrorMessage(fmt.Errorf("failed to add files for component %q, to remote '%s' %q to repository in %q %q: %s", e.componentName, e.remoteName, e.remoteURL, e.repoPath, e.cmdResult, e.err)).Error()
+}
+
+func (e *GitAddFilesToRemoteError) Unwrap() error {
+	return e.err
 }
 
 type GitAddFilesError struct {
@@ -159,6 +329,10 @@ func (e *GitAddFilesError) Error() string {
 	return util.SanitizeErrorMessage(fmt.Errorf("failed to add files for component %q to repository in %q %q: %s", e.componentName, e.repoPath, e.cmdResult, e.err)).Error()
 }
 
+func (e *GitAddFilesError) Unwrap() error {
+	return e.err
+}
+
 type GitOpsRepoGenError struct {
 	gitopsURL string
 	errMsg    string
@@ -16,8 +16,15 @@
 package gitops
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
 	gitopsv1alpha1 "github.com/redhat-developer/gitops-generator/api/v1alpha1"
 
@@ -26,40 +33,119 @@ import (
 	"github.com/redhat-developer/gitops-generator/pkg/util"
 	"github.com/spf13/afero"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
 
 	yaml "github.com/redhat-developer/gitops-generator/pkg/yaml"
 )
 
 const (
-	kustomizeFileName        = "kustomization.yaml"
-	deploymentFileName       = "deployment.yaml"
-	deploymentPatchFileName  = "deployment-patch.yaml"
-	statefulsetFileName      = "statefulset.yaml"
-	statefulsetPatchFileName = "statefulset-patch.yaml"
-	daemonsetFileName        = "daemonset.yaml"
-	daemonsetPatchFileName   = "daemonset-patch.yaml"
-	ingressFileName          = "ingress.yaml"
-	routeFileName            = "route.yaml"
-	serviceFileName          = "service.yaml"
-	otherFileName            = "other_resources.yaml"
+	kustomizeFileName           = "kustomization.yaml"
+	deploymentFileName          = "deployment.yaml"
+	deploymentPatchFileName     = "deployment-patch.yaml"
+	statefulsetFileName         = "statefulset.yaml"
+	statefulsetPatchFileName    = "statefulset-patch.yaml"
+	daemonsetFileName           = "daemonset.yaml"
+	daemonsetPatchFileName      = "daemonset-patch.yaml"
+	cronjobFileName             = "cronjob.yaml"
+	cronjobPatchFileName        = "cronjob-patch.yaml"
+	knativeServiceFileName      = "knative-service.yaml"
+	knativeServicePatchFileName = "knative-service-patch.yaml"
+	ingressFileName             = "ingress.yaml"
+	routeFileName               = "route.yaml"
+	serviceFileName             = "service.yaml"
+	serviceAccountFileName      = "serviceaccount.yaml"
+	pdbFileName                 = "pdb.yaml"
+	pdbPatchFileName            = "pdb-patch.yaml"
+	hpaFileName                 = "hpa.yaml"
+	networkPolicyFileName       = "networkpolicy.yaml"
+	namespaceFileName           = "namespace.yaml"
+	otherFileName               = "other_resources.yaml"
+	pvcFileNameFormat           = "pvc-%s.yaml"
+	otherResourceFileNameFormat = "%s-%s.yaml"
+	crdFileNameFormat           = "crd-%s.yaml"
+
+	// generatedResourcesAnnotation lists the resources of the base kustomization owned by the generator, when the
+	// resources added by users are preserved
+	generatedResourcesAnnotation = "gitops-generator.redhat-developer.com/generated-resources"
+
+	// managedByLabelBuildMetadata is the build metadata option that has kustomize add the app.kubernetes.io/managed-by label
+	managedByLabelBuildMetadata = "managedByLabel"
+
+	// knativeMinScaleAnnotation and knativeMaxScaleAnnotation bound the number of replicas of a Knative service
+	knativeMinScaleAnnotation = "autoscaling.knative.dev/min-scale"
+	knativeMaxScaleAnnotation = "autoscaling.knative.dev/max-scale"
+
+	// legacyIngressClassAnnotation is the annotation used to set the ingress class before the ingressClassName field
+	legacyIngressClassAnnotation = "kubernetes.io/ingress.class"
+
+	// defaultPortName is the name of the port generated from the target port, if it is named
+	defaultPortName = "http"
+
+	// defaultContainerName is the name of the component's container in the generated deployment, if none was set
+	defaultContainerName = "container-image"
 )
 
 var CreatedBy = "application-service"
 
+// minNodePort and maxNodePort bound the default node port range of Kubernetes clusters
+const (
+	minNodePort = 30000
+	maxNodePort = 32767
+)
+
+// maxRouteBackends is the primary backend of a route plus at most 3 alternate backends. maxRouteBackendWeight bounds
+// the weight of each backend, and maxRouteTotalWeight the sum of their weights
+const (
+	maxRouteBackends      = 4
+	maxRouteBackendWeight = 256
+	maxRouteTotalWeight   = 200
+)
+
+// DefaultResourceRequests are the resource requests set on the component's container when ApplyDefaultResources is set
+var DefaultResourceRequests = corev1.ResourceList{
+	corev1.ResourceCPU:    resource.MustParse("10m"),
+	corev1.ResourceMemory: resource.MustParse("64Mi"),
+}
+
 // Generate takes in a given Component CR and
 // spits out a deployment, service, and route file to disk
 func Generate(fs afero.Afero, gitOpsFolder string, outputFolder string, options gitopsv1alpha1.GeneratorOptions) error {
+	if err := validateOptions(options); err != nil {
+		return err
+	}
 
 	var deployment *appsv1.Deployment
 	var statefulSet *appsv1.StatefulSet
 	var daemonSet *appsv1.DaemonSet
+	var cronJob *batchv1.CronJob
+	var knativeService *resources.KnativeService
+	var storageClaimTemplates bool
 
 	if len(options.KubernetesResources.Deployments) == 0 && len(options.KubernetesResources.StatefulSets) == 0 && len(options.KubernetesResources.DaemonSets) == 0 {
-		deployment = generateDeployment(options)
+		if options.WorkloadType == gitopsv1alpha1.WorkloadTypeStatefulSet {
+			statefulSet = generateStatefulSet(options)
+			storageClaimTemplates = true
+		} else if options.WorkloadType == gitopsv1alpha1.WorkloadTypeCronJob {
+			cronJob = generateCronJob(options)
+		} else if options.WorkloadType == gitopsv1alpha1.WorkloadTypeKnativeService {
+			// A Knative service passed in is written to the other resources as is
+			if !hasKnativeService(options.KubernetesResources.Others) {
+				knativeService = generateKnativeService(options)
+			}
+		} else {
+			deployment = generateDeployment(options)
+		}
 	} else if len(options.KubernetesResources.Deployments) > 0 {
 		deployment, options.KubernetesResources.Deployments = &options.KubernetesResources.Deployments[0], options.KubernetesResources.Deployments[1:]
 		var otherDeployments []interface{}
@@ -84,6 +170,19 @@ func Generate(fs afero.Afero, gitOpsFolder string, outputFolder string, options
 		options.KubernetesResources.Others = append(options.KubernetesResources.Others, otherDaemonSets...)
 	}
 
+	// The base is regenerated, but the components and unknown fields added by users to an existing kustomization are kept
+	var originalKustomizeFileContent resources.Kustomization
+	kustomizeFileExist, err := fs.Exists(filepath.Join(outputFolder, kustomizeFileName))
+	if err != nil {
+		return err
+	}
+	if kustomizeFileExist {
+		err = yaml.UnMarshalItemFromFile(fs, filepath.Join(outputFolder, kustomizeFileName), &originalKustomizeFileContent)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal items from %q: %v", filepath.Join(outputFolder, kustomizeFileName), err)
+		}
+	}
+
 	k := resources.Kustomization{
 		APIVersion: "kustomize.config.k8s.io/v1beta1",
 		Kind:       "Kustomization",
@@ -100,13 +199,20 @@ func Generate(fs afero.Afero, gitOpsFolder string, outputFolder string, options
 	} else if daemonSet != nil {
 		k.AddResources(daemonsetFileName)
 		resources[daemonsetFileName] = daemonSet
+	} else if cronJob != nil {
+		k.AddResources(cronjobFileName)
+		resources[cronjobFileName] = cronJob
+	} else if knativeService != nil {
+		k.AddResources(knativeServiceFileName)
+		resources[knativeServiceFileName] = knativeService
 	}
 
 	var service *corev1.Service
 
-	if len(options.KubernetesResources.Services) == 0 && options.TargetPort != 0 {
-		// If service was not provided, generate a service only if target port was provided
-		// If service was not provided and target port is 0, skip generation
+	if len(options.KubernetesResources.Services) == 0 && len(getPorts(options)) > 0 && cronJob == nil && options.WorkloadType != gitopsv1alpha1.WorkloadTypeKnativeService && !options.SkipService {
+		// If service was not provided, generate a service only if ports were provided
+		// If service was not provided and there are no ports, or it was skipped, skip generation
+		// A cronjob is not a long running workload, so it is never exposed over a service, and a Knative service routes itself
 		service = generateService(options)
 	} else if len(options.KubernetesResources.Services) > 0 {
 		// If a service was provided, get the first and append the rest to others
@@ -149,24 +255,467 @@ func Generate(fs afero.Afero, gitOpsFolder string, outputFolder string, options
 		resources[serviceFileName] = service
 	}
 
-	if len(options.KubernetesResources.Others) > 0 {
+	if options.CreateServiceAccount {
+		k.AddResources(serviceAccountFileName)
+		resources[serviceAccountFileName] = generateServiceAccount(options)
+	}
+
+	if options.Autoscaling != nil && (deployment != nil || statefulSet != nil) {
+		k.AddResources(hpaFileName)
+		if deployment != nil {
+			resources[hpaFileName] = generateHorizontalPodAutoscaler(options, "Deployment", deployment.Name)
+		} else {
+			resources[hpaFileName] = generateHorizontalPodAutoscaler(options, "StatefulSet", statefulSet.Name)
+		}
+	}
+
+	if options.PodDisruptionBudget != nil {
+		k.AddResources(pdbFileName)
+		resources[pdbFileName] = generatePodDisruptionBudget(options)
+	}
+
+	if options.GenerateNetworkPolicy {
+		k.AddResources(networkPolicyFileName)
+		resources[networkPolicyFileName] = generateNetworkPolicy(options)
+	}
+
+	// The base folder is regenerated, so the files of any removed storage entries are dropped.
+	// A generated statefulset claims its storage through volume claim templates instead
+	if !storageClaimTemplates {
+		for _, storage := range options.Storage {
+			pvcFileName := fmt.Sprintf(pvcFileNameFormat, storage.Name)
+			k.AddResources(pvcFileName)
+			resources[pvcFileName] = generatePersistentVolumeClaim(options, storage)
+		}
+	}
+
+	// The passed in CRDs are always written to their own files, which are listed in the crds field too so that
+	// kustomize knows the schema of their custom resources
+	others, crdFiles, err := getCustomResourceDefinitionFiles(options.KubernetesResources.Others)
+	if err != nil {
+		return err
+	}
+	var crdFileNames []string
+	for fileName, crd := range crdFiles {
+		if err := checkPassedInResourceFile(fs, outputFolder, fileName, resources, originalKustomizeFileContent); err != nil {
+			return err
+		}
+		crdFileNames = append(crdFileNames, fileName)
+		k.AddResources(fileName)
+		k.AddCrds(fileName)
+		resources[fileName] = crd
+	}
+
+	// Each extra resource is written to its own file, named after its kind and name. Those of an unknown kind are
+	// written to the other resources file, along with all the extra resources in the single file mode
+	if !options.SingleOtherResourcesFile {
+		otherFiles, remaining, err := getOtherResourceFiles(others)
+		if err != nil {
+			return err
+		}
+		for fileName, other := range otherFiles {
+			if err := checkPassedInResourceFile(fs, outputFolder, fileName, resources, originalKustomizeFileContent); err != nil {
+				return err
+			}
+			k.AddResources(fileName)
+			resources[fileName] = other
+		}
+		others = remaining
+	}
+	if len(others) > 0 {
 		k.AddResources(otherFileName)
-		resources[otherFileName] = options.KubernetesResources.Others
+		resources[otherFileName] = others
+	}
+
+	// The resources of the previous generation that are no longer generated are removed, while those added by users
+	// are kept along with their kustomization entries
+	k.Metadata = originalKustomizeFileContent.Metadata
+	if options.PreserveUserResources {
+		generated := append([]string{}, k.Resources...)
+		previouslyGenerated := getGeneratedResources(originalKustomizeFileContent)
+		for _, resource := range originalKustomizeFileContent.Resources {
+			if containsString(generated, resource) {
+				continue
+			}
+			if !containsString(previouslyGenerated, resource) {
+				k.AddResources(resource)
+			} else if err := fs.RemoveAll(filepath.Join(outputFolder, resource)); err != nil {
+				return fmt.Errorf("failed to remove the %q resource, which is no longer generated: %v", resource, err)
+			}
+		}
+		k.Metadata = setGeneratedResources(k.Metadata, generated)
+	}
+
+	k.CommonAnnotations = mergeAnnotations(originalKustomizeFileContent.CommonAnnotations, options.CommonAnnotations)
+	k.ConfigMapGenerator = mergeConfigMapGenerators(originalKustomizeFileContent.ConfigMapGenerator, generateConfigMapGenerators(options, nil))
+	// keep the fields of the existing kustomization that the base doesn't generate
+	k.SecretGenerator = originalKustomizeFileContent.SecretGenerator
+	k.Images = originalKustomizeFileContent.Images
+	k.NamePrefix = originalKustomizeFileContent.NamePrefix
+	k.NameSuffix = originalKustomizeFileContent.NameSuffix
+	k.Namespace = originalKustomizeFileContent.Namespace
+	k.Replacements = originalKustomizeFileContent.Replacements
+	k.CommonLabels = originalKustomizeFileContent.CommonLabels
+	k.Labels = originalKustomizeFileContent.Labels
+	k.GeneratorOptions = getKustomizeGeneratorOptions(options, originalKustomizeFileContent.GeneratorOptions)
+	k.SortOptions = originalKustomizeFileContent.SortOptions
+	k.BuildMetadata = originalKustomizeFileContent.BuildMetadata
+	if options.UseKustomizeManagedByLabel {
+		k.AddBuildMetadata(managedByLabelBuildMetadata)
+	}
+	if len(options.CommonLabels) > 0 {
+		if options.UseKustomizeLabels {
+			k.Labels = mergeKustomizeLabels(k.Labels, options.CommonLabels)
+		} else {
+			k.CommonLabels = mergeAnnotations(k.CommonLabels, options.CommonLabels)
+		}
+	}
+	k.UnknownFields = originalKustomizeFileContent.UnknownFields
+	k.AddComponents(originalKustomizeFileContent.Components...)
+	k.AddComponents(options.KustomizeComponents...)
+	k.AddCrds(originalKustomizeFileContent.Crds...)
+	k.OpenAPI = originalKustomizeFileContent.OpenAPI
+
+	// The CRDs are applied before the custom resources that use them
+	sort.Strings(crdFileNames)
+	k.PrependResources(crdFileNames...)
+	if len(options.ResourceOrder) > 0 {
+		k.Resources = orderResources(k.Resources, options.ResourceOrder)
 	}
 
 	resources[kustomizeFileName] = k
 
-	_, err := yaml.WriteResources(fs, outputFolder, resources)
+	_, err = yaml.WriteResources(fs, outputFolder, resources)
 	if err != nil {
 		return err
 	}
+	if err := writeConfigMapFiles(fs, outputFolder, options); err != nil {
+		return err
+	}
+	if options.ValidateKustomize {
+		if err := validateKustomization(fs, outputFolder); err != nil {
+			return err
+		}
+	}
 
 	// Re-generate the parent kustomize file and return
 	return nil
 }
 
+// validateOptions validates the generator options before any resources are generated
+func validateOptions(options gitopsv1alpha1.GeneratorOptions) error {
+	for _, mapping := range options.SecretEnv {
+		if mapping.SecretName == "" || mapping.Key == "" || mapping.EnvName == "" {
+			return fmt.Errorf("the secret name, key and env name must all be set for secret env %q", mapping.EnvName)
+		}
+	}
+	if errs := validation.IsDNS1123Label(getContainerName(options)); len(errs) > 0 {
+		return fmt.Errorf("invalid container name %q: %s", options.ContainerName, strings.Join(errs, ", "))
+	}
+	for _, sidecar := range options.Sidecars {
+		if sidecar.Name == getContainerName(options) {
+			return fmt.Errorf("sidecar container name %q conflicts with the component's container name", sidecar.Name)
+		}
+	}
+	switch options.WorkloadType {
+	case "", gitopsv1alpha1.WorkloadTypeDeployment, gitopsv1alpha1.WorkloadTypeStatefulSet, gitopsv1alpha1.WorkloadTypeCronJob, gitopsv1alpha1.WorkloadTypeKnativeService:
+	default:
+		return fmt.Errorf("unsupported workload type %q, must be one of %q, %q, %q or %q", options.WorkloadType, gitopsv1alpha1.WorkloadTypeDeployment, gitopsv1alpha1.WorkloadTypeStatefulSet, gitopsv1alpha1.WorkloadTypeCronJob, gitopsv1alpha1.WorkloadTypeKnativeService)
+	}
+	if options.WorkloadType == gitopsv1alpha1.WorkloadTypeCronJob || options.WorkloadType == gitopsv1alpha1.WorkloadTypeKnativeService {
+		if options.Route != "" || options.IngressHost != "" || len(options.KubernetesResources.Routes) > 0 || len(options.KubernetesResources.Ingresses) > 0 {
+			return fmt.Errorf("a route or ingress cannot be generated for the %q workload type, it is not exposed over a service", options.WorkloadType)
+		}
+	}
+	if options.WorkloadType == gitopsv1alpha1.WorkloadTypeCronJob {
+		if options.Schedule == "" {
+			return fmt.Errorf("a schedule is required for the %q workload type", options.WorkloadType)
+		}
+		switch options.ConcurrencyPolicy {
+		case "", batchv1.AllowConcurrent, batchv1.ForbidConcurrent, batchv1.ReplaceConcurrent:
+		default:
+			return fmt.Errorf("unsupported concurrency policy %q, must be one of %q, %q or %q", options.ConcurrencyPolicy, batchv1.AllowConcurrent, batchv1.ForbidConcurrent, batchv1.ReplaceConcurrent)
+		}
+	}
+	if pdb := options.PodDisruptionBudget; pdb != nil && (pdb.MinAvailable == nil) == (pdb.MaxUnavailable == nil) {
+		return fmt.Errorf("exactly one of minAvailable or maxUnavailable must be set on the pod disruption budget")
+	}
+	if hpa := options.Autoscaling; hpa != nil {
+		if options.WorkloadType == gitopsv1alpha1.WorkloadTypeCronJob {
+			return fmt.Errorf("autoscaling is not supported for the %q workload type", options.WorkloadType)
+		}
+		if hpa.MaxReplicas < 1 {
+			return fmt.Errorf("the autoscaling max replicas must be at least 1, got %d", hpa.MaxReplicas)
+		}
+		if hpa.MinReplicas != nil && (*hpa.MinReplicas < 1 || *hpa.MinReplicas > hpa.MaxReplicas) {
+			return fmt.Errorf("the autoscaling min replicas must be between 1 and the max replicas %d, got %d", hpa.MaxReplicas, *hpa.MinReplicas)
+		}
+	}
+	switch options.ServiceType {
+	case "", corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		return fmt.Errorf("unsupported service type %q, must be one of %q, %q or %q", options.ServiceType, corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)
+	}
+	switch options.SessionAffinity {
+	case "", corev1.ServiceAffinityNone, corev1.ServiceAffinityClientIP:
+	default:
+		return fmt.Errorf("unsupported session affinity %q, must be one of %q or %q", options.SessionAffinity, corev1.ServiceAffinityNone, corev1.ServiceAffinityClientIP)
+	}
+	switch options.ExternalTrafficPolicy {
+	case "":
+	case corev1.ServiceExternalTrafficPolicyTypeCluster, corev1.ServiceExternalTrafficPolicyTypeLocal:
+		if options.ServiceType != corev1.ServiceTypeNodePort && options.ServiceType != corev1.ServiceTypeLoadBalancer {
+			return fmt.Errorf("an external traffic policy can only be set for the %q or %q service types", corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)
+		}
+	default:
+		return fmt.Errorf("unsupported external traffic policy %q, must be one of %q or %q", options.ExternalTrafficPolicy, corev1.ServiceExternalTrafficPolicyTypeCluster, corev1.ServiceExternalTrafficPolicyTypeLocal)
+	}
+	if options.Route != "" && options.RouteSubdomain != "" {
+		return fmt.Errorf("the route host %q and subdomain %q are mutually exclusive, only one can be set", options.Route, options.RouteSubdomain)
+	}
+	switch options.RouteWildcardPolicy {
+	case "", routev1.WildcardPolicyNone, routev1.WildcardPolicySubdomain:
+	default:
+		return fmt.Errorf("unsupported route wildcard policy %q, must be either %q or %q", options.RouteWildcardPolicy, routev1.WildcardPolicyNone, routev1.WildcardPolicySubdomain)
+	}
+	if len(options.RouteBackends) > maxRouteBackends {
+		return fmt.Errorf("a route can have at most %d backends, got %d", maxRouteBackends, len(options.RouteBackends))
+	}
+	var totalWeight int32
+	for _, backend := range options.RouteBackends {
+		if backend.ServiceName == "" {
+			return fmt.Errorf("the service name of a route backend is required")
+		}
+		if backend.Weight < 0 || backend.Weight > maxRouteBackendWeight {
+			return fmt.Errorf("the weight %d of the route backend %q must be between 0 and %d", backend.Weight, backend.ServiceName, maxRouteBackendWeight)
+		}
+		totalWeight += backend.Weight
+	}
+	if totalWeight > maxRouteTotalWeight {
+		return fmt.Errorf("the weights of the route backends add up to %d, which is more than %d", totalWeight, maxRouteTotalWeight)
+	}
+	if options.RouteAllowInsecure && options.RouteTLS != nil {
+		return fmt.Errorf("the route TLS configuration cannot be set on an insecure route")
+	}
+	if tls := options.RouteTLS; tls != nil {
+		switch tls.Termination {
+		case "", routev1.TLSTerminationEdge, routev1.TLSTerminationPassthrough, routev1.TLSTerminationReencrypt:
+		default:
+			return fmt.Errorf("unsupported route TLS termination %q, must be one of %q, %q or %q", tls.Termination, routev1.TLSTerminationEdge, routev1.TLSTerminationPassthrough, routev1.TLSTerminationReencrypt)
+		}
+		switch tls.InsecureEdgeTerminationPolicy {
+		case "", routev1.InsecureEdgeTerminationPolicyRedirect, routev1.InsecureEdgeTerminationPolicyNone:
+		case routev1.InsecureEdgeTerminationPolicyAllow:
+			if tls.Termination == routev1.TLSTerminationPassthrough {
+				return fmt.Errorf("the %q insecure edge termination policy is not supported with passthrough route termination", tls.InsecureEdgeTerminationPolicy)
+			}
+		default:
+			return fmt.Errorf("unsupported route insecure edge termination policy %q", tls.InsecureEdgeTerminationPolicy)
+		}
+		if tls.DestinationCACertificate != "" && tls.Termination != routev1.TLSTerminationReencrypt {
+			return fmt.Errorf("a route destination CA certificate is only valid with reencrypt termination")
+		}
+	}
+	if options.IngressPath != "" && !strings.HasPrefix(options.IngressPath, "/") {
+		return fmt.Errorf("the ingress path %q must start with \"/\"", options.IngressPath)
+	}
+	switch options.IngressPathType {
+	case "", networkingv1.PathTypePrefix, networkingv1.PathTypeExact:
+	case networkingv1.PathTypeImplementationSpecific:
+		if !options.AllowImplementationSpecificPathType {
+			return fmt.Errorf("the %q ingress path type is only allowed if explicitly allowed", options.IngressPathType)
+		}
+	default:
+		return fmt.Errorf("unsupported ingress path type %q, must be one of %q or %q", options.IngressPathType, networkingv1.PathTypePrefix, networkingv1.PathTypeExact)
+	}
+	if tls := options.IngressTLS; tls != nil && tls.Auto {
+		if getIngressHost(options) == "" {
+			return fmt.Errorf("an ingress or route host is required for the ingress TLS auto mode")
+		}
+		if len(tls.Hosts) > 0 {
+			return fmt.Errorf("the ingress TLS hosts cannot be set in auto mode, the ingress host is used")
+		}
+	}
+	configMapNames := make(map[string]bool)
+	for _, configMap := range options.ConfigMaps {
+		if errs := validation.IsDNS1123Subdomain(configMap.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid ConfigMap name %q: %s", configMap.Name, strings.Join(errs, ", "))
+		}
+		if configMapNames[configMap.Name] {
+			return fmt.Errorf("the %q ConfigMap is set more than once", configMap.Name)
+		}
+		configMapNames[configMap.Name] = true
+		for key := range configMap.Literals {
+			if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+				return fmt.Errorf("invalid key %q in the %q ConfigMap: %s", key, configMap.Name, strings.Join(errs, ", "))
+			}
+		}
+		for fileName := range configMap.Files {
+			if errs := validation.IsConfigMapKey(fileName); len(errs) > 0 {
+				return fmt.Errorf("invalid file name %q in the %q ConfigMap: %s", fileName, configMap.Name, strings.Join(errs, ", "))
+			}
+			if _, ok := configMap.Literals[fileName]; ok {
+				return fmt.Errorf("the key %q is set as both a literal and a file in the %q ConfigMap", fileName, configMap.Name)
+			}
+			if fileName == kustomizeFileName {
+				return fmt.Errorf("the %q file name of the %q ConfigMap is reserved for the kustomization", fileName, configMap.Name)
+			}
+		}
+	}
+	if options.BaseOverlay != "" && (filepath.IsAbs(options.BaseOverlay) || filepath.Clean(options.BaseOverlay) == ".") {
+		return fmt.Errorf("the base overlay %q must be a path relative to the overlays", options.BaseOverlay)
+	}
+	for _, targetedPatch := range options.OverlayTargetedPatches {
+		if (targetedPatch.Patch == "") == (targetedPatch.Path == "") {
+			return fmt.Errorf("exactly one of the patch or the path of the patch targeting %q must be set", targetedPatch.Target.Kind)
+		}
+		if targetedPatch.Target.Kind == "" {
+			return fmt.Errorf("the kind of the target of a patch is required")
+		}
+	}
+	for _, replacement := range options.OverlayReplacements {
+		inline := replacement.Source != nil || len(replacement.Targets) > 0
+		if (replacement.Path == "") != inline {
+			return fmt.Errorf("exactly one of the path or the source and targets of a replacement must be set")
+		}
+		if inline && (replacement.Source == nil || len(replacement.Targets) == 0) {
+			return fmt.Errorf("both the source and the targets of a replacement must be set")
+		}
+		for _, target := range replacement.Targets {
+			if target.Select == nil || len(target.FieldPaths) == 0 {
+				return fmt.Errorf("the targets of a replacement must select resources and set their field paths")
+			}
+		}
+	}
+	secretNames := make(map[string]bool)
+	for _, secret := range options.OverlaySecretGenerators {
+		if errs := validation.IsDNS1123Subdomain(secret.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid Secret name %q: %s", secret.Name, strings.Join(errs, ", "))
+		}
+		if secretNames[secret.Name] {
+			return fmt.Errorf("the %q Secret is set more than once", secret.Name)
+		}
+		secretNames[secret.Name] = true
+		if len(secret.Literals) == 0 && len(secret.Files) == 0 && len(secret.Envs) == 0 {
+			return fmt.Errorf("the %q Secret must have at least one literal, file or env file", secret.Name)
+		}
+	}
+	if _, err := expandHostTemplate(options.HostTemplate, options, "", ""); err != nil {
+		return err
+	}
+	switch options.ExposeMode {
+	case "", gitopsv1alpha1.ExposeModeAuto, gitopsv1alpha1.ExposeModeRoute, gitopsv1alpha1.ExposeModeIngress, gitopsv1alpha1.ExposeModeBoth, gitopsv1alpha1.ExposeModeNone:
+	default:
+		return fmt.Errorf("unsupported expose mode %q, must be one of %q, %q, %q, %q or %q", options.ExposeMode, gitopsv1alpha1.ExposeModeAuto, gitopsv1alpha1.ExposeModeRoute, gitopsv1alpha1.ExposeModeIngress, gitopsv1alpha1.ExposeModeBoth, gitopsv1alpha1.ExposeModeNone)
+	}
+	if options.Headless {
+		if options.Route != "" || options.IngressHost != "" {
+			return fmt.Errorf("a route or ingress cannot be generated for a headless service")
+		}
+		if options.ServiceType != "" && options.ServiceType != corev1.ServiceTypeClusterIP {
+			return fmt.Errorf("a headless service must be of the %q service type, got %q", corev1.ServiceTypeClusterIP, options.ServiceType)
+		}
+	}
+	if options.NodePort != 0 {
+		if options.ServiceType != corev1.ServiceTypeNodePort && options.ServiceType != corev1.ServiceTypeLoadBalancer {
+			return fmt.Errorf("a node port can only be set for the %q or %q service types", corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)
+		}
+		if options.NodePort < minNodePort || options.NodePort > maxNodePort {
+			return fmt.Errorf("node port %d is outside of the range %d-%d", options.NodePort, minNodePort, maxNodePort)
+		}
+	}
+	switch options.ImagePullPolicy {
+	case "", corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+	default:
+		return fmt.Errorf("unsupported image pull policy %q, must be one of %q, %q or %q", options.ImagePullPolicy, corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever)
+	}
+	if strategy := options.DeploymentStrategy; strategy != nil {
+		switch strategy.Type {
+		case "", appsv1.RollingUpdateDeploymentStrategyType:
+		case appsv1.RecreateDeploymentStrategyType:
+			if strategy.RollingUpdate != nil {
+				return fmt.Errorf("rolling update parameters cannot be set with the %q deployment strategy", strategy.Type)
+			}
+		default:
+			return fmt.Errorf("unsupported deployment strategy %q, must be one of %q or %q", strategy.Type, appsv1.RollingUpdateDeploymentStrategyType, appsv1.RecreateDeploymentStrategyType)
+		}
+	}
+	exposedPorts := 0
+	for _, port := range options.Ports {
+		if port.ContainerPort <= 0 {
+			return fmt.Errorf("invalid container port %d", port.ContainerPort)
+		}
+		if port.Name == "" && len(options.Ports) > 1 {
+			return fmt.Errorf("all ports must be named when more than one port is set")
+		}
+		if port.TargetPortName != "" {
+			if errs := validation.IsValidPortName(port.TargetPortName); len(errs) > 0 {
+				return fmt.Errorf("invalid target port name %q: %s", port.TargetPortName, strings.Join(errs, ", "))
+			}
+		}
+		if port.Expose {
+			exposedPorts++
+		}
+	}
+	if exposedPorts > 1 {
+		return fmt.Errorf("only one port can be exposed, got %d", exposedPorts)
+	}
+	for _, probe := range []*gitopsv1alpha1.ProbeOptions{options.ReadinessProbe, options.LivenessProbe, options.StartupProbe} {
+		if err := validateProbe(probe); err != nil {
+			return err
+		}
+	}
+	for _, storage := range options.Storage {
+		if storage.Name == "" || storage.MountPath == "" {
+			return fmt.Errorf("storage entries must have a name and a mount path")
+		}
+		if _, err := resource.ParseQuantity(storage.Size); err != nil {
+			return fmt.Errorf("invalid size %q for storage %q: %v", storage.Size, storage.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateProbe validates the type and scheme of the given probe options, if set
+func validateProbe(probe *gitopsv1alpha1.ProbeOptions) error {
+	if probe == nil {
+		return nil
+	}
+	switch probe.Type {
+	case "", gitopsv1alpha1.ProbeTypeTCP, gitopsv1alpha1.ProbeTypeHTTP:
+	case gitopsv1alpha1.ProbeTypeExec:
+		if len(probe.Command) == 0 {
+			return fmt.Errorf("exec probes must have a command")
+		}
+	default:
+		return fmt.Errorf("unsupported probe type %q, must be one of %q, %q or %q", probe.Type, gitopsv1alpha1.ProbeTypeTCP, gitopsv1alpha1.ProbeTypeHTTP, gitopsv1alpha1.ProbeTypeExec)
+	}
+	if probe.Scheme != "" && probe.Scheme != corev1.URISchemeHTTP && probe.Scheme != corev1.URISchemeHTTPS {
+		return fmt.Errorf("unsupported probe scheme %q, must be one of %q or %q", probe.Scheme, corev1.URISchemeHTTP, corev1.URISchemeHTTPS)
+	}
+	return nil
+}
+
 // GenerateOverlays generates the overlays director in an existing GitOps structure
 func GenerateOverlays(fs afero.Afero, gitOpsFolder string, outputFolder string, options gitopsv1alpha1.GeneratorOptions, imageName, namespace string, componentGeneratedResources map[string][]string) error {
+	if err := validateOptions(options); err != nil {
+		return err
+	}
+	if options.CreateNamespace && namespace == "" {
+		return fmt.Errorf("unable to create the namespace of the overlays, the namespace is empty")
+	}
+
+	// The overlays are based on the base, unless they inherit from a shared overlay
+	baseResource := "../../base"
+	if options.BaseOverlay != "" {
+		baseResource = filepath.ToSlash(filepath.Clean(options.BaseOverlay))
+		baseOverlayPath := filepath.Join(outputFolder, filepath.FromSlash(baseResource))
+		isDir, err := fs.IsDir(baseOverlayPath)
+		if err != nil || !isDir {
+			return fmt.Errorf("unable to base the overlays on %q, %q is not a directory", options.BaseOverlay, baseOverlayPath)
+		}
+	}
+
 	kustomizeFileExist, err := fs.Exists(filepath.Join(outputFolder, kustomizeFileName))
 	if err != nil {
 		return err
@@ -184,6 +733,20 @@ func GenerateOverlays(fs afero.Afero, gitOpsFolder string, outputFolder string,
 		}
 	}
 
+	// The ConfigMaps generated by the base are merged into by the overlays
+	var baseKustomizeFileContent resources.Kustomization
+	baseKustomizeFilePath := filepath.Join(outputFolder, "../../base/", kustomizeFileName)
+	baseKustomizeFileExist, err := fs.Exists(baseKustomizeFilePath)
+	if err != nil {
+		return err
+	}
+	if baseKustomizeFileExist {
+		err = yaml.UnMarshalItemFromFile(fs, baseKustomizeFilePath, &baseKustomizeFileContent)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal items from %q: %v", baseKustomizeFilePath, err)
+		}
+	}
+
 	k := resources.Kustomization{
 		APIVersion: "kustomize.config.k8s.io/v1beta1",
 		Kind:       "Kustomization",
@@ -213,7 +776,32 @@ func GenerateOverlays(fs afero.Afero, gitOpsFolder string, outputFolder string,
 	if err != nil {
 		return err
 	}
-	containerName := "container-image"
+	baseCronJobFilePath := filepath.Join(outputFolder, "../../base/", cronjobFileName)
+	CronJobExist, err := fs.Exists(baseCronJobFilePath)
+	if err != nil {
+		return err
+	}
+
+	baseKnativeServiceFilePath := filepath.Join(outputFolder, "../../base/", knativeServiceFileName)
+	KnativeServiceExist, err := fs.Exists(baseKnativeServiceFilePath)
+	if err != nil {
+		return err
+	}
+	var originalKnativeServiceContent resources.KnativeService
+	var baseContainers []corev1.Container
+	containerName := getContainerName(options)
+
+	// With the kustomize images, the image is left out of the patches and set in the kustomization instead
+	patchImageName := imageName
+	if options.UseKustomizeImages {
+		patchImageName = ""
+	}
+
+	// With the namespace transformer, the namespace is left out of the patches and set in the kustomization instead
+	patchNamespace := namespace
+	if options.EnableNamespaceTransformer {
+		patchNamespace = ""
+	}
 
 	resources := make(map[string]interface{})
 	if DeploymentFileExist {
@@ -222,8 +810,9 @@ func GenerateOverlays(fs afero.Afero, gitOpsFolder string, outputFolder string,
 			return fmt.Errorf("failed to unmarshal items from %q: %v", baseDeploymentFilePath, err)
 		}
 
-		if len(originalDeploymentContent.Spec.Template.Spec.Containers) > 0 {
-			containerName = originalDeploymentContent.Spec.Template.Spec.Containers[0].Name
+		baseContainers = originalDeploymentContent.Spec.Template.Spec.Containers
+		if len(baseContainers) > 0 {
+			containerName = getPrimaryContainerName(baseContainers, containerName)
 		}
 	} else if StatefulSetExist {
 		err = yaml.UnMarshalItemFromFile(fs, baseStatefulSetFilePath, &originalStatefulSetContent)
@@ -231,15 +820,19 @@ func GenerateOverlays(fs afero.Afero, gitOpsFolder string, outputFolder string,
 			return fmt.Errorf("failed to unmarshal items from %q: %v", baseStatefulSetFilePath, err)
 		}
 
-		if len(originalStatefulSetContent.Spec.Template.Spec.Containers) > 0 {
-			containerName = originalStatefulSetContent.Spec.Template.Spec.Containers[0].Name
+		baseContainers = originalStatefulSetContent.Spec.Template.Spec.Containers
+		if len(baseContainers) > 0 {
+			containerName = getPrimaryContainerName(baseContainers, containerName)
 		}
 
-		statefulSetPatch := generateStatefulSetPatch(options, imageName, containerName, namespace)
+		statefulSetPatch := generateStatefulSetPatch(options, patchImageName, containerName, patchNamespace)
+		if options.UseKustomizeReplicas {
+			statefulSetPatch.Spec.Replicas = nil
+		}
 
 		resources[statefulsetPatchFileName] = statefulSetPatch
 
-		k.AddResources("../../base")
+		k.AddResources(baseResource)
 		k.AddPatches(statefulsetPatchFileName)
 		componentGeneratedResources[options.Name] = append(componentGeneratedResources[options.Name], statefulsetPatchFileName)
 	} else if DaemonSetExist {
@@ -248,49 +841,168 @@ func GenerateOverlays(fs afero.Afero, gitOpsFolder string, outputFolder string,
 			return fmt.Errorf("failed to unmarshal items from %q: %v", baseDaemonSetFilePath, err)
 		}
 
-		if len(originalDaemonSetContent.Spec.Template.Spec.Containers) > 0 {
-			containerName = originalDaemonSetContent.Spec.Template.Spec.Containers[0].Name
+		baseContainers = originalDaemonSetContent.Spec.Template.Spec.Containers
+		if len(baseContainers) > 0 {
+			containerName = getPrimaryContainerName(baseContainers, containerName)
 		}
 
-		daemonSetPatch := generateDaemonSetPatch(options, imageName, containerName, namespace)
+		daemonSetPatch := generateDaemonSetPatch(options, patchImageName, containerName, patchNamespace)
 
 		resources[daemonsetPatchFileName] = daemonSetPatch
 
-		k.AddResources("../../base")
+		k.AddResources(baseResource)
 		k.AddPatches(daemonsetPatchFileName)
 		componentGeneratedResources[options.Name] = append(componentGeneratedResources[options.Name], daemonsetPatchFileName)
+	} else if CronJobExist {
+		var originalCronJobContent batchv1.CronJob
+		err = yaml.UnMarshalItemFromFile(fs, baseCronJobFilePath, &originalCronJobContent)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal items from %q: %v", baseCronJobFilePath, err)
+		}
+
+		baseContainers = originalCronJobContent.Spec.JobTemplate.Spec.Template.Spec.Containers
+		if len(baseContainers) > 0 {
+			containerName = getPrimaryContainerName(baseContainers, containerName)
+		}
+
+		cronJobPatch := generateCronJobPatch(options, patchImageName, containerName, patchNamespace)
+
+		resources[cronjobPatchFileName] = cronJobPatch
+
+		k.AddResources(baseResource)
+		k.AddPatches(cronjobPatchFileName)
+		componentGeneratedResources[options.Name] = append(componentGeneratedResources[options.Name], cronjobPatchFileName)
+	} else if KnativeServiceExist {
+		err = yaml.UnMarshalItemFromFile(fs, baseKnativeServiceFilePath, &originalKnativeServiceContent)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal items from %q: %v", baseKnativeServiceFilePath, err)
+		}
+
+		baseContainers = originalKnativeServiceContent.Spec.Template.Spec.Containers
+		if len(baseContainers) > 0 {
+			containerName = getPrimaryContainerName(baseContainers, containerName)
+		}
+
+		// The Knative patch replaces the containers of the base, so it keeps the base image for kustomize to replace
+		knativePatchImageName := patchImageName
+		if options.UseKustomizeImages {
+			knativePatchImageName = getBaseImage(baseContainers, containerName, imageName)
+		}
+		knativeServicePatch := generateKnativeServicePatch(options, knativePatchImageName, containerName, patchNamespace)
+
+		resources[knativeServicePatchFileName] = knativeServicePatch
+
+		k.AddResources(baseResource)
+		k.AddPatches(knativeServicePatchFileName)
+		componentGeneratedResources[options.Name] = append(componentGeneratedResources[options.Name], knativeServicePatchFileName)
 	}
 
 	// Generate the deployment patch file
-	// If the StatefulSet or DaemonSet file exists already in the base, don't generate the patch file
-	if !StatefulSetExist && !DaemonSetExist {
-		deploymentPatch := generateDeploymentPatch(options, imageName, containerName, namespace)
+	// If the StatefulSet, DaemonSet, CronJob or Knative service file exists already in the base, don't generate the patch file
+	var deploymentPatchName string
+	if !StatefulSetExist && !DaemonSetExist && !CronJobExist && !KnativeServiceExist {
+		deploymentPatch := generateDeploymentPatch(options, patchImageName, containerName, patchNamespace)
+		if options.UseKustomizeReplicas {
+			deploymentPatch.Spec.Replicas = nil
+		}
+
+		// The command and args of the base deployment win, in case it was passed in rather than generated
+		if baseContainer := getContainer(originalDeploymentContent.Spec.Template.Spec.Containers, containerName); baseContainer != nil {
+			if len(baseContainer.Command) > 0 {
+				deploymentPatch.Spec.Template.Spec.Containers[0].Command = nil
+			}
+			if len(baseContainer.Args) > 0 {
+				deploymentPatch.Spec.Template.Spec.Containers[0].Args = nil
+			}
+		}
 
 		resources[deploymentPatchFileName] = deploymentPatch
+		deploymentPatchName = deploymentPatch.Name
 
-		k.AddResources("../../base")
+		k.AddResources(baseResource)
 		k.AddPatches(deploymentPatchFileName)
 		componentGeneratedResources[options.Name] = append(componentGeneratedResources[options.Name], deploymentPatchFileName)
 	}
+	// An autoscaled deployment or statefulset has its replicas set by the autoscaler
+	// The replicas and images entries added by users are kept, while those of the component are replaced by the
+	// generated ones, or removed when they're no longer generated
+	k.Replicas = mergeKustomizeReplicas(originalKustomizeFileContent.Replicas, options.Name)
+	if options.UseKustomizeReplicas && options.Replicas > 0 && options.Autoscaling == nil && !DaemonSetExist && !CronJobExist && !KnativeServiceExist {
+		k.Replicas = mergeKustomizeReplicas(originalKustomizeFileContent.Replicas, options.Name, generateKustomizeReplica(options.Name, options.Replicas))
+	}
+	k.Images = originalKustomizeFileContent.Images
+	if imageName != "" {
+		baseImage := getBaseImage(baseContainers, containerName, imageName)
+		k.Images = mergeKustomizeImages(originalKustomizeFileContent.Images, baseImage)
+		if options.UseKustomizeImages {
+			k.Images = mergeKustomizeImages(originalKustomizeFileContent.Images, baseImage, generateKustomizeImage(baseImage, imageName))
+		}
+	}
+	if options.PodDisruptionBudget != nil {
+		basePDBFilePath := filepath.Join(outputFolder, "../../base/", pdbFileName)
+		pdbExist, err := fs.Exists(basePDBFilePath)
+		if err != nil {
+			return err
+		}
+		if !pdbExist {
+			return fmt.Errorf("unable to patch the pod disruption budget, %q does not exist", basePDBFilePath)
+		}
+
+		resources[pdbPatchFileName] = generatePodDisruptionBudgetPatch(options, patchNamespace)
+
+		k.AddPatches(pdbPatchFileName)
+		componentGeneratedResources[options.Name] = append(componentGeneratedResources[options.Name], pdbPatchFileName)
+	}
+
 	var route *routev1.Route
 	var ingress *networkingv1.Ingress
 
 	// Create an ingress if its a Kubernetes cluster, route if its an OpenShift cluster
-	if options.IsKubernetesCluster {
-		if len(options.KubernetesResources.Ingresses) == 0 && options.TargetPort != 0 {
-			// If no Ingresses were provided and TargetPort is not 0, generate the Ingress
-			ingress = generateIngress(options)
-		} else if len(options.KubernetesResources.Ingresses) > 0 {
-			// If Ingresses were provided, get the first Ingress
-			ingress = &options.KubernetesResources.Ingresses[0]
+	// A cronjob has no service to route to, so neither is generated for it
+	// A Knative service handles its own routing
+	if CronJobExist {
+		if options.Route != "" {
+			return fmt.Errorf("a route cannot be generated for the %q workload type, it is not exposed over a service", gitopsv1alpha1.WorkloadTypeCronJob)
+		}
+	} else if KnativeServiceExist {
+		if options.Route != "" {
+			return fmt.Errorf("a route cannot be generated for the %q workload type, it is not exposed over a service", gitopsv1alpha1.WorkloadTypeKnativeService)
 		}
+	} else if options.Headless {
+		// A headless service has no cluster IP to route to
 	} else {
-		if len(options.KubernetesResources.Routes) == 0 && options.TargetPort != 0 {
-			// If no Routes were provided and TargetPort is not 0, generate the Route
-			route = generateRoute(options)
-		} else if len(options.KubernetesResources.Routes) > 0 {
-			// If Routes were provided, get the first Route
-			route = &options.KubernetesResources.Routes[0]
+		// Generate the host names from the template, unless they were explicitly set
+		if options.HostTemplate != "" {
+			host, err := expandHostTemplate(options.HostTemplate, options, filepath.Base(outputFolder), namespace)
+			if err != nil {
+				return err
+			}
+			if options.Route == "" && options.RouteSubdomain == "" {
+				options.Route = host
+			}
+			if options.Route == "" && options.IngressHost == "" {
+				options.IngressHost = host
+			}
+		}
+
+		exposeRoute, exposeIngress := getExposeResources(options)
+		if exposeIngress {
+			if len(options.KubernetesResources.Ingresses) == 0 && getExposedPort(options) != nil {
+				// If no Ingresses were provided and a port is exposed, generate the Ingress
+				ingress = generateIngress(options)
+			} else if len(options.KubernetesResources.Ingresses) > 0 {
+				// If Ingresses were provided, get the first Ingress
+				ingress = &options.KubernetesResources.Ingresses[0]
+			}
+		}
+		if exposeRoute {
+			if len(options.KubernetesResources.Routes) == 0 && getExposedPort(options) != nil {
+				// If no Routes were provided and a port is exposed, generate the Route
+				route = generateRoute(options)
+			} else if len(options.KubernetesResources.Routes) > 0 {
+				// If Routes were provided, get the first Route
+				route = &options.KubernetesResources.Routes[0]
+			}
 		}
 	}
 
@@ -304,13 +1016,92 @@ func GenerateOverlays(fs afero.Afero, gitOpsFolder string, outputFolder string,
 		resources[routeFileName] = route
 	}
 
+	// The namespace file is removed along with its kustomization entry once the namespace is no longer created
+	if options.CreateNamespace {
+		k.AddResources(namespaceFileName)
+		resources[namespaceFileName] = generateNamespace(options, namespace)
+	} else if containsString(originalKustomizeFileContent.Resources, namespaceFileName) {
+		if err := fs.RemoveAll(filepath.Join(outputFolder, namespaceFileName)); err != nil {
+			return fmt.Errorf("failed to remove the %s file in folder %q: %v", namespaceFileName, outputFolder, err)
+		}
+	}
+
+	// keep the fields of the kustomization that aren't managed by the generator
+	k.UnknownFields = originalKustomizeFileContent.UnknownFields
+	k.Metadata = originalKustomizeFileContent.Metadata
+	k.CommonLabels = originalKustomizeFileContent.CommonLabels
+	k.Labels = originalKustomizeFileContent.Labels
+
 	// add back custom kustomization patches
 	k.CompareDifferenceAndAddCustomPatches(originalKustomizeFileContent.Patches, componentGeneratedResources[options.Name])
 
+	// With more than one deployment patch, the generated one only targets the component's deployment
+	if deploymentPatchName != "" && countDeploymentPatches(componentGeneratedResources[options.Name]) > 1 {
+		k.SetPatchTarget(deploymentPatchFileName, generateDeploymentPatchTarget(deploymentPatchName))
+	}
+
+	k.AddTargetedPatches(generateTargetedPatches(options)...)
+	k.Replacements = originalKustomizeFileContent.Replacements
+	k.AddReplacements(generateReplacements(options)...)
+
+	// keep the components added by users
+	k.AddComponents(originalKustomizeFileContent.Components...)
+	k.AddComponents(options.KustomizeComponents...)
+
+	// merge the common annotations into the existing ones, rather than dropping those added by users
+	k.CommonAnnotations = mergeAnnotations(originalKustomizeFileContent.CommonAnnotations, options.CommonAnnotations)
+
+	// keep the configMapGenerator and secretGenerator entries added by users
+	k.ConfigMapGenerator = mergeConfigMapGenerators(originalKustomizeFileContent.ConfigMapGenerator, generateConfigMapGenerators(options, baseKustomizeFileContent.ConfigMapGenerator))
+
+	k.SecretGenerator = mergeSecretGenerators(originalKustomizeFileContent.SecretGenerator, generateSecretGenerators(options))
+
+	// keep the name prefix and suffix of the existing kustomization, unless they were set
+	k.NamePrefix = originalKustomizeFileContent.NamePrefix
+	if options.OverlayNamePrefix != "" {
+		k.NamePrefix = options.OverlayNamePrefix
+	}
+	k.NameSuffix = originalKustomizeFileContent.NameSuffix
+	if options.OverlayNameSuffix != "" {
+		k.NameSuffix = options.OverlayNameSuffix
+	}
+
+	// keep the sort options and build metadata set by users
+	k.SortOptions = originalKustomizeFileContent.SortOptions
+	k.BuildMetadata = originalKustomizeFileContent.BuildMetadata
+	k.Crds = originalKustomizeFileContent.Crds
+	k.OpenAPI = originalKustomizeFileContent.OpenAPI
+
+	// keep the generator options of the existing kustomization, unless they were set
+	k.GeneratorOptions = getKustomizeGeneratorOptions(options, originalKustomizeFileContent.GeneratorOptions)
+
+	// keep the namespace of the existing kustomization, unless the namespace transformer is enabled
+	k.Namespace = originalKustomizeFileContent.Namespace
+	if options.EnableNamespaceTransformer && namespace != "" {
+		k.Namespace = namespace
+	}
+
+	// The ingress and route are resources rather than patches, so they're only recorded once the patches are added
+	if ingress != nil {
+		componentGeneratedResources[options.Name] = append(componentGeneratedResources[options.Name], ingressFileName)
+	}
+	if route != nil {
+		componentGeneratedResources[options.Name] = append(componentGeneratedResources[options.Name], routeFileName)
+	}
+
 	resources[kustomizeFileName] = k
 
 	_, err = yaml.WriteResources(fs, outputFolder, resources)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := writeConfigMapFiles(fs, outputFolder, options); err != nil {
+		return err
+	}
+	if options.ValidateKustomize {
+		return validateKustomization(fs, outputFolder)
+	}
+	return nil
 }
 
 func UpdateExistingKustomize(fs afero.Afero, outputFolder string) error {
@@ -339,16 +1130,26 @@ func UpdateExistingKustomize(fs afero.Afero, outputFolder string) error {
 	return err
 }
 
-func generateDeployment(component gitopsv1alpha1.GeneratorOptions) *appsv1.Deployment {
-	var revHistoryLimit *int32
-	if component.RevisionHistoryLimit != nil {
-		revHistoryLimit = component.RevisionHistoryLimit
+// generateNamespace returns the namespace of the overlays, with the labels of the component
+func generateNamespace(options gitopsv1alpha1.GeneratorOptions, namespace string) *corev1.Namespace {
+	return &corev1.Namespace{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Namespace",
+			APIVersion: "v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:   namespace,
+			Labels: generateK8sLabels(options),
+		},
 	}
+}
 
-	var containerImage string
-	if component.ContainerImage != "" {
-		containerImage = component.ContainerImage
+func generateDeployment(component gitopsv1alpha1.GeneratorOptions) *appsv1.Deployment {
+	var revHistoryLimit *int32
+	if component.RevisionHistoryLimit != nil {
+		revHistoryLimit = component.RevisionHistoryLimit
 	}
+
 	replicas := getReplicas(component)
 	k8sLabels := generateK8sLabels(component)
 	matchLabels := getMatchLabel(component)
@@ -358,77 +1159,472 @@ func generateDeployment(component gitopsv1alpha1.GeneratorOptions) *appsv1.Deplo
 			APIVersion: "apps/v1",
 		},
 		ObjectMeta: v1.ObjectMeta{
-			Name:      component.Name,
-			Namespace: component.Namespace,
-			Labels:    k8sLabels,
+			Name:        component.Name,
+			Namespace:   component.Namespace,
+			Labels:      k8sLabels,
+			Annotations: component.Annotations,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
 			Selector: &v1.LabelSelector{
 				MatchLabels: matchLabels,
 			},
-			Template: corev1.PodTemplateSpec{
+			Template: generatePodTemplateSpec(component),
+		},
+	}
+
+	// The autoscaler owns the replicas, so leave them out rather than have it fight with the GitOps controller
+	if component.Autoscaling != nil {
+		deployment.Spec.Replicas = nil
+	}
+
+	if revHistoryLimit != nil {
+		deployment.Spec.RevisionHistoryLimit = revHistoryLimit
+	}
+
+	if component.MinReadySeconds != nil {
+		deployment.Spec.MinReadySeconds = *component.MinReadySeconds
+	}
+
+	if component.ProgressDeadlineSeconds != nil {
+		deployment.Spec.ProgressDeadlineSeconds = component.ProgressDeadlineSeconds
+	}
+
+	if component.DeploymentStrategy != nil {
+		deployment.Spec.Strategy = *component.DeploymentStrategy
+	}
+
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, generateStorageVolumes(component)...)
+
+	return &deployment
+}
+
+func generateStatefulSet(component gitopsv1alpha1.GeneratorOptions) *appsv1.StatefulSet {
+	replicas := getReplicas(component)
+	k8sLabels := generateK8sLabels(component)
+	matchLabels := getMatchLabel(component)
+	statefulSet := appsv1.StatefulSet{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        component.Name,
+			Namespace:   component.Namespace,
+			Labels:      k8sLabels,
+			Annotations: component.Annotations,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &v1.LabelSelector{
+				MatchLabels: matchLabels,
+			},
+			// The generated service is named after the component
+			ServiceName:          component.Name,
+			Template:             generatePodTemplateSpec(component),
+			RevisionHistoryLimit: component.RevisionHistoryLimit,
+		},
+	}
+
+	if component.Autoscaling != nil {
+		statefulSet.Spec.Replicas = nil
+	}
+
+	// Each pod gets its own claim from the templates, rather than sharing the generated persistent volume claims
+	for _, storage := range component.Storage {
+		pvc := generatePersistentVolumeClaim(component, storage)
+		statefulSet.Spec.VolumeClaimTemplates = append(statefulSet.Spec.VolumeClaimTemplates, corev1.PersistentVolumeClaim{
+			ObjectMeta: v1.ObjectMeta{
+				Name: storage.Name,
+			},
+			Spec: pvc.Spec,
+		})
+	}
+
+	return &statefulSet
+}
+
+func generateCronJob(component gitopsv1alpha1.GeneratorOptions) *batchv1.CronJob {
+	k8sLabels := generateK8sLabels(component)
+	template := generatePodTemplateSpec(component)
+	// Jobs run to completion, so the container is restarted only on failure, and only explicitly configured probes are kept
+	template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+	if component.ReadinessProbe == nil {
+		template.Spec.Containers[0].ReadinessProbe = nil
+	}
+	if component.LivenessProbe == nil {
+		template.Spec.Containers[0].LivenessProbe = nil
+	}
+	template.Spec.Volumes = append(template.Spec.Volumes, generateStorageVolumes(component)...)
+
+	cronJob := batchv1.CronJob{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "CronJob",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        component.Name,
+			Namespace:   component.Namespace,
+			Labels:      k8sLabels,
+			Annotations: component.Annotations,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   component.Schedule,
+			ConcurrencyPolicy:          component.ConcurrencyPolicy,
+			SuccessfulJobsHistoryLimit: component.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     component.FailedJobsHistoryLimit,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: template,
+				},
+			},
+		},
+	}
+
+	return &cronJob
+}
+
+func generateKnativeService(component gitopsv1alpha1.GeneratorOptions) *resources.KnativeService {
+	k8sLabels := generateK8sLabels(component)
+	knativeService := resources.KnativeService{
+		TypeMeta: v1.TypeMeta{
+			Kind:       resources.KnativeServiceKind,
+			APIVersion: resources.KnativeServiceAPIVersion,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        component.Name,
+			Namespace:   component.Namespace,
+			Labels:      k8sLabels,
+			Annotations: component.Annotations,
+		},
+		Spec: resources.KnativeServiceSpec{
+			Template: resources.KnativeRevisionTemplateSpec{
 				ObjectMeta: v1.ObjectMeta{
-					Labels: matchLabels,
+					Annotations: getKnativeRevisionAnnotations(component),
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
-						{
-							Name:            "container-image",
-							Image:           containerImage,
-							ImagePullPolicy: corev1.PullAlways,
-							Env:             component.BaseEnvVar,
-							Resources:       component.Resources,
-						},
+						generateKnativeContainer(component, getContainerName(component), component.ContainerImage, component.Resources),
 					},
 				},
 			},
 		},
 	}
 
-	// If a container image source was set in the component *and* a given secret was set for it,
-	// Set the secret as an image pull secret, in case the component references a private image component
 	if component.ContainerImage != "" && component.Secret != "" {
-		deployment.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{
+		knativeService.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{
 			{
 				Name: component.Secret,
 			},
 		}
 	}
 
-	// Set fields that may have been optionally configured by the component CR
-	if component.TargetPort != 0 {
-		deployment.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{
+	return &knativeService
+}
+
+// generateKnativeContainer returns the container of a Knative service. Knative only routes to a single port, so
+// only the target port is set
+func generateKnativeContainer(component gitopsv1alpha1.GeneratorOptions, containerName, image string, resourceRequirements corev1.ResourceRequirements) corev1.Container {
+	container := corev1.Container{
+		Name:      containerName,
+		Image:     image,
+		Env:       getBaseEnv(component),
+		EnvFrom:   mergeEnvFrom(getBaseEnvFrom(component), nil),
+		Resources: resourceRequirements,
+	}
+	if targetPort := getTargetPort(component); targetPort != 0 {
+		container.Ports = []corev1.ContainerPort{
 			{
-				ContainerPort: int32(component.TargetPort),
+				ContainerPort: int32(targetPort),
 			},
 		}
-		deployment.Spec.Template.Spec.Containers[0].ReadinessProbe = &corev1.Probe{
-			InitialDelaySeconds: 10,
-			PeriodSeconds:       10,
-			ProbeHandler: corev1.ProbeHandler{
-				TCPSocket: &corev1.TCPSocketAction{
-					Port: intstr.FromInt(component.TargetPort),
+	}
+	return container
+}
+
+// getKnativeRevisionAnnotations returns the pod annotations, along with the min and max scale of the Knative service if autoscaling is set
+func getKnativeRevisionAnnotations(component gitopsv1alpha1.GeneratorOptions) map[string]string {
+	if component.Autoscaling == nil {
+		return component.PodAnnotations
+	}
+	annotations := make(map[string]string)
+	for key, value := range component.PodAnnotations {
+		annotations[key] = value
+	}
+	if component.Autoscaling.MinReplicas != nil {
+		annotations[knativeMinScaleAnnotation] = strconv.Itoa(int(*component.Autoscaling.MinReplicas))
+	}
+	annotations[knativeMaxScaleAnnotation] = strconv.Itoa(int(component.Autoscaling.MaxReplicas))
+	return annotations
+}
+
+// getOtherResourceFiles returns the extra resources by file name, named after their kind and name. The resources whose
+// kind or name can't be determined are returned as the remaining resources
+func getOtherResourceFiles(others []interface{}) (map[string]interface{}, []interface{}, error) {
+	files := make(map[string]interface{})
+	var remaining []interface{}
+	for _, other := range others {
+		kind, name := getResourceKindAndName(other)
+		if kind == "" || name == "" {
+			remaining = append(remaining, other)
+			continue
+		}
+		fileName := fmt.Sprintf(otherResourceFileNameFormat, strings.ToLower(kind), name)
+		if _, ok := files[fileName]; ok {
+			return nil, nil, fmt.Errorf("the %s %q is passed in more than once", kind, name)
+		}
+		files[fileName] = other
+	}
+	return files, remaining, nil
+}
+
+// orderResources returns the resources with those listed in the order first, in the given order, followed by the others
+func orderResources(existing []string, order []string) []string {
+	var k resources.Kustomization
+	for _, resource := range order {
+		if containsString(existing, resource) {
+			k.AddResourcesOrdered(resource)
+		}
+	}
+	k.AddResourcesOrdered(existing...)
+	return k.Resources
+}
+
+// getCustomResourceDefinitionFiles returns the passed in CRDs by the name of their file, along with the other resources
+func getCustomResourceDefinitionFiles(others []interface{}) ([]interface{}, map[string]interface{}, error) {
+	files := make(map[string]interface{})
+	var remaining []interface{}
+	for _, other := range others {
+		kind, name := getResourceKindAndName(other)
+		if kind != "CustomResourceDefinition" || name == "" {
+			remaining = append(remaining, other)
+			continue
+		}
+		fileName := fmt.Sprintf(crdFileNameFormat, name)
+		if _, ok := files[fileName]; ok {
+			return nil, nil, fmt.Errorf("the %s %q is passed in more than once", kind, name)
+		}
+		files[fileName] = other
+	}
+	return remaining, files, nil
+}
+
+// checkPassedInResourceFile checks that the file of a passed in resource doesn't conflict with a generated file, and
+// that only the files of the existing kustomization are overwritten, as the others were added by users
+func checkPassedInResourceFile(fs afero.Afero, outputFolder string, fileName string, generated map[string]interface{}, original resources.Kustomization) error {
+	if _, ok := generated[fileName]; ok {
+		return fmt.Errorf("the %q file of a passed in resource conflicts with a generated file", fileName)
+	}
+	fileExist, err := fs.Exists(filepath.Join(outputFolder, fileName))
+	if err != nil {
+		return err
+	}
+	if fileExist && !containsString(original.Resources, fileName) {
+		return fmt.Errorf("unable to write a passed in resource to %q, the file already exists in %q and isn't part of its kustomization", fileName, outputFolder)
+	}
+	return nil
+}
+
+// getResourceKindAndName returns the kind and name of the resource. The kind of the typed resources passed in is
+// known even if their type meta isn't set
+func getResourceKindAndName(resource interface{}) (string, string) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return "", ""
+	}
+	var object struct {
+		v1.TypeMeta `json:",inline"`
+		Metadata    v1.ObjectMeta `json:"metadata,omitempty"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return "", ""
+	}
+	kind := object.Kind
+	if kind == "" {
+		switch resource.(type) {
+		case appsv1.Deployment:
+			kind = "Deployment"
+		case appsv1.StatefulSet:
+			kind = "StatefulSet"
+		case appsv1.DaemonSet:
+			kind = "DaemonSet"
+		case corev1.Service:
+			kind = "Service"
+		case routev1.Route:
+			kind = "Route"
+		case networkingv1.Ingress:
+			kind = "Ingress"
+		}
+	}
+	return kind, object.Metadata.Name
+}
+
+// getGeneratedResources returns the resources of the kustomization owned by the generator
+func getGeneratedResources(k resources.Kustomization) []string {
+	if k.Metadata == nil || k.Metadata.Annotations[generatedResourcesAnnotation] == "" {
+		return nil
+	}
+	return strings.Split(k.Metadata.Annotations[generatedResourcesAnnotation], ",")
+}
+
+// setGeneratedResources returns the kustomization metadata with the resources owned by the generator
+func setGeneratedResources(metadata *resources.ObjectMeta, generated []string) *resources.ObjectMeta {
+	updated := &resources.ObjectMeta{}
+	if metadata != nil {
+		*updated = *metadata
+	}
+	updated.Annotations = mergeAnnotations(updated.Annotations, map[string]string{
+		generatedResourcesAnnotation: strings.Join(generated, ","),
+	})
+	return updated
+}
+
+// containsString returns true if the string is one of the given strings
+func containsString(s []string, value string) bool {
+	for _, v := range s {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKnativeService returns true if one of the given resources is a Knative service
+func hasKnativeService(others []interface{}) bool {
+	for _, other := range others {
+		data, err := json.Marshal(other)
+		if err != nil {
+			continue
+		}
+		var typeMeta v1.TypeMeta
+		if err := json.Unmarshal(data, &typeMeta); err != nil {
+			continue
+		}
+		if typeMeta.APIVersion == resources.KnativeServiceAPIVersion && typeMeta.Kind == resources.KnativeServiceKind {
+			return true
+		}
+	}
+	return false
+}
+
+// generateStorageVolumes returns a volume for each storage entry of the component, backed by its generated persistent volume claim
+func generateStorageVolumes(component gitopsv1alpha1.GeneratorOptions) []corev1.Volume {
+	var volumes []corev1.Volume
+	for _, storage := range component.Storage {
+		volumes = append(volumes, corev1.Volume{
+			Name: storage.Name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: storage.Name,
 				},
 			},
-		}
-		deployment.Spec.Template.Spec.Containers[0].LivenessProbe = &corev1.Probe{
-			InitialDelaySeconds: 10,
-			PeriodSeconds:       10,
-			ProbeHandler: corev1.ProbeHandler{
-				HTTPGet: &corev1.HTTPGetAction{
-					Port: intstr.FromInt(component.TargetPort),
-					Path: "/",
+		})
+	}
+	return volumes
+}
+
+// generatePodTemplateSpec returns the pod template shared by the generated workloads. It runs the component's
+// container, followed by any sidecars
+func generatePodTemplateSpec(component gitopsv1alpha1.GeneratorOptions) corev1.PodTemplateSpec {
+	var containerImage string
+	if component.ContainerImage != "" {
+		containerImage = component.ContainerImage
+	}
+	imagePullPolicy := corev1.PullAlways
+	if component.ImagePullPolicy != "" {
+		imagePullPolicy = component.ImagePullPolicy
+	}
+	matchLabels := getMatchLabel(component)
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:      matchLabels,
+			Annotations: component.PodAnnotations,
+		},
+		Spec: corev1.PodSpec{
+			TerminationGracePeriodSeconds: component.TerminationGracePeriodSeconds,
+			InitContainers:                component.InitContainers,
+			Containers: []corev1.Container{
+				{
+					Name:            getContainerName(component),
+					Image:           containerImage,
+					ImagePullPolicy: imagePullPolicy,
+					Command:         component.Command,
+					Args:            component.Args,
+					Lifecycle:       component.Lifecycle,
+					Env:             getBaseEnv(component),
+					EnvFrom:         mergeEnvFrom(getBaseEnvFrom(component), nil),
+					Resources:       getResources(component),
 				},
 			},
+		},
+	}
+
+	// If a container image source was set in the component *and* a given secret was set for it,
+	// Set the secret as an image pull secret, in case the component references a private image component
+	if component.ContainerImage != "" && component.Secret != "" {
+		template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{
+			{
+				Name: component.Secret,
+			},
 		}
 	}
 
-	if revHistoryLimit != nil {
-		deployment.Spec.RevisionHistoryLimit = revHistoryLimit
+	if serviceAccountName := getServiceAccountName(component); serviceAccountName != "" {
+		template.Spec.ServiceAccountName = serviceAccountName
 	}
 
-	return &deployment
+	// Set fields that may have been optionally configured by the component CR
+	container := &template.Spec.Containers[0]
+	for _, port := range getPorts(component) {
+		container.Ports = append(container.Ports, corev1.ContainerPort{
+			Name:          getContainerPortName(port),
+			ContainerPort: int32(port.ContainerPort),
+			Protocol:      port.Protocol,
+		})
+	}
+	targetPort := getTargetPort(component)
+	if !component.DisableProbes {
+		if isProbeGenerated(component.ReadinessProbe, targetPort) {
+			container.ReadinessProbe = generateProbe(component.ReadinessProbe, gitopsv1alpha1.ProbeTypeTCP, targetPort)
+		}
+		if isProbeGenerated(component.LivenessProbe, targetPort) {
+			container.LivenessProbe = generateProbe(component.LivenessProbe, gitopsv1alpha1.ProbeTypeHTTP, targetPort)
+		}
+		if component.StartupProbe != nil && isProbeGenerated(component.StartupProbe, targetPort) {
+			container.StartupProbe = generateProbe(component.StartupProbe, gitopsv1alpha1.ProbeTypeTCP, targetPort)
+		}
+	}
+
+	for _, storage := range component.Storage {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      storage.Name,
+			MountPath: storage.MountPath,
+		})
+	}
+
+	if component.ContainerSecurityContext != nil {
+		container.SecurityContext = component.ContainerSecurityContext
+	}
+
+	// Sidecars always go after the component's container, so that it stays the primary container
+	template.Spec.Containers = append(template.Spec.Containers, component.Sidecars...)
+
+	setSchedulingConstraints(&template.Spec, component)
+
+	if component.RuntimeClassName != "" {
+		runtimeClassName := component.RuntimeClassName
+		template.Spec.RuntimeClassName = &runtimeClassName
+	}
+
+	template.Spec.HostAliases = component.HostAliases
+	template.Spec.DNSPolicy = component.DNSPolicy
+	template.Spec.DNSConfig = component.DNSConfig
+
+	if component.PodSecurityContext != nil {
+		template.Spec.SecurityContext = component.PodSecurityContext
+	}
+
+	return template
 }
 
 func generateDeploymentPatch(options gitopsv1alpha1.GeneratorOptions, imageName, containerName, namespace string) *appsv1.Deployment {
@@ -457,37 +1653,87 @@ func generateDeploymentPatch(options gitopsv1alpha1.GeneratorOptions, imageName,
 		},
 	}
 
-	for _, env := range options.BaseEnvVar {
-		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
-			Name:  env.Name,
-			Value: env.Value,
-		})
+	// Only the primary container is patched. The containers are merged by name, so any sidecars
+	// in the base deployment are left untouched
+	container := &deployment.Spec.Template.Spec.Containers[0]
+
+	for _, env := range getBaseEnv(options) {
+		container.Env = append(container.Env, env)
 	}
 
 	// only add the environment env configurations, if a deployment/binding env is not present with the same env name
 	for _, env := range options.OverlayEnvVar {
-		isPresent := false
-		for _, deploymentEnv := range deployment.Spec.Template.Spec.Containers[0].Env {
-			if deploymentEnv.Name == env.Name {
-				isPresent = true
-				break
-			}
+		if !isEnvVarPresent(container.Env, env.Name) {
+			container.Env = append(container.Env, env)
 		}
+	}
 
-		if !isPresent {
-			deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
-				Name:  env.Name,
-				Value: env.Value,
-			})
+	container.EnvFrom = mergeEnvFrom(getBaseEnvFrom(options), options.OverlayEnvFrom)
+
+	// carry the init containers through so that the environment env configurations are applied to them as well
+	for _, initContainer := range options.InitContainers {
+		var env []corev1.EnvVar
+		env = append(env, initContainer.Env...)
+		for _, overlayEnv := range options.OverlayEnvVar {
+			if !isEnvVarPresent(env, overlayEnv.Name) {
+				env = append(env, overlayEnv)
+			}
 		}
+		deployment.Spec.Template.Spec.InitContainers = append(deployment.Spec.Template.Spec.InitContainers, corev1.Container{
+			Name:  initContainer.Name,
+			Image: initContainer.Image,
+			Env:   env,
+		})
 	}
 
-	if options.Replicas > 0 {
+	if options.Replicas > 0 && options.Autoscaling == nil {
 		replica := int32(options.Replicas)
 		deployment.Spec.Replicas = &replica
 	}
 
-	deployment.Spec.Template.Spec.Containers[0].Resources = options.Resources
+	if options.MinReadySeconds != nil {
+		deployment.Spec.MinReadySeconds = *options.MinReadySeconds
+	}
+
+	if options.ProgressDeadlineSeconds != nil {
+		deployment.Spec.ProgressDeadlineSeconds = options.ProgressDeadlineSeconds
+	}
+
+	if options.OverlayRevisionHistoryLimit != nil {
+		deployment.Spec.RevisionHistoryLimit = options.OverlayRevisionHistoryLimit
+	}
+
+	deployment.Spec.Paused = options.Paused
+
+	container.Resources = mergeResources(options.Resources, options.OverlayResources)
+	container.ImagePullPolicy = options.ImagePullPolicy
+	container.Command = options.Command
+	container.Args = options.Args
+	container.Lifecycle = options.OverlayLifecycle
+	deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = options.OverlayTerminationGracePeriodSeconds
+
+	// only patch the probes that were configured, so that the overlays don't revert them to the defaults
+	targetPort := getTargetPort(options)
+	if !options.DisableProbes {
+		if options.ReadinessProbe != nil && isProbeGenerated(options.ReadinessProbe, targetPort) {
+			container.ReadinessProbe = generateProbe(options.ReadinessProbe, gitopsv1alpha1.ProbeTypeTCP, targetPort)
+		}
+		if options.LivenessProbe != nil && isProbeGenerated(options.LivenessProbe, targetPort) {
+			container.LivenessProbe = generateProbe(options.LivenessProbe, gitopsv1alpha1.ProbeTypeHTTP, targetPort)
+		}
+		if options.StartupProbe != nil && isProbeGenerated(options.StartupProbe, targetPort) {
+			container.StartupProbe = generateProbe(options.StartupProbe, gitopsv1alpha1.ProbeTypeTCP, targetPort)
+		}
+	}
+
+	setSchedulingConstraints(&deployment.Spec.Template.Spec, options)
+
+	if options.PodSecurityContext != nil {
+		deployment.Spec.Template.Spec.SecurityContext = options.PodSecurityContext
+	}
+	if options.ContainerSecurityContext != nil {
+		container.SecurityContext = options.ContainerSecurityContext
+	}
 
 	return &deployment
 }
@@ -518,11 +1764,8 @@ func generateStatefulSetPatch(options gitopsv1alpha1.GeneratorOptions, imageName
 		},
 	}
 
-	for _, env := range options.BaseEnvVar {
-		statefulSet.Spec.Template.Spec.Containers[0].Env = append(statefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
-			Name:  env.Name,
-			Value: env.Value,
-		})
+	for _, env := range getBaseEnv(options) {
+		statefulSet.Spec.Template.Spec.Containers[0].Env = append(statefulSet.Spec.Template.Spec.Containers[0].Env, env)
 	}
 
 	// only add the environment env configurations, if a deployment/binding env is not present with the same env name
@@ -536,10 +1779,7 @@ func generateStatefulSetPatch(options gitopsv1alpha1.GeneratorOptions, imageName
 		}
 
 		if !isPresent {
-			statefulSet.Spec.Template.Spec.Containers[0].Env = append(statefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
-				Name:  env.Name,
-				Value: env.Value,
-			})
+			statefulSet.Spec.Template.Spec.Containers[0].Env = append(statefulSet.Spec.Template.Spec.Containers[0].Env, env)
 		}
 	}
 
@@ -548,30 +1788,33 @@ func generateStatefulSetPatch(options gitopsv1alpha1.GeneratorOptions, imageName
 		statefulSet.Spec.Replicas = &replica
 	}
 
-	statefulSet.Spec.Template.Spec.Containers[0].Resources = options.Resources
+	statefulSet.Spec.Template.Spec.Containers[0].Resources = mergeResources(options.Resources, options.OverlayResources)
 
 	return &statefulSet
 }
 
-func generateDaemonSetPatch(options gitopsv1alpha1.GeneratorOptions, imageName, containerName, namespace string) *appsv1.DaemonSet {
+func generateCronJobPatch(options gitopsv1alpha1.GeneratorOptions, imageName, containerName, namespace string) *batchv1.CronJob {
 
-	daemonSet := appsv1.DaemonSet{
+	cronJob := batchv1.CronJob{
 		TypeMeta: v1.TypeMeta{
-			Kind:       "DaemonSet",
-			APIVersion: "apps/v1",
+			Kind:       "CronJob",
+			APIVersion: "batch/v1",
 		},
 		ObjectMeta: v1.ObjectMeta{
 			Name:      options.Name,
 			Namespace: namespace,
 		},
-		Spec: appsv1.DaemonSetSpec{
-			Selector: &v1.LabelSelector{},
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:  containerName,
-							Image: imageName,
+		Spec: batchv1.CronJobSpec{
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: imageName,
+								},
+							},
 						},
 					},
 				},
@@ -579,61 +1822,381 @@ func generateDaemonSetPatch(options gitopsv1alpha1.GeneratorOptions, imageName,
 		},
 	}
 
-	for _, env := range options.BaseEnvVar {
-		daemonSet.Spec.Template.Spec.Containers[0].Env = append(daemonSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
-			Name:  env.Name,
-			Value: env.Value,
-		})
+	container := &cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+
+	for _, env := range getBaseEnv(options) {
+		container.Env = append(container.Env, env)
 	}
 
-	// only add the environment env configurations, if a deployment/binding env is not present with the same env name
+	// only add the environment env configurations, if a cronjob/binding env is not present with the same env name
 	for _, env := range options.OverlayEnvVar {
-		isPresent := false
-		for _, statefulSetEnv := range daemonSet.Spec.Template.Spec.Containers[0].Env {
-			if statefulSetEnv.Name == env.Name {
-				isPresent = true
-				break
-			}
-		}
-
-		if !isPresent {
-			daemonSet.Spec.Template.Spec.Containers[0].Env = append(daemonSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
-				Name:  env.Name,
-				Value: env.Value,
-			})
+		if !isEnvVarPresent(container.Env, env.Name) {
+			container.Env = append(container.Env, env)
 		}
 	}
 
-	daemonSet.Spec.Template.Spec.Containers[0].Resources = options.Resources
+	container.EnvFrom = mergeEnvFrom(getBaseEnvFrom(options), options.OverlayEnvFrom)
 
-	return &daemonSet
+	container.Resources = mergeResources(options.Resources, options.OverlayResources)
+
+	return &cronJob
 }
 
-func generateService(options gitopsv1alpha1.GeneratorOptions) *corev1.Service {
-	k8sLabels := generateK8sLabels(options)
-	matchLabels := getMatchLabel(options)
-	service := corev1.Service{
+func generateKnativeServicePatch(options gitopsv1alpha1.GeneratorOptions, imageName, containerName, namespace string) *resources.KnativeService {
+	// Kustomize doesn't know the schema of Knative services, so the containers list is replaced rather than merged
+	// by name. The patch therefore holds the complete container, with the environment env configurations merged in
+	container := generateKnativeContainer(options, containerName, imageName, mergeResources(options.Resources, options.OverlayResources))
+	var env []corev1.EnvVar
+	env = append(env, container.Env...)
+	for _, overlayEnv := range options.OverlayEnvVar {
+		if !isEnvVarPresent(env, overlayEnv.Name) {
+			env = append(env, overlayEnv)
+		}
+	}
+	container.Env = env
+	container.EnvFrom = mergeEnvFrom(getBaseEnvFrom(options), options.OverlayEnvFrom)
+
+	knativeService := resources.KnativeService{
+		TypeMeta: v1.TypeMeta{
+			Kind:       resources.KnativeServiceKind,
+			APIVersion: resources.KnativeServiceAPIVersion,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      options.Name,
+			Namespace: namespace,
+		},
+		Spec: resources.KnativeServiceSpec{
+			Template: resources.KnativeRevisionTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						container,
+					},
+				},
+			},
+		},
+	}
+
+	return &knativeService
+}
+
+func generateDaemonSetPatch(options gitopsv1alpha1.GeneratorOptions, imageName, containerName, namespace string) *appsv1.DaemonSet {
+
+	daemonSet := appsv1.DaemonSet{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      options.Name,
+			Namespace: namespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &v1.LabelSelector{},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  containerName,
+							Image: imageName,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, env := range getBaseEnv(options) {
+		daemonSet.Spec.Template.Spec.Containers[0].Env = append(daemonSet.Spec.Template.Spec.Containers[0].Env, env)
+	}
+
+	// only add the environment env configurations, if a deployment/binding env is not present with the same env name
+	for _, env := range options.OverlayEnvVar {
+		isPresent := false
+		for _, statefulSetEnv := range daemonSet.Spec.Template.Spec.Containers[0].Env {
+			if statefulSetEnv.Name == env.Name {
+				isPresent = true
+				break
+			}
+		}
+
+		if !isPresent {
+			daemonSet.Spec.Template.Spec.Containers[0].Env = append(daemonSet.Spec.Template.Spec.Containers[0].Env, env)
+		}
+	}
+
+	daemonSet.Spec.Template.Spec.Containers[0].Resources = mergeResources(options.Resources, options.OverlayResources)
+
+	return &daemonSet
+}
+
+func generateService(options gitopsv1alpha1.GeneratorOptions) *corev1.Service {
+	k8sLabels := generateK8sLabels(options)
+	matchLabels := getMatchLabel(options)
+	service := corev1.Service{
 		TypeMeta: v1.TypeMeta{
 			APIVersion: "v1",
 			Kind:       "Service",
 		},
 		ObjectMeta: v1.ObjectMeta{
-			Name:      options.Name,
-			Namespace: options.Namespace,
-			Labels:    k8sLabels,
+			Name:        options.Name,
+			Namespace:   options.Namespace,
+			Labels:      k8sLabels,
+			Annotations: mergeAnnotations(options.Annotations, options.ServiceAnnotations),
 		},
 		Spec: corev1.ServiceSpec{
-			Selector: matchLabels,
-			Ports: []corev1.ServicePort{
-				{
-					Port:       int32(options.TargetPort),
-					TargetPort: intstr.FromInt(options.TargetPort),
+			Selector:              matchLabels,
+			Type:                  options.ServiceType,
+			SessionAffinity:       options.SessionAffinity,
+			ExternalTrafficPolicy: options.ExternalTrafficPolicy,
+		},
+	}
+
+	if options.Headless {
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+
+	targetPort := getTargetPort(options)
+	for _, port := range getPorts(options) {
+		servicePort := corev1.ServicePort{
+			Name:        port.Name,
+			Protocol:    port.Protocol,
+			AppProtocol: port.AppProtocol,
+			Port:        int32(port.ContainerPort),
+			TargetPort:  intstr.FromInt(port.ContainerPort),
+		}
+		// The container port carries the target port name, so the service can reference it by name
+		if port.TargetPortName != "" {
+			servicePort.TargetPort = intstr.FromString(port.TargetPortName)
+		}
+		if port.ContainerPort == targetPort {
+			servicePort.NodePort = options.NodePort
+		}
+		service.Spec.Ports = append(service.Spec.Ports, servicePort)
+	}
+
+	return &service
+}
+
+func generateServiceAccount(options gitopsv1alpha1.GeneratorOptions) *corev1.ServiceAccount {
+	k8sLabels := generateK8sLabels(options)
+	serviceAccount := corev1.ServiceAccount{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ServiceAccount",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        getServiceAccountName(options),
+			Namespace:   options.Namespace,
+			Labels:      k8sLabels,
+			Annotations: options.Annotations,
+		},
+	}
+
+	// As with the deployment, only attach the secret as an image pull secret for image components
+	if options.ContainerImage != "" && options.Secret != "" {
+		serviceAccount.ImagePullSecrets = []corev1.LocalObjectReference{
+			{
+				Name: options.Secret,
+			},
+		}
+	}
+
+	return &serviceAccount
+}
+
+func generatePodDisruptionBudget(options gitopsv1alpha1.GeneratorOptions) *policyv1.PodDisruptionBudget {
+	k8sLabels := generateK8sLabels(options)
+	matchLabels := getMatchLabel(options)
+	pdb := policyv1.PodDisruptionBudget{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "policy/v1",
+			Kind:       "PodDisruptionBudget",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        options.Name,
+			Namespace:   options.Namespace,
+			Labels:      k8sLabels,
+			Annotations: options.Annotations,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   options.PodDisruptionBudget.MinAvailable,
+			MaxUnavailable: options.PodDisruptionBudget.MaxUnavailable,
+			Selector: &v1.LabelSelector{
+				MatchLabels: matchLabels,
+			},
+		},
+	}
+
+	return &pdb
+}
+
+// generateNetworkPolicy returns a network policy that only allows ingress traffic to the component's ports, from within
+// its namespace and the allowed namespaces. If the component has no ports, all ingress traffic is denied
+func generateNetworkPolicy(options gitopsv1alpha1.GeneratorOptions) *networkingv1.NetworkPolicy {
+	k8sLabels := generateK8sLabels(options)
+	matchLabels := getMatchLabel(options)
+	networkPolicy := networkingv1.NetworkPolicy{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        options.Name,
+			Namespace:   options.Namespace,
+			Labels:      k8sLabels,
+			Annotations: options.Annotations,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: v1.LabelSelector{
+				MatchLabels: matchLabels,
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+
+	ports := getPorts(options)
+	if len(ports) == 0 {
+		return &networkPolicy
+	}
+
+	// An empty pod selector allows all pods of the namespace
+	rule := networkingv1.NetworkPolicyIngressRule{
+		From: []networkingv1.NetworkPolicyPeer{
+			{
+				PodSelector: &v1.LabelSelector{},
+			},
+		},
+	}
+	for _, namespace := range options.NetworkPolicyAllowedNamespaces {
+		rule.From = append(rule.From, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &v1.LabelSelector{
+				MatchLabels: map[string]string{
+					corev1.LabelMetadataName: namespace,
 				},
 			},
+		})
+	}
+	if len(options.NetworkPolicyAllowedNamespaceLabels) > 0 {
+		rule.From = append(rule.From, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &v1.LabelSelector{
+				MatchLabels: options.NetworkPolicyAllowedNamespaceLabels,
+			},
+		})
+	}
+	for _, port := range ports {
+		portNumber := intstr.FromInt(port.ContainerPort)
+		policyPort := networkingv1.NetworkPolicyPort{
+			Port: &portNumber,
+		}
+		if port.Protocol != "" {
+			protocol := port.Protocol
+			policyPort.Protocol = &protocol
+		}
+		rule.Ports = append(rule.Ports, policyPort)
+	}
+	networkPolicy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{rule}
+
+	return &networkPolicy
+}
+
+func generatePodDisruptionBudgetPatch(options gitopsv1alpha1.GeneratorOptions, namespace string) *policyv1.PodDisruptionBudget {
+	pdb := policyv1.PodDisruptionBudget{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "policy/v1",
+			Kind:       "PodDisruptionBudget",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      options.Name,
+			Namespace: namespace,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   options.PodDisruptionBudget.MinAvailable,
+			MaxUnavailable: options.PodDisruptionBudget.MaxUnavailable,
+		},
+	}
+
+	return &pdb
+}
+
+// generateHorizontalPodAutoscaler returns an autoscaler that scales the apps/v1 workload of the given kind and name
+func generateHorizontalPodAutoscaler(options gitopsv1alpha1.GeneratorOptions, targetKind, targetName string) *autoscalingv2.HorizontalPodAutoscaler {
+	k8sLabels := generateK8sLabels(options)
+	hpa := autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "autoscaling/v2",
+			Kind:       "HorizontalPodAutoscaler",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        options.Name,
+			Namespace:   options.Namespace,
+			Labels:      k8sLabels,
+			Annotations: options.Annotations,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       targetKind,
+				Name:       targetName,
+			},
+			MinReplicas: options.Autoscaling.MinReplicas,
+			MaxReplicas: options.Autoscaling.MaxReplicas,
 		},
 	}
 
-	return &service
+	if options.Autoscaling.CPUUtilization != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, generateResourceMetric(corev1.ResourceCPU, options.Autoscaling.CPUUtilization))
+	}
+	if options.Autoscaling.MemoryUtilization != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, generateResourceMetric(corev1.ResourceMemory, options.Autoscaling.MemoryUtilization))
+	}
+
+	return &hpa
+}
+
+func generateResourceMetric(name corev1.ResourceName, utilization *int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: utilization,
+			},
+		},
+	}
+}
+
+func generatePersistentVolumeClaim(options gitopsv1alpha1.GeneratorOptions, storage gitopsv1alpha1.ComponentStorage) *corev1.PersistentVolumeClaim {
+	k8sLabels := generateK8sLabels(options)
+	pvc := corev1.PersistentVolumeClaim{
+		TypeMeta: v1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        storage.Name,
+			Namespace:   options.Namespace,
+			Labels:      k8sLabels,
+			Annotations: options.Annotations,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteOnce,
+			},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(storage.Size),
+				},
+			},
+		},
+	}
+
+	if storage.StorageClass != "" {
+		storageClass := storage.StorageClass
+		pvc.Spec.StorageClassName = &storageClass
+	}
+
+	return &pvc
 }
 
 func generateIngress(options gitopsv1alpha1.GeneratorOptions) *networkingv1.Ingress {
@@ -641,7 +2204,14 @@ func generateIngress(options gitopsv1alpha1.GeneratorOptions) *networkingv1.Ingr
 	ingressName := options.Name
 	k8sLabels := generateK8sLabels(options)
 
-	implementationSpecific := networkingv1.PathTypeImplementationSpecific
+	path := "/"
+	if options.IngressPath != "" {
+		path = options.IngressPath
+	}
+	pathType := networkingv1.PathTypePrefix
+	if options.IngressPathType != "" {
+		pathType = options.IngressPathType
+	}
 
 	ingress := networkingv1.Ingress{
 		TypeMeta: v1.TypeMeta{
@@ -649,9 +2219,10 @@ func generateIngress(options gitopsv1alpha1.GeneratorOptions) *networkingv1.Ingr
 			APIVersion: "networking.k8s.io/v1",
 		},
 		ObjectMeta: v1.ObjectMeta{
-			Name:      ingressName,
-			Namespace: options.Namespace,
-			Labels:    k8sLabels,
+			Name:        ingressName,
+			Namespace:   options.Namespace,
+			Labels:      k8sLabels,
+			Annotations: mergeAnnotations(options.Annotations, getIngressAnnotations(options)),
 		},
 		Spec: networkingv1.IngressSpec{
 			Rules: []networkingv1.IngressRule{
@@ -660,13 +2231,13 @@ func generateIngress(options gitopsv1alpha1.GeneratorOptions) *networkingv1.Ingr
 						HTTP: &networkingv1.HTTPIngressRuleValue{
 							Paths: []networkingv1.HTTPIngressPath{
 								{
-									Path:     "/",
-									PathType: &implementationSpecific,
+									Path:     path,
+									PathType: &pathType,
 									Backend: networkingv1.IngressBackend{
 										Service: &networkingv1.IngressServiceBackend{
 											Name: options.Name,
 											Port: networkingv1.ServiceBackendPort{
-												Number: int32(options.TargetPort),
+												Number: int32(getTargetPort(options)),
 											},
 										},
 									},
@@ -679,13 +2250,113 @@ func generateIngress(options gitopsv1alpha1.GeneratorOptions) *networkingv1.Ingr
 		},
 	}
 
-	if options.Route != "" && len(ingress.Spec.Rules) > 0 {
-		ingress.Spec.Rules[0].Host = options.Route
+	if options.IngressClassName != "" && !options.LegacyIngressClassAnnotation {
+		ingressClassName := options.IngressClassName
+		ingress.Spec.IngressClassName = &ingressClassName
+	}
+
+	if host := getIngressHost(options); host != "" && len(ingress.Spec.Rules) > 0 {
+		ingress.Spec.Rules[0].Host = host
+	}
+
+	if tls := options.IngressTLS; tls != nil && (tls.SecretName != "" || tls.Auto) {
+		hosts := tls.Hosts
+		if tls.Auto {
+			hosts = []string{getIngressHost(options)}
+		}
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      hosts,
+				SecretName: tls.SecretName,
+			},
+		}
+
+		// Serve each of the TLS hosts, routing them to the same backend as the route host
+		for _, host := range hosts {
+			if !isIngressHostPresent(ingress.Spec.Rules, host) {
+				ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{
+					Host:             host,
+					IngressRuleValue: *ingress.Spec.Rules[0].IngressRuleValue.DeepCopy(),
+				})
+			}
+		}
 	}
 
 	return &ingress
 }
 
+// getIngressHost returns the host name of the ingress, which defaults to the route host name
+func getIngressHost(options gitopsv1alpha1.GeneratorOptions) string {
+	if options.IngressHost != "" {
+		return options.IngressHost
+	}
+	return options.Route
+}
+
+// hostTemplatePlaceholder matches the placeholders of a host template, such as {component}
+var hostTemplatePlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// expandHostTemplate returns the host name generated from the template for the component in the given environment
+func expandHostTemplate(template string, options gitopsv1alpha1.GeneratorOptions, environment, namespace string) (string, error) {
+	values := map[string]string{
+		"{component}":   options.Name,
+		"{application}": options.Application,
+		"{environment}": environment,
+		"{namespace}":   namespace,
+	}
+	var err error
+	host := hostTemplatePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		value, ok := values[placeholder]
+		if !ok && err == nil {
+			err = fmt.Errorf("unsupported placeholder %q in the host template %q", placeholder, template)
+		}
+		return value
+	})
+	return host, err
+}
+
+// getExposeResources returns whether a route and whether an ingress should be generated for the component
+func getExposeResources(options gitopsv1alpha1.GeneratorOptions) (bool, bool) {
+	// Without a service there's nothing to route to, so only the routes and ingresses passed in are written
+	if options.ExposeMode == gitopsv1alpha1.ExposeModeNone || (options.SkipService && len(options.KubernetesResources.Services) == 0) {
+		return len(options.KubernetesResources.Routes) > 0, len(options.KubernetesResources.Ingresses) > 0
+	}
+	switch options.ExposeMode {
+	case gitopsv1alpha1.ExposeModeRoute:
+		return true, false
+	case gitopsv1alpha1.ExposeModeIngress:
+		return false, true
+	case gitopsv1alpha1.ExposeModeBoth:
+		return true, true
+	default:
+		return !options.IsKubernetesCluster, options.IsKubernetesCluster
+	}
+}
+
+// getIngressAnnotations returns the ingress specific annotations, including the legacy ingress class annotation if requested
+func getIngressAnnotations(options gitopsv1alpha1.GeneratorOptions) map[string]string {
+	if options.IngressClassName == "" || !options.LegacyIngressClassAnnotation {
+		return options.IngressAnnotations
+	}
+	annotations := map[string]string{
+		legacyIngressClassAnnotation: options.IngressClassName,
+	}
+	for key, value := range options.IngressAnnotations {
+		annotations[key] = value
+	}
+	return annotations
+}
+
+// isIngressHostPresent returns true if one of the rules is for the given host
+func isIngressHostPresent(rules []networkingv1.IngressRule, host string) bool {
+	for _, rule := range rules {
+		if rule.Host == host {
+			return true
+		}
+	}
+	return false
+}
+
 func generateRoute(options gitopsv1alpha1.GeneratorOptions) *routev1.Route {
 
 	// If a specific Route name was passed in, use it, otherwise use the Component's name
@@ -710,18 +2381,16 @@ func generateRoute(options gitopsv1alpha1.GeneratorOptions) *routev1.Route {
 			APIVersion: "route.openshift.io/v1",
 		},
 		ObjectMeta: v1.ObjectMeta{
-			Name:      routeName,
-			Namespace: options.Namespace,
-			Labels:    k8sLabels,
+			Name:        routeName,
+			Namespace:   options.Namespace,
+			Labels:      k8sLabels,
+			Annotations: mergeAnnotations(options.Annotations, options.RouteAnnotations),
 		},
 		Spec: routev1.RouteSpec{
 			Port: &routev1.RoutePort{
-				TargetPort: intstr.FromInt(options.TargetPort),
-			},
-			TLS: &routev1.TLSConfig{
-				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
-				Termination:                   routev1.TLSTerminationEdge,
+				TargetPort: intstr.FromInt(getTargetPort(options)),
 			},
+			TLS: generateRouteTLS(options),
 			To: routev1.RouteTargetReference{
 				Kind:   "Service",
 				Name:   options.Name,
@@ -730,41 +2399,1045 @@ func generateRoute(options gitopsv1alpha1.GeneratorOptions) *routev1.Route {
 		},
 	}
 
+	if len(options.RouteBackends) > 0 {
+		route.Spec.To = generateRouteTargetReference(options.RouteBackends[0])
+		for _, backend := range options.RouteBackends[1:] {
+			route.Spec.AlternateBackends = append(route.Spec.AlternateBackends, generateRouteTargetReference(backend))
+		}
+	}
+
+	// Passthrough routes hand the encrypted traffic to the component's port as is, so they can't match on a path
+	if route.Spec.TLS != nil && route.Spec.TLS.Termination == routev1.TLSTerminationPassthrough {
+		route.Spec.Path = ""
+	}
+
 	// If the route field is set in the spec, set it to be the host for the route
 	if options.Route != "" {
 		route.Spec.Host = options.Route
 	}
+	route.Spec.Subdomain = options.RouteSubdomain
+	route.Spec.WildcardPolicy = options.RouteWildcardPolicy
 
 	return &route
 }
 
-// getReplicas returns the number of replicas to be created for the component
-// If the field is not set, it returns a default value of 1
-// ToDo: Handle as part of a defaulting webhook
-func getReplicas(options gitopsv1alpha1.GeneratorOptions) int32 {
-	if options.Replicas > 0 {
-		return int32(options.Replicas)
+// generateRouteTargetReference returns the route target sending the backend's share of the traffic to its service
+func generateRouteTargetReference(backend gitopsv1alpha1.RouteBackend) routev1.RouteTargetReference {
+	weight := backend.Weight
+	return routev1.RouteTargetReference{
+		Kind:   "Service",
+		Name:   backend.ServiceName,
+		Weight: &weight,
 	}
-	return 1
 }
 
-// generateLabels returns a map containing the following common Kubernetes labels:
-// app.kubernetes.io/name: "<component-name>"
-// app.kubernetes.io/instance: "<component-cr-name>"
-// app.kubernetes.io/part-of: "<application-name>"
-// app.kubernetes.io/managed-by: "kustomize"
-// app.kubernetes.io/created-by: "application-service"
-func generateK8sLabels(options gitopsv1alpha1.GeneratorOptions) map[string]string {
-	if options.K8sLabels != nil {
-		return options.K8sLabels
+// generateRouteTLS returns the TLS configuration of the route, defaulting to edge termination redirecting plain HTTP.
+// An insecure route has no TLS configuration
+func generateRouteTLS(options gitopsv1alpha1.GeneratorOptions) *routev1.TLSConfig {
+	if options.RouteAllowInsecure {
+		return nil
 	}
-	return map[string]string{
-		"app.kubernetes.io/name":       options.Name,
-		"app.kubernetes.io/instance":   options.Name,
-		"app.kubernetes.io/part-of":    options.Application,
-		"app.kubernetes.io/managed-by": "kustomize",
-		"app.kubernetes.io/created-by": CreatedBy,
+	tls := routev1.TLSConfig{
+		InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		Termination:                   routev1.TLSTerminationEdge,
+	}
+	if options.RouteTLS != nil {
+		if options.RouteTLS.Termination != "" {
+			tls.Termination = options.RouteTLS.Termination
+		}
+		if options.RouteTLS.InsecureEdgeTerminationPolicy != "" {
+			tls.InsecureEdgeTerminationPolicy = options.RouteTLS.InsecureEdgeTerminationPolicy
+		}
+		tls.DestinationCACertificate = options.RouteTLS.DestinationCACertificate
+	}
+	return &tls
+}
+
+// getPrimaryContainerName returns the name of the component's container in the given list. This is the container
+// with the given name if present, otherwise the first container, as any sidecars are added after it
+func getPrimaryContainerName(containers []corev1.Container, name string) string {
+	for _, container := range containers {
+		if container.Name == name {
+			return container.Name
+		}
+	}
+	return containers[0].Name
+}
+
+// getContainerName returns the name of the component's container, defaulting to defaultContainerName
+func getContainerName(options gitopsv1alpha1.GeneratorOptions) string {
+	if options.ContainerName != "" {
+		return options.ContainerName
+	}
+	return defaultContainerName
+}
+
+// countDeploymentPatches returns the number of deployment patches among the generated files
+func countDeploymentPatches(generatedFiles []string) int {
+	deploymentPatches := make(map[string]bool)
+	for _, file := range generatedFiles {
+		if strings.HasSuffix(file, deploymentPatchFileName) {
+			deploymentPatches[file] = true
+		}
+	}
+	return len(deploymentPatches)
+}
+
+// generateDeploymentPatchTarget returns the target of a deployment patch, selecting the deployment by name
+func generateDeploymentPatchTarget(name string) resources.PatchTarget {
+	return resources.PatchTarget{
+		Kind: "Deployment",
+		Name: name,
+	}
+}
+
+// generateTargetedPatches returns the kustomize patches of the component's overlays targeted patches
+func generateTargetedPatches(options gitopsv1alpha1.GeneratorOptions) []resources.Patch {
+	var patches []resources.Patch
+	for _, targetedPatch := range options.OverlayTargetedPatches {
+		patches = append(patches, resources.Patch{
+			Path:  targetedPatch.Path,
+			Patch: targetedPatch.Patch,
+			Target: &resources.PatchTarget{
+				Group:   targetedPatch.Target.Group,
+				Version: targetedPatch.Target.Version,
+				Kind:    targetedPatch.Target.Kind,
+				Name:    targetedPatch.Target.Name,
+			},
+		})
+	}
+	return patches
+}
+
+// generateReplacements returns the kustomize replacements of the overlays replacements passed in
+func generateReplacements(options gitopsv1alpha1.GeneratorOptions) []resources.Replacement {
+	var replacements []resources.Replacement
+	for _, overlayReplacement := range options.OverlayReplacements {
+		replacement := resources.Replacement{
+			Path: overlayReplacement.Path,
+		}
+		if source := overlayReplacement.Source; source != nil {
+			replacement.Source = &resources.SourceSelector{
+				Group:     source.Group,
+				Version:   source.Version,
+				Kind:      source.Kind,
+				Name:      source.Name,
+				Namespace: source.Namespace,
+				FieldPath: source.FieldPath,
+				Options:   generateFieldOptions(source.Options),
+			}
+		}
+		for _, target := range overlayReplacement.Targets {
+			targetSelector := resources.TargetSelector{
+				FieldPaths: target.FieldPaths,
+				Options:    generateFieldOptions(target.Options),
+			}
+			if target.Select != nil {
+				selector := generateSelector(*target.Select)
+				targetSelector.Select = &selector
+			}
+			for _, reject := range target.Reject {
+				targetSelector.Reject = append(targetSelector.Reject, generateSelector(reject))
+			}
+			replacement.Targets = append(replacement.Targets, targetSelector)
+		}
+		replacements = append(replacements, replacement)
+	}
+	return replacements
+}
+
+// generateSelector returns the kustomize selector of a replacement target
+func generateSelector(selector gitopsv1alpha1.ReplacementSelector) resources.Selector {
+	return resources.Selector{
+		Group:              selector.Group,
+		Version:            selector.Version,
+		Kind:               selector.Kind,
+		Name:               selector.Name,
+		Namespace:          selector.Namespace,
+		AnnotationSelector: selector.AnnotationSelector,
+		LabelSelector:      selector.LabelSelector,
+	}
+}
+
+// generateFieldOptions returns the kustomize field options of a replacement source or target
+func generateFieldOptions(options *gitopsv1alpha1.ReplacementFieldOptions) *resources.FieldOptions {
+	if options == nil {
+		return nil
+	}
+	return &resources.FieldOptions{
+		Delimiter: options.Delimiter,
+		Index:     options.Index,
+		Encoding:  options.Encoding,
+		Create:    options.Create,
+	}
+}
+
+// generateKustomizeReplica returns the kustomize replica count of the workload with the given name
+func generateKustomizeReplica(name string, replicas int) resources.Replica {
+	return resources.Replica{
+		Name:  name,
+		Count: int64(replicas),
+	}
+}
+
+// getBaseImage returns the image of the component's container in the base, which kustomize replaces with the overlays
+// image. If the base has no image, the overlays image without its tag or digest is used
+func getBaseImage(baseContainers []corev1.Container, containerName, imageName string) string {
+	if baseContainer := getContainer(baseContainers, containerName); baseContainer != nil && baseContainer.Image != "" {
+		return splitImageName(baseContainer.Image)[0]
+	}
+	return splitImageName(imageName)[0]
+}
+
+// generateKustomizeImage returns the kustomize image replacing the base image with the given image
+func generateKustomizeImage(baseImage, imageName string) resources.Image {
+	parts := splitImageName(imageName)
+	image := resources.Image{
+		Name:    baseImage,
+		NewName: parts[0],
+	}
+	if strings.HasPrefix(parts[1], "@") {
+		image.Digest = strings.TrimPrefix(parts[1], "@")
+	} else {
+		image.NewTag = strings.TrimPrefix(parts[1], ":")
+	}
+	return image
+}
+
+// splitImageName splits the image into its name and its ":tag" or "@digest" suffix, which is empty if not set
+func splitImageName(imageName string) [2]string {
+	if i := strings.Index(imageName, "@"); i != -1 {
+		return [2]string{imageName[:i], imageName[i:]}
+	}
+	// A colon before the last slash separates a registry's port, not a tag
+	if i := strings.LastIndex(imageName, ":"); i != -1 && i > strings.LastIndex(imageName, "/") {
+		return [2]string{imageName[:i], imageName[i:]}
+	}
+	return [2]string{imageName, ""}
+}
+
+// getContainer returns the container with the given name from the list, or nil if there is none
+func getContainer(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+// isEnvVarPresent returns true if an env var with the given name is in the list
+func isEnvVarPresent(envs []corev1.EnvVar, name string) bool {
+	for _, env := range envs {
+		if env.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getBaseEnv returns the env vars of the component's container, followed by the env vars sourced from secret keys
+func getBaseEnv(component gitopsv1alpha1.GeneratorOptions) []corev1.EnvVar {
+	if len(component.SecretEnv) == 0 {
+		return component.BaseEnvVar
+	}
+	var env []corev1.EnvVar
+	env = append(env, component.BaseEnvVar...)
+	for _, mapping := range component.SecretEnv {
+		env = append(env, corev1.EnvVar{
+			Name: mapping.EnvName,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: mapping.SecretName,
+					},
+					Key: mapping.Key,
+				},
+			},
+		})
+	}
+	return env
+}
+
+// mergeAnnotations returns the common annotations with the resource specific annotations added, which win on conflicts
+func mergeAnnotations(common, specific map[string]string) map[string]string {
+	if len(specific) == 0 {
+		return common
+	}
+	annotations := make(map[string]string, len(common)+len(specific))
+	for key, value := range common {
+		annotations[key] = value
+	}
+	for key, value := range specific {
+		annotations[key] = value
+	}
+	return annotations
+}
+
+// getResources returns the resources of the component's container, with the default requests filled in if requested.
+// A resource with only a limit set is left alone, as its request defaults to the limit
+func getResources(component gitopsv1alpha1.GeneratorOptions) corev1.ResourceRequirements {
+	if !component.ApplyDefaultResources {
+		return component.Resources
+	}
+	resources := *component.Resources.DeepCopy()
+	for name, quantity := range DefaultResourceRequests {
+		if _, ok := resources.Requests[name]; ok {
+			continue
+		}
+		if _, ok := resources.Limits[name]; ok {
+			continue
+		}
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		resources.Requests[name] = quantity.DeepCopy()
+	}
+	return resources
+}
+
+// mergeKustomizeLabels adds the labels to the existing kustomize labels entry that includes the pod templates but not
+// the selectors, or to a new one if there's none
+func mergeKustomizeLabels(existing []resources.Label, labels map[string]string) []resources.Label {
+	merged := append([]resources.Label{}, existing...)
+	for i := range merged {
+		if !merged[i].IncludeSelectors && merged[i].IncludeTemplates {
+			merged[i].Pairs = mergeAnnotations(merged[i].Pairs, labels)
+			return merged
+		}
+	}
+	return append(merged, resources.Label{
+		Pairs:            labels,
+		IncludeTemplates: true,
+	})
+}
+
+// mergeResources returns the base resource requirements with the overlay limits and requests applied on top,
+// key by key. Resources only set in the base, such as extended resources, are kept
+func mergeResources(base, overlay corev1.ResourceRequirements) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Limits:   mergeResourceList(base.Limits, overlay.Limits),
+		Requests: mergeResourceList(base.Requests, overlay.Requests),
+		Claims:   base.Claims,
+	}
+}
+
+func mergeResourceList(base, overlay corev1.ResourceList) corev1.ResourceList {
+	if len(overlay) == 0 {
+		return base
+	}
+	merged := make(corev1.ResourceList, len(base)+len(overlay))
+	for name, quantity := range base {
+		merged[name] = quantity
+	}
+	for name, quantity := range overlay {
+		merged[name] = quantity
+	}
+	return merged
+}
+
+// getPorts returns the ports of the component. If no ports were set, the target port is used as the only exposed port
+func getPorts(options gitopsv1alpha1.GeneratorOptions) []gitopsv1alpha1.ComponentPort {
+	if len(options.Ports) > 0 {
+		return options.Ports
+	}
+	if options.TargetPort != 0 {
+		port := gitopsv1alpha1.ComponentPort{
+			ContainerPort: options.TargetPort,
+			Expose:        true,
+		}
+		if options.NameTargetPort {
+			port.Name = defaultPortName
+		}
+		return []gitopsv1alpha1.ComponentPort{port}
+	}
+	return nil
+}
+
+// getContainerPortName returns the name of the container port, which is the target port name if set
+func getContainerPortName(port gitopsv1alpha1.ComponentPort) string {
+	if port.TargetPortName != "" {
+		return port.TargetPortName
+	}
+	return port.Name
+}
+
+// getExposedPort returns the port targeted by the route or ingress, or nil if no port is exposed
+func getExposedPort(options gitopsv1alpha1.GeneratorOptions) *gitopsv1alpha1.ComponentPort {
+	for _, port := range getPorts(options) {
+		if port.Expose {
+			return &port
+		}
+	}
+	return nil
+}
+
+// getTargetPort returns the port number that the route, ingress and default probes target. This is the exposed port,
+// or the first port if none is exposed. If there are no ports, 0 is returned
+func getTargetPort(options gitopsv1alpha1.GeneratorOptions) int {
+	if exposedPort := getExposedPort(options); exposedPort != nil {
+		return exposedPort.ContainerPort
+	}
+	if ports := getPorts(options); len(ports) > 0 {
+		return ports[0].ContainerPort
+	}
+	return 0
+}
+
+// isProbeGenerated returns true if the probe can be generated. tcp and http probes need a target port, exec probes don't
+func isProbeGenerated(probe *gitopsv1alpha1.ProbeOptions, targetPort int) bool {
+	if probe != nil && probe.Type == gitopsv1alpha1.ProbeTypeExec {
+		return true
+	}
+	return targetPort != 0
+}
+
+// generateProbe returns the probe described by the given options, using defaultType if no type was set.
+// If no options are given, the default probe is returned
+func generateProbe(options *gitopsv1alpha1.ProbeOptions, defaultType gitopsv1alpha1.ProbeType, targetPort int) *corev1.Probe {
+	if options == nil {
+		options = &gitopsv1alpha1.ProbeOptions{}
+	}
+
+	probe := corev1.Probe{
+		InitialDelaySeconds: 10,
+		PeriodSeconds:       10,
+		FailureThreshold:    options.FailureThreshold,
+	}
+	if options.InitialDelaySeconds != 0 {
+		probe.InitialDelaySeconds = options.InitialDelaySeconds
+	}
+	if options.PeriodSeconds != 0 {
+		probe.PeriodSeconds = options.PeriodSeconds
+	}
+
+	probeType := options.Type
+	if probeType == "" {
+		probeType = defaultType
+	}
+	switch probeType {
+	case gitopsv1alpha1.ProbeTypeTCP:
+		probe.TCPSocket = &corev1.TCPSocketAction{
+			Port: intstr.FromInt(targetPort),
+		}
+	case gitopsv1alpha1.ProbeTypeHTTP:
+		path := "/"
+		if options.Path != "" {
+			path = options.Path
+		}
+		probe.HTTPGet = &corev1.HTTPGetAction{
+			Port:   intstr.FromInt(targetPort),
+			Path:   path,
+			Scheme: options.Scheme,
+		}
+	case gitopsv1alpha1.ProbeTypeExec:
+		probe.Exec = &corev1.ExecAction{
+			Command: options.Command,
+		}
+	}
+
+	return &probe
+}
+
+// setSchedulingConstraints sets the node selector, tolerations, affinity, priority class and topology spread
+// constraints on the pod spec, if they were configured
+func setSchedulingConstraints(podSpec *corev1.PodSpec, options gitopsv1alpha1.GeneratorOptions) {
+	if len(options.NodeSelector) > 0 {
+		podSpec.NodeSelector = options.NodeSelector
+	}
+	if len(options.Tolerations) > 0 {
+		podSpec.Tolerations = options.Tolerations
+	}
+	if options.Affinity != nil {
+		podSpec.Affinity = options.Affinity
+	}
+	if options.PriorityClassName != "" {
+		podSpec.PriorityClassName = options.PriorityClassName
+	}
+	for _, constraint := range options.TopologySpreadConstraints {
+		// Default to the match labels, so that the constraints don't have to repeat them
+		if constraint.LabelSelector == nil || (len(constraint.LabelSelector.MatchLabels) == 0 && len(constraint.LabelSelector.MatchExpressions) == 0) {
+			constraint.LabelSelector = &v1.LabelSelector{
+				MatchLabels: getMatchLabel(options),
+			}
+		}
+		podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, constraint)
+	}
+}
+
+// getServiceAccountName returns the name of the service account to run the component as. If a service account
+// is generated without a name set, it is named after the component
+func getServiceAccountName(options gitopsv1alpha1.GeneratorOptions) string {
+	if options.ServiceAccountName == "" && options.CreateServiceAccount {
+		return options.Name
+	}
+	return options.ServiceAccountName
+}
+
+// mergeEnvFrom merges the overlay env from sources into the base ones. Overlay entries replace the base entries that
+// reference the same ConfigMap or Secret, and duplicated references are dropped, keeping the first one
+func mergeEnvFrom(base []corev1.EnvFromSource, overlay []corev1.EnvFromSource) []corev1.EnvFromSource {
+	var merged []corev1.EnvFromSource
+	indexes := make(map[string]int)
+	for _, envFrom := range base {
+		key := getEnvFromSourceKey(envFrom)
+		if _, ok := indexes[key]; !ok {
+			indexes[key] = len(merged)
+			merged = append(merged, envFrom)
+		}
+	}
+
+	overlayKeys := make(map[string]bool)
+	for _, envFrom := range overlay {
+		key := getEnvFromSourceKey(envFrom)
+		if overlayKeys[key] {
+			continue
+		}
+		overlayKeys[key] = true
+		if i, ok := indexes[key]; ok {
+			merged[i] = envFrom
+		} else {
+			indexes[key] = len(merged)
+			merged = append(merged, envFrom)
+		}
+	}
+	return merged
+}
+
+// getBaseEnvFrom returns the env from sources of the component, followed by the ConfigMaps generated for it that are
+// added to its envFrom
+func getBaseEnvFrom(component gitopsv1alpha1.GeneratorOptions) []corev1.EnvFromSource {
+	envFrom := append([]corev1.EnvFromSource{}, component.BaseEnvFrom...)
+	for _, configMap := range component.ConfigMaps {
+		if configMap.EnvFrom {
+			envFrom = append(envFrom, corev1.EnvFromSource{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: configMap.Name,
+					},
+				},
+			})
+		}
+	}
+	if len(envFrom) == 0 {
+		return nil
+	}
+	return envFrom
+}
+
+// generateConfigMapGenerators returns the kustomize configMapGenerator entries of the component's ConfigMaps. The
+// entries of ConfigMaps also generated by the given base entries merge into them
+func generateConfigMapGenerators(options gitopsv1alpha1.GeneratorOptions, base []resources.ConfigMapArgs) []resources.ConfigMapArgs {
+	baseNames := make(map[string]bool)
+	for _, generator := range base {
+		baseNames[generator.Name] = true
+	}
+
+	var generators []resources.ConfigMapArgs
+	for _, configMap := range options.ConfigMaps {
+		generator := resources.ConfigMapArgs{
+			GeneratorArgs: resources.GeneratorArgs{
+				Name: configMap.Name,
+			},
+		}
+		if baseNames[configMap.Name] {
+			generator.Behavior = "merge"
+		}
+		for _, key := range getSortedKeys(configMap.Literals) {
+			generator.Literals = append(generator.Literals, key+"="+configMap.Literals[key])
+		}
+		generator.Files = getSortedKeys(configMap.Files)
+		if configMap.DisableNameSuffixHash {
+			generator.Options = &resources.GeneratorOptions{
+				DisableNameSuffixHash: true,
+			}
+		}
+		generators = append(generators, generator)
+	}
+	return generators
+}
+
+// getKustomizeGeneratorOptions returns the generatorOptions of the kustomization, which are the existing ones unless
+// they were set
+func getKustomizeGeneratorOptions(options gitopsv1alpha1.GeneratorOptions, existing *resources.GeneratorOptions) *resources.GeneratorOptions {
+	if options.KustomizeGeneratorOptions == nil {
+		return existing
+	}
+	return &resources.GeneratorOptions{
+		Labels:                options.KustomizeGeneratorOptions.Labels,
+		Annotations:           options.KustomizeGeneratorOptions.Annotations,
+		DisableNameSuffixHash: options.KustomizeGeneratorOptions.DisableNameSuffixHash,
+	}
+}
+
+// mergeConfigMapGenerators returns the existing configMapGenerator entries, with those of the generated ConfigMaps
+// replaced and any new ones added after them
+func mergeConfigMapGenerators(existing, generated []resources.ConfigMapArgs) []resources.ConfigMapArgs {
+	indexes := make(map[string]int)
+	merged := append([]resources.ConfigMapArgs{}, existing...)
+	for i, generator := range merged {
+		indexes[generator.Name] = i
+	}
+	for _, generator := range generated {
+		if i, ok := indexes[generator.Name]; ok {
+			merged[i] = generator
+		} else {
+			merged = append(merged, generator)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// mergeKustomizeReplicas returns the existing replicas entries, with the one named name replaced by the generated
+// ones, or removed without any. Generated replicas without an existing entry are added after them
+func mergeKustomizeReplicas(existing []resources.Replica, name string, generated ...resources.Replica) []resources.Replica {
+	var merged []resources.Replica
+	for _, replica := range existing {
+		if replica.Name != name {
+			merged = append(merged, replica)
+		} else {
+			merged = append(merged, generated...)
+			generated = nil
+		}
+	}
+	return append(merged, generated...)
+}
+
+// mergeKustomizeImages returns the existing images entries, with the one named name replaced by the generated ones,
+// or removed without any. Generated images without an existing entry are added after them
+func mergeKustomizeImages(existing []resources.Image, name string, generated ...resources.Image) []resources.Image {
+	var merged []resources.Image
+	for _, image := range existing {
+		if image.Name != name {
+			merged = append(merged, image)
+		} else {
+			merged = append(merged, generated...)
+			generated = nil
+		}
+	}
+	return append(merged, generated...)
+}
+
+// generateSecretGenerators returns the kustomize secretGenerator entries of the component's overlays Secrets
+func generateSecretGenerators(options gitopsv1alpha1.GeneratorOptions) []resources.SecretArgs {
+	var generators []resources.SecretArgs
+	for _, secret := range options.OverlaySecretGenerators {
+		generator := resources.SecretArgs{
+			GeneratorArgs: resources.GeneratorArgs{
+				Name:  secret.Name,
+				Files: secret.Files,
+				Envs:  secret.Envs,
+			},
+			Type: string(secret.Type),
+		}
+		for _, key := range getSortedKeys(secret.Literals) {
+			generator.Literals = append(generator.Literals, key+"="+secret.Literals[key])
+		}
+		if secret.DisableNameSuffixHash {
+			generator.Options = &resources.GeneratorOptions{
+				DisableNameSuffixHash: true,
+			}
+		}
+		generators = append(generators, generator)
+	}
+	return generators
+}
+
+// mergeSecretGenerators returns the existing secretGenerator entries, with those of the generated Secrets replaced
+// and any new ones added after them
+func mergeSecretGenerators(existing, generated []resources.SecretArgs) []resources.SecretArgs {
+	indexes := make(map[string]int)
+	merged := append([]resources.SecretArgs{}, existing...)
+	for i, generator := range merged {
+		indexes[generator.Name] = i
+	}
+	for _, generator := range generated {
+		if i, ok := indexes[generator.Name]; ok {
+			merged[i] = generator
+		} else {
+			merged = append(merged, generator)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// writeConfigMapFiles writes the data files of the component's ConfigMaps next to the kustomization
+func writeConfigMapFiles(fs afero.Afero, outputFolder string, options gitopsv1alpha1.GeneratorOptions) error {
+	for _, configMap := range options.ConfigMaps {
+		for _, fileName := range getSortedKeys(configMap.Files) {
+			if err := fs.WriteFile(filepath.Join(outputFolder, fileName), []byte(configMap.Files[fileName]), 0644); err != nil {
+				return fmt.Errorf("failed to write the %q file of the %q ConfigMap: %v", fileName, configMap.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateKustomization builds the kustomization in the folder, along with those of the directories it references,
+// and returns a KustomizeValidationError if the build fails: a referenced file doesn't exist, a resource file or patch
+// can't be decoded, a resource is added more than once, a patch doesn't match any resource, or a transformer is
+// invalid. Remote references are left to kustomize
+func validateKustomization(fs afero.Afero, folder string) error {
+	if _, _, err := buildKustomization(fs, folder, nil, nil); err != nil {
+		return &KustomizeValidationError{path: folder, err: err}
+	}
+	return nil
+}
+
+// kustomizeResourceID identifies a resource built by a kustomization. The original name is the name of the resource
+// before the name prefixes and suffixes of the kustomizations are added to it
+type kustomizeResourceID struct {
+	group        string
+	kind         string
+	namespace    string
+	name         string
+	originalName string
+}
+
+func (id kustomizeResourceID) String() string {
+	kind := id.kind
+	if id.group != "" {
+		kind = id.kind + "." + id.group
+	}
+	if id.namespace != "" {
+		return fmt.Sprintf("%s %q in namespace %q", kind, id.name, id.namespace)
+	}
+	return fmt.Sprintf("%s %q", kind, id.name)
+}
+
+// buildKustomization returns the resources built by the kustomization in the folder, added to the input resources,
+// which are those of the parent of a kustomize component. parents are the folders of the kustomizations being built,
+// to detect cycles. The returned bool is false if the kustomization has remote references, whose resources are
+// unknown, so that patches that don't match any resource aren't reported
+func buildKustomization(fs afero.Afero, folder string, input []kustomizeResourceID, parents []string) ([]kustomizeResourceID, bool, error) {
+	folder = filepath.Clean(folder)
+	if containsString(parents, folder) {
+		return nil, false, fmt.Errorf("the kustomization in %q references itself", folder)
+	}
+	parents = append(parents, folder)
+
+	var k resources.Kustomization
+	kustomizeFilePath := filepath.Join(folder, kustomizeFileName)
+	if err := yaml.UnMarshalItemFromFile(fs, kustomizeFilePath, &k); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal items from %q: %v", kustomizeFilePath, err)
+	}
+
+	ids := append([]kustomizeResourceID{}, input...)
+	complete := true
+	add := func(added ...kustomizeResourceID) error {
+		for _, id := range added {
+			for _, existing := range ids {
+				if existing.group == id.group && existing.kind == id.kind && existing.namespace == id.namespace && existing.name == id.name {
+					return fmt.Errorf("the kustomization in %q adds %s more than once", folder, id)
+				}
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	}
+
+	var references []string
+	references = append(references, k.Resources...)
+	references = append(references, k.Bases...)
+	for _, reference := range references {
+		if isRemoteKustomizeReference(reference) {
+			complete = false
+			continue
+		}
+		referencePath := filepath.Join(folder, reference)
+		isDir, err := fs.IsDir(referencePath)
+		if err != nil {
+			return nil, false, fmt.Errorf("the kustomization in %q references %q, which doesn't exist", folder, reference)
+		}
+		var built []kustomizeResourceID
+		if isDir {
+			var builtComplete bool
+			built, builtComplete, err = buildKustomization(fs, referencePath, nil, parents)
+			if err != nil {
+				return nil, false, err
+			}
+			complete = complete && builtComplete
+		} else {
+			built, err = readKustomizeResources(fs, referencePath)
+			if err != nil {
+				return nil, false, fmt.Errorf("the kustomization in %q references %q, which isn't a valid resource file: %v", folder, reference, err)
+			}
+		}
+		if err := add(built...); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// The files of the generators must exist, and the ConfigMaps and Secrets they create are added to the resources,
+	// unless they're merged into or replace those of a base
+	var files []string
+	generators := make(map[string][]resources.GeneratorArgs)
+	for _, generator := range k.ConfigMapGenerator {
+		generators["ConfigMap"] = append(generators["ConfigMap"], generator.GeneratorArgs)
+	}
+	for _, generator := range k.SecretGenerator {
+		generators["Secret"] = append(generators["Secret"], generator.GeneratorArgs)
+	}
+	for _, kind := range []string{"ConfigMap", "Secret"} {
+		for _, generator := range generators[kind] {
+			for _, file := range generator.Files {
+				// the files of a generator can be set as key=path
+				if i := strings.Index(file, "="); i >= 0 {
+					file = file[i+1:]
+				}
+				files = append(files, file)
+			}
+			files = append(files, generator.Envs...)
+			if generator.Behavior == "merge" || generator.Behavior == "replace" {
+				continue
+			}
+			if err := add(kustomizeResourceID{kind: kind, name: generator.Name, originalName: generator.Name}); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	for _, file := range files {
+		exists, err := fs.Exists(filepath.Join(folder, file))
+		if err != nil {
+			return nil, false, err
+		}
+		if !exists {
+			return nil, false, fmt.Errorf("the kustomization in %q references %q, which doesn't exist", folder, file)
+		}
+	}
+
+	// The components are built on top of the resources of the kustomization
+	for _, component := range k.Components {
+		if isRemoteKustomizeReference(component) {
+			complete = false
+			continue
+		}
+		componentPath := filepath.Join(folder, component)
+		if isDir, err := fs.IsDir(componentPath); err != nil || !isDir {
+			return nil, false, fmt.Errorf("the kustomization in %q references %q, which doesn't exist", folder, component)
+		}
+		built, builtComplete, err := buildKustomization(fs, componentPath, ids, parents)
+		if err != nil {
+			return nil, false, err
+		}
+		ids = built
+		complete = complete && builtComplete
+	}
+
+	for _, patch := range k.Patches {
+		if err := validateKustomizePatch(fs, folder, patch, ids, complete); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := validateKustomizeTransformers(fs, folder, k.UnknownFields["transformers"]); err != nil {
+		return nil, false, err
+	}
+
+	for i := range ids[len(input):] {
+		id := &ids[len(input)+i]
+		if k.Namespace != "" {
+			id.namespace = k.Namespace
+		}
+		id.name = k.NamePrefix + id.name + k.NameSuffix
+	}
+	return ids, complete, nil
+}
+
+// readKustomizeResources returns the resources of the YAML or JSON file
+func readKustomizeResources(fs afero.Afero, path string) ([]kustomizeResourceID, error) {
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKustomizeResources(data)
+}
+
+// decodeKustomizeResources returns the resources of the YAML or JSON data, which are separated by "---". The items of
+// a List are resources too
+func decodeKustomizeResources(data []byte) ([]kustomizeResourceID, error) {
+	var ids []kustomizeResourceID
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err == io.EOF {
+			return ids, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if len(object) == 0 {
+			continue
+		}
+		resource := unstructured.Unstructured{Object: object}
+		var items []unstructured.Unstructured
+		if resource.IsList() {
+			err := resource.EachListItem(func(item runtime.Object) error {
+				items = append(items, *item.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			items = append(items, resource)
+		}
+		for _, resource := range items {
+			if resource.GetKind() == "" {
+				return nil, fmt.Errorf("a resource has no kind")
+			}
+			if resource.GetName() == "" {
+				return nil, fmt.Errorf("the %s resource has no name", resource.GetKind())
+			}
+			ids = append(ids, kustomizeResourceID{
+				group:        resource.GroupVersionKind().Group,
+				kind:         resource.GetKind(),
+				namespace:    resource.GetNamespace(),
+				name:         resource.GetName(),
+				originalName: resource.GetName(),
+			})
+		}
+	}
+}
+
+// validateKustomizePatch returns an error if the patch of the kustomization in the folder can't be decoded, or if
+// it's a strategic merge patch without a target that doesn't match the kind and name of any of the resources. The
+// namespace of the patch is left to kustomize, as is a patch of a set of resources that isn't complete
+func validateKustomizePatch(fs afero.Afero, folder string, patch resources.Patch, ids []kustomizeResourceID, complete bool) error {
+	name := patch.Path
+	content := []byte(patch.Patch)
+	if patch.Path != "" {
+		var err error
+		content, err = fs.ReadFile(filepath.Join(folder, patch.Path))
+		if err != nil {
+			return fmt.Errorf("the kustomization in %q references %q, which doesn't exist", folder, patch.Path)
+		}
+	} else {
+		name = "inline patch"
+	}
+
+	// A patch with a target is either a JSON 6902 or strategic merge patch, and may not match any resource
+	if patch.Target != nil {
+		var decoded interface{}
+		if err := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096).Decode(&decoded); err != nil && err != io.EOF {
+			return fmt.Errorf("the %q patch of the kustomization in %q isn't valid: %v", name, folder, err)
+		}
+		return nil
+	}
+
+	patched, err := decodeKustomizeResources(content)
+	if err != nil {
+		return fmt.Errorf("the %q patch of the kustomization in %q isn't valid: %v", name, folder, err)
+	}
+	if !complete {
+		return nil
+	}
+	for _, target := range patched {
+		matched := false
+		for _, id := range ids {
+			if id.kind == target.kind && (id.name == target.name || id.originalName == target.name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("the %q patch of the kustomization in %q doesn't match any resource, %s %q isn't built by the kustomization", name, folder, target.kind, target.name)
+		}
+	}
+	return nil
+}
+
+// validateKustomizeTransformers returns an error if a transformer file of the kustomization in the folder doesn't
+// exist or isn't a valid resource file. Transformer directories and remote transformers are left to kustomize
+func validateKustomizeTransformers(fs afero.Afero, folder string, transformers interface{}) error {
+	if transformers == nil {
+		return nil
+	}
+	entries, ok := transformers.([]interface{})
+	if !ok {
+		return fmt.Errorf("the transformers of the kustomization in %q aren't a list", folder)
+	}
+	for _, entry := range entries {
+		transformer, ok := entry.(string)
+		if !ok || isRemoteKustomizeReference(transformer) {
+			continue
+		}
+		transformerPath := filepath.Join(folder, transformer)
+		isDir, err := fs.IsDir(transformerPath)
+		if err != nil {
+			return fmt.Errorf("the kustomization in %q references %q, which doesn't exist", folder, transformer)
+		}
+		if isDir {
+			continue
+		}
+		if _, err := readKustomizeResources(fs, transformerPath); err != nil {
+			return fmt.Errorf("the kustomization in %q references %q, which isn't a valid transformer: %v", folder, transformer, err)
+		}
+	}
+	return nil
+}
+
+// isRemoteKustomizeReference returns true if the kustomize reference is a URL rather than a local path
+func isRemoteKustomizeReference(reference string) bool {
+	return strings.Contains(reference, "://") || strings.HasPrefix(reference, "github.com/") || strings.HasPrefix(reference, "git@")
+}
+
+// getSortedKeys returns the keys of the map in order, so that the generated files don't change between runs
+func getSortedKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getEnvFromSourceKey returns a key identifying the ConfigMap or Secret referenced by the env from source
+func getEnvFromSourceKey(envFrom corev1.EnvFromSource) string {
+	if envFrom.ConfigMapRef != nil {
+		return "configmap/" + envFrom.ConfigMapRef.Name
+	}
+	if envFrom.SecretRef != nil {
+		return "secret/" + envFrom.SecretRef.Name
+	}
+	return ""
+}
+
+// getReplicas returns the number of replicas to be created for the component
+// If the field is not set, it returns a default value of 1
+// ToDo: Handle as part of a defaulting webhook
+func getReplicas(options gitopsv1alpha1.GeneratorOptions) int32 {
+	if options.Replicas > 0 {
+		return int32(options.Replicas)
+	}
+	return 1
+}
+
+// generateLabels returns a map containing the following common Kubernetes labels:
+// app.kubernetes.io/name: "<component-name>"
+// app.kubernetes.io/instance: "<component-cr-name>"
+// app.kubernetes.io/part-of: "<application-name>"
+// app.kubernetes.io/managed-by: "kustomize", unless kustomize adds it
+// app.kubernetes.io/created-by: "application-service"
+func generateK8sLabels(options gitopsv1alpha1.GeneratorOptions) map[string]string {
+	if options.K8sLabels != nil {
+		return options.K8sLabels
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/name":       options.Name,
+		"app.kubernetes.io/instance":   options.Name,
+		"app.kubernetes.io/part-of":    options.Application,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": CreatedBy,
+	}
+	if options.UseKustomizeManagedByLabel {
+		delete(labels, "app.kubernetes.io/managed-by")
 	}
+	return labels
 }
 
 // GetMatchLabel returns the label selector that will be used to tie deployments, services, and pods together
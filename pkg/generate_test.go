@@ -16,6 +16,8 @@
 package gitops
 
 import (
+	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -32,8 +34,11 @@ import (
 	"github.com/redhat-developer/gitops-generator/pkg/util/ioutils"
 	"github.com/spf13/afero"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -67,6 +72,73 @@ func TestGenerateDeployment(t *testing.T) {
 	}
 
 	revisionHistoryLimit := int32(0)
+	minReadySeconds := int32(10)
+	progressDeadlineSeconds := int32(300)
+
+	runAsNonRoot := true
+	allowPrivilegeEscalation := false
+	podSecurityContext := corev1.PodSecurityContext{
+		RunAsNonRoot: &runAsNonRoot,
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+	containerSecurityContext := corev1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+
+	ports := []gitopsv1alpha1.ComponentPort{
+		{
+			Name:          "metrics",
+			ContainerPort: 9090,
+		},
+		{
+			Name:          "http",
+			ContainerPort: 8080,
+			Protocol:      corev1.ProtocolTCP,
+			Expose:        true,
+		},
+		{
+			Name:          "syslog",
+			ContainerPort: 5514,
+			Protocol:      corev1.ProtocolUDP,
+		},
+	}
+
+	runtimeClassName := "gvisor"
+	maxSurge := intstr.FromString("25%")
+	maxUnavailable := intstr.FromInt(0)
+
+	nodeSelector := map[string]string{
+		"kubernetes.io/arch": "arm64",
+	}
+	tolerations := []corev1.Toleration{
+		{
+			Key:      "nvidia.com/gpu",
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	}
+	affinity := corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      "node.kubernetes.io/instance-type",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"g4dn.xlarge"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
 
 	tests := []struct {
 		name           string
@@ -111,6 +183,87 @@ func TestGenerateDeployment(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Component with rollout tuning set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                    componentName,
+				Namespace:               namespace,
+				Application:             applicationName,
+				MinReadySeconds:         &minReadySeconds,
+				ProgressDeadlineSeconds: &progressDeadlineSeconds,
+			},
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					MinReadySeconds:         10,
+					ProgressDeadlineSeconds: &progressDeadlineSeconds,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with a container name set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:          componentName,
+				Namespace:     namespace,
+				Application:   applicationName,
+				ContainerName: componentName,
+			},
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            componentName,
+									ImagePullPolicy: corev1.PullAlways,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Component, optional fields set",
 			component: gitopsv1alpha1.GeneratorOptions{
@@ -298,64 +451,15 @@ func TestGenerateDeployment(t *testing.T) {
 				},
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			generatedDeployment := generateDeployment(tt.component)
-
-			if !reflect.DeepEqual(*generatedDeployment, tt.wantDeployment) {
-				t.Errorf("TestGenerateDeployment() error: expected %v got %v", tt.wantDeployment, generatedDeployment)
-			}
-		})
-	}
-}
-
-func TestGenerateDeploymentPatch(t *testing.T) {
-	componentName := "test-component"
-	namespace := "test-namespace"
-	containerName := "test-container"
-	replicas := int32(1)
-	image := "image"
-
-	tests := []struct {
-		name           string
-		component      gitopsv1alpha1.GeneratorOptions
-		containerName  string
-		imageName      string
-		namespace      string
-		wantDeployment appsv1.Deployment
-	}{
 		{
-			name: "Simple component, no optional fields set",
+			name: "Component with pod and container security contexts set",
 			component: gitopsv1alpha1.GeneratorOptions{
-				Name:     componentName,
-				Replicas: int(replicas),
-				BaseEnvVar: []corev1.EnvVar{
-					{
-						Name:  "FOO",
-						Value: "BAR",
-					},
-				},
-				Resources: corev1.ResourceRequirements{
-					Limits: corev1.ResourceList{
-						corev1.ResourceCPU: resource.MustParse("1"),
-					},
-				},
-				OverlayEnvVar: []corev1.EnvVar{
-					{
-						Name:  "FOO",
-						Value: "BAR_ENV",
-					},
-					{
-						Name:  "FOO2",
-						Value: "BAR2_ENV",
-					},
-				},
+				Name:                     componentName,
+				Namespace:                namespace,
+				Application:              applicationName,
+				PodSecurityContext:       &podSecurityContext,
+				ContainerSecurityContext: &containerSecurityContext,
 			},
-			namespace:     namespace,
-			imageName:     image,
-			containerName: containerName,
 			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
 					Kind:       "Deployment",
@@ -364,31 +468,24 @@ func TestGenerateDeploymentPatch(t *testing.T) {
 				ObjectMeta: v1.ObjectMeta{
 					Name:      componentName,
 					Namespace: namespace,
+					Labels:    k8slabels,
 				},
 				Spec: appsv1.DeploymentSpec{
 					Replicas: &replicas,
-					Selector: &v1.LabelSelector{},
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
 					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
 						Spec: corev1.PodSpec{
+							SecurityContext: &podSecurityContext,
 							Containers: []corev1.Container{
 								{
-									Name:  containerName,
-									Image: image,
-									Env: []corev1.EnvVar{
-										{
-											Name:  "FOO",
-											Value: "BAR",
-										},
-										{
-											Name:  "FOO2",
-											Value: "BAR2_ENV",
-										},
-									},
-									Resources: corev1.ResourceRequirements{
-										Limits: corev1.ResourceList{
-											corev1.ResourceCPU: resource.MustParse("1"),
-										},
-									},
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									SecurityContext: &containerSecurityContext,
 								},
 							},
 						},
@@ -396,97 +493,39 @@ func TestGenerateDeploymentPatch(t *testing.T) {
 				},
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			generatedDeployment := generateDeploymentPatch(tt.component, tt.imageName, tt.containerName, tt.namespace)
-
-			if !reflect.DeepEqual(*generatedDeployment, tt.wantDeployment) {
-				t.Errorf("TestGenerateDeploymentPatch() error: expected %v got %v", tt.wantDeployment, *generatedDeployment)
-			}
-		})
-	}
-}
-
-func TestGenerateStatefulSetPatch(t *testing.T) {
-	componentName := "test-component"
-	namespace := "test-namespace"
-	containerName := "test-container"
-	replicas := int32(1)
-	image := "image"
-
-	tests := []struct {
-		name            string
-		component       gitopsv1alpha1.GeneratorOptions
-		containerName   string
-		imageName       string
-		namespace       string
-		wantStatefulSet appsv1.StatefulSet
-	}{
 		{
-			name: "Simple component, no optional fields set",
+			name: "Component with only the pod security context set",
 			component: gitopsv1alpha1.GeneratorOptions{
-				Name:     componentName,
-				Replicas: int(replicas),
-				BaseEnvVar: []corev1.EnvVar{
-					{
-						Name:  "FOO",
-						Value: "BAR",
-					},
-				},
-				Resources: corev1.ResourceRequirements{
-					Limits: corev1.ResourceList{
-						corev1.ResourceCPU: resource.MustParse("1"),
-					},
-				},
-				OverlayEnvVar: []corev1.EnvVar{
-					{
-						Name:  "FOO",
-						Value: "BAR_ENV",
-					},
-					{
-						Name:  "FOO2",
-						Value: "BAR2_ENV",
-					},
-				},
+				Name:               componentName,
+				Namespace:          namespace,
+				Application:        applicationName,
+				PodSecurityContext: &podSecurityContext,
 			},
-			namespace:     namespace,
-			imageName:     image,
-			containerName: containerName,
-			wantStatefulSet: appsv1.StatefulSet{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					Kind:       "StatefulSet",
+					Kind:       "Deployment",
 					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
 					Name:      componentName,
 					Namespace: namespace,
+					Labels:    k8slabels,
 				},
-				Spec: appsv1.StatefulSetSpec{
+				Spec: appsv1.DeploymentSpec{
 					Replicas: &replicas,
-					Selector: &v1.LabelSelector{},
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
 					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
 						Spec: corev1.PodSpec{
+							SecurityContext: &podSecurityContext,
 							Containers: []corev1.Container{
 								{
-									Name:  containerName,
-									Image: image,
-									Env: []corev1.EnvVar{
-										{
-											Name:  "FOO",
-											Value: "BAR",
-										},
-										{
-											Name:  "FOO2",
-											Value: "BAR2_ENV",
-										},
-									},
-									Resources: corev1.ResourceRequirements{
-										Limits: corev1.ResourceList{
-											corev1.ResourceCPU: resource.MustParse("1"),
-										},
-									},
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
 								},
 							},
 						},
@@ -494,96 +533,39 @@ func TestGenerateStatefulSetPatch(t *testing.T) {
 				},
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			generatedStatefulSet := generateStatefulSetPatch(tt.component, tt.imageName, tt.containerName, tt.namespace)
-
-			if !reflect.DeepEqual(*generatedStatefulSet, tt.wantStatefulSet) {
-				t.Errorf("TestGenerateStatefulSetPatch() error: expected %v got %v", tt.wantStatefulSet, *generatedStatefulSet)
-			}
-		})
-	}
-}
-
-func TestGenerateDaemonSetPatch(t *testing.T) {
-	componentName := "test-component"
-	namespace := "test-namespace"
-	containerName := "test-container"
-	replicas := int32(1)
-	image := "image"
-
-	tests := []struct {
-		name          string
-		component     gitopsv1alpha1.GeneratorOptions
-		containerName string
-		imageName     string
-		namespace     string
-		wantDaemonSet appsv1.DaemonSet
-	}{
 		{
-			name: "Simple component, no optional fields set",
+			name: "Component with only the container security context set",
 			component: gitopsv1alpha1.GeneratorOptions{
-				Name:     componentName,
-				Replicas: int(replicas),
-				BaseEnvVar: []corev1.EnvVar{
-					{
-						Name:  "FOO",
-						Value: "BAR",
-					},
-				},
-				Resources: corev1.ResourceRequirements{
-					Limits: corev1.ResourceList{
-						corev1.ResourceCPU: resource.MustParse("1"),
-					},
-				},
-				OverlayEnvVar: []corev1.EnvVar{
-					{
-						Name:  "FOO",
-						Value: "BAR_ENV",
-					},
-					{
-						Name:  "FOO2",
-						Value: "BAR2_ENV",
-					},
-				},
+				Name:                     componentName,
+				Namespace:                namespace,
+				Application:              applicationName,
+				ContainerSecurityContext: &containerSecurityContext,
 			},
-			namespace:     namespace,
-			imageName:     image,
-			containerName: containerName,
-			wantDaemonSet: appsv1.DaemonSet{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					Kind:       "DaemonSet",
+					Kind:       "Deployment",
 					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
 					Name:      componentName,
 					Namespace: namespace,
+					Labels:    k8slabels,
 				},
-				Spec: appsv1.DaemonSetSpec{
-					Selector: &v1.LabelSelector{},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
 					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
 						Spec: corev1.PodSpec{
 							Containers: []corev1.Container{
 								{
-									Name:  containerName,
-									Image: image,
-									Env: []corev1.EnvVar{
-										{
-											Name:  "FOO",
-											Value: "BAR",
-										},
-										{
-											Name:  "FOO2",
-											Value: "BAR2_ENV",
-										},
-									},
-									Resources: corev1.ResourceRequirements{
-										Limits: corev1.ResourceList{
-											corev1.ResourceCPU: resource.MustParse("1"),
-										},
-									},
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									SecurityContext: &containerSecurityContext,
 								},
 							},
 						},
@@ -591,840 +573,4384 @@ func TestGenerateDaemonSetPatch(t *testing.T) {
 				},
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			generatedDaemonSet := generateDaemonSetPatch(tt.component, tt.imageName, tt.containerName, tt.namespace)
-
-			if !reflect.DeepEqual(*generatedDaemonSet, tt.wantDaemonSet) {
-				t.Errorf("TestGenerateDaemonSetPatch() error: expected %v got %v", tt.wantDaemonSet, *generatedDaemonSet)
-			}
-		})
-	}
-}
-
-func TestGenerateService(t *testing.T) {
-	applicationName := "test-application"
-	componentName := "test-component"
-	namespace := "test-namespace"
-	customK8sLabels := map[string]string{
-		"app.kubernetes.io/name":       componentName,
-		"app.kubernetes.io/instance":   "ComponentCRName",
-		"app.kubernetes.io/part-of":    applicationName,
-		"app.kubernetes.io/managed-by": "kustomize",
-		"app.kubernetes.io/created-by": "GitOps Generator Test",
-	}
-	k8slabels := map[string]string{
-		"app.kubernetes.io/name":       componentName,
-		"app.kubernetes.io/instance":   componentName,
-		"app.kubernetes.io/part-of":    applicationName,
-		"app.kubernetes.io/managed-by": "kustomize",
-		"app.kubernetes.io/created-by": "application-service",
-	}
-	matchLabels := map[string]string{
-		"app.kubernetes.io/instance": componentName,
-	}
-
-	tests := []struct {
-		name        string
-		component   gitopsv1alpha1.GeneratorOptions
-		wantService corev1.Service
-	}{
 		{
-			name: "Simple component object",
+			name: "Component with sidecars set",
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				TargetPort:  5000,
+				Sidecars: []corev1.Container{
+					{
+						Name:  "envoy",
+						Image: "envoyproxy/envoy:v1.25.0",
+					},
+				},
 			},
-			wantService: corev1.Service{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					APIVersion: "v1",
-					Kind:       "Service",
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
 					Name:      componentName,
 					Namespace: namespace,
 					Labels:    k8slabels,
 				},
-				Spec: corev1.ServiceSpec{
-					Selector: matchLabels,
-					Ports: []corev1.ServicePort{
-						{
-							Port:       int32(5000),
-							TargetPort: intstr.FromInt(5000),
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+								},
+								{
+									Name:  "envoy",
+									Image: "envoyproxy/envoy:v1.25.0",
+								},
+							},
 						},
 					},
 				},
 			},
 		},
 		{
-			name: "Simple component object with custom k8s labels",
+			name: "Component with storage set",
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				TargetPort:  5000,
-				K8sLabels:   customK8sLabels,
+				Storage: []gitopsv1alpha1.ComponentStorage{
+					{
+						Name:      "data",
+						Size:      "1Gi",
+						MountPath: "/var/lib/data",
+					},
+				},
 			},
-			wantService: corev1.Service{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					APIVersion: "v1",
-					Kind:       "Service",
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
 					Name:      componentName,
 					Namespace: namespace,
-					Labels:    customK8sLabels,
+					Labels:    k8slabels,
 				},
-				Spec: corev1.ServiceSpec{
-					Selector: matchLabels,
-					Ports: []corev1.ServicePort{
-						{
-							Port:       int32(5000),
-							TargetPort: intstr.FromInt(5000),
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Volumes: []corev1.Volume{
+								{
+									Name: "data",
+									VolumeSource: corev1.VolumeSource{
+										PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+											ClaimName: "data",
+										},
+									},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/var/lib/data",
+										},
+									},
+								},
+							},
 						},
 					},
 				},
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			generatedService := generateService(tt.component)
-
-			if !reflect.DeepEqual(*generatedService, tt.wantService) {
-				t.Errorf("TestGenerateService() error: expected %v got %v", tt.wantService, generatedService)
-			}
-		})
-	}
-}
-
-func TestGenerateRoute(t *testing.T) {
-	applicationName := "test-application"
-	componentName := "test-component"
-	namespace := "test-namespace"
-	customK8sLabels := map[string]string{
-		"app.kubernetes.io/name":       componentName,
-		"app.kubernetes.io/instance":   "ComponentCRName",
-		"app.kubernetes.io/part-of":    applicationName,
-		"app.kubernetes.io/managed-by": "kustomize",
-		"app.kubernetes.io/created-by": "GitOps Generator Test",
-	}
-	k8slabels := map[string]string{
-		"app.kubernetes.io/name":       componentName,
-		"app.kubernetes.io/instance":   componentName,
-		"app.kubernetes.io/part-of":    applicationName,
-		"app.kubernetes.io/managed-by": "kustomize",
-		"app.kubernetes.io/created-by": "application-service",
-	}
-	weight := int32(100)
-
-	tests := []struct {
-		name      string
-		component gitopsv1alpha1.GeneratorOptions
-		wantRoute routev1.Route
-	}{
 		{
-			name: "Simple component object",
+			name: "Component with exec readiness probe and https liveness probe set",
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				TargetPort:  5000,
+				TargetPort:  8443,
+				ReadinessProbe: &gitopsv1alpha1.ProbeOptions{
+					Type:    gitopsv1alpha1.ProbeTypeExec,
+					Command: []string{"cat", "/tmp/ready"},
+				},
+				LivenessProbe: &gitopsv1alpha1.ProbeOptions{
+					Path:                "/healthz",
+					Scheme:              corev1.URISchemeHTTPS,
+					InitialDelaySeconds: 30,
+					PeriodSeconds:       5,
+					FailureThreshold:    6,
+				},
 			},
-			wantRoute: routev1.Route{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					Kind:       "Route",
-					APIVersion: "route.openshift.io/v1",
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
 					Name:      componentName,
 					Namespace: namespace,
 					Labels:    k8slabels,
 				},
-				Spec: routev1.RouteSpec{
-					Port: &routev1.RoutePort{
-						TargetPort: intstr.FromInt(5000),
-					},
-					TLS: &routev1.TLSConfig{
-						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
-						Termination:                   routev1.TLSTerminationEdge,
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
 					},
-					To: routev1.RouteTargetReference{
-						Kind:   "Service",
-						Name:   componentName,
-						Weight: &weight,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									Ports: []corev1.ContainerPort{
+										{
+											ContainerPort: int32(8443),
+										},
+									},
+									ReadinessProbe: &corev1.Probe{
+										InitialDelaySeconds: 10,
+										PeriodSeconds:       10,
+										ProbeHandler: corev1.ProbeHandler{
+											Exec: &corev1.ExecAction{
+												Command: []string{"cat", "/tmp/ready"},
+											},
+										},
+									},
+									LivenessProbe: &corev1.Probe{
+										InitialDelaySeconds: 30,
+										PeriodSeconds:       5,
+										FailureThreshold:    6,
+										ProbeHandler: corev1.ProbeHandler{
+											HTTPGet: &corev1.HTTPGetAction{
+												Port:   intstr.FromInt(8443),
+												Path:   "/healthz",
+												Scheme: corev1.URISchemeHTTPS,
+											},
+										},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
 		},
 		{
-			name: "Component object with route/hostname and custom k8s labels set",
+			name: "Component with exec probe set and no target port",
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				TargetPort:  5000,
-				K8sLabels:   customK8sLabels,
-				Route:       "example.com",
+				LivenessProbe: &gitopsv1alpha1.ProbeOptions{
+					Type:    gitopsv1alpha1.ProbeTypeExec,
+					Command: []string{"/bin/healthcheck"},
+				},
 			},
-			wantRoute: routev1.Route{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					Kind:       "Route",
-					APIVersion: "route.openshift.io/v1",
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
 					Name:      componentName,
 					Namespace: namespace,
-					Labels:    customK8sLabels,
+					Labels:    k8slabels,
 				},
-				Spec: routev1.RouteSpec{
-					Host: "example.com",
-					Port: &routev1.RoutePort{
-						TargetPort: intstr.FromInt(5000),
-					},
-					TLS: &routev1.TLSConfig{
-						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
-						Termination:                   routev1.TLSTerminationEdge,
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
 					},
-					To: routev1.RouteTargetReference{
-						Kind:   "Service",
-						Name:   componentName,
-						Weight: &weight,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									LivenessProbe: &corev1.Probe{
+										InitialDelaySeconds: 10,
+										PeriodSeconds:       10,
+										ProbeHandler: corev1.ProbeHandler{
+											Exec: &corev1.ExecAction{
+												Command: []string{"/bin/healthcheck"},
+											},
+										},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
 		},
 		{
-			name: "Generated route with trimmed CR name",
+			name: "Component with image pull policy set",
 			component: gitopsv1alpha1.GeneratorOptions{
-				Name:        "some-longer-component-name-test-string",
-				Namespace:   namespace,
-				Application: applicationName,
-				TargetPort:  5000,
-				K8sLabels: map[string]string{
-					"app.kubernetes.io/name":       "some-longer-component-name-test-string",
-					"app.kubernetes.io/instance":   "ComponentCRName",
-					"app.kubernetes.io/part-of":    applicationName,
-					"app.kubernetes.io/managed-by": "kustomize",
-					"app.kubernetes.io/created-by": "GitOps Generator Test",
-				},
-				Route: "example.com",
+				Name:            componentName,
+				Namespace:       namespace,
+				Application:     applicationName,
+				ContainerImage:  "quay.io/test/test@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+				ImagePullPolicy: corev1.PullIfNotPresent,
 			},
-			wantRoute: routev1.Route{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					Kind:       "Route",
-					APIVersion: "route.openshift.io/v1",
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
 					Namespace: namespace,
-					Labels: map[string]string{
-						"app.kubernetes.io/name":       "some-longer-component-name-test-string",
-						"app.kubernetes.io/instance":   "ComponentCRName",
-						"app.kubernetes.io/part-of":    applicationName,
-						"app.kubernetes.io/managed-by": "kustomize",
-						"app.kubernetes.io/created-by": "GitOps Generator Test",
-					},
+					Labels:    k8slabels,
 				},
-				Spec: routev1.RouteSpec{
-					Host: "example.com",
-					Port: &routev1.RoutePort{
-						TargetPort: intstr.FromInt(5000),
-					},
-					TLS: &routev1.TLSConfig{
-						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
-						Termination:                   routev1.TLSTerminationEdge,
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
 					},
-					To: routev1.RouteTargetReference{
-						Kind:   "Service",
-						Name:   "some-longer-component-name-test-string",
-						Weight: &weight,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									Image:           "quay.io/test/test@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+									ImagePullPolicy: corev1.PullIfNotPresent,
+								},
+							},
+						},
 					},
 				},
 			},
 		},
 		{
-			name: "Component object with custom route name set",
+			name: "Component with scheduling constraints set",
 			component: gitopsv1alpha1.GeneratorOptions{
-				Name:        componentName,
-				Namespace:   namespace,
-				Application: applicationName,
-				TargetPort:  5000,
-				K8sLabels:   customK8sLabels,
-				Route:       "example.com",
-				RouteName:   "my-route-name",
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				NodeSelector: nodeSelector,
+				Tolerations:  tolerations,
+				Affinity:     &affinity,
 			},
-			wantRoute: routev1.Route{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					Kind:       "Route",
-					APIVersion: "route.openshift.io/v1",
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
-					Name:      "my-route-name",
+					Name:      componentName,
 					Namespace: namespace,
-					Labels:    customK8sLabels,
+					Labels:    k8slabels,
 				},
-				Spec: routev1.RouteSpec{
-					Host: "example.com",
-					Port: &routev1.RoutePort{
-						TargetPort: intstr.FromInt(5000),
-					},
-					TLS: &routev1.TLSConfig{
-						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
-						Termination:                   routev1.TLSTerminationEdge,
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
 					},
-					To: routev1.RouteTargetReference{
-						Kind:   "Service",
-						Name:   componentName,
-						Weight: &weight,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							NodeSelector: nodeSelector,
+							Tolerations:  tolerations,
+							Affinity:     &affinity,
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+								},
+							},
+						},
 					},
 				},
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			generatedRoute := generateRoute(tt.component)
-			if len(generatedRoute.Name) > 30 {
-				t.Errorf("TestGenerateRoute() error: expected CR name of length 30, got %v", len(generatedRoute.Name))
-			}
-
-			if tt.name == "Generated route with trimmed CR name" {
-				trimmedComponentName := "some-longer-component-nam"
-				if !strings.Contains(generatedRoute.Name, trimmedComponentName) {
-					t.Errorf("TestGenerateRoute() error: expected component CR name to contain %v got %v", tt.wantRoute, generatedRoute)
-				}
-
-				tt.wantRoute.Name = generatedRoute.Name
-			}
-			if !reflect.DeepEqual(*generatedRoute, tt.wantRoute) {
-				t.Errorf("TestGenerateRoute() error: expected %v got %v", tt.wantRoute, generatedRoute)
-			}
-		})
-	}
-}
-
-func TestGenerateIngress(t *testing.T) {
-	applicationName := "test-application"
-	componentName := "test-component"
-	namespace := "test-namespace"
-	customK8sLabels := map[string]string{
-		"app.kubernetes.io/name":       componentName,
-		"app.kubernetes.io/instance":   "ComponentCRName",
-		"app.kubernetes.io/part-of":    applicationName,
-		"app.kubernetes.io/managed-by": "kustomize",
-		"app.kubernetes.io/created-by": "GitOps Generator Test",
-	}
-	k8slabels := map[string]string{
-		"app.kubernetes.io/name":       componentName,
-		"app.kubernetes.io/instance":   componentName,
-		"app.kubernetes.io/part-of":    applicationName,
-		"app.kubernetes.io/managed-by": "kustomize",
-		"app.kubernetes.io/created-by": "application-service",
-	}
-	implementationSpecific := networkingv1.PathTypeImplementationSpecific
-
-	tests := []struct {
-		name        string
-		options     gitopsv1alpha1.GeneratorOptions
-		wantIngress networkingv1.Ingress
-	}{
 		{
-			name: "Simple options object",
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name:        componentName,
-				Namespace:   namespace,
-				Application: applicationName,
-				TargetPort:  5000,
-				Route:       componentName + ".example.com",
+			name: "Component with empty scheduling constraints set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				NodeSelector: map[string]string{},
+				Tolerations:  []corev1.Toleration{},
 			},
-			wantIngress: networkingv1.Ingress{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					Kind:       "Ingress",
-					APIVersion: "networking.k8s.io/v1",
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
 					Name:      componentName,
 					Namespace: namespace,
 					Labels:    k8slabels,
 				},
-				Spec: networkingv1.IngressSpec{
-					Rules: []networkingv1.IngressRule{
-						{
-							IngressRuleValue: networkingv1.IngressRuleValue{
-								HTTP: &networkingv1.HTTPIngressRuleValue{
-									Paths: []networkingv1.HTTPIngressPath{
-										{
-											Path:     "/",
-											PathType: &implementationSpecific,
-											Backend: networkingv1.IngressBackend{
-												Service: &networkingv1.IngressServiceBackend{
-													Name: componentName,
-													Port: networkingv1.ServiceBackendPort{
-														Number: 5000,
-													},
-												},
-											},
-										},
-									},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
 								},
 							},
-							Host: componentName + ".example.com",
 						},
 					},
 				},
 			},
 		},
 		{
-			name: "Options object with custom k8s labels set",
-			options: gitopsv1alpha1.GeneratorOptions{
+			name: "Component with command and args set",
+			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				TargetPort:  5000,
-				K8sLabels:   customK8sLabels,
-				Route:       componentName + ".example.com",
+				Command:     []string{"/bin/sh", "-c"},
+				Args:        []string{"exec ./server"},
 			},
-			wantIngress: networkingv1.Ingress{
+			wantDeployment: appsv1.Deployment{
 				TypeMeta: v1.TypeMeta{
-					Kind:       "Ingress",
-					APIVersion: "networking.k8s.io/v1",
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
 				},
 				ObjectMeta: v1.ObjectMeta{
 					Name:      componentName,
 					Namespace: namespace,
-					Labels:    customK8sLabels,
+					Labels:    k8slabels,
 				},
-				Spec: networkingv1.IngressSpec{
-					Rules: []networkingv1.IngressRule{
-						{
-							IngressRuleValue: networkingv1.IngressRuleValue{
-								HTTP: &networkingv1.HTTPIngressRuleValue{
-									Paths: []networkingv1.HTTPIngressPath{
-										{
-											Path:     "/",
-											PathType: &implementationSpecific,
-											Backend: networkingv1.IngressBackend{
-												Service: &networkingv1.IngressServiceBackend{
-													Name: componentName,
-													Port: networkingv1.ServiceBackendPort{
-														Number: 5000,
-													},
-												},
-											},
-										},
-									},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									Command:         []string{"/bin/sh", "-c"},
+									Args:            []string{"exec ./server"},
 								},
 							},
-							Host: componentName + ".example.com",
 						},
 					},
 				},
 			},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			generatedIngress := generateIngress(tt.options)
-			if len(generatedIngress.Name) > 30 {
-				t.Errorf("TestGenerateIngress() error: expected CR name of length 30, got %v", len(generatedIngress.Name))
-			}
-
-			if tt.name == "Generated ingress with trimmed CR name" {
-				trimmedComponentName := "some-longer-component-nam"
-				if !strings.Contains(generatedIngress.Name, trimmedComponentName) {
-					t.Errorf("TestGenerateRoute() error: expected component CR name to contain %v got %v", tt.wantIngress, generatedIngress)
-				}
-
-				tt.wantIngress.Name = generatedIngress.Name
-			}
-			if !reflect.DeepEqual(*generatedIngress, tt.wantIngress) {
-				t.Errorf("TestGenerateRoute() error: expected %v got %v", tt.wantIngress, *generatedIngress)
-			}
-		})
-	}
-}
-
-func TestGenerateOverlays(t *testing.T) {
-
-	imageName := "test-image"
-	namespace := "test-namespace"
-	containerName := "test-container"
-
-	fs := ioutils.NewMemoryFilesystem()
-	readOnlyFs := ioutils.NewReadOnlyFs()
-
-	route1 := routev1.Route{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "route1",
-		},
-	}
-	route2 := routev1.Route{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "route2",
-		},
-	}
-
-	ingress1 := networkingv1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "ingress1",
-		},
-	}
-	ingress2 := networkingv1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "ingress2",
-		},
-	}
-
-	// Prepopulate the fs with components
-	gitOpsFolder := "/tmp/gitops"
-	fs.MkdirAll(gitOpsFolder, 0755)
-	baseFolder := filepath.Join(gitOpsFolder, "../", "base")
-	fs.MkdirAll(baseFolder, 0755)
-	baseDeploymentFilePath := filepath.Join(baseFolder, "deployment.yaml")
-	baseDeployment := appsv1.Deployment{
-		TypeMeta: v1.TypeMeta{
-			Kind:       "Deployment",
-			APIVersion: "apps/v1",
-		},
-		ObjectMeta: v1.ObjectMeta{
-			Name:      "test-component",
-			Namespace: namespace,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Selector: &v1.LabelSelector{},
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:  containerName,
-							Image: imageName,
+		{
+			name: "Component with rolling update strategy set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				DeploymentStrategy: &appsv1.DeploymentStrategy{
+					Type: appsv1.RollingUpdateDeploymentStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDeployment{
+						MaxSurge:       &maxSurge,
+						MaxUnavailable: &maxUnavailable,
+					},
+				},
+			},
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Strategy: appsv1.DeploymentStrategy{
+						Type: appsv1.RollingUpdateDeploymentStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateDeployment{
+							MaxSurge:       &maxSurge,
+							MaxUnavailable: &maxUnavailable,
+						},
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+								},
+							},
 						},
 					},
 				},
 			},
 		},
-	}
-
-	bytes, err := yaml.Marshal(baseDeployment)
-	if err != nil {
-		t.Errorf("unexpected error when marshal the base deployment yaml %v", err)
-	}
-	err = fs.WriteFile(baseDeploymentFilePath, bytes, 0755)
-	if err != nil {
-		t.Errorf("unexpected error when writing to base deployment file: %v", err)
-	}
-
-	outputFolder := filepath.Join(gitOpsFolder, "overlays")
-	fs.MkdirAll(outputFolder, 0755)
-
-	outputFolderWithKustomizationFile := filepath.Join(gitOpsFolder, "overlays-2")
-	fs.MkdirAll(outputFolderWithKustomizationFile, 0755)
-	preExistKustomizationFilepath := filepath.Join(outputFolderWithKustomizationFile, "kustomization.yaml")
-	k := resources.Kustomization{
-		Patches: []resources.Patch{
-			{
-				Path: "patch1.yaml",
-			},
-			{
-				Path: "custom-patch1.yaml",
+		{
+			name: "Component with recreate strategy set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				DeploymentStrategy: &appsv1.DeploymentStrategy{
+					Type: appsv1.RecreateDeploymentStrategyType,
+				},
 			},
-		},
-	}
-	bytes, err = yaml.Marshal(k)
-	if err != nil {
-		t.Errorf("unexpected error when marshal the kustomization yaml %v", err)
-	}
-	err = fs.WriteFile(preExistKustomizationFilepath, bytes, 0755)
-	if err != nil {
-		t.Errorf("unexpected error when writing to kustomizatipn file: %v", err)
-	}
-
-	invalidKustomizationFileFolder := filepath.Join(gitOpsFolder, "overlays-error")
-	fs.MkdirAll(invalidKustomizationFileFolder, 0755)
-	invalidKustomizationFilepath := filepath.Join(invalidKustomizationFileFolder, "kustomization.yaml")
-	invalidKustomization := map[string]interface{}{
-		"Resources": 8,
-	}
-	bytes, err = yaml.Marshal(invalidKustomization)
-	if err != nil {
-		t.Errorf("unexpected error when marshal the kustomization yaml %v", err)
-	}
-	err = fs.WriteFile(invalidKustomizationFilepath, bytes, 0755)
-	if err != nil {
-		t.Errorf("unexpected error when writing to kustomizatipn file: %v", err)
-	}
-
-	gitOpsFolder2 := "/tmp/statefulset"
-	fs.MkdirAll(gitOpsFolder2, 0755)
-	baseFolder2 := filepath.Join(gitOpsFolder2, "base")
-	fs.MkdirAll(baseFolder2, 0755)
-	baseStatefulSetFilePath := filepath.Join(baseFolder2, "statefulset.yaml")
-	baseStatefulSet := appsv1.StatefulSet{
-		TypeMeta: v1.TypeMeta{
-			Kind:       "StatefulSet",
-			APIVersion: "apps/v1",
-		},
-		ObjectMeta: v1.ObjectMeta{
-			Name:      "test-component",
-			Namespace: namespace,
-		},
-		Spec: appsv1.StatefulSetSpec{
-			Selector: &v1.LabelSelector{},
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:  containerName,
-							Image: imageName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Strategy: appsv1.DeploymentStrategy{
+						Type: appsv1.RecreateDeploymentStrategyType,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+								},
+							},
 						},
 					},
 				},
 			},
 		},
-	}
-
-	bytes, err = yaml.Marshal(baseStatefulSet)
-	if err != nil {
-		t.Errorf("unexpected error when marshal the base statefulset yaml %v", err)
-	}
-	err = fs.WriteFile(baseStatefulSetFilePath, bytes, 0755)
-	if err != nil {
-		t.Errorf("unexpected error when writing to base statefulset file: %v", err)
-	}
-
-	outputFolder2 := filepath.Join(gitOpsFolder2, "overlays", "development")
-	fs.MkdirAll(outputFolder2, 0755)
-
-	gitOpsFolder3 := "/tmp/daemonset"
-	fs.MkdirAll(gitOpsFolder2, 0755)
-	baseFolder3 := filepath.Join(gitOpsFolder3, "base")
-	fs.MkdirAll(baseFolder2, 0755)
-	baseDaemonSetFilePath := filepath.Join(baseFolder3, "daemonset.yaml")
-	baseDaemonSet := appsv1.DaemonSet{
-		TypeMeta: v1.TypeMeta{
-			Kind:       "DaemonSet",
-			APIVersion: "apps/v1",
-		},
-		ObjectMeta: v1.ObjectMeta{
-			Name:      "test-component",
-			Namespace: namespace,
-		},
-		Spec: appsv1.DaemonSetSpec{
-			Selector: &v1.LabelSelector{},
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:  containerName,
-							Image: imageName,
+		{
+			name: "Component with annotations set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				Annotations: map[string]string{
+					"example.com/owner": "team-a",
+				},
+				PodAnnotations: map[string]string{
+					"prometheus.io/scrape": "true",
+					"prometheus.io/port":   "9090",
+				},
+			},
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+					Annotations: map[string]string{
+						"example.com/owner": "team-a",
+					},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+							Annotations: map[string]string{
+								"prometheus.io/scrape": "true",
+								"prometheus.io/port":   "9090",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+								},
+							},
 						},
 					},
 				},
 			},
 		},
-	}
-
-	bytes, err = yaml.Marshal(baseDaemonSet)
-	if err != nil {
-		t.Errorf("unexpected error when marshal the base daemonset yaml %v", err)
-	}
-	err = fs.WriteFile(baseDaemonSetFilePath, bytes, 0755)
-	if err != nil {
-		t.Errorf("unexpected error when writing to base daemonset file: %v", err)
-	}
-
-	outputFolder3 := filepath.Join(gitOpsFolder3, "overlays", "development")
-	fs.MkdirAll(outputFolder3, 0755)
-
-	tests := []struct {
-		name                        string
-		fs                          afero.Afero
-		options                     gitopsv1alpha1.GeneratorOptions
-		outputFolder                string
-		expectPatchEntries          int
-		componentGeneratedResources map[string][]string
-		wantErr                     string
-	}{
-		{
-			name: "simple success case",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
-			},
-			outputFolder:       outputFolder,
-			expectPatchEntries: 1,
-			wantErr:            "",
-		},
 		{
-			name: "simple success case with statefulset",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
+			name: "Component with multiple ports set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				Ports:       ports,
 			},
-			outputFolder:       outputFolder2,
-			expectPatchEntries: 1,
-			wantErr:            "",
-		},
-		{
-			name: "simple success case with daemonset",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									Ports: []corev1.ContainerPort{
+										{
+											Name:          "metrics",
+											ContainerPort: int32(9090),
+										},
+										{
+											Name:          "http",
+											ContainerPort: int32(8080),
+											Protocol:      corev1.ProtocolTCP,
+										},
+										{
+											Name:          "syslog",
+											ContainerPort: int32(5514),
+											Protocol:      corev1.ProtocolUDP,
+										},
+									},
+									ReadinessProbe: &corev1.Probe{
+										InitialDelaySeconds: 10,
+										PeriodSeconds:       10,
+										ProbeHandler: corev1.ProbeHandler{
+											TCPSocket: &corev1.TCPSocketAction{
+												Port: intstr.FromInt(8080),
+											},
+										},
+									},
+									LivenessProbe: &corev1.Probe{
+										InitialDelaySeconds: 10,
+										PeriodSeconds:       10,
+										ProbeHandler: corev1.ProbeHandler{
+											HTTPGet: &corev1.HTTPGetAction{
+												Port: intstr.FromInt(8080),
+												Path: "/",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
 			},
-			outputFolder:       outputFolder3,
-			expectPatchEntries: 1,
-			wantErr:            "",
 		},
 		{
-			name: "simple success case on OpenShift with Routes",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Routes: []routev1.Route{
-						route1,
-						route2,
+			name: "Component with topology spread constraints set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+					{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.ScheduleAnyway,
 					},
-					Ingresses: []networkingv1.Ingress{
-						ingress1,
-						ingress2,
+					{
+						MaxSkew:           2,
+						TopologyKey:       "kubernetes.io/hostname",
+						WhenUnsatisfiable: corev1.DoNotSchedule,
+						LabelSelector: &v1.LabelSelector{
+							MatchLabels: map[string]string{
+								"tier": "frontend",
+							},
+						},
 					},
 				},
 			},
-			outputFolder:       outputFolder,
-			expectPatchEntries: 1,
-			wantErr:            "",
-		},
-		{
-			name: "simple success case on OpenShift where Route is generated",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name:       "test-component",
-				TargetPort: 8080,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Ingresses: []networkingv1.Ingress{
-						ingress1,
-						ingress2,
-					},
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
 				},
-			},
-			outputFolder:       outputFolder,
-			expectPatchEntries: 1,
-			wantErr:            "",
-		},
-		{
-			name: "simple success case on Kuberntes with Ingresses",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Routes: []routev1.Route{
-						route1,
-						route2,
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
 					},
-					Ingresses: []networkingv1.Ingress{
-						ingress1,
-						ingress2,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+								{
+									MaxSkew:           1,
+									TopologyKey:       "topology.kubernetes.io/zone",
+									WhenUnsatisfiable: corev1.ScheduleAnyway,
+									LabelSelector: &v1.LabelSelector{
+										MatchLabels: matchLabels,
+									},
+								},
+								{
+									MaxSkew:           2,
+									TopologyKey:       "kubernetes.io/hostname",
+									WhenUnsatisfiable: corev1.DoNotSchedule,
+									LabelSelector: &v1.LabelSelector{
+										MatchLabels: map[string]string{
+											"tier": "frontend",
+										},
+									},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+								},
+							},
+						},
 					},
 				},
-				IsKubernetesCluster: true,
 			},
-			outputFolder:       outputFolder,
-			expectPatchEntries: 1,
-			wantErr:            "",
 		},
 		{
-			name: "simple success case on OpenShift where Ingress is generated",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name:                "test-component",
-				TargetPort:          8080,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{},
-				IsKubernetesCluster: true,
+			name: "Component with priority and runtime class names set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:              componentName,
+				Namespace:         namespace,
+				Application:       applicationName,
+				PriorityClassName: "system-cluster-critical",
+				RuntimeClassName:  runtimeClassName,
 			},
-			outputFolder:       outputFolder,
-			expectPatchEntries: 1,
-			wantErr:            "",
-		},
-		{
-			name: "existing kustomization file with custom patches",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							PriorityClassName: "system-cluster-critical",
+							RuntimeClassName:  &runtimeClassName,
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+								},
+							},
+						},
+					},
+				},
 			},
-			outputFolder:       outputFolderWithKustomizationFile,
-			expectPatchEntries: 3,
-			wantErr:            "",
 		},
 		{
-			name: "read only fs",
-			fs:   readOnlyFs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
+			name: "Component with probes disabled, should keep the container port",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:          componentName,
+				Namespace:     namespace,
+				Application:   applicationName,
+				TargetPort:    9000,
+				DisableProbes: true,
+				ReadinessProbe: &gitopsv1alpha1.ProbeOptions{
+					Type: gitopsv1alpha1.ProbeTypeHTTP,
+				},
 			},
-			outputFolder: outputFolderWithKustomizationFile,
-			wantErr:      "failed to MkDirAll",
-		},
-		{
-			name: "unmarshall error",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									Ports: []corev1.ContainerPort{
+										{
+											ContainerPort: 9000,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
 			},
-			outputFolder: invalidKustomizationFileFolder,
-			wantErr:      " failed to unmarshal data: error unmarshaling JSON: while decoding JSON: json: cannot unmarshal number into Go struct field Kustomization.resources",
 		},
 		{
-			name: "genereated an additional patch",
-			fs:   fs,
-			options: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
+			name: "Component with secret env set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				BaseEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR",
+					},
+				},
+				SecretEnv: []gitopsv1alpha1.SecretKeyMapping{
+					{
+						SecretName: "app-secret",
+						Key:        "password",
+						EnvName:    "DB_PASSWORD",
+					},
+				},
 			},
-			outputFolder: outputFolderWithKustomizationFile,
-			componentGeneratedResources: map[string][]string{
-				"test-component": {
-					"patch1.yaml",
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									Env: []corev1.EnvVar{
+										{
+											Name:  "FOO",
+											Value: "BAR",
+										},
+										{
+											Name: "DB_PASSWORD",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{
+														Name: "app-secret",
+													},
+													Key: "password",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 				},
 			},
-			expectPatchEntries: 3,
-			wantErr:            "",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := GenerateOverlays(tt.fs, gitOpsFolder, tt.outputFolder, tt.options, imageName, namespace, tt.componentGeneratedResources)
+			generatedDeployment := generateDeployment(tt.component)
 
-			if !testutils.ErrorMatch(t, tt.wantErr, err) {
-				t.Errorf("unexpected error return value. Got %v", err)
+			if !reflect.DeepEqual(*generatedDeployment, tt.wantDeployment) {
+				t.Errorf("TestGenerateDeployment() error: expected %v got %v", tt.wantDeployment, generatedDeployment)
 			}
+		})
+	}
+}
 
-			var exists bool
-			if tt.wantErr == "" {
-				if strings.Contains(tt.name, "statefulset") {
-					// Validate that the statefulset-patch.yaml preserve the container name
-					statefulSetPatchFilepath := filepath.Join(tt.outputFolder, statefulsetPatchFileName)
-					exists, err := tt.fs.Exists(statefulSetPatchFilepath)
-					if err != nil {
-						t.Errorf("unexpected error checking if statefulset patch file exists %v", err)
-					}
-					if !exists {
-						t.Errorf("statefulset file does not exist at path %v", statefulSetPatchFilepath)
-					}
+func TestGenerateDeploymentPatch(t *testing.T) {
+	componentName := "test-component"
+	namespace := "test-namespace"
+	containerName := "test-container"
+	replicas := int32(1)
+	image := "image"
+	runAsNonRoot := true
+	readOnlyRootFilesystem := true
+	podSecurityContext := corev1.PodSecurityContext{
+		RunAsNonRoot: &runAsNonRoot,
+	}
+	containerSecurityContext := corev1.SecurityContext{
+		ReadOnlyRootFilesystem: &readOnlyRootFilesystem,
+	}
+	minReadySeconds := int32(30)
+	progressDeadlineSeconds := int32(600)
+	overlayRevisionHistoryLimit := int32(2)
 
-					statefulSetPatch := appsv1.StatefulSet{}
-					statefulSetPatchBytes, err := tt.fs.ReadFile(statefulSetPatchFilepath)
-					if err != nil {
-						t.Errorf("unexpected error reading statefulset file")
-					}
-					err = yaml.Unmarshal(statefulSetPatchBytes, &statefulSetPatch)
+	tests := []struct {
+		name           string
+		component      gitopsv1alpha1.GeneratorOptions
+		containerName  string
+		imageName      string
+		namespace      string
+		wantDeployment appsv1.Deployment
+	}{
+		{
+			name: "Simple component, no optional fields set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:     componentName,
+				Replicas: int(replicas),
+				BaseEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR",
+					},
+				},
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+				},
+				OverlayEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR_ENV",
+					},
+					{
+						Name:  "FOO2",
+						Value: "BAR2_ENV",
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+									Env: []corev1.EnvVar{
+										{
+											Name:  "FOO",
+											Value: "BAR",
+										},
+										{
+											Name:  "FOO2",
+											Value: "BAR2_ENV",
+										},
+									},
+									Resources: corev1.ResourceRequirements{
+										Limits: corev1.ResourceList{
+											corev1.ResourceCPU: resource.MustParse("1"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with security contexts set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                     componentName,
+				PodSecurityContext:       &podSecurityContext,
+				ContainerSecurityContext: &containerSecurityContext,
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							SecurityContext: &podSecurityContext,
+							Containers: []corev1.Container{
+								{
+									Name:            containerName,
+									Image:           image,
+									SecurityContext: &containerSecurityContext,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with init containers set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: componentName,
+				InitContainers: []corev1.Container{
+					{
+						Name:    "migrate",
+						Image:   "migrate-image",
+						Command: []string{"./migrate.sh"},
+						Env: []corev1.EnvVar{
+							{
+								Name:  "FOO",
+								Value: "BAR",
+							},
+						},
+					},
+				},
+				OverlayEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR_ENV",
+					},
+					{
+						Name:  "FOO2",
+						Value: "BAR2_ENV",
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name:  "migrate",
+									Image: "migrate-image",
+									Env: []corev1.EnvVar{
+										{
+											Name:  "FOO",
+											Value: "BAR",
+										},
+										{
+											Name:  "FOO2",
+											Value: "BAR2_ENV",
+										},
+									},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+									Env: []corev1.EnvVar{
+										{
+											Name:  "FOO",
+											Value: "BAR_ENV",
+										},
+										{
+											Name:  "FOO2",
+											Value: "BAR2_ENV",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with probes set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:       componentName,
+				TargetPort: 8443,
+				LivenessProbe: &gitopsv1alpha1.ProbeOptions{
+					Path:   "/healthz",
+					Scheme: corev1.URISchemeHTTPS,
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+									LivenessProbe: &corev1.Probe{
+										InitialDelaySeconds: 10,
+										PeriodSeconds:       10,
+										ProbeHandler: corev1.ProbeHandler{
+											HTTPGet: &corev1.HTTPGetAction{
+												Port:   intstr.FromInt(8443),
+												Path:   "/healthz",
+												Scheme: corev1.URISchemeHTTPS,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with image pull policy set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:            componentName,
+				ImagePullPolicy: corev1.PullNever,
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            containerName,
+									Image:           image,
+									ImagePullPolicy: corev1.PullNever,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with environment scheduling constraints set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: componentName,
+				NodeSelector: map[string]string{
+					"node-pool": "production",
+				},
+				Tolerations: []corev1.Toleration{
+					{
+						Key:      "dedicated",
+						Operator: corev1.TolerationOpEqual,
+						Value:    "production",
+						Effect:   corev1.TaintEffectNoSchedule,
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							NodeSelector: map[string]string{
+								"node-pool": "production",
+							},
+							Tolerations: []corev1.Toleration{
+								{
+									Key:      "dedicated",
+									Operator: corev1.TolerationOpEqual,
+									Value:    "production",
+									Effect:   corev1.TaintEffectNoSchedule,
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with command and args set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:    componentName,
+				Command: []string{"/bin/sh", "-c"},
+				Args:    []string{"exec ./server"},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:    containerName,
+									Image:   image,
+									Command: []string{"/bin/sh", "-c"},
+									Args:    []string{"exec ./server"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with base and overlay env from sources set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: componentName,
+				BaseEnvFrom: []corev1.EnvFromSource{
+					{
+						ConfigMapRef: &corev1.ConfigMapEnvSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"},
+						},
+					},
+					{
+						SecretRef: &corev1.SecretEnvSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "db-credentials"},
+						},
+					},
+					{
+						ConfigMapRef: &corev1.ConfigMapEnvSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"},
+						},
+						Prefix: "DUPLICATE_",
+					},
+				},
+				OverlayEnvFrom: []corev1.EnvFromSource{
+					{
+						SecretRef: &corev1.SecretEnvSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "db-credentials"},
+						},
+						Prefix: "DB_",
+					},
+					{
+						ConfigMapRef: &corev1.ConfigMapEnvSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "db-credentials"},
+						},
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+									EnvFrom: []corev1.EnvFromSource{
+										{
+											ConfigMapRef: &corev1.ConfigMapEnvSource{
+												LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"},
+											},
+										},
+										{
+											SecretRef: &corev1.SecretEnvSource{
+												LocalObjectReference: corev1.LocalObjectReference{Name: "db-credentials"},
+											},
+											Prefix: "DB_",
+										},
+										{
+											ConfigMapRef: &corev1.ConfigMapEnvSource{
+												LocalObjectReference: corev1.LocalObjectReference{Name: "db-credentials"},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with environment topology spread constraints set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: componentName,
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+					{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.DoNotSchedule,
+						LabelSelector:     &v1.LabelSelector{},
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+								{
+									MaxSkew:           1,
+									TopologyKey:       "topology.kubernetes.io/zone",
+									WhenUnsatisfiable: corev1.DoNotSchedule,
+									LabelSelector: &v1.LabelSelector{
+										MatchLabels: map[string]string{
+											"app.kubernetes.io/instance": componentName,
+										},
+									},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with environment priority class name set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:              componentName,
+				PriorityClassName: "high-priority",
+				RuntimeClassName:  "gvisor",
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							PriorityClassName: "high-priority",
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with rollout tuning set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                    componentName,
+				MinReadySeconds:         &minReadySeconds,
+				ProgressDeadlineSeconds: &progressDeadlineSeconds,
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector:                &v1.LabelSelector{},
+					MinReadySeconds:         30,
+					ProgressDeadlineSeconds: &progressDeadlineSeconds,
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with overlay resources set, should merge with the base resources",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: componentName,
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:                    resource.MustParse("2"),
+						corev1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+					},
+				},
+				OverlayResources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+					Requests: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+									Resources: corev1.ResourceRequirements{
+										Limits: corev1.ResourceList{
+											corev1.ResourceCPU:                    resource.MustParse("2"),
+											corev1.ResourceMemory:                 resource.MustParse("4Gi"),
+											corev1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+										},
+										Requests: corev1.ResourceList{
+											corev1.ResourceMemory: resource.MustParse("2Gi"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with an overlay limit overriding a base limit, should keep the other base limits",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: componentName,
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:                    resource.MustParse("2"),
+						corev1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+					},
+				},
+				OverlayResources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceName("nvidia.com/gpu"): resource.MustParse("4"),
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+									Resources: corev1.ResourceRequirements{
+										Limits: corev1.ResourceList{
+											corev1.ResourceCPU:                    resource.MustParse("2"),
+											corev1.ResourceName("nvidia.com/gpu"): resource.MustParse("4"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with probes disabled, should not patch in the configured probes",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:          componentName,
+				TargetPort:    9000,
+				DisableProbes: true,
+				LivenessProbe: &gitopsv1alpha1.ProbeOptions{
+					Path: "/healthz",
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with literal and valueFrom env vars, should keep both when merging the overlay env vars",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: componentName,
+				BaseEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR",
+					},
+				},
+				SecretEnv: []gitopsv1alpha1.SecretKeyMapping{
+					{
+						SecretName: "app-secret",
+						Key:        "password",
+						EnvName:    "DB_PASSWORD",
+					},
+				},
+				OverlayEnvVar: []corev1.EnvVar{
+					{
+						Name:  "DB_PASSWORD",
+						Value: "overridden",
+					},
+					{
+						Name: "POD_NAME",
+						ValueFrom: &corev1.EnvVarSource{
+							FieldRef: &corev1.ObjectFieldSelector{
+								FieldPath: "metadata.name",
+							},
+						},
+					},
+					{
+						Name:  "FOO2",
+						Value: "BAR2_ENV",
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+									Env: []corev1.EnvVar{
+										{
+											Name:  "FOO",
+											Value: "BAR",
+										},
+										{
+											Name: "DB_PASSWORD",
+											ValueFrom: &corev1.EnvVarSource{
+												SecretKeyRef: &corev1.SecretKeySelector{
+													LocalObjectReference: corev1.LocalObjectReference{
+														Name: "app-secret",
+													},
+													Key: "password",
+												},
+											},
+										},
+										{
+											Name: "POD_NAME",
+											ValueFrom: &corev1.EnvVarSource{
+												FieldRef: &corev1.ObjectFieldSelector{
+													FieldPath: "metadata.name",
+												},
+											},
+										},
+										{
+											Name:  "FOO2",
+											Value: "BAR2_ENV",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with an overlay revision history limit and paused set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                        componentName,
+				OverlayRevisionHistoryLimit: &overlayRevisionHistoryLimit,
+				Paused:                      true,
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDeployment: appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector:             &v1.LabelSelector{},
+					RevisionHistoryLimit: &overlayRevisionHistoryLimit,
+					Paused:               true,
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedDeployment := generateDeploymentPatch(tt.component, tt.imageName, tt.containerName, tt.namespace)
+
+			if !reflect.DeepEqual(*generatedDeployment, tt.wantDeployment) {
+				t.Errorf("TestGenerateDeploymentPatch() error: expected %v got %v", tt.wantDeployment, *generatedDeployment)
+			}
+		})
+	}
+}
+
+func TestGenerateStatefulSet(t *testing.T) {
+	componentName := "test-component"
+	namespace := "test-namespace"
+	applicationName := "test-application"
+	replicas := int32(1)
+	matchLabels := map[string]string{
+		"app.kubernetes.io/instance": componentName,
+	}
+	storageClass := "fast"
+
+	tests := []struct {
+		name            string
+		component       gitopsv1alpha1.GeneratorOptions
+		wantStatefulSet appsv1.StatefulSet
+	}{
+		{
+			name: "Simple component, no optional fields set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				WorkloadType: gitopsv1alpha1.WorkloadTypeStatefulSet,
+			},
+			wantStatefulSet: appsv1.StatefulSet{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "StatefulSet",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       componentName,
+						"app.kubernetes.io/instance":   componentName,
+						"app.kubernetes.io/part-of":    applicationName,
+						"app.kubernetes.io/managed-by": "kustomize",
+						"app.kubernetes.io/created-by": "application-service",
+					},
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					ServiceName: componentName,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with storage set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				WorkloadType: gitopsv1alpha1.WorkloadTypeStatefulSet,
+				Storage: []gitopsv1alpha1.ComponentStorage{
+					{
+						Name:         "data",
+						Size:         "1Gi",
+						MountPath:    "/var/lib/data",
+						StorageClass: storageClass,
+					},
+				},
+			},
+			wantStatefulSet: appsv1.StatefulSet{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "StatefulSet",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       componentName,
+						"app.kubernetes.io/instance":   componentName,
+						"app.kubernetes.io/part-of":    applicationName,
+						"app.kubernetes.io/managed-by": "kustomize",
+						"app.kubernetes.io/created-by": "application-service",
+					},
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					ServiceName: componentName,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Labels: matchLabels,
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "container-image",
+									ImagePullPolicy: corev1.PullAlways,
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "data",
+											MountPath: "/var/lib/data",
+										},
+									},
+								},
+							},
+						},
+					},
+					VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+						{
+							ObjectMeta: v1.ObjectMeta{
+								Name: "data",
+							},
+							Spec: corev1.PersistentVolumeClaimSpec{
+								AccessModes: []corev1.PersistentVolumeAccessMode{
+									corev1.ReadWriteOnce,
+								},
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceStorage: resource.MustParse("1Gi"),
+									},
+								},
+								StorageClassName: &storageClass,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedStatefulSet := generateStatefulSet(tt.component)
+
+			if !reflect.DeepEqual(*generatedStatefulSet, tt.wantStatefulSet) {
+				t.Errorf("TestGenerateStatefulSet() error: expected %v got %v", tt.wantStatefulSet, *generatedStatefulSet)
+			}
+		})
+	}
+}
+
+func TestGenerateCronJob(t *testing.T) {
+	componentName := "test-component"
+	namespace := "test-namespace"
+	applicationName := "test-application"
+	historyLimit := int32(2)
+	matchLabels := map[string]string{
+		"app.kubernetes.io/instance": componentName,
+	}
+
+	tests := []struct {
+		name        string
+		component   gitopsv1alpha1.GeneratorOptions
+		wantCronJob batchv1.CronJob
+	}{
+		{
+			name: "Component with a target port, no probes or service port should be generated",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                       componentName,
+				Namespace:                  namespace,
+				Application:                applicationName,
+				WorkloadType:               gitopsv1alpha1.WorkloadTypeCronJob,
+				Schedule:                   "*/5 * * * *",
+				ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+				SuccessfulJobsHistoryLimit: &historyLimit,
+				FailedJobsHistoryLimit:     &historyLimit,
+				ContainerImage:             "quay.io/test/test:latest",
+				TargetPort:                 8080,
+			},
+			wantCronJob: batchv1.CronJob{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "CronJob",
+					APIVersion: "batch/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       componentName,
+						"app.kubernetes.io/instance":   componentName,
+						"app.kubernetes.io/part-of":    applicationName,
+						"app.kubernetes.io/managed-by": "kustomize",
+						"app.kubernetes.io/created-by": "application-service",
+					},
+				},
+				Spec: batchv1.CronJobSpec{
+					Schedule:                   "*/5 * * * *",
+					ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+					SuccessfulJobsHistoryLimit: &historyLimit,
+					FailedJobsHistoryLimit:     &historyLimit,
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{
+							Template: corev1.PodTemplateSpec{
+								ObjectMeta: v1.ObjectMeta{
+									Labels: matchLabels,
+								},
+								Spec: corev1.PodSpec{
+									RestartPolicy: corev1.RestartPolicyOnFailure,
+									Containers: []corev1.Container{
+										{
+											Name:            "container-image",
+											Image:           "quay.io/test/test:latest",
+											ImagePullPolicy: corev1.PullAlways,
+											Ports: []corev1.ContainerPort{
+												{
+													ContainerPort: 8080,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedCronJob := generateCronJob(tt.component)
+
+			if !reflect.DeepEqual(*generatedCronJob, tt.wantCronJob) {
+				t.Errorf("TestGenerateCronJob() error: expected %v got %v", tt.wantCronJob, *generatedCronJob)
+			}
+		})
+	}
+}
+
+func TestGenerateKnativeService(t *testing.T) {
+	componentName := "test-component"
+	namespace := "test-namespace"
+	applicationName := "test-application"
+	minReplicas := int32(1)
+
+	tests := []struct {
+		name               string
+		component          gitopsv1alpha1.GeneratorOptions
+		wantKnativeService resources.KnativeService
+	}{
+		{
+			name: "Component with a target port, env, resources and autoscaling set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:           componentName,
+				Namespace:      namespace,
+				Application:    applicationName,
+				WorkloadType:   gitopsv1alpha1.WorkloadTypeKnativeService,
+				ContainerImage: "quay.io/test/test:latest",
+				TargetPort:     8080,
+				BaseEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR",
+					},
+				},
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+				},
+				Autoscaling: &gitopsv1alpha1.HPAOptions{
+					MinReplicas: &minReplicas,
+					MaxReplicas: 10,
+				},
+			},
+			wantKnativeService: resources.KnativeService{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Service",
+					APIVersion: "serving.knative.dev/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       componentName,
+						"app.kubernetes.io/instance":   componentName,
+						"app.kubernetes.io/part-of":    applicationName,
+						"app.kubernetes.io/managed-by": "kustomize",
+						"app.kubernetes.io/created-by": "application-service",
+					},
+				},
+				Spec: resources.KnativeServiceSpec{
+					Template: resources.KnativeRevisionTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{
+							Annotations: map[string]string{
+								"autoscaling.knative.dev/min-scale": "1",
+								"autoscaling.knative.dev/max-scale": "10",
+							},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "container-image",
+									Image: "quay.io/test/test:latest",
+									Ports: []corev1.ContainerPort{
+										{
+											ContainerPort: 8080,
+										},
+									},
+									Env: []corev1.EnvVar{
+										{
+											Name:  "FOO",
+											Value: "BAR",
+										},
+									},
+									Resources: corev1.ResourceRequirements{
+										Limits: corev1.ResourceList{
+											corev1.ResourceCPU: resource.MustParse("1"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedKnativeService := generateKnativeService(tt.component)
+
+			if !reflect.DeepEqual(*generatedKnativeService, tt.wantKnativeService) {
+				t.Errorf("TestGenerateKnativeService() error: expected %v got %v", tt.wantKnativeService, *generatedKnativeService)
+			}
+		})
+	}
+}
+
+func TestGenerateStatefulSetPatch(t *testing.T) {
+	componentName := "test-component"
+	namespace := "test-namespace"
+	containerName := "test-container"
+	replicas := int32(1)
+	image := "image"
+
+	tests := []struct {
+		name            string
+		component       gitopsv1alpha1.GeneratorOptions
+		containerName   string
+		imageName       string
+		namespace       string
+		wantStatefulSet appsv1.StatefulSet
+	}{
+		{
+			name: "Simple component, no optional fields set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:     componentName,
+				Replicas: int(replicas),
+				BaseEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR",
+					},
+				},
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+				},
+				OverlayEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR_ENV",
+					},
+					{
+						Name:  "FOO2",
+						Value: "BAR2_ENV",
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantStatefulSet: appsv1.StatefulSet{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "StatefulSet",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: &replicas,
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+									Env: []corev1.EnvVar{
+										{
+											Name:  "FOO",
+											Value: "BAR",
+										},
+										{
+											Name:  "FOO2",
+											Value: "BAR2_ENV",
+										},
+									},
+									Resources: corev1.ResourceRequirements{
+										Limits: corev1.ResourceList{
+											corev1.ResourceCPU: resource.MustParse("1"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedStatefulSet := generateStatefulSetPatch(tt.component, tt.imageName, tt.containerName, tt.namespace)
+
+			if !reflect.DeepEqual(*generatedStatefulSet, tt.wantStatefulSet) {
+				t.Errorf("TestGenerateStatefulSetPatch() error: expected %v got %v", tt.wantStatefulSet, *generatedStatefulSet)
+			}
+		})
+	}
+}
+
+func TestGenerateDaemonSetPatch(t *testing.T) {
+	componentName := "test-component"
+	namespace := "test-namespace"
+	containerName := "test-container"
+	replicas := int32(1)
+	image := "image"
+
+	tests := []struct {
+		name          string
+		component     gitopsv1alpha1.GeneratorOptions
+		containerName string
+		imageName     string
+		namespace     string
+		wantDaemonSet appsv1.DaemonSet
+	}{
+		{
+			name: "Simple component, no optional fields set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:     componentName,
+				Replicas: int(replicas),
+				BaseEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR",
+					},
+				},
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+				},
+				OverlayEnvVar: []corev1.EnvVar{
+					{
+						Name:  "FOO",
+						Value: "BAR_ENV",
+					},
+					{
+						Name:  "FOO2",
+						Value: "BAR2_ENV",
+					},
+				},
+			},
+			namespace:     namespace,
+			imageName:     image,
+			containerName: containerName,
+			wantDaemonSet: appsv1.DaemonSet{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "DaemonSet",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+				},
+				Spec: appsv1.DaemonSetSpec{
+					Selector: &v1.LabelSelector{},
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  containerName,
+									Image: image,
+									Env: []corev1.EnvVar{
+										{
+											Name:  "FOO",
+											Value: "BAR",
+										},
+										{
+											Name:  "FOO2",
+											Value: "BAR2_ENV",
+										},
+									},
+									Resources: corev1.ResourceRequirements{
+										Limits: corev1.ResourceList{
+											corev1.ResourceCPU: resource.MustParse("1"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedDaemonSet := generateDaemonSetPatch(tt.component, tt.imageName, tt.containerName, tt.namespace)
+
+			if !reflect.DeepEqual(*generatedDaemonSet, tt.wantDaemonSet) {
+				t.Errorf("TestGenerateDaemonSetPatch() error: expected %v got %v", tt.wantDaemonSet, *generatedDaemonSet)
+			}
+		})
+	}
+}
+
+func TestGenerateService(t *testing.T) {
+	applicationName := "test-application"
+	componentName := "test-component"
+	namespace := "test-namespace"
+	customK8sLabels := map[string]string{
+		"app.kubernetes.io/name":       componentName,
+		"app.kubernetes.io/instance":   "ComponentCRName",
+		"app.kubernetes.io/part-of":    applicationName,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": "GitOps Generator Test",
+	}
+	k8slabels := map[string]string{
+		"app.kubernetes.io/name":       componentName,
+		"app.kubernetes.io/instance":   componentName,
+		"app.kubernetes.io/part-of":    applicationName,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": "application-service",
+	}
+	matchLabels := map[string]string{
+		"app.kubernetes.io/instance": componentName,
+	}
+	httpAppProtocol := "http"
+	grpcAppProtocol := "grpc"
+
+	tests := []struct {
+		name        string
+		component   gitopsv1alpha1.GeneratorOptions
+		wantService corev1.Service
+	}{
+		{
+			name: "Simple component object",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: matchLabels,
+					Ports: []corev1.ServicePort{
+						{
+							Port:       int32(5000),
+							TargetPort: intstr.FromInt(5000),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Simple component object with custom k8s labels",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				K8sLabels:   customK8sLabels,
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    customK8sLabels,
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: matchLabels,
+					Ports: []corev1.ServicePort{
+						{
+							Port:       int32(5000),
+							TargetPort: intstr.FromInt(5000),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with annotations set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				Annotations: map[string]string{
+					"example.com/owner": "team-a",
+				},
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+					Annotations: map[string]string{
+						"example.com/owner": "team-a",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: matchLabels,
+					Ports: []corev1.ServicePort{
+						{
+							Port:       int32(5000),
+							TargetPort: intstr.FromInt(5000),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with multiple ports set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				Ports: []gitopsv1alpha1.ComponentPort{
+					{
+						Name:          "http",
+						ContainerPort: 8080,
+						Expose:        true,
+					},
+					{
+						Name:          "syslog",
+						ContainerPort: 5514,
+						Protocol:      corev1.ProtocolUDP,
+					},
+				},
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: matchLabels,
+					Ports: []corev1.ServicePort{
+						{
+							Name:       "http",
+							Port:       int32(8080),
+							TargetPort: intstr.FromInt(8080),
+						},
+						{
+							Name:       "syslog",
+							Protocol:   corev1.ProtocolUDP,
+							Port:       int32(5514),
+							TargetPort: intstr.FromInt(5514),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with a NodePort service type, node port and service annotations set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				ServiceType: corev1.ServiceTypeNodePort,
+				NodePort:    30080,
+				Annotations: map[string]string{
+					"example.com/owner": "team-a",
+				},
+				ServiceAnnotations: map[string]string{
+					"example.com/owner":   "team-b",
+					"example.com/network": "internal",
+				},
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+					Annotations: map[string]string{
+						"example.com/owner":   "team-b",
+						"example.com/network": "internal",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: matchLabels,
+					Type:     corev1.ServiceTypeNodePort,
+					Ports: []corev1.ServicePort{
+						{
+							Port:       int32(5000),
+							TargetPort: intstr.FromInt(5000),
+							NodePort:   30080,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with a LoadBalancer service type set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				ServiceType: corev1.ServiceTypeLoadBalancer,
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: matchLabels,
+					Type:     corev1.ServiceTypeLoadBalancer,
+					Ports: []corev1.ServicePort{
+						{
+							Port:       int32(5000),
+							TargetPort: intstr.FromInt(5000),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with a named target port",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:           componentName,
+				Namespace:      namespace,
+				Application:    applicationName,
+				TargetPort:     5000,
+				NameTargetPort: true,
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: matchLabels,
+					Ports: []corev1.ServicePort{
+						{
+							Name:       "http",
+							Port:       int32(5000),
+							TargetPort: intstr.FromInt(5000),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with multiple ports, should keep the configured order",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:           componentName,
+				Namespace:      namespace,
+				Application:    applicationName,
+				NameTargetPort: true,
+				Ports: []gitopsv1alpha1.ComponentPort{
+					{
+						Name:          "metrics",
+						ContainerPort: 9090,
+						AppProtocol:   &httpAppProtocol,
+					},
+					{
+						Name:          "grpc",
+						ContainerPort: 8081,
+						Protocol:      corev1.ProtocolTCP,
+						AppProtocol:   &grpcAppProtocol,
+						Expose:        true,
+					},
+					{
+						Name:          "http",
+						ContainerPort: 8080,
+					},
+				},
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: matchLabels,
+					Ports: []corev1.ServicePort{
+						{
+							Name:        "metrics",
+							AppProtocol: &httpAppProtocol,
+							Port:        int32(9090),
+							TargetPort:  intstr.FromInt(9090),
+						},
+						{
+							Name:        "grpc",
+							Protocol:    corev1.ProtocolTCP,
+							AppProtocol: &grpcAppProtocol,
+							Port:        int32(8081),
+							TargetPort:  intstr.FromInt(8081),
+						},
+						{
+							Name:       "http",
+							Port:       int32(8080),
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with a headless service",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				Headless:    true,
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: corev1.ServiceSpec{
+					Selector:  matchLabels,
+					ClusterIP: corev1.ClusterIPNone,
+					Ports: []corev1.ServicePort{
+						{
+							Port:       int32(5000),
+							TargetPort: intstr.FromInt(5000),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with a ClientIP session affinity",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:            componentName,
+				Namespace:       namespace,
+				Application:     applicationName,
+				TargetPort:      5000,
+				SessionAffinity: corev1.ServiceAffinityClientIP,
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: corev1.ServiceSpec{
+					Selector:        matchLabels,
+					SessionAffinity: corev1.ServiceAffinityClientIP,
+					Ports: []corev1.ServicePort{
+						{
+							Port:       int32(5000),
+							TargetPort: intstr.FromInt(5000),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component with a LoadBalancer service and a Local external traffic policy",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                  componentName,
+				Namespace:             namespace,
+				Application:           applicationName,
+				TargetPort:            5000,
+				ServiceType:           corev1.ServiceTypeLoadBalancer,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+			},
+			wantService: corev1.Service{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: corev1.ServiceSpec{
+					Selector:              matchLabels,
+					Type:                  corev1.ServiceTypeLoadBalancer,
+					ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+					Ports: []corev1.ServicePort{
+						{
+							Port:       int32(5000),
+							TargetPort: intstr.FromInt(5000),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedService := generateService(tt.component)
+
+			// Generating the service again must give the same ports in the same order
+			if regeneratedService := generateService(tt.component); !reflect.DeepEqual(generatedService.Spec.Ports, regeneratedService.Spec.Ports) {
+				t.Errorf("TestGenerateService() error: ports are not deterministic, got %v then %v", generatedService.Spec.Ports, regeneratedService.Spec.Ports)
+			}
+
+			if !reflect.DeepEqual(*generatedService, tt.wantService) {
+				t.Errorf("TestGenerateService() error: expected %v got %v", tt.wantService, generatedService)
+			}
+		})
+	}
+}
+
+func TestGenerateServiceAccount(t *testing.T) {
+	applicationName := "test-application"
+	componentName := "test-component"
+	namespace := "test-namespace"
+	k8slabels := map[string]string{
+		"app.kubernetes.io/name":       componentName,
+		"app.kubernetes.io/instance":   componentName,
+		"app.kubernetes.io/part-of":    applicationName,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": "application-service",
+	}
+
+	tests := []struct {
+		name               string
+		component          gitopsv1alpha1.GeneratorOptions
+		wantServiceAccount corev1.ServiceAccount
+	}{
+		{
+			name: "Service account named after the component",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                 componentName,
+				Namespace:            namespace,
+				Application:          applicationName,
+				CreateServiceAccount: true,
+			},
+			wantServiceAccount: corev1.ServiceAccount{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ServiceAccount",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+			},
+		},
+		{
+			name: "Named service account with an image pull secret",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                 componentName,
+				Namespace:            namespace,
+				Application:          applicationName,
+				ContainerImage:       "quay.io/test/test:latest",
+				Secret:               "my-image-pull-secret",
+				ServiceAccountName:   "my-service-account",
+				CreateServiceAccount: true,
+			},
+			wantServiceAccount: corev1.ServiceAccount{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ServiceAccount",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "my-service-account",
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				ImagePullSecrets: []corev1.LocalObjectReference{
+					{
+						Name: "my-image-pull-secret",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedServiceAccount := generateServiceAccount(tt.component)
+
+			if !reflect.DeepEqual(*generatedServiceAccount, tt.wantServiceAccount) {
+				t.Errorf("TestGenerateServiceAccount() error: expected %v got %v", tt.wantServiceAccount, *generatedServiceAccount)
+			}
+
+			generatedDeployment := generateDeployment(tt.component)
+			if generatedDeployment.Spec.Template.Spec.ServiceAccountName != tt.wantServiceAccount.Name {
+				t.Errorf("TestGenerateServiceAccount() error: expected deployment service account %v got %v", tt.wantServiceAccount.Name, generatedDeployment.Spec.Template.Spec.ServiceAccountName)
+			}
+		})
+	}
+}
+
+func TestGenerateNetworkPolicy(t *testing.T) {
+	applicationName := "test-application"
+	componentName := "test-component"
+	namespace := "test-namespace"
+	k8slabels := map[string]string{
+		"app.kubernetes.io/name":       componentName,
+		"app.kubernetes.io/instance":   componentName,
+		"app.kubernetes.io/part-of":    applicationName,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": "application-service",
+	}
+	matchLabels := map[string]string{
+		"app.kubernetes.io/instance": componentName,
+	}
+	httpPort := intstr.FromInt(8080)
+	metricsPort := intstr.FromInt(9090)
+	udp := corev1.ProtocolUDP
+
+	tests := []struct {
+		name              string
+		component         gitopsv1alpha1.GeneratorOptions
+		wantNetworkPolicy networkingv1.NetworkPolicy
+	}{
+		{
+			name: "Component with ports allows ingress to them from the namespace and the allowed namespaces",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                  componentName,
+				Namespace:             namespace,
+				Application:           applicationName,
+				GenerateNetworkPolicy: true,
+				Ports: []gitopsv1alpha1.ComponentPort{
+					{
+						Name:          "http",
+						ContainerPort: 8080,
+						Expose:        true,
+					},
+					{
+						Name:          "metrics",
+						ContainerPort: 9090,
+						Protocol:      corev1.ProtocolUDP,
+					},
+				},
+				NetworkPolicyAllowedNamespaces: []string{"monitoring"},
+				NetworkPolicyAllowedNamespaceLabels: map[string]string{
+					"network.openshift.io/policy-group": "ingress",
+				},
+			},
+			wantNetworkPolicy: networkingv1.NetworkPolicy{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "networking.k8s.io/v1",
+					Kind:       "NetworkPolicy",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					Ingress: []networkingv1.NetworkPolicyIngressRule{
+						{
+							Ports: []networkingv1.NetworkPolicyPort{
+								{
+									Port: &httpPort,
+								},
+								{
+									Protocol: &udp,
+									Port:     &metricsPort,
+								},
+							},
+							From: []networkingv1.NetworkPolicyPeer{
+								{
+									PodSelector: &v1.LabelSelector{},
+								},
+								{
+									NamespaceSelector: &v1.LabelSelector{
+										MatchLabels: map[string]string{
+											"kubernetes.io/metadata.name": "monitoring",
+										},
+									},
+								},
+								{
+									NamespaceSelector: &v1.LabelSelector{
+										MatchLabels: map[string]string{
+											"network.openshift.io/policy-group": "ingress",
+										},
+									},
+								},
+							},
+						},
+					},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+				},
+			},
+		},
+		{
+			name: "Component without ports denies all ingress",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                           componentName,
+				Namespace:                      namespace,
+				Application:                    applicationName,
+				GenerateNetworkPolicy:          true,
+				NetworkPolicyAllowedNamespaces: []string{"monitoring"},
+			},
+			wantNetworkPolicy: networkingv1.NetworkPolicy{
+				TypeMeta: v1.TypeMeta{
+					APIVersion: "networking.k8s.io/v1",
+					Kind:       "NetworkPolicy",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: v1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedNetworkPolicy := generateNetworkPolicy(tt.component)
+
+			if !reflect.DeepEqual(*generatedNetworkPolicy, tt.wantNetworkPolicy) {
+				t.Errorf("TestGenerateNetworkPolicy() error: expected %v got %v", tt.wantNetworkPolicy, *generatedNetworkPolicy)
+			}
+
+			// The network policy is written to the base folder
+			fs := ioutils.NewMemoryFilesystem()
+			err := Generate(fs, "/tmp/networkpolicy", "/tmp/networkpolicy/base", tt.component)
+			assertNoError(t, err)
+			exist, err := fs.Exists(filepath.Join("/tmp/networkpolicy/base", networkPolicyFileName))
+			assertNoError(t, err)
+			assert.True(t, exist)
+			kustomizationBytes, err := fs.ReadFile(filepath.Join("/tmp/networkpolicy/base", kustomizeFileName))
+			assertNoError(t, err)
+			assert.Contains(t, string(kustomizationBytes), networkPolicyFileName)
+		})
+	}
+}
+
+func TestGenerateRoute(t *testing.T) {
+	applicationName := "test-application"
+	componentName := "test-component"
+	namespace := "test-namespace"
+	customK8sLabels := map[string]string{
+		"app.kubernetes.io/name":       componentName,
+		"app.kubernetes.io/instance":   "ComponentCRName",
+		"app.kubernetes.io/part-of":    applicationName,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": "GitOps Generator Test",
+	}
+	k8slabels := map[string]string{
+		"app.kubernetes.io/name":       componentName,
+		"app.kubernetes.io/instance":   componentName,
+		"app.kubernetes.io/part-of":    applicationName,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": "application-service",
+	}
+	weight := int32(100)
+	blueWeight := int32(90)
+	greenWeight := int32(10)
+	canaryWeight := int32(0)
+
+	tests := []struct {
+		name      string
+		component gitopsv1alpha1.GeneratorOptions
+		wantRoute routev1.Route
+	}{
+		{
+			name: "Simple component object",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with route/hostname and custom k8s labels set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				K8sLabels:   customK8sLabels,
+				Route:       "example.com",
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    customK8sLabels,
+				},
+				Spec: routev1.RouteSpec{
+					Host: "example.com",
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Generated route with trimmed CR name",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        "some-longer-component-name-test-string",
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				K8sLabels: map[string]string{
+					"app.kubernetes.io/name":       "some-longer-component-name-test-string",
+					"app.kubernetes.io/instance":   "ComponentCRName",
+					"app.kubernetes.io/part-of":    applicationName,
+					"app.kubernetes.io/managed-by": "kustomize",
+					"app.kubernetes.io/created-by": "GitOps Generator Test",
+				},
+				Route: "example.com",
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       "some-longer-component-name-test-string",
+						"app.kubernetes.io/instance":   "ComponentCRName",
+						"app.kubernetes.io/part-of":    applicationName,
+						"app.kubernetes.io/managed-by": "kustomize",
+						"app.kubernetes.io/created-by": "GitOps Generator Test",
+					},
+				},
+				Spec: routev1.RouteSpec{
+					Host: "example.com",
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   "some-longer-component-name-test-string",
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with custom route name set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				K8sLabels:   customK8sLabels,
+				Route:       "example.com",
+				RouteName:   "my-route-name",
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "my-route-name",
+					Namespace: namespace,
+					Labels:    customK8sLabels,
+				},
+				Spec: routev1.RouteSpec{
+					Host: "example.com",
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with annotations set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				Annotations: map[string]string{
+					"example.com/owner": "team-a",
+				},
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+					Annotations: map[string]string{
+						"example.com/owner": "team-a",
+					},
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with multiple ports set, targets the exposed port",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				Ports: []gitopsv1alpha1.ComponentPort{
+					{
+						Name:          "metrics",
+						ContainerPort: 9090,
+					},
+					{
+						Name:          "http",
+						ContainerPort: 8080,
+						Expose:        true,
+					},
+				},
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(8080),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with edge route termination",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				RouteTLS: &gitopsv1alpha1.RouteTLSOptions{
+					InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
+				},
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with passthrough route termination",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  8443,
+				RouteTLS: &gitopsv1alpha1.RouteTLSOptions{
+					Termination:                   routev1.TLSTerminationPassthrough,
+					InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+				},
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(8443),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+						Termination:                   routev1.TLSTerminationPassthrough,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with reencrypt route termination",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  8443,
+				RouteTLS: &gitopsv1alpha1.RouteTLSOptions{
+					Termination:              routev1.TLSTerminationReencrypt,
+					DestinationCACertificate: "-----BEGIN CERTIFICATE-----",
+				},
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(8443),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationReencrypt,
+						DestinationCACertificate:      "-----BEGIN CERTIFICATE-----",
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with a wildcard route subdomain",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                componentName,
+				Namespace:           namespace,
+				Application:         applicationName,
+				TargetPort:          5000,
+				RouteSubdomain:      "preview",
+				RouteWildcardPolicy: routev1.WildcardPolicySubdomain,
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: routev1.RouteSpec{
+					Subdomain: "preview",
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+					WildcardPolicy: routev1.WildcardPolicySubdomain,
+				},
+			},
+		},
+		{
+			name: "Component object with route annotations set",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				Annotations: map[string]string{
+					"example.com/owner": "team-a",
+				},
+				RouteAnnotations: map[string]string{
+					"haproxy.router.openshift.io/timeout": "2m",
+					"example.com/owner":                   "team-b",
+				},
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+					Annotations: map[string]string{
+						"haproxy.router.openshift.io/timeout": "2m",
+						"example.com/owner":                   "team-b",
+					},
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with two route backends",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				RouteBackends: []gitopsv1alpha1.RouteBackend{
+					{
+						ServiceName: componentName + "-blue",
+						Weight:      90,
+					},
+					{
+						ServiceName: componentName + "-green",
+						Weight:      10,
+					},
+				},
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName + "-blue",
+						Weight: &blueWeight,
+					},
+					AlternateBackends: []routev1.RouteTargetReference{
+						{
+							Kind:   "Service",
+							Name:   componentName + "-green",
+							Weight: &greenWeight,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with three route backends",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				RouteBackends: []gitopsv1alpha1.RouteBackend{
+					{
+						ServiceName: componentName + "-blue",
+						Weight:      90,
+					},
+					{
+						ServiceName: componentName + "-green",
+						Weight:      10,
+					},
+					{
+						ServiceName: componentName + "-canary",
+						Weight:      0,
+					},
+				},
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					TLS: &routev1.TLSConfig{
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+						Termination:                   routev1.TLSTerminationEdge,
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName + "-blue",
+						Weight: &blueWeight,
+					},
+					AlternateBackends: []routev1.RouteTargetReference{
+						{
+							Kind:   "Service",
+							Name:   componentName + "-green",
+							Weight: &greenWeight,
+						},
+						{
+							Kind:   "Service",
+							Name:   componentName + "-canary",
+							Weight: &canaryWeight,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Component object with an insecure route",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:               componentName,
+				Namespace:          namespace,
+				Application:        applicationName,
+				TargetPort:         5000,
+				RouteAllowInsecure: true,
+			},
+			wantRoute: routev1.Route{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Route",
+					APIVersion: "route.openshift.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: routev1.RouteSpec{
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromInt(5000),
+					},
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   componentName,
+						Weight: &weight,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedRoute := generateRoute(tt.component)
+			if len(generatedRoute.Name) > 30 {
+				t.Errorf("TestGenerateRoute() error: expected CR name of length 30, got %v", len(generatedRoute.Name))
+			}
+
+			if tt.name == "Generated route with trimmed CR name" {
+				trimmedComponentName := "some-longer-component-nam"
+				if !strings.Contains(generatedRoute.Name, trimmedComponentName) {
+					t.Errorf("TestGenerateRoute() error: expected component CR name to contain %v got %v", tt.wantRoute, generatedRoute)
+				}
+
+				tt.wantRoute.Name = generatedRoute.Name
+			}
+			if !reflect.DeepEqual(*generatedRoute, tt.wantRoute) {
+				t.Errorf("TestGenerateRoute() error: expected %v got %v", tt.wantRoute, generatedRoute)
+			}
+
+			// An insecure route must have no TLS block at all, rather than an empty one
+			if tt.component.RouteAllowInsecure {
+				routeBytes, err := yaml.Marshal(generatedRoute)
+				assertNoError(t, err)
+				assert.NotContains(t, string(routeBytes), "tls:")
+			}
+		})
+	}
+}
+
+func TestGenerateIngress(t *testing.T) {
+	applicationName := "test-application"
+	componentName := "test-component"
+	namespace := "test-namespace"
+	customK8sLabels := map[string]string{
+		"app.kubernetes.io/name":       componentName,
+		"app.kubernetes.io/instance":   "ComponentCRName",
+		"app.kubernetes.io/part-of":    applicationName,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": "GitOps Generator Test",
+	}
+	k8slabels := map[string]string{
+		"app.kubernetes.io/name":       componentName,
+		"app.kubernetes.io/instance":   componentName,
+		"app.kubernetes.io/part-of":    applicationName,
+		"app.kubernetes.io/managed-by": "kustomize",
+		"app.kubernetes.io/created-by": "application-service",
+	}
+	prefixPathType := networkingv1.PathTypePrefix
+	exactPathType := networkingv1.PathTypeExact
+	ingressClassName := "nginx"
+	httpRuleValue := networkingv1.IngressRuleValue{
+		HTTP: &networkingv1.HTTPIngressRuleValue{
+			Paths: []networkingv1.HTTPIngressPath{
+				{
+					Path:     "/",
+					PathType: &prefixPathType,
+					Backend: networkingv1.IngressBackend{
+						Service: &networkingv1.IngressServiceBackend{
+							Name: componentName,
+							Port: networkingv1.ServiceBackendPort{
+								Number: 5000,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		options     gitopsv1alpha1.GeneratorOptions
+		wantIngress networkingv1.Ingress
+	}{
+		{
+			name: "Simple options object",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				Route:       componentName + ".example.com",
+			},
+			wantIngress: networkingv1.Ingress{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Ingress",
+					APIVersion: "networking.k8s.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{
+							IngressRuleValue: networkingv1.IngressRuleValue{
+								HTTP: &networkingv1.HTTPIngressRuleValue{
+									Paths: []networkingv1.HTTPIngressPath{
+										{
+											Path:     "/",
+											PathType: &prefixPathType,
+											Backend: networkingv1.IngressBackend{
+												Service: &networkingv1.IngressServiceBackend{
+													Name: componentName,
+													Port: networkingv1.ServiceBackendPort{
+														Number: 5000,
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+							Host: componentName + ".example.com",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Options object with custom k8s labels set",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				K8sLabels:   customK8sLabels,
+				Route:       componentName + ".example.com",
+			},
+			wantIngress: networkingv1.Ingress{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Ingress",
+					APIVersion: "networking.k8s.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    customK8sLabels,
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{
+							IngressRuleValue: networkingv1.IngressRuleValue{
+								HTTP: &networkingv1.HTTPIngressRuleValue{
+									Paths: []networkingv1.HTTPIngressPath{
+										{
+											Path:     "/",
+											PathType: &prefixPathType,
+											Backend: networkingv1.IngressBackend{
+												Service: &networkingv1.IngressServiceBackend{
+													Name: componentName,
+													Port: networkingv1.ServiceBackendPort{
+														Number: 5000,
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+							Host: componentName + ".example.com",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Options object with multiple TLS hosts sharing one secret",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				Route:       componentName + ".example.com",
+				IngressTLS: &gitopsv1alpha1.IngressTLSOptions{
+					SecretName: "example-tls",
+					Hosts:      []string{componentName + ".example.com", componentName + ".example.org"},
+				},
+			},
+			wantIngress: networkingv1.Ingress{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Ingress",
+					APIVersion: "networking.k8s.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: networkingv1.IngressSpec{
+					TLS: []networkingv1.IngressTLS{
+						{
+							Hosts:      []string{componentName + ".example.com", componentName + ".example.org"},
+							SecretName: "example-tls",
+						},
+					},
+					Rules: []networkingv1.IngressRule{
+						{
+							Host:             componentName + ".example.com",
+							IngressRuleValue: httpRuleValue,
+						},
+						{
+							Host:             componentName + ".example.org",
+							IngressRuleValue: httpRuleValue,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Options object with TLS in auto mode",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				Route:       componentName + ".example.com",
+				IngressTLS: &gitopsv1alpha1.IngressTLSOptions{
+					Auto: true,
+				},
+			},
+			wantIngress: networkingv1.Ingress{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Ingress",
+					APIVersion: "networking.k8s.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: networkingv1.IngressSpec{
+					TLS: []networkingv1.IngressTLS{
+						{
+							Hosts: []string{componentName + ".example.com"},
+						},
+					},
+					Rules: []networkingv1.IngressRule{
+						{
+							Host:             componentName + ".example.com",
+							IngressRuleValue: httpRuleValue,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Options object with TLS hosts but no secret, should not set TLS",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				Route:       componentName + ".example.com",
+				IngressTLS: &gitopsv1alpha1.IngressTLSOptions{
+					Hosts: []string{componentName + ".example.org"},
+				},
+			},
+			wantIngress: networkingv1.Ingress{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Ingress",
+					APIVersion: "networking.k8s.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{
+							Host:             componentName + ".example.com",
+							IngressRuleValue: httpRuleValue,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Options object with an ingress class name",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:             componentName,
+				Namespace:        namespace,
+				Application:      applicationName,
+				TargetPort:       5000,
+				Route:            componentName + ".example.com",
+				IngressClassName: "nginx",
+			},
+			wantIngress: networkingv1.Ingress{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Ingress",
+					APIVersion: "networking.k8s.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: networkingv1.IngressSpec{
+					IngressClassName: &ingressClassName,
+					Rules: []networkingv1.IngressRule{
+						{
+							Host:             componentName + ".example.com",
+							IngressRuleValue: httpRuleValue,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Options object with the legacy ingress class annotation and ingress annotations",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:                         componentName,
+				Namespace:                    namespace,
+				Application:                  applicationName,
+				TargetPort:                   5000,
+				Route:                        componentName + ".example.com",
+				K8sLabels:                    customK8sLabels,
+				IngressClassName:             "nginx",
+				LegacyIngressClassAnnotation: true,
+				Annotations: map[string]string{
+					"example.com/owner": "team-a",
+				},
+				IngressAnnotations: map[string]string{
+					"cert-manager.io/cluster-issuer": "letsencrypt",
+				},
+			},
+			wantIngress: networkingv1.Ingress{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Ingress",
+					APIVersion: "networking.k8s.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    customK8sLabels,
+					Annotations: map[string]string{
+						"example.com/owner":              "team-a",
+						"cert-manager.io/cluster-issuer": "letsencrypt",
+						"kubernetes.io/ingress.class":    "nginx",
+					},
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{
+							Host:             componentName + ".example.com",
+							IngressRuleValue: httpRuleValue,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Options object with a custom ingress path and path type",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:            componentName,
+				Namespace:       namespace,
+				Application:     applicationName,
+				TargetPort:      5000,
+				Route:           componentName + ".example.com",
+				IngressPath:     "/api",
+				IngressPathType: networkingv1.PathTypeExact,
+			},
+			wantIngress: networkingv1.Ingress{
+				TypeMeta: v1.TypeMeta{
+					Kind:       "Ingress",
+					APIVersion: "networking.k8s.io/v1",
+				},
+				ObjectMeta: v1.ObjectMeta{
+					Name:      componentName,
+					Namespace: namespace,
+					Labels:    k8slabels,
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{
+							IngressRuleValue: networkingv1.IngressRuleValue{
+								HTTP: &networkingv1.HTTPIngressRuleValue{
+									Paths: []networkingv1.HTTPIngressPath{
+										{
+											Path:     "/api",
+											PathType: &exactPathType,
+											Backend: networkingv1.IngressBackend{
+												Service: &networkingv1.IngressServiceBackend{
+													Name: componentName,
+													Port: networkingv1.ServiceBackendPort{
+														Number: 5000,
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+							Host: componentName + ".example.com",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations := make(map[string]string)
+			for key, value := range tt.options.Annotations {
+				annotations[key] = value
+			}
+			generatedIngress := generateIngress(tt.options)
+			// The common annotations must not be modified by the ingress specific ones
+			if len(tt.options.Annotations) > 0 && !reflect.DeepEqual(tt.options.Annotations, annotations) {
+				t.Errorf("TestGenerateIngress() error: common annotations were modified, expected %v got %v", annotations, tt.options.Annotations)
+			}
+			if len(generatedIngress.Name) > 30 {
+				t.Errorf("TestGenerateIngress() error: expected CR name of length 30, got %v", len(generatedIngress.Name))
+			}
+
+			if tt.name == "Generated ingress with trimmed CR name" {
+				trimmedComponentName := "some-longer-component-nam"
+				if !strings.Contains(generatedIngress.Name, trimmedComponentName) {
+					t.Errorf("TestGenerateRoute() error: expected component CR name to contain %v got %v", tt.wantIngress, generatedIngress)
+				}
+
+				tt.wantIngress.Name = generatedIngress.Name
+			}
+			if !reflect.DeepEqual(*generatedIngress, tt.wantIngress) {
+				t.Errorf("TestGenerateRoute() error: expected %v got %v", tt.wantIngress, *generatedIngress)
+			}
+		})
+	}
+}
+
+func TestGenerateOverlays(t *testing.T) {
+
+	imageName := "test-image"
+	namespace := "test-namespace"
+	containerName := "test-container"
+
+	fs := ioutils.NewMemoryFilesystem()
+	readOnlyFs := ioutils.NewReadOnlyFs()
+
+	route1 := routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "route1",
+		},
+	}
+	route2 := routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "route2",
+		},
+	}
+
+	ingress1 := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ingress1",
+		},
+	}
+	ingress2 := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ingress2",
+		},
+	}
+
+	// Prepopulate the fs with components
+	gitOpsFolder := "/tmp/gitops"
+	fs.MkdirAll(gitOpsFolder, 0755)
+	baseFolder := filepath.Join(gitOpsFolder, "../", "base")
+	fs.MkdirAll(baseFolder, 0755)
+	baseDeploymentFilePath := filepath.Join(baseFolder, "deployment.yaml")
+	baseDeployment := appsv1.Deployment{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test-component",
+			Namespace: namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &v1.LabelSelector{},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  containerName,
+							Image: imageName,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	bytes, err := yaml.Marshal(baseDeployment)
+	if err != nil {
+		t.Errorf("unexpected error when marshal the base deployment yaml %v", err)
+	}
+	err = fs.WriteFile(baseDeploymentFilePath, bytes, 0755)
+	if err != nil {
+		t.Errorf("unexpected error when writing to base deployment file: %v", err)
+	}
+
+	outputFolder := filepath.Join(gitOpsFolder, "overlays")
+	fs.MkdirAll(outputFolder, 0755)
+
+	outputFolderWithKustomizationFile := filepath.Join(gitOpsFolder, "overlays-2")
+	fs.MkdirAll(outputFolderWithKustomizationFile, 0755)
+	preExistKustomizationFilepath := filepath.Join(outputFolderWithKustomizationFile, "kustomization.yaml")
+	k := resources.Kustomization{
+		Patches: []resources.Patch{
+			{
+				Path: "patch1.yaml",
+			},
+			{
+				Path: "custom-patch1.yaml",
+			},
+		},
+	}
+	bytes, err = yaml.Marshal(k)
+	if err != nil {
+		t.Errorf("unexpected error when marshal the kustomization yaml %v", err)
+	}
+	err = fs.WriteFile(preExistKustomizationFilepath, bytes, 0755)
+	if err != nil {
+		t.Errorf("unexpected error when writing to kustomizatipn file: %v", err)
+	}
+
+	invalidKustomizationFileFolder := filepath.Join(gitOpsFolder, "overlays-error")
+	fs.MkdirAll(invalidKustomizationFileFolder, 0755)
+	invalidKustomizationFilepath := filepath.Join(invalidKustomizationFileFolder, "kustomization.yaml")
+	invalidKustomization := map[string]interface{}{
+		"Resources": 8,
+	}
+	bytes, err = yaml.Marshal(invalidKustomization)
+	if err != nil {
+		t.Errorf("unexpected error when marshal the kustomization yaml %v", err)
+	}
+	err = fs.WriteFile(invalidKustomizationFilepath, bytes, 0755)
+	if err != nil {
+		t.Errorf("unexpected error when writing to kustomizatipn file: %v", err)
+	}
+
+	gitOpsFolder2 := "/tmp/statefulset"
+	fs.MkdirAll(gitOpsFolder2, 0755)
+	baseFolder2 := filepath.Join(gitOpsFolder2, "base")
+	fs.MkdirAll(baseFolder2, 0755)
+	baseStatefulSetFilePath := filepath.Join(baseFolder2, "statefulset.yaml")
+	baseStatefulSet := appsv1.StatefulSet{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test-component",
+			Namespace: namespace,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &v1.LabelSelector{},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  containerName,
+							Image: imageName,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	bytes, err = yaml.Marshal(baseStatefulSet)
+	if err != nil {
+		t.Errorf("unexpected error when marshal the base statefulset yaml %v", err)
+	}
+	err = fs.WriteFile(baseStatefulSetFilePath, bytes, 0755)
+	if err != nil {
+		t.Errorf("unexpected error when writing to base statefulset file: %v", err)
+	}
+
+	outputFolder2 := filepath.Join(gitOpsFolder2, "overlays", "development")
+	fs.MkdirAll(outputFolder2, 0755)
+
+	gitOpsFolder3 := "/tmp/daemonset"
+	fs.MkdirAll(gitOpsFolder2, 0755)
+	baseFolder3 := filepath.Join(gitOpsFolder3, "base")
+	fs.MkdirAll(baseFolder2, 0755)
+	baseDaemonSetFilePath := filepath.Join(baseFolder3, "daemonset.yaml")
+	baseDaemonSet := appsv1.DaemonSet{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test-component",
+			Namespace: namespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &v1.LabelSelector{},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  containerName,
+							Image: imageName,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	bytes, err = yaml.Marshal(baseDaemonSet)
+	if err != nil {
+		t.Errorf("unexpected error when marshal the base daemonset yaml %v", err)
+	}
+	err = fs.WriteFile(baseDaemonSetFilePath, bytes, 0755)
+	if err != nil {
+		t.Errorf("unexpected error when writing to base daemonset file: %v", err)
+	}
+
+	outputFolder3 := filepath.Join(gitOpsFolder3, "overlays", "development")
+	fs.MkdirAll(outputFolder3, 0755)
+
+	tests := []struct {
+		name                        string
+		fs                          afero.Afero
+		options                     gitopsv1alpha1.GeneratorOptions
+		outputFolder                string
+		expectPatchEntries          int
+		componentGeneratedResources map[string][]string
+		wantErr                     string
+	}{
+		{
+			name: "simple success case",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+			},
+			outputFolder:       outputFolder,
+			expectPatchEntries: 1,
+			wantErr:            "",
+		},
+		{
+			name: "simple success case with statefulset",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+			},
+			outputFolder:       outputFolder2,
+			expectPatchEntries: 1,
+			wantErr:            "",
+		},
+		{
+			name: "simple success case with daemonset",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+			},
+			outputFolder:       outputFolder3,
+			expectPatchEntries: 1,
+			wantErr:            "",
+		},
+		{
+			name: "simple success case on OpenShift with Routes",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Routes: []routev1.Route{
+						route1,
+						route2,
+					},
+					Ingresses: []networkingv1.Ingress{
+						ingress1,
+						ingress2,
+					},
+				},
+			},
+			outputFolder:       outputFolder,
+			expectPatchEntries: 1,
+			wantErr:            "",
+		},
+		{
+			name: "simple success case on OpenShift where Route is generated",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:       "test-component",
+				TargetPort: 8080,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Ingresses: []networkingv1.Ingress{
+						ingress1,
+						ingress2,
+					},
+				},
+			},
+			outputFolder:       outputFolder,
+			expectPatchEntries: 1,
+			wantErr:            "",
+		},
+		{
+			name: "simple success case on Kuberntes with Ingresses",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Routes: []routev1.Route{
+						route1,
+						route2,
+					},
+					Ingresses: []networkingv1.Ingress{
+						ingress1,
+						ingress2,
+					},
+				},
+				IsKubernetesCluster: true,
+			},
+			outputFolder:       outputFolder,
+			expectPatchEntries: 1,
+			wantErr:            "",
+		},
+		{
+			name: "simple success case on OpenShift where Ingress is generated",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:                "test-component",
+				TargetPort:          8080,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{},
+				IsKubernetesCluster: true,
+			},
+			outputFolder:       outputFolder,
+			expectPatchEntries: 1,
+			wantErr:            "",
+		},
+		{
+			name: "existing kustomization file with custom patches",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+			},
+			outputFolder:       outputFolderWithKustomizationFile,
+			expectPatchEntries: 3,
+			wantErr:            "",
+		},
+		{
+			name: "read only fs",
+			fs:   readOnlyFs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+			},
+			outputFolder: outputFolderWithKustomizationFile,
+			wantErr:      "failed to MkDirAll",
+		},
+		{
+			name: "unmarshall error",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+			},
+			outputFolder: invalidKustomizationFileFolder,
+			wantErr:      " failed to unmarshal data: error unmarshaling JSON: while decoding JSON: json: cannot unmarshal number into Go struct field Kustomization.resources",
+		},
+		{
+			name: "genereated an additional patch",
+			fs:   fs,
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+			},
+			outputFolder: outputFolderWithKustomizationFile,
+			componentGeneratedResources: map[string][]string{
+				"test-component": {
+					"patch1.yaml",
+				},
+			},
+			expectPatchEntries: 3,
+			wantErr:            "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := GenerateOverlays(tt.fs, gitOpsFolder, tt.outputFolder, tt.options, imageName, namespace, tt.componentGeneratedResources)
+
+			if !testutils.ErrorMatch(t, tt.wantErr, err) {
+				t.Errorf("unexpected error return value. Got %v", err)
+			}
+
+			var exists bool
+			if tt.wantErr == "" {
+				if strings.Contains(tt.name, "statefulset") {
+					// Validate that the statefulset-patch.yaml preserve the container name
+					statefulSetPatchFilepath := filepath.Join(tt.outputFolder, statefulsetPatchFileName)
+					exists, err := tt.fs.Exists(statefulSetPatchFilepath)
+					if err != nil {
+						t.Errorf("unexpected error checking if statefulset patch file exists %v", err)
+					}
+					if !exists {
+						t.Errorf("statefulset file does not exist at path %v", statefulSetPatchFilepath)
+					}
+
+					statefulSetPatch := appsv1.StatefulSet{}
+					statefulSetPatchBytes, err := tt.fs.ReadFile(statefulSetPatchFilepath)
+					if err != nil {
+						t.Errorf("unexpected error reading statefulset file")
+					}
+					err = yaml.Unmarshal(statefulSetPatchBytes, &statefulSetPatch)
 					if err != nil {
 						t.Errorf("unexpected error unmarshaling statefulset patch file bytes")
 					}
@@ -1442,132 +4968,2676 @@ func TestGenerateOverlays(t *testing.T) {
 						t.Errorf("daemonset file does not exist at path %v", daemonSetPatchFilepath)
 					}
 
-					daemonSetPatch := appsv1.DaemonSet{}
-					daemonSetPatchBytes, err := tt.fs.ReadFile(daemonSetPatchFilepath)
-					if err != nil {
-						t.Errorf("unexpected error reading daemonset file")
-					}
-					err = yaml.Unmarshal(daemonSetPatchBytes, &daemonSetPatch)
-					if err != nil {
-						t.Errorf("unexpected error unmarshaling daemonset patch file bytes")
-					}
-					if daemonSetPatch.Spec.Template.Spec.Containers[0].Name != containerName {
-						t.Errorf("expected container name %v, got %v", containerName, daemonSetPatch.Spec.Template.Spec.Containers[0].Name)
-					}
-				} else {
-					// Validate that the deployment-patch.yaml preserve the container name
-					deploymentPatchFilepath := filepath.Join(tt.outputFolder, deploymentPatchFileName)
-					exists, err := tt.fs.Exists(deploymentPatchFilepath)
-					if err != nil {
-						t.Errorf("unexpected error checking if deployment patch file exists %v", err)
-					}
-					if !exists {
-						t.Errorf("deployment file does not exist at path %v", deploymentPatchFilepath)
-					}
+					daemonSetPatch := appsv1.DaemonSet{}
+					daemonSetPatchBytes, err := tt.fs.ReadFile(daemonSetPatchFilepath)
+					if err != nil {
+						t.Errorf("unexpected error reading daemonset file")
+					}
+					err = yaml.Unmarshal(daemonSetPatchBytes, &daemonSetPatch)
+					if err != nil {
+						t.Errorf("unexpected error unmarshaling daemonset patch file bytes")
+					}
+					if daemonSetPatch.Spec.Template.Spec.Containers[0].Name != containerName {
+						t.Errorf("expected container name %v, got %v", containerName, daemonSetPatch.Spec.Template.Spec.Containers[0].Name)
+					}
+				} else {
+					// Validate that the deployment-patch.yaml preserve the container name
+					deploymentPatchFilepath := filepath.Join(tt.outputFolder, deploymentPatchFileName)
+					exists, err := tt.fs.Exists(deploymentPatchFilepath)
+					if err != nil {
+						t.Errorf("unexpected error checking if deployment patch file exists %v", err)
+					}
+					if !exists {
+						t.Errorf("deployment file does not exist at path %v", deploymentPatchFilepath)
+					}
+
+					deployPatch := appsv1.Deployment{}
+					deploymentPatchBytes, err := tt.fs.ReadFile(deploymentPatchFilepath)
+					if err != nil {
+						t.Errorf("unexpected error reading deployment file")
+					}
+					err = yaml.Unmarshal(deploymentPatchBytes, &deployPatch)
+					if err != nil {
+						t.Errorf("unexpected error unmarshaling deployment patch file bytes")
+					}
+					if deployPatch.Spec.Template.Spec.Containers[0].Name != containerName {
+						t.Errorf("expected container name %v, got %v", containerName, deployPatch.Spec.Template.Spec.Containers[0].Name)
+					}
+				}
+
+				if tt.options.IsKubernetesCluster {
+					// Validate that the ingress.yaml got created successfully and contains the proper entries
+					ingressFilepath := filepath.Join(tt.outputFolder, ingressFileName)
+					exists, err = tt.fs.Exists(ingressFilepath)
+					if err != nil {
+						t.Errorf("unexpected error checking if ingress file exists %v", err)
+					}
+					if !exists && tt.options.TargetPort != 0 {
+						t.Errorf("ingress file does not exist at path %v", ingressFilepath)
+					} else if exists {
+						ingress := networkingv1.Ingress{}
+						ingressBytes, err := tt.fs.ReadFile(ingressFilepath)
+						if err != nil {
+							t.Errorf("unexpected error reading ingress file")
+						}
+						err = yaml.Unmarshal(ingressBytes, &ingress)
+						if err != nil {
+							t.Errorf("unexpected error unmarshaling ingress file bytes")
+						}
+						if len(tt.options.KubernetesResources.Ingresses) > 0 && ingress.Name != tt.options.KubernetesResources.Ingresses[0].Name {
+							t.Errorf("expected ingress name %v, got %v", tt.options.KubernetesResources.Ingresses[0].Name, ingress.Name)
+						} else if len(tt.options.KubernetesResources.Ingresses) == 0 && ingress.Name != tt.options.Name {
+							t.Errorf("expected ingress name %v, got %v", tt.options.Name, ingress.Name)
+						}
+					}
+
+				} else {
+					// Validate that the route.yaml got created successfully and contains the proper entries
+					routeFilepath := filepath.Join(tt.outputFolder, routeFileName)
+					exists, err = tt.fs.Exists(routeFilepath)
+					if err != nil {
+						t.Errorf("unexpected error checking if route file exists %v", err)
+					}
+					if !exists && tt.options.TargetPort != 0 {
+						t.Errorf("route file does not exist at path %v", routeFilepath)
+					} else if exists {
+						route := routev1.Route{}
+						routeBytes, err := tt.fs.ReadFile(routeFilepath)
+						if err != nil {
+							t.Errorf("unexpected error reading route file")
+						}
+						err = yaml.Unmarshal(routeBytes, &route)
+						if err != nil {
+							t.Errorf("unexpected error unmarshaling route file bytes")
+						}
+						if len(tt.options.KubernetesResources.Routes) > 0 && route.Name != tt.options.KubernetesResources.Routes[0].Name {
+							t.Errorf("expected route name %v, got %v", tt.options.KubernetesResources.Routes[0].Name, route.Name)
+						} else if len(tt.options.KubernetesResources.Routes) == 0 && route.Name != tt.options.Name {
+							t.Errorf("expected route name %v, got %v", tt.options.Name, route.Name)
+						}
+					}
+				}
+
+				// Validate that the kustomization.yaml got created successfully and contains the proper entries
+				kustomizationFilepath := filepath.Join(tt.outputFolder, kustomizeFileName)
+				exists, err = tt.fs.Exists(kustomizationFilepath)
+				if err != nil {
+					t.Errorf("unexpected error checking if kustomize file exists %v", err)
+				}
+				if !exists {
+					t.Errorf("kustomize file does not exist at path %v", kustomizationFilepath)
+				}
+
+				// Read the kustomization.yaml and validate its entries
+				k := resources.Kustomization{}
+				kustomizationBytes, err := tt.fs.ReadFile(kustomizationFilepath)
+				if err != nil {
+					t.Errorf("unexpected error reading parent kustomize file")
+				}
+				err = yaml.Unmarshal(kustomizationBytes, &k)
+				if err != nil {
+					t.Errorf("unexpected error unmarshaling file")
+				}
+				// There match patch entries in the kustomization file
+				if len(k.Patches) != tt.expectPatchEntries {
+					t.Errorf("expected %v kustomization bases, got %v patches: %v", tt.expectPatchEntries, len(k.Patches), k.Patches)
+				}
+
+				// Validate that the APIVersion and Kind are set properly
+				if k.Kind != "Kustomization" {
+					t.Errorf("expected kustomize kind %v, got %v", "Kustomization", k.Kind)
+				}
+				if k.APIVersion != "kustomize.config.k8s.io/v1beta1" {
+					t.Errorf("expected kustomize apiversion %v, got %v", "kustomize.config.k8s.io/v1beta1", k.APIVersion)
+				}
+
+			}
+		})
+	}
+}
+
+func TestGenerateOverlaysWithSidecars(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	// The sidecar is deliberately first, the primary container must still be matched by name
+	baseDeployment := appsv1.Deployment{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: componentName,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "envoy",
+							Image: "envoyproxy/envoy:v1.25.0",
+						},
+						{
+							Name:  "container-image",
+							Image: "quay.io/test/test:latest",
+						},
+					},
+				},
+			},
+		},
+	}
+	componentFolder := filepath.Join("/tmp/sidecars", "components", componentName)
+	bytes, err := yaml.Marshal(baseDeployment)
+	assertNoError(t, err)
+	err = fs.WriteFile(filepath.Join(componentFolder, "base", deploymentFileName), bytes, 0755)
+	assertNoError(t, err)
+
+	outputFolder := filepath.Join(componentFolder, "overlays", "development")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		OverlayEnvVar: []corev1.EnvVar{
+			{
+				Name:  "FOO",
+				Value: "BAR",
+			},
+		},
+	}
+	err = GenerateOverlays(fs, "/tmp/sidecars", outputFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	deploymentPatchBytes, err := fs.ReadFile(filepath.Join(outputFolder, deploymentPatchFileName))
+	assertNoError(t, err)
+	deploymentPatch := appsv1.Deployment{}
+	err = yaml.Unmarshal(deploymentPatchBytes, &deploymentPatch)
+	assertNoError(t, err)
+
+	containers := deploymentPatch.Spec.Template.Spec.Containers
+	if len(containers) != 1 {
+		t.Fatalf("expected only the primary container to be patched, got %v", containers)
+	}
+	assert.Equal(t, "container-image", containers[0].Name)
+	assert.Equal(t, imageName, containers[0].Image)
+}
+
+func TestGenerateOverlaysWithCronJob(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	baseOptions := gitopsv1alpha1.GeneratorOptions{
+		Name:           componentName,
+		WorkloadType:   gitopsv1alpha1.WorkloadTypeCronJob,
+		Schedule:       "0 * * * *",
+		ContainerImage: "quay.io/test/test:latest",
+		TargetPort:     8080,
+	}
+	componentFolder := filepath.Join("/tmp/cronjob", "components", componentName)
+	err := Generate(fs, "/tmp/cronjob", filepath.Join(componentFolder, "base"), baseOptions)
+	assertNoError(t, err)
+
+	outputFolder := filepath.Join(componentFolder, "overlays", "development")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:       componentName,
+		TargetPort: 8080,
+		OverlayEnvVar: []corev1.EnvVar{
+			{
+				Name:  "FOO",
+				Value: "BAR",
+			},
+		},
+	}
+	err = GenerateOverlays(fs, "/tmp/cronjob", outputFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	cronJobPatchBytes, err := fs.ReadFile(filepath.Join(outputFolder, cronjobPatchFileName))
+	assertNoError(t, err)
+	cronJobPatch := batchv1.CronJob{}
+	err = yaml.Unmarshal(cronJobPatchBytes, &cronJobPatch)
+	assertNoError(t, err)
+
+	containers := cronJobPatch.Spec.JobTemplate.Spec.Template.Spec.Containers
+	if len(containers) != 1 {
+		t.Fatalf("expected only the primary container to be patched, got %v", containers)
+	}
+	assert.Equal(t, "container-image", containers[0].Name)
+	assert.Equal(t, imageName, containers[0].Image)
+	assert.Equal(t, options.OverlayEnvVar, containers[0].Env)
+
+	for _, fileName := range []string{deploymentPatchFileName, routeFileName, ingressFileName} {
+		exists, err := fs.Exists(filepath.Join(outputFolder, fileName))
+		assertNoError(t, err)
+		assert.False(t, exists, "expected %s not to be generated for a cronjob", fileName)
+	}
+
+	// A route cannot be requested for a cronjob
+	options.Route = "test-route.example.com"
+	err = GenerateOverlays(fs, "/tmp/cronjob", outputFolder, options, imageName, namespace, nil)
+	assert.Error(t, err)
+}
+
+func TestGenerateOverlaysWithPodDisruptionBudget(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+	baseMinAvailable := intstr.FromInt(1)
+	overlayMinAvailable := intstr.FromString("50%")
+
+	componentFolder := filepath.Join("/tmp/pdb", "components", componentName)
+	outputFolder := filepath.Join(componentFolder, "overlays", "development")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		PodDisruptionBudget: &gitopsv1alpha1.PDBOptions{
+			MinAvailable: &overlayMinAvailable,
+		},
+	}
+
+	// The budget can only be patched if the base has one
+	err := GenerateOverlays(fs, "/tmp/pdb", outputFolder, options, imageName, namespace, nil)
+	assert.Error(t, err)
+
+	baseOptions := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		PodDisruptionBudget: &gitopsv1alpha1.PDBOptions{
+			MinAvailable: &baseMinAvailable,
+		},
+	}
+	err = Generate(fs, "/tmp/pdb", filepath.Join(componentFolder, "base"), baseOptions)
+	assertNoError(t, err)
+
+	err = GenerateOverlays(fs, "/tmp/pdb", outputFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	pdbPatchBytes, err := fs.ReadFile(filepath.Join(outputFolder, pdbPatchFileName))
+	assertNoError(t, err)
+	pdbPatch := policyv1.PodDisruptionBudget{}
+	err = yaml.Unmarshal(pdbPatchBytes, &pdbPatch)
+	assertNoError(t, err)
+	assert.Equal(t, componentName, pdbPatch.Name)
+	assert.Equal(t, &overlayMinAvailable, pdbPatch.Spec.MinAvailable)
+
+	kustomizationBytes, err := fs.ReadFile(filepath.Join(outputFolder, kustomizeFileName))
+	assertNoError(t, err)
+	kustomization := resources.Kustomization{}
+	err = yaml.Unmarshal(kustomizationBytes, &kustomization)
+	assertNoError(t, err)
+	assert.Contains(t, kustomization.Patches, resources.Patch{Path: pdbPatchFileName})
+}
+
+func TestGenerateWithAutoscaling(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+	minReplicas := int32(2)
+	cpuUtilization := int32(75)
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:      componentName,
+		Namespace: namespace,
+		Replicas:  3,
+		Autoscaling: &gitopsv1alpha1.HPAOptions{
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    5,
+			CPUUtilization: &cpuUtilization,
+		},
+	}
+	componentFolder := filepath.Join("/tmp/autoscaling", "components", componentName)
+	err := Generate(fs, "/tmp/autoscaling", filepath.Join(componentFolder, "base"), options)
+	assertNoError(t, err)
+
+	// The replicas must be left out entirely, an explicit zero would still be applied by the GitOps controller
+	deploymentBytes, err := fs.ReadFile(filepath.Join(componentFolder, "base", deploymentFileName))
+	assertNoError(t, err)
+	assert.NotContains(t, string(deploymentBytes), "replicas:")
+
+	hpaBytes, err := fs.ReadFile(filepath.Join(componentFolder, "base", hpaFileName))
+	assertNoError(t, err)
+	hpa := autoscalingv2.HorizontalPodAutoscaler{}
+	err = yaml.Unmarshal(hpaBytes, &hpa)
+	assertNoError(t, err)
+	assert.Equal(t, autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: componentName}, hpa.Spec.ScaleTargetRef)
+	assert.Equal(t, &minReplicas, hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(5), hpa.Spec.MaxReplicas)
+	assert.Equal(t, []autoscalingv2.MetricSpec{
+		{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: &cpuUtilization,
+				},
+			},
+		},
+	}, hpa.Spec.Metrics)
+
+	outputFolder := filepath.Join(componentFolder, "overlays", "development")
+	err = GenerateOverlays(fs, "/tmp/autoscaling", outputFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	deploymentPatchBytes, err := fs.ReadFile(filepath.Join(outputFolder, deploymentPatchFileName))
+	assertNoError(t, err)
+	assert.NotContains(t, string(deploymentPatchBytes), "replicas:")
+}
+
+func TestGenerateOverlaysWithKnativeService(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	baseOptions := gitopsv1alpha1.GeneratorOptions{
+		Name:           componentName,
+		WorkloadType:   gitopsv1alpha1.WorkloadTypeKnativeService,
+		ContainerImage: "quay.io/test/test:latest",
+		TargetPort:     8080,
+	}
+	componentFolder := filepath.Join("/tmp/knative", "components", componentName)
+	err := Generate(fs, "/tmp/knative", filepath.Join(componentFolder, "base"), baseOptions)
+	assertNoError(t, err)
+
+	outputFolder := filepath.Join(componentFolder, "overlays", "development")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:       componentName,
+		TargetPort: 8080,
+		OverlayEnvVar: []corev1.EnvVar{
+			{
+				Name:  "FOO",
+				Value: "BAR",
+			},
+		},
+	}
+	err = GenerateOverlays(fs, "/tmp/knative", outputFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	knativeServicePatchBytes, err := fs.ReadFile(filepath.Join(outputFolder, knativeServicePatchFileName))
+	assertNoError(t, err)
+	knativeServicePatch := resources.KnativeService{}
+	err = yaml.Unmarshal(knativeServicePatchBytes, &knativeServicePatch)
+	assertNoError(t, err)
+
+	// The containers of a Knative service are replaced rather than merged, so the patch must hold the port as well
+	assert.Equal(t, []corev1.Container{
+		{
+			Name:  "container-image",
+			Image: imageName,
+			Ports: []corev1.ContainerPort{
+				{
+					ContainerPort: 8080,
+				},
+			},
+			Env: options.OverlayEnvVar,
+		},
+	}, knativeServicePatch.Spec.Template.Spec.Containers)
+
+	for _, fileName := range []string{deploymentPatchFileName, routeFileName, ingressFileName} {
+		exists, err := fs.Exists(filepath.Join(outputFolder, fileName))
+		assertNoError(t, err)
+		assert.False(t, exists, "expected %s not to be generated for a Knative service", fileName)
+	}
+}
+
+func TestGenerateWithHostAliases(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	ndots := "2"
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		HostAliases: []corev1.HostAlias{
+			{
+				IP:        "10.0.0.15",
+				Hostnames: []string{"legacy-db.example.com", "legacy-db"},
+			},
+		},
+		DNSPolicy: corev1.DNSNone,
+		DNSConfig: &corev1.PodDNSConfig{
+			Nameservers: []string{"10.0.0.53"},
+			Searches:    []string{"example.com"},
+			Options: []corev1.PodDNSConfigOption{
+				{
+					Name:  "ndots",
+					Value: &ndots,
+				},
+			},
+		},
+	}
+	outputFolder := filepath.Join("/tmp/hostaliases", "components", componentName, "base")
+	err := Generate(fs, "/tmp/hostaliases", outputFolder, options)
+	assertNoError(t, err)
+
+	deploymentBytes, err := fs.ReadFile(filepath.Join(outputFolder, deploymentFileName))
+	assertNoError(t, err)
+	deployment := appsv1.Deployment{}
+	err = yaml.Unmarshal(deploymentBytes, &deployment)
+	assertNoError(t, err)
+	assert.Equal(t, options.HostAliases, deployment.Spec.Template.Spec.HostAliases)
+	assert.Equal(t, options.DNSPolicy, deployment.Spec.Template.Spec.DNSPolicy)
+	assert.Equal(t, options.DNSConfig, deployment.Spec.Template.Spec.DNSConfig)
+
+	// The fields are left out entirely when not set
+	options = gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+	}
+	err = Generate(fs, "/tmp/hostaliases", outputFolder, options)
+	assertNoError(t, err)
+
+	deploymentBytes, err = fs.ReadFile(filepath.Join(outputFolder, deploymentFileName))
+	assertNoError(t, err)
+	for _, field := range []string{"hostAliases:", "dnsPolicy:", "dnsConfig:"} {
+		assert.NotContains(t, string(deploymentBytes), field)
+	}
+}
+
+func TestGenerateOverlaysWithPausedRollouts(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/paused", "components", componentName)
+	err := Generate(fs, "/tmp/paused", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	readDeploymentPatch := func(environment string) string {
+		deploymentPatchBytes, err := fs.ReadFile(filepath.Join(componentFolder, "overlays", environment, deploymentPatchFileName))
+		assertNoError(t, err)
+		return string(deploymentPatchBytes)
+	}
+
+	err = GenerateOverlays(fs, "/tmp/paused", filepath.Join(componentFolder, "overlays", "prod"), gitopsv1alpha1.GeneratorOptions{Name: componentName, Paused: true}, imageName, namespace, nil)
+	assertNoError(t, err)
+	err = GenerateOverlays(fs, "/tmp/paused", filepath.Join(componentFolder, "overlays", "staging"), gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	assert.Contains(t, readDeploymentPatch("prod"), "paused: true")
+	assert.NotContains(t, readDeploymentPatch("staging"), "paused:")
+
+	// Unsetting the field on a later run removes it from the regenerated patch
+	err = GenerateOverlays(fs, "/tmp/paused", filepath.Join(componentFolder, "overlays", "prod"), gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.NotContains(t, readDeploymentPatch("prod"), "paused:")
+}
+
+func TestGenerateWithHeadlessService(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:       componentName,
+		TargetPort: 8080,
+		Headless:   true,
+	}
+	componentFolder := filepath.Join("/tmp/headless", "components", componentName)
+	err := Generate(fs, "/tmp/headless", filepath.Join(componentFolder, "base"), options)
+	assertNoError(t, err)
+
+	serviceBytes, err := fs.ReadFile(filepath.Join(componentFolder, "base", serviceFileName))
+	assertNoError(t, err)
+	assert.Contains(t, string(serviceBytes), "clusterIP: None")
+
+	for _, isKubernetesCluster := range []bool{false, true} {
+		options.IsKubernetesCluster = isKubernetesCluster
+		outputFolder := filepath.Join(componentFolder, "overlays", "development")
+		err = GenerateOverlays(fs, "/tmp/headless", outputFolder, options, "test-image", "test-namespace", nil)
+		assertNoError(t, err)
+
+		for _, fileName := range []string{routeFileName, ingressFileName} {
+			exists, err := fs.Exists(filepath.Join(outputFolder, fileName))
+			assertNoError(t, err)
+			assert.False(t, exists, "expected %s not to be generated for a headless service", fileName)
+		}
+	}
+}
+
+func TestGenerateOverlaysWithRouteSubdomain(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/subdomain", "components", componentName)
+	err := Generate(fs, "/tmp/subdomain", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	readRoute := func(environment string) routev1.Route {
+		routeBytes, err := fs.ReadFile(filepath.Join(componentFolder, "overlays", environment, routeFileName))
+		assertNoError(t, err)
+		var route routev1.Route
+		assertNoError(t, yaml.Unmarshal(routeBytes, &route))
+		return route
+	}
+
+	for _, environment := range []string{"preview-1", "preview-2"} {
+		options := gitopsv1alpha1.GeneratorOptions{
+			Name:                componentName,
+			TargetPort:          8080,
+			RouteSubdomain:      environment,
+			RouteWildcardPolicy: routev1.WildcardPolicySubdomain,
+		}
+		err = GenerateOverlays(fs, "/tmp/subdomain", filepath.Join(componentFolder, "overlays", environment), options, imageName, namespace, nil)
+		assertNoError(t, err)
+	}
+
+	// Each environment has its own subdomain
+	for _, environment := range []string{"preview-1", "preview-2"} {
+		route := readRoute(environment)
+		assert.Equal(t, environment, route.Spec.Subdomain)
+		assert.Equal(t, routev1.WildcardPolicySubdomain, route.Spec.WildcardPolicy)
+		assert.Empty(t, route.Spec.Host)
+	}
+
+	// The route host and subdomain are mutually exclusive
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:           componentName,
+		TargetPort:     8080,
+		Route:          "test-component.example.com",
+		RouteSubdomain: "preview-3",
+	}
+	err = GenerateOverlays(fs, "/tmp/subdomain", filepath.Join(componentFolder, "overlays", "preview-3"), options, imageName, namespace, nil)
+	assert.EqualError(t, err, `the route host "test-component.example.com" and subdomain "preview-3" are mutually exclusive, only one can be set`)
+	err = Generate(fs, "/tmp/subdomain", filepath.Join(componentFolder, "base"), options)
+	assert.EqualError(t, err, `the route host "test-component.example.com" and subdomain "preview-3" are mutually exclusive, only one can be set`)
+}
+
+func TestGenerateOverlaysWithRouteAnnotations(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/route-annotations", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	err := Generate(fs, "/tmp/route-annotations", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:       componentName,
+		TargetPort: 8080,
+		K8sLabels: map[string]string{
+			"haproxy.router.openshift.io/timeout": "5m",
+		},
+		RouteAnnotations: map[string]string{
+			"haproxy.router.openshift.io/timeout": "5m",
+		},
+	}
+
+	// Regenerating the overlay keeps the annotation
+	for i := 0; i < 2; i++ {
+		err = GenerateOverlays(fs, "/tmp/route-annotations", overlayFolder, options, imageName, namespace, nil)
+		assertNoError(t, err)
+
+		routeBytes, err := fs.ReadFile(filepath.Join(overlayFolder, routeFileName))
+		assertNoError(t, err)
+		var route routev1.Route
+		assertNoError(t, yaml.Unmarshal(routeBytes, &route))
+		assert.Equal(t, "5m", route.Annotations["haproxy.router.openshift.io/timeout"])
+		assert.Equal(t, "5m", route.Labels["haproxy.router.openshift.io/timeout"])
+	}
+}
+
+func TestGenerateOverlaysWithExposeMode(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/expose", "components", componentName)
+	err := Generate(fs, "/tmp/expose", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	tests := []struct {
+		name        string
+		options     gitopsv1alpha1.GeneratorOptions
+		wantRoute   bool
+		wantIngress bool
+	}{
+		{
+			name: "Auto mode on an OpenShift cluster generates a route",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:       componentName,
+				TargetPort: 8080,
+				Route:      "test-component.apps.example.com",
+			},
+			wantRoute: true,
+		},
+		{
+			name: "Auto mode on a Kubernetes cluster generates an ingress",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:                componentName,
+				TargetPort:          8080,
+				Route:               "test-component.apps.example.com",
+				IsKubernetesCluster: true,
+			},
+			wantIngress: true,
+		},
+		{
+			name: "Route mode on a Kubernetes cluster generates a route",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:                componentName,
+				TargetPort:          8080,
+				Route:               "test-component.apps.example.com",
+				IsKubernetesCluster: true,
+				ExposeMode:          gitopsv1alpha1.ExposeModeRoute,
+			},
+			wantRoute: true,
+		},
+		{
+			name: "Both mode generates a route and an ingress",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				TargetPort:  8080,
+				Route:       "test-component.apps.example.com",
+				IngressHost: "test-component.example.com",
+				ExposeMode:  gitopsv1alpha1.ExposeModeBoth,
+			},
+			wantRoute:   true,
+			wantIngress: true,
+		},
+		{
+			name: "None mode generates neither",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:       componentName,
+				TargetPort: 8080,
+				Route:      "test-component.apps.example.com",
+				ExposeMode: gitopsv1alpha1.ExposeModeNone,
+			},
+		},
+		{
+			name: "Skipped service generates neither",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				TargetPort:  8080,
+				Route:       "test-component.apps.example.com",
+				SkipService: true,
+			},
+		},
+		{
+			name: "None mode writes the route passed in",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:       componentName,
+				TargetPort: 8080,
+				ExposeMode: gitopsv1alpha1.ExposeModeNone,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Routes: []routev1.Route{
+						{
+							ObjectMeta: v1.ObjectMeta{
+								Name: componentName,
+							},
+						},
+					},
+				},
+			},
+			wantRoute: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlayFolder := filepath.Join(componentFolder, "overlays", strings.ReplaceAll(tt.name, " ", "-"))
+			componentGeneratedResources := make(map[string][]string)
+			err := GenerateOverlays(fs, "/tmp/expose", overlayFolder, tt.options, imageName, namespace, componentGeneratedResources)
+			assertNoError(t, err)
+
+			var k resources.Kustomization
+			kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+			assertNoError(t, err)
+			assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+
+			for file, want := range map[string]bool{routeFileName: tt.wantRoute, ingressFileName: tt.wantIngress} {
+				exist, err := fs.Exists(filepath.Join(overlayFolder, file))
+				assertNoError(t, err)
+				assert.Equal(t, want, exist, file)
+				if want {
+					assert.Contains(t, k.Resources, file)
+					assert.Contains(t, componentGeneratedResources[componentName], file)
+				} else {
+					assert.NotContains(t, k.Resources, file)
+					assert.NotContains(t, componentGeneratedResources[componentName], file)
+				}
+			}
+
+			// The ingress and route hosts are set independently
+			if tt.options.ExposeMode == gitopsv1alpha1.ExposeModeBoth {
+				var route routev1.Route
+				routeBytes, err := fs.ReadFile(filepath.Join(overlayFolder, routeFileName))
+				assertNoError(t, err)
+				assertNoError(t, yaml.Unmarshal(routeBytes, &route))
+				assert.Equal(t, tt.options.Route, route.Spec.Host)
+
+				var ingress networkingv1.Ingress
+				ingressBytes, err := fs.ReadFile(filepath.Join(overlayFolder, ingressFileName))
+				assertNoError(t, err)
+				assertNoError(t, yaml.Unmarshal(ingressBytes, &ingress))
+				assert.Equal(t, tt.options.IngressHost, ingress.Spec.Rules[0].Host)
+
+				// Neither is listed as a patch
+				assert.NotContains(t, string(kustomizationBytes), "path: "+routeFileName)
+				assert.NotContains(t, string(kustomizationBytes), "path: "+ingressFileName)
+			}
+		})
+	}
+}
+
+func TestGenerateWithNamedTargetPort(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	outputFolder := filepath.Join("/tmp/named-port", "components", componentName, "base")
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		Ports: []gitopsv1alpha1.ComponentPort{
+			{
+				Name:           "web",
+				ContainerPort:  8080,
+				TargetPortName: "http",
+				Expose:         true,
+			},
+			{
+				Name:          "metrics",
+				ContainerPort: 9090,
+			},
+		},
+	}
+	err := Generate(fs, "/tmp/named-port", outputFolder, options)
+	assertNoError(t, err)
+
+	// The named target port survives the round trip through the YAML rather than being coerced to a number
+	var service corev1.Service
+	serviceBytes, err := fs.ReadFile(filepath.Join(outputFolder, serviceFileName))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(serviceBytes, &service))
+	assert.Equal(t, intstr.FromString("http"), service.Spec.Ports[0].TargetPort)
+	assert.Equal(t, "web", service.Spec.Ports[0].Name)
+	assert.Equal(t, intstr.FromInt(9090), service.Spec.Ports[1].TargetPort)
+
+	// The container port carries the name the service references
+	var deployment appsv1.Deployment
+	deploymentBytes, err := fs.ReadFile(filepath.Join(outputFolder, deploymentFileName))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(deploymentBytes, &deployment))
+	assert.Equal(t, []corev1.ContainerPort{
+		{
+			Name:          "http",
+			ContainerPort: 8080,
+		},
+		{
+			Name:          "metrics",
+			ContainerPort: 9090,
+		},
+	}, deployment.Spec.Template.Spec.Containers[0].Ports)
+}
+
+func TestGenerateOverlaysWithHostTemplate(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/host-template", "components", componentName)
+	err := Generate(fs, "/tmp/host-template", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	tests := []struct {
+		name            string
+		environment     string
+		options         gitopsv1alpha1.GeneratorOptions
+		wantRouteHost   string
+		wantIngressHost string
+		wantErr         string
+	}{
+		{
+			name:        "Route host generated from the template",
+			environment: "staging",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Application:  "test-application",
+				TargetPort:   8080,
+				HostTemplate: "{component}-{environment}.apps.example.com",
+			},
+			wantRouteHost: "test-component-staging.apps.example.com",
+		},
+		{
+			name:        "Ingress host generated from the template",
+			environment: "prod",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:                componentName,
+				Application:         "test-application",
+				TargetPort:          8080,
+				IsKubernetesCluster: true,
+				HostTemplate:        "{component}.{application}.{namespace}.{environment}.example.com",
+			},
+			wantIngressHost: "test-component.test-application.test-namespace.prod.example.com",
+		},
+		{
+			name:        "Explicit route host takes precedence over the template",
+			environment: "dev",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				TargetPort:   8080,
+				Route:        "test-component.example.com",
+				HostTemplate: "{component}-{environment}.apps.example.com",
+			},
+			wantRouteHost: "test-component.example.com",
+		},
+		{
+			name:        "Unsupported placeholder in the template",
+			environment: "dev",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				TargetPort:   8080,
+				HostTemplate: "{component}-{cluster}.apps.example.com",
+			},
+			wantErr: `unsupported placeholder "{cluster}" in the host template "{component}-{cluster}.apps.example.com"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlayFolder := filepath.Join(componentFolder, "overlays", tt.environment)
+			err := GenerateOverlays(fs, "/tmp/host-template", overlayFolder, tt.options, imageName, namespace, nil)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assertNoError(t, err)
+
+			if tt.wantRouteHost != "" {
+				var route routev1.Route
+				routeBytes, err := fs.ReadFile(filepath.Join(overlayFolder, routeFileName))
+				assertNoError(t, err)
+				assertNoError(t, yaml.Unmarshal(routeBytes, &route))
+				assert.Equal(t, tt.wantRouteHost, route.Spec.Host)
+			}
+			if tt.wantIngressHost != "" {
+				var ingress networkingv1.Ingress
+				ingressBytes, err := fs.ReadFile(filepath.Join(overlayFolder, ingressFileName))
+				assertNoError(t, err)
+				assertNoError(t, yaml.Unmarshal(ingressBytes, &ingress))
+				assert.Equal(t, tt.wantIngressHost, ingress.Spec.Rules[0].Host)
+			}
+		})
+	}
+}
+
+func TestGenerateWithSkipService(t *testing.T) {
+	componentName := "test-component"
+	outputFolder := filepath.Join("/tmp/skip-service", "components", componentName, "base")
+
+	tests := []struct {
+		name        string
+		options     gitopsv1alpha1.GeneratorOptions
+		wantService bool
+	}{
+		{
+			name: "Service skipped even though a target port is set",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				TargetPort:  8080,
+				SkipService: true,
+			},
+		},
+		{
+			name: "Service passed in is written even though generation is skipped",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				TargetPort:  8080,
+				SkipService: true,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Services: []corev1.Service{
+						{
+							ObjectMeta: v1.ObjectMeta{
+								Name: componentName,
+							},
+						},
+					},
+				},
+			},
+			wantService: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			err := Generate(fs, "/tmp/skip-service", outputFolder, tt.options)
+			assertNoError(t, err)
+
+			exist, err := fs.Exists(filepath.Join(outputFolder, serviceFileName))
+			assertNoError(t, err)
+			assert.Equal(t, tt.wantService, exist)
+
+			var k resources.Kustomization
+			kustomizationBytes, err := fs.ReadFile(filepath.Join(outputFolder, kustomizeFileName))
+			assertNoError(t, err)
+			assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+			if tt.wantService {
+				assert.Contains(t, k.Resources, serviceFileName)
+			} else {
+				assert.NotContains(t, k.Resources, serviceFileName)
+			}
+		})
+	}
+}
+
+func TestGenerateOverlaysWithNamePrefixAndSuffix(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/name-prefix", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	err := Generate(fs, "/tmp/name-prefix", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:              componentName,
+		OverlayNamePrefix: "blue-",
+		OverlayNameSuffix: "-v1",
+	}
+	err = GenerateOverlays(fs, "/tmp/name-prefix", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	k := readKustomization()
+	assert.Equal(t, "blue-", k.NamePrefix)
+	assert.Equal(t, "-v1", k.NameSuffix)
+
+	// A pre-existing name prefix and suffix survive a re-run without them
+	err = GenerateOverlays(fs, "/tmp/name-prefix", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, nil)
+	assertNoError(t, err)
+	k = readKustomization()
+	assert.Equal(t, "blue-", k.NamePrefix)
+	assert.Equal(t, "-v1", k.NameSuffix)
+
+	// Setting them again overrides the existing ones
+	err = GenerateOverlays(fs, "/tmp/name-prefix", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName, OverlayNamePrefix: "green-"}, imageName, namespace, nil)
+	assertNoError(t, err)
+	k = readKustomization()
+	assert.Equal(t, "green-", k.NamePrefix)
+	assert.Equal(t, "-v1", k.NameSuffix)
+}
+
+func TestGenerateOverlaysWithCommonAnnotations(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/common-annotations", "components", componentName)
+	baseFolder := filepath.Join(componentFolder, "base")
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		CommonAnnotations: map[string]string{
+			"example.com/owner": "team-a",
+		},
+	}
+	err := Generate(fs, "/tmp/common-annotations", baseFolder, options)
+	assertNoError(t, err)
+
+	readKustomization := func(folder string) resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(folder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+	assert.Equal(t, options.CommonAnnotations, readKustomization(baseFolder).CommonAnnotations)
+
+	err = GenerateOverlays(fs, "/tmp/common-annotations", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, options.CommonAnnotations, readKustomization(overlayFolder).CommonAnnotations)
+
+	// Add an annotation to the overlays kustomization by hand
+	k := readKustomization(overlayFolder)
+	k.CommonAnnotations["example.com/cost-center"] = "1234"
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), kustomizationBytes, 0644))
+
+	// Two consecutive runs keep the user's annotation, while updating the generated one
+	options.CommonAnnotations = map[string]string{
+		"example.com/owner": "team-b",
+	}
+	for i := 0; i < 2; i++ {
+		err = GenerateOverlays(fs, "/tmp/common-annotations", overlayFolder, options, imageName, namespace, nil)
+		assertNoError(t, err)
+		assert.Equal(t, map[string]string{
+			"example.com/owner":       "team-b",
+			"example.com/cost-center": "1234",
+		}, readKustomization(overlayFolder).CommonAnnotations)
+	}
+}
+
+func TestGenerateOverlaysWithKustomizeImages(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/kustomize-images", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	err := Generate(fs, "/tmp/kustomize-images", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName, ContainerImage: "quay.io/test/test-image:base"})
+	assertNoError(t, err)
+
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+	readDeploymentPatch := func() string {
+		deploymentPatchBytes, err := fs.ReadFile(filepath.Join(overlayFolder, deploymentPatchFileName))
+		assertNoError(t, err)
+		return string(deploymentPatchBytes)
+	}
+
+	// The image is set in the kustomization and left out of the patch
+	err = GenerateOverlays(fs, "/tmp/kustomize-images", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName, UseKustomizeImages: true}, "quay.io/test/test-image:v2", namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, []resources.Image{
+		{
+			Name:    "quay.io/test/test-image",
+			NewName: "quay.io/test/test-image",
+			NewTag:  "v2",
+		},
+	}, readKustomization().Images)
+	assert.NotContains(t, readDeploymentPatch(), "image:")
+
+	// Switching back to the patch drops the images from the kustomization
+	err = GenerateOverlays(fs, "/tmp/kustomize-images", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName}, "quay.io/test/test-image:v3", namespace, nil)
+	assertNoError(t, err)
+	assert.Empty(t, readKustomization().Images)
+	assert.Contains(t, readDeploymentPatch(), "image: quay.io/test/test-image:v3")
+
+	// And switching to the kustomization again drops the image from the patch
+	err = GenerateOverlays(fs, "/tmp/kustomize-images", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName, UseKustomizeImages: true}, "registry.example.com:5000/test/test-image@sha256:abcdef", namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, []resources.Image{
+		{
+			Name:    "quay.io/test/test-image",
+			NewName: "registry.example.com:5000/test/test-image",
+			Digest:  "sha256:abcdef",
+		},
+	}, readKustomization().Images)
+	assert.NotContains(t, readDeploymentPatch(), "image:")
+}
+
+func TestGenerateWithConfigMaps(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/configmaps", "components", componentName)
+	baseFolder := filepath.Join(componentFolder, "base")
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+
+	readKustomization := func(folder string) resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(folder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		ConfigMaps: []gitopsv1alpha1.ConfigMapOptions{
+			{
+				Name: "app-config",
+				Literals: map[string]string{
+					"LOG_LEVEL": "info",
+					"GREETING":  "hello",
+				},
+				Files: map[string]string{
+					"app.properties": "feature.enabled=false\n",
+				},
+				EnvFrom: true,
+			},
+		},
+	}
+	err := Generate(fs, "/tmp/configmaps", baseFolder, options)
+	assertNoError(t, err)
+
+	// The base generates the ConfigMap from the literals and the data file written next to the kustomization
+	assert.Equal(t, []resources.ConfigMapArgs{
+		{
+			GeneratorArgs: resources.GeneratorArgs{
+				Name:     "app-config",
+				Literals: []string{"GREETING=hello", "LOG_LEVEL=info"},
+				Files:    []string{"app.properties"},
+			},
+		},
+	}, readKustomization(baseFolder).ConfigMapGenerator)
+	propertiesBytes, err := fs.ReadFile(filepath.Join(baseFolder, "app.properties"))
+	assertNoError(t, err)
+	assert.Equal(t, "feature.enabled=false\n", string(propertiesBytes))
+
+	// The deployment references the ConfigMap by its name, which kustomize updates to the generated one
+	var deployment appsv1.Deployment
+	deploymentBytes, err := fs.ReadFile(filepath.Join(baseFolder, deploymentFileName))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(deploymentBytes, &deployment))
+	assert.Equal(t, "app-config", deployment.Spec.Template.Spec.Containers[0].EnvFrom[0].ConfigMapRef.Name)
+
+	// Add a configMapGenerator entry to the overlays kustomization by hand
+	err = GenerateOverlays(fs, "/tmp/configmaps", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, nil)
+	assertNoError(t, err)
+	k := readKustomization(overlayFolder)
+	k.ConfigMapGenerator = append(k.ConfigMapGenerator, resources.ConfigMapArgs{
+		GeneratorArgs: resources.GeneratorArgs{
+			Name: "user-config",
+			Envs: []string{"user.env"},
+		},
+	})
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), kustomizationBytes, 0644))
+
+	// The overlays merge into the base ConfigMap and keep the user's entry
+	options.ConfigMaps = []gitopsv1alpha1.ConfigMapOptions{
+		{
+			Name: "app-config",
+			Literals: map[string]string{
+				"LOG_LEVEL": "warn",
+			},
+			EnvFrom: true,
+		},
+		{
+			Name: "prod-config",
+			Files: map[string]string{
+				"prod.properties": "replicas=3\n",
+			},
+			DisableNameSuffixHash: true,
+		},
+	}
+	for i := 0; i < 2; i++ {
+		err = GenerateOverlays(fs, "/tmp/configmaps", overlayFolder, options, imageName, namespace, nil)
+		assertNoError(t, err)
+		assert.Equal(t, []resources.ConfigMapArgs{
+			{
+				GeneratorArgs: resources.GeneratorArgs{
+					Name: "user-config",
+					Envs: []string{"user.env"},
+				},
+			},
+			{
+				GeneratorArgs: resources.GeneratorArgs{
+					Name:     "app-config",
+					Behavior: "merge",
+					Literals: []string{"LOG_LEVEL=warn"},
+				},
+			},
+			{
+				GeneratorArgs: resources.GeneratorArgs{
+					Name:  "prod-config",
+					Files: []string{"prod.properties"},
+					Options: &resources.GeneratorOptions{
+						DisableNameSuffixHash: true,
+					},
+				},
+			},
+		}, readKustomization(overlayFolder).ConfigMapGenerator)
+	}
+	propertiesBytes, err = fs.ReadFile(filepath.Join(overlayFolder, "prod.properties"))
+	assertNoError(t, err)
+	assert.Equal(t, "replicas=3\n", string(propertiesBytes))
+}
+
+func TestGenerateOverlaysWithSecretGenerators(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/secrets", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	err := Generate(fs, "/tmp/secrets", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+
+	// Add a secretGenerator entry to the overlays kustomization by hand
+	err = GenerateOverlays(fs, "/tmp/secrets", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, nil)
+	assertNoError(t, err)
+	k := readKustomization()
+	k.SecretGenerator = append(k.SecretGenerator, resources.SecretArgs{
+		GeneratorArgs: resources.GeneratorArgs{
+			Name: "user-secret",
+			Envs: []string{"user.env"},
+		},
+	})
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), kustomizationBytes, 0644))
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		OverlaySecretGenerators: []gitopsv1alpha1.SecretGeneratorOptions{
+			{
+				Name:                  "app-tls",
+				Type:                  corev1.SecretTypeTLS,
+				Files:                 []string{"tls.crt=certs/server.crt", "tls.key=certs/server.key"},
+				DisableNameSuffixHash: true,
+			},
+			{
+				Name: "app-credentials",
+				Literals: map[string]string{
+					"username": "admin",
+				},
+			},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		err = GenerateOverlays(fs, "/tmp/secrets", overlayFolder, options, imageName, namespace, nil)
+		assertNoError(t, err)
+		assert.Equal(t, []resources.SecretArgs{
+			{
+				GeneratorArgs: resources.GeneratorArgs{
+					Name: "user-secret",
+					Envs: []string{"user.env"},
+				},
+			},
+			{
+				GeneratorArgs: resources.GeneratorArgs{
+					Name:  "app-tls",
+					Files: []string{"tls.crt=certs/server.crt", "tls.key=certs/server.key"},
+					Options: &resources.GeneratorOptions{
+						DisableNameSuffixHash: true,
+					},
+				},
+				Type: "kubernetes.io/tls",
+			},
+			{
+				GeneratorArgs: resources.GeneratorArgs{
+					Name:     "app-credentials",
+					Literals: []string{"username=admin"},
+				},
+			},
+		}, readKustomization().SecretGenerator)
+	}
+
+	// The generator doesn't write the secret data itself
+	exist, err := fs.Exists(filepath.Join(overlayFolder, "certs"))
+	assertNoError(t, err)
+	assert.False(t, exist)
+}
+
+func TestGenerateWithKustomizeComponents(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/kustomize-components", "components", componentName)
+	baseFolder := filepath.Join(componentFolder, "base")
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+
+	readKustomization := func(folder string) resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(folder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+	addComponent := func(folder, component string) {
+		k := readKustomization(folder)
+		k.Components = append(k.Components, component)
+		kustomizationBytes, err := yaml.Marshal(k)
+		assertNoError(t, err)
+		assertNoError(t, fs.WriteFile(filepath.Join(folder, kustomizeFileName), kustomizationBytes, 0644))
+	}
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:                componentName,
+		KustomizeComponents: []string{"../../../../kustomize/monitoring", "../../../../kustomize/add-istio-sidecar", "../../../../kustomize/monitoring"},
+	}
+	err := Generate(fs, "/tmp/kustomize-components", baseFolder, options)
+	assertNoError(t, err)
+	err = GenerateOverlays(fs, "/tmp/kustomize-components", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	// The components are deduplicated and sorted
+	wantComponents := []string{"../../../../kustomize/add-istio-sidecar", "../../../../kustomize/monitoring"}
+	assert.Equal(t, wantComponents, readKustomization(baseFolder).Components)
+	assert.Equal(t, wantComponents, readKustomization(overlayFolder).Components)
+
+	// The components added by hand are kept on regeneration
+	addComponent(baseFolder, "../../../../kustomize/base-extras")
+	addComponent(overlayFolder, "../../../../kustomize/prod-extras")
+	err = Generate(fs, "/tmp/kustomize-components", baseFolder, options)
+	assertNoError(t, err)
+	err = GenerateOverlays(fs, "/tmp/kustomize-components", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, []string{"../../../../kustomize/add-istio-sidecar", "../../../../kustomize/base-extras", "../../../../kustomize/monitoring"}, readKustomization(baseFolder).Components)
+	assert.Equal(t, []string{"../../../../kustomize/add-istio-sidecar", "../../../../kustomize/monitoring", "../../../../kustomize/prod-extras"}, readKustomization(overlayFolder).Components)
+}
+
+func TestGenerateOverlaysWithKustomizeReplicas(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/kustomize-replicas", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	err := Generate(fs, "/tmp/kustomize-replicas", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+	readDeploymentPatch := func() appsv1.Deployment {
+		var deployment appsv1.Deployment
+		deploymentPatchBytes, err := fs.ReadFile(filepath.Join(overlayFolder, deploymentPatchFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(deploymentPatchBytes, &deployment))
+		return deployment
+	}
+
+	// The replicas are set in the kustomization and left out of the patch
+	err = GenerateOverlays(fs, "/tmp/kustomize-replicas", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName, Replicas: 3, UseKustomizeReplicas: true}, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, []resources.Replica{
+		{
+			Name:  componentName,
+			Count: 3,
+		},
+	}, readKustomization().Replicas)
+	assert.Nil(t, readDeploymentPatch().Spec.Replicas)
+
+	// Otherwise they're set in the patch only
+	err = GenerateOverlays(fs, "/tmp/kustomize-replicas", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName, Replicas: 3}, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Empty(t, readKustomization().Replicas)
+	replicas := int32(3)
+	assert.Equal(t, &replicas, readDeploymentPatch().Spec.Replicas)
+
+	// An autoscaled deployment has neither
+	autoscaling := &gitopsv1alpha1.HPAOptions{MaxReplicas: 5}
+	err = GenerateOverlays(fs, "/tmp/kustomize-replicas", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName, Replicas: 3, UseKustomizeReplicas: true, Autoscaling: autoscaling}, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Empty(t, readKustomization().Replicas)
+	assert.Nil(t, readDeploymentPatch().Spec.Replicas)
+}
+
+func TestGenerateOverlaysWithTargetedPatches(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/targeted-patches", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	err := Generate(fs, "/tmp/targeted-patches", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		OverlayTargetedPatches: []gitopsv1alpha1.TargetedPatch{
+			{
+				Target: gitopsv1alpha1.PatchTarget{
+					Group:   "apps",
+					Version: "v1",
+					Kind:    "Deployment",
+					Name:    componentName,
+				},
+				Patch: "- op: remove\n  path: /spec/template/spec/containers/0/ports/1\n",
+			},
+		},
+	}
+	wantPatches := []resources.Patch{
+		{
+			Path: deploymentPatchFileName,
+		},
+		{
+			Patch: "- op: remove\n  path: /spec/template/spec/containers/0/ports/1\n",
+			Target: &resources.PatchTarget{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+				Name:    componentName,
+			},
+		},
+	}
+
+	// Regenerating the overlays doesn't duplicate the targeted patch
+	for i := 0; i < 2; i++ {
+		err = GenerateOverlays(fs, "/tmp/targeted-patches", overlayFolder, options, imageName, namespace, nil)
+		assertNoError(t, err)
+
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		assert.Equal(t, wantPatches, k.Patches)
+	}
+}
+
+func TestGenerateOverlaysWithUnknownKustomizeFields(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/unknown-fields", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	options := gitopsv1alpha1.GeneratorOptions{Name: componentName}
+	err := Generate(fs, "/tmp/unknown-fields", filepath.Join(componentFolder, "base"), options)
+	assertNoError(t, err)
+	err = GenerateOverlays(fs, "/tmp/unknown-fields", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	// Add fields the generator doesn't know about to the overlay kustomization
+	kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+	assertNoError(t, err)
+	unknownFields := "openapi:\n  path: schema.json\ntransformers:\n- transformer.yaml\n"
+	err = fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), append(kustomizationBytes, []byte(unknownFields)...), 0644)
+	assertNoError(t, err)
+
+	err = GenerateOverlays(fs, "/tmp/unknown-fields", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	regeneratedBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+	assertNoError(t, err)
+	var want, got map[string]interface{}
+	assertNoError(t, yaml.Unmarshal(append(kustomizationBytes, []byte(unknownFields)...), &want))
+	assertNoError(t, yaml.Unmarshal(regeneratedBytes, &got))
+	assert.Equal(t, want, got)
+}
+
+func TestGenerateOverlaysKeepsUserKustomizeFields(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "quay.io/test/test-image:v2"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/user-fields", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	options := gitopsv1alpha1.GeneratorOptions{Name: componentName, ContainerImage: "quay.io/test/test-image:v1", Replicas: 2, UseKustomizeImages: true, UseKustomizeReplicas: true}
+	err := Generate(fs, "/tmp/user-fields", filepath.Join(componentFolder, "base"), options)
+	assertNoError(t, err)
+	err = GenerateOverlays(fs, "/tmp/user-fields", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+
+	kustomizationPath := filepath.Join(overlayFolder, kustomizeFileName)
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(kustomizationPath)
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+
+	// Add the images, replicas, labels and metadata of other workloads to the overlay kustomization by hand
+	k := readKustomization()
+	k.Metadata = &resources.ObjectMeta{Name: "prod", Annotations: map[string]string{"owner": "test"}}
+	k.CommonLabels = map[string]string{"env": "prod"}
+	k.Labels = []resources.Label{{Pairs: map[string]string{"team": "test"}}}
+	k.Images = append(k.Images, resources.Image{Name: "quay.io/test/sidecar", NewTag: "v5"})
+	k.Replicas = append(k.Replicas, resources.Replica{Name: "test-worker", Count: 4})
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(kustomizationPath, kustomizationBytes, 0644))
+
+	// Regenerate with a new image and replica count
+	options.Replicas = 3
+	err = GenerateOverlays(fs, "/tmp/user-fields", overlayFolder, options, "quay.io/test/test-image:v3", namespace, nil)
+	assertNoError(t, err)
+
+	got := readKustomization()
+	assert.Equal(t, k.Metadata, got.Metadata)
+	assert.Equal(t, k.CommonLabels, got.CommonLabels)
+	assert.Equal(t, k.Labels, got.Labels)
+	assert.Equal(t, []resources.Image{
+		{
+			Name:    "quay.io/test/test-image",
+			NewName: "quay.io/test/test-image",
+			NewTag:  "v3",
+		},
+		{
+			Name:   "quay.io/test/sidecar",
+			NewTag: "v5",
+		},
+	}, got.Images)
+	assert.Equal(t, []resources.Replica{
+		{
+			Name:  componentName,
+			Count: 3,
+		},
+		{
+			Name:  "test-worker",
+			Count: 4,
+		},
+	}, got.Replicas)
+
+	// Switching back to the patches only drops the entries of the component
+	options.UseKustomizeImages = false
+	options.UseKustomizeReplicas = false
+	err = GenerateOverlays(fs, "/tmp/user-fields", overlayFolder, options, "quay.io/test/test-image:v3", namespace, nil)
+	assertNoError(t, err)
+	got = readKustomization()
+	assert.Equal(t, []resources.Image{{Name: "quay.io/test/sidecar", NewTag: "v5"}}, got.Images)
+	assert.Equal(t, []resources.Replica{{Name: "test-worker", Count: 4}}, got.Replicas)
+	assert.Equal(t, k.CommonLabels, got.CommonLabels)
+}
+
+func TestGenerateKeepsUserBaseKustomizeFields(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	outputFolder := filepath.Join("/tmp/user-base-fields", "components", componentName, "base")
+	options := gitopsv1alpha1.GeneratorOptions{Name: componentName, ContainerImage: "quay.io/test/test-image:v1"}
+	err := Generate(fs, "/tmp/user-base-fields", outputFolder, options)
+	assertNoError(t, err)
+
+	kustomizationPath := filepath.Join(outputFolder, kustomizeFileName)
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(kustomizationPath)
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+
+	// Add the fields the base doesn't generate to the base kustomization by hand
+	k := readKustomization()
+	k.SecretGenerator = []resources.SecretArgs{{GeneratorArgs: resources.GeneratorArgs{Name: "test-secret", Literals: []string{"TOKEN=test"}}}}
+	k.Images = []resources.Image{{Name: "quay.io/test/sidecar", NewTag: "v5"}}
+	k.NamePrefix = "test-"
+	k.NameSuffix = "-v1"
+	k.Namespace = "test-namespace"
+	k.Replacements = []resources.Replacement{{Path: "replacements.yaml"}}
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(kustomizationPath, kustomizationBytes, 0644))
+
+	// Regenerate with a new image
+	options.ContainerImage = "quay.io/test/test-image:v2"
+	err = Generate(fs, "/tmp/user-base-fields", outputFolder, options)
+	assertNoError(t, err)
+
+	got := readKustomization()
+	assert.Equal(t, k.SecretGenerator, got.SecretGenerator)
+	assert.Equal(t, k.Images, got.Images)
+	assert.Equal(t, k.NamePrefix, got.NamePrefix)
+	assert.Equal(t, k.NameSuffix, got.NameSuffix)
+	assert.Equal(t, k.Namespace, got.Namespace)
+	assert.Equal(t, k.Replacements, got.Replacements)
+}
+
+func TestGenerateMergesCommonAnnotations(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	outputFolder := filepath.Join("/tmp/user-annotations", "components", componentName, "base")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:              componentName,
+		ContainerImage:    "quay.io/test/test-image:v1",
+		CommonAnnotations: map[string]string{"owner": "gitops"},
+	}
+	err := Generate(fs, "/tmp/user-annotations", outputFolder, options)
+	assertNoError(t, err)
+
+	kustomizationPath := filepath.Join(outputFolder, kustomizeFileName)
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(kustomizationPath)
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+
+	// Add an annotation and change the generated one in the base kustomization by hand
+	k := readKustomization()
+	k.CommonAnnotations["team"] = "test"
+	k.CommonAnnotations["owner"] = "user"
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(kustomizationPath, kustomizationBytes, 0644))
+
+	// Regenerating keeps the annotation of the user, while the generated one wins
+	err = Generate(fs, "/tmp/user-annotations", outputFolder, options)
+	assertNoError(t, err)
+	assert.Equal(t, map[string]string{"owner": "gitops", "team": "test"}, readKustomization().CommonAnnotations)
+}
+
+func TestGenerateKeepsUserConfigMapGenerators(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	outputFolder := filepath.Join("/tmp/user-configmaps", "components", componentName, "base")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:           componentName,
+		ContainerImage: "quay.io/test/test-image:v1",
+		ConfigMaps:     []gitopsv1alpha1.ConfigMapOptions{{Name: "test-config", Literals: map[string]string{"LOG_LEVEL": "info"}}},
+	}
+	err := Generate(fs, "/tmp/user-configmaps", outputFolder, options)
+	assertNoError(t, err)
+
+	kustomizationPath := filepath.Join(outputFolder, kustomizeFileName)
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(kustomizationPath)
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+
+	// Add a configMapGenerator entry to the base kustomization by hand
+	k := readKustomization()
+	userGenerator := resources.ConfigMapArgs{GeneratorArgs: resources.GeneratorArgs{Name: "user-config", Literals: []string{"FEATURE=on"}}}
+	k.ConfigMapGenerator = append(k.ConfigMapGenerator, userGenerator)
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(kustomizationPath, kustomizationBytes, 0644))
+
+	// Regenerate with a new value of the generated ConfigMap
+	options.ConfigMaps[0].Literals["LOG_LEVEL"] = "debug"
+	err = Generate(fs, "/tmp/user-configmaps", outputFolder, options)
+	assertNoError(t, err)
+
+	got := readKustomization()
+	assert.Equal(t, []resources.ConfigMapArgs{
+		{GeneratorArgs: resources.GeneratorArgs{Name: "test-config", Literals: []string{"LOG_LEVEL=debug"}}},
+		userGenerator,
+	}, got.ConfigMapGenerator)
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:           componentName,
+		Application:    "test-application",
+		ContainerImage: "quay.io/test/test-image:latest",
+		TargetPort:     8080,
+		Route:          "test-component.example.com",
+		Replicas:       2,
+		K8sLabels: map[string]string{
+			"app.kubernetes.io/name":    componentName,
+			"app.kubernetes.io/part-of": "test-application",
+			"team":                      "test",
+		},
+		CommonAnnotations: map[string]string{
+			"owner":   "test",
+			"contact": "test@example.com",
+		},
+		BaseEnvVar: []corev1.EnvVar{
+			{
+				Name:  "b",
+				Value: "2",
+			},
+			{
+				Name:  "a",
+				Value: "1",
+			},
+		},
+		ConfigMaps: []gitopsv1alpha1.ConfigMapOptions{
+			{
+				Name: "test-config",
+				Literals: map[string]string{
+					"z": "26",
+					"a": "1",
+					"m": "13",
+				},
+				Files: map[string]string{
+					"b.properties": "b=2",
+					"a.properties": "a=1",
+				},
+			},
+		},
+	}
+
+	// Generate the same component twice, and make sure the files are identical
+	for _, gitOpsFolder := range []string{"/tmp/deterministic-1", "/tmp/deterministic-2"} {
+		componentFolder := filepath.Join(gitOpsFolder, "components", componentName)
+		err := Generate(fs, gitOpsFolder, filepath.Join(componentFolder, "base"), options)
+		assertNoError(t, err)
+		err = GenerateOverlays(fs, gitOpsFolder, filepath.Join(componentFolder, "overlays", "prod"), options, "quay.io/test/test-image:v2", "test-namespace", map[string][]string{})
+		assertNoError(t, err)
+	}
+
+	var files []string
+	err := fs.Walk("/tmp/deterministic-1", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		files = append(files, strings.TrimPrefix(path, "/tmp/deterministic-1"))
+		return nil
+	})
+	assertNoError(t, err)
+	assert.NotEmpty(t, files)
+	for _, file := range files {
+		want, err := fs.ReadFile(filepath.Join("/tmp/deterministic-1", file))
+		assertNoError(t, err)
+		got, err := fs.ReadFile(filepath.Join("/tmp/deterministic-2", file))
+		assertNoError(t, err)
+		assert.Equal(t, string(want), string(got), "file %s differs between runs", file)
+	}
+}
+
+func TestGenerateOverlaysWithValidateKustomize(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/validate-kustomize", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:              componentName,
+		ValidateKustomize: true,
+		ConfigMaps: []gitopsv1alpha1.ConfigMapOptions{
+			{
+				Name: "test-config",
+				Files: map[string]string{
+					"application.properties": "key=value",
+				},
+			},
+		},
+	}
+	err := Generate(fs, "/tmp/validate-kustomize", filepath.Join(componentFolder, "base"), options)
+	assertNoError(t, err)
+	err = GenerateOverlays(fs, "/tmp/validate-kustomize", overlayFolder, options, imageName, namespace, map[string][]string{})
+	assertNoError(t, err)
+
+	// A patch added by users to the overlays that references a missing file is caught on regeneration
+	var k resources.Kustomization
+	kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+	k.AddPatches("missing-patch.yaml")
+	kustomizationBytes, err = yaml.Marshal(k)
+	assertNoError(t, err)
+	err = fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), kustomizationBytes, 0644)
+	assertNoError(t, err)
+
+	err = GenerateOverlays(fs, "/tmp/validate-kustomize", overlayFolder, options, imageName, namespace, map[string][]string{})
+	testutils.AssertErrorMatch(t, "the kustomization in \"/tmp/validate-kustomize/components/test-component/overlays/prod\" references \"missing-patch.yaml\", which doesn't exist", err)
+
+	// A deleted ConfigMap file of the base is caught as well
+	err = fs.Remove(filepath.Join(componentFolder, "base", "application.properties"))
+	assertNoError(t, err)
+	err = validateKustomization(fs, filepath.Join(componentFolder, "base"))
+	testutils.AssertErrorMatch(t, "references \"application.properties\", which doesn't exist", err)
+}
+
+func TestValidateKustomization(t *testing.T) {
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	tests := []struct {
+		name          string
+		files         map[string]string
+		patches       []resources.Patch
+		resources     []string
+		transformers  []interface{}
+		wantErrString string
+	}{
+		{
+			name: "Generated kustomization",
+		},
+		{
+			name: "Patch of a resource of the base",
+			files: map[string]string{
+				"labels-patch.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: test-component\n  labels:\n    team: test\n",
+			},
+			patches: []resources.Patch{{Path: "labels-patch.yaml"}},
+		},
+		{
+			name: "Patch that doesn't match any resource",
+			files: map[string]string{
+				"labels-patch.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: other-component\n",
+			},
+			patches:       []resources.Patch{{Path: "labels-patch.yaml"}},
+			wantErrString: "the \"labels-patch.yaml\" patch of the kustomization in \"/tmp/validate/components/test-component/overlays/prod\" doesn't match any resource, Deployment \"other-component\" isn't built by the kustomization",
+		},
+		{
+			name:          "Inline patch that isn't valid",
+			patches:       []resources.Patch{{Patch: "kind: [Deployment"}},
+			wantErrString: "the \"inline patch\" patch of the kustomization in \"/tmp/validate/components/test-component/overlays/prod\" isn't valid",
+		},
+		{
+			name: "Patch with a target that doesn't match any resource",
+			files: map[string]string{
+				"replicas-patch.yaml": "- op: replace\n  path: /spec/replicas\n  value: 2\n",
+			},
+			patches: []resources.Patch{{Path: "replicas-patch.yaml", Target: &resources.PatchTarget{Kind: "StatefulSet", Name: "other-component"}}},
+		},
+		{
+			name: "Resource added more than once",
+			files: map[string]string{
+				"deployment-copy.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: test-component\n",
+			},
+			resources:     []string{"deployment-copy.yaml"},
+			wantErrString: "the kustomization in \"/tmp/validate/components/test-component/overlays/prod\" adds Deployment.apps \"test-component\" more than once",
+		},
+		{
+			name: "Resource without a name",
+			files: map[string]string{
+				"config.yaml": "apiVersion: v1\nkind: ConfigMap\n",
+			},
+			resources:     []string{"config.yaml"},
+			wantErrString: "references \"config.yaml\", which isn't a valid resource file: the ConfigMap resource has no name",
+		},
+		{
+			name: "Transformer",
+			files: map[string]string{
+				"labels-transformer.yaml": "apiVersion: builtin\nkind: LabelTransformer\nmetadata:\n  name: labels\nlabels:\n  team: test\nfieldSpecs:\n- path: metadata/labels\n  create: true\n",
+			},
+			transformers: []interface{}{"labels-transformer.yaml"},
+		},
+		{
+			name:          "Missing transformer",
+			transformers:  []interface{}{"missing-transformer.yaml"},
+			wantErrString: "references \"missing-transformer.yaml\", which doesn't exist",
+		},
+		{
+			name: "Transformer that isn't valid",
+			files: map[string]string{
+				"labels-transformer.yaml": "labels:\n  team: test\n",
+			},
+			transformers:  []interface{}{"labels-transformer.yaml"},
+			wantErrString: "references \"labels-transformer.yaml\", which isn't a valid transformer: a resource has no kind",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			componentFolder := filepath.Join("/tmp/validate", "components", componentName)
+			overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+			options := gitopsv1alpha1.GeneratorOptions{Name: componentName, ContainerImage: imageName}
+			err := Generate(fs, "/tmp/validate", filepath.Join(componentFolder, "base"), options)
+			assertNoError(t, err)
+			err = GenerateOverlays(fs, "/tmp/validate", overlayFolder, options, imageName, namespace, nil)
+			assertNoError(t, err)
+
+			for name, content := range tt.files {
+				assertNoError(t, fs.WriteFile(filepath.Join(overlayFolder, name), []byte(content), 0644))
+			}
+			var k resources.Kustomization
+			kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+			assertNoError(t, err)
+			assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+			k.Patches = append(k.Patches, tt.patches...)
+			k.Resources = append(k.Resources, tt.resources...)
+			if tt.transformers != nil {
+				k.UnknownFields = map[string]interface{}{"transformers": tt.transformers}
+			}
+			kustomizationBytes, err = yaml.Marshal(k)
+			assertNoError(t, err)
+			assertNoError(t, fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), kustomizationBytes, 0644))
+
+			err = validateKustomization(fs, overlayFolder)
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				var validationErr *KustomizeValidationError
+				assert.True(t, errors.As(err, &validationErr))
+			} else {
+				assertNoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGenerateOverlaysWithNamespaceTransformer(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/namespace-transformer", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	err := Generate(fs, "/tmp/namespace-transformer", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+	readDeploymentPatch := func() appsv1.Deployment {
+		var deployment appsv1.Deployment
+		deploymentPatchBytes, err := fs.ReadFile(filepath.Join(overlayFolder, deploymentPatchFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(deploymentPatchBytes, &deployment))
+		return deployment
+	}
+
+	// The namespace is set in the patch only
+	err = GenerateOverlays(fs, "/tmp/namespace-transformer", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Empty(t, readKustomization().Namespace)
+	assert.Equal(t, namespace, readDeploymentPatch().Namespace)
+
+	// A namespace set by users in the kustomization is kept
+	k := readKustomization()
+	k.Namespace = "user-namespace"
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), kustomizationBytes, 0644))
+	err = GenerateOverlays(fs, "/tmp/namespace-transformer", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, "user-namespace", readKustomization().Namespace)
+	assert.Equal(t, namespace, readDeploymentPatch().Namespace)
+
+	// With the namespace transformer, the namespace is set in the kustomization and left out of the patch
+	err = GenerateOverlays(fs, "/tmp/namespace-transformer", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName, EnableNamespaceTransformer: true}, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, namespace, readKustomization().Namespace)
+	assert.Empty(t, readDeploymentPatch().Namespace)
+}
+
+func TestGenerateOverlaysWithBaseOverlay(t *testing.T) {
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+	tempDir, cleanup := makeTempDir(t)
+	defer cleanup()
+
+	tests := []struct {
+		name         string
+		fs           afero.Afero
+		gitOpsFolder string
+	}{
+		{
+			name:         "Memory filesystem",
+			fs:           ioutils.NewMemoryFilesystem(),
+			gitOpsFolder: "/tmp/base-overlay",
+		},
+		{
+			name:         "Real filesystem",
+			fs:           ioutils.NewFilesystem(),
+			gitOpsFolder: tempDir,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			componentFolder := filepath.Join(tt.gitOpsFolder, "components", componentName)
+			err := Generate(tt.fs, tt.gitOpsFolder, filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+			assertNoError(t, err)
+			err = GenerateOverlays(tt.fs, tt.gitOpsFolder, filepath.Join(componentFolder, "overlays", "common"), gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, nil)
+			assertNoError(t, err)
+
+			// The prod overlays inherit from the common overlays rather than the base
+			prodFolder := filepath.Join(componentFolder, "overlays", "prod")
+			err = GenerateOverlays(tt.fs, tt.gitOpsFolder, prodFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName, BaseOverlay: "../common/"}, imageName, namespace, nil)
+			assertNoError(t, err)
+
+			var k resources.Kustomization
+			kustomizationBytes, err := tt.fs.ReadFile(filepath.Join(prodFolder, kustomizeFileName))
+			assertNoError(t, err)
+			assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+			assert.Equal(t, []string{"../common"}, k.Resources)
+
+			// The shared overlay must exist
+			err = GenerateOverlays(tt.fs, tt.gitOpsFolder, prodFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName, BaseOverlay: "../missing"}, imageName, namespace, nil)
+			testutils.AssertErrorMatch(t, "unable to base the overlays on \"../missing\"", err)
+			exists, err := tt.fs.Exists(filepath.Join(prodFolder, kustomizeFileName))
+			assertNoError(t, err)
+			assert.True(t, exists)
+		})
+	}
+}
+
+func TestGenerateWithOtherResourceFiles(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	outputFolder := "/tmp/other-resource-files/components/test-component/base"
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		KubernetesResources: gitopsv1alpha1.KubernetesResources{
+			Deployments: []appsv1.Deployment{
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name: componentName,
+					},
+				},
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name: "worker",
+					},
+				},
+			},
+		},
+	}
+
+	// The files of the previous generation are overwritten
+	for i := 0; i < 2; i++ {
+		err := Generate(fs, "/tmp/other-resource-files", outputFolder, options)
+		assertNoError(t, err)
+	}
+	exists, err := fs.Exists(filepath.Join(outputFolder, "deployment-worker.yaml"))
+	assertNoError(t, err)
+	assert.True(t, exists)
+
+	// A file added by users isn't overwritten
+	options.KubernetesResources.Services = []corev1.Service{
+		{
+			ObjectMeta: v1.ObjectMeta{
+				Name: componentName,
+			},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{
+				Name: "metrics",
+			},
+		},
+	}
+	err = fs.WriteFile(filepath.Join(outputFolder, "service-metrics.yaml"), []byte("user content"), 0644)
+	assertNoError(t, err)
+	err = Generate(fs, "/tmp/other-resource-files", outputFolder, options)
+	testutils.AssertErrorMatch(t, "unable to write a passed in resource to \"service-metrics.yaml\"", err)
+	content, err := fs.ReadFile(filepath.Join(outputFolder, "service-metrics.yaml"))
+	assertNoError(t, err)
+	assert.Equal(t, "user content", string(content))
+
+	// Unless the resources are written to a single file
+	options.SingleOtherResourcesFile = true
+	err = Generate(fs, "/tmp/other-resource-files", outputFolder, options)
+	assertNoError(t, err)
+	var k resources.Kustomization
+	kustomizationBytes, err := fs.ReadFile(filepath.Join(outputFolder, kustomizeFileName))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+	assert.Equal(t, []string{deploymentFileName, otherFileName, serviceFileName}, k.Resources)
+}
+
+func TestGenerateOverlaysWithMultipleDeploymentPatches(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/multiple-deployment-patches", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	err := Generate(fs, "/tmp/multiple-deployment-patches", filepath.Join(componentFolder, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName})
+	assertNoError(t, err)
+
+	readPatches := func() []resources.Patch {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k.Patches
+	}
+
+	// With a single deployment patch, the patch has no target
+	err = GenerateOverlays(fs, "/tmp/multiple-deployment-patches", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, map[string][]string{})
+	assertNoError(t, err)
+	assert.Equal(t, []resources.Patch{{Path: deploymentPatchFileName}}, readPatches())
+
+	// With the patch of a second deployment, the generated patch targets the component's deployment
+	componentGeneratedResources := map[string][]string{
+		componentName: {"worker-" + deploymentPatchFileName},
+	}
+	err = GenerateOverlays(fs, "/tmp/multiple-deployment-patches", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, componentGeneratedResources)
+	assertNoError(t, err)
+	assert.Equal(t, []resources.Patch{
+		{
+			Path: "worker-" + deploymentPatchFileName,
+		},
+		{
+			Path: deploymentPatchFileName,
+			Target: &resources.PatchTarget{
+				Kind: "Deployment",
+				Name: componentName,
+			},
+		},
+	}, readPatches())
+}
+
+func TestGenerateWithCommonLabels(t *testing.T) {
+	componentName := "test-component"
+	commonLabels := map[string]string{
+		"team": "test",
+	}
+
+	tests := []struct {
+		name             string
+		existing         *resources.Kustomization
+		options          gitopsv1alpha1.GeneratorOptions
+		wantCommonLabels map[string]string
+		wantLabels       []resources.Label
+	}{
+		{
+			name: "Common labels set as commonLabels",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				CommonLabels: commonLabels,
+			},
+			wantCommonLabels: commonLabels,
+		},
+		{
+			name: "Common labels set as labels",
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:               componentName,
+				CommonLabels:       commonLabels,
+				UseKustomizeLabels: true,
+			},
+			wantLabels: []resources.Label{
+				{
+					Pairs:            commonLabels,
+					IncludeTemplates: true,
+				},
+			},
+		},
+		{
+			name: "Existing commonLabels and labels are kept",
+			existing: &resources.Kustomization{
+				CommonLabels: map[string]string{
+					"owner": "test",
+				},
+				Labels: []resources.Label{
+					{
+						Pairs: map[string]string{
+							"app": componentName,
+						},
+						IncludeSelectors: true,
+					},
+					{
+						Pairs: map[string]string{
+							"tier": "backend",
+						},
+						IncludeTemplates: true,
+					},
+				},
+			},
+			options: gitopsv1alpha1.GeneratorOptions{
+				Name:               componentName,
+				CommonLabels:       commonLabels,
+				UseKustomizeLabels: true,
+			},
+			wantCommonLabels: map[string]string{
+				"owner": "test",
+			},
+			wantLabels: []resources.Label{
+				{
+					Pairs: map[string]string{
+						"app": componentName,
+					},
+					IncludeSelectors: true,
+				},
+				{
+					Pairs: map[string]string{
+						"team": "test",
+						"tier": "backend",
+					},
+					IncludeTemplates: true,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			outputFolder := "/tmp/common-labels/components/test-component/base"
+			if tt.existing != nil {
+				kustomizationBytes, err := yaml.Marshal(tt.existing)
+				assertNoError(t, err)
+				assertNoError(t, fs.MkdirAll(outputFolder, 0755))
+				assertNoError(t, fs.WriteFile(filepath.Join(outputFolder, kustomizeFileName), kustomizationBytes, 0644))
+			}
+
+			err := Generate(fs, "/tmp/common-labels", outputFolder, tt.options)
+			assertNoError(t, err)
+
+			var k resources.Kustomization
+			kustomizationBytes, err := fs.ReadFile(filepath.Join(outputFolder, kustomizeFileName))
+			assertNoError(t, err)
+			assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+			assert.Equal(t, tt.wantCommonLabels, k.CommonLabels)
+			assert.Equal(t, tt.wantLabels, k.Labels)
+		})
+	}
+}
+
+func TestGenerateOverlaysWithSortOptionsAndBuildMetadata(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/sort-options", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	options := gitopsv1alpha1.GeneratorOptions{Name: componentName, UseKustomizeManagedByLabel: true}
+	err := Generate(fs, "/tmp/sort-options", filepath.Join(componentFolder, "base"), options)
+	assertNoError(t, err)
+
+	// kustomize adds the managed-by label instead of the generated resources
+	var k resources.Kustomization
+	kustomizationBytes, err := fs.ReadFile(filepath.Join(componentFolder, "base", kustomizeFileName))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+	assert.Equal(t, []string{"managedByLabel"}, k.BuildMetadata)
+	var deployment appsv1.Deployment
+	deploymentBytes, err := fs.ReadFile(filepath.Join(componentFolder, "base", deploymentFileName))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(deploymentBytes, &deployment))
+	assert.NotContains(t, deployment.Labels, "app.kubernetes.io/managed-by")
+
+	// The sort options and build metadata of the overlays kustomization survive its regeneration
+	err = GenerateOverlays(fs, "/tmp/sort-options", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	kustomizationBytes, err = fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+	assertNoError(t, err)
+	kustomizationBytes = append(kustomizationBytes, []byte("sortOptions:\n  order: fifo\nbuildMetadata:\n- originAnnotations\n")...)
+	assertNoError(t, fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), kustomizationBytes, 0644))
+
+	err = GenerateOverlays(fs, "/tmp/sort-options", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	k = resources.Kustomization{}
+	kustomizationBytes, err = fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+	assert.Equal(t, &resources.SortOptions{Order: "fifo"}, k.SortOptions)
+	assert.Equal(t, []string{"originAnnotations"}, k.BuildMetadata)
+}
+
+func TestGenerateOverlaysWithKustomizeGeneratorOptions(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/kustomize-generator-options", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		KustomizeGeneratorOptions: &gitopsv1alpha1.KustomizeGeneratorOptions{
+			Labels: map[string]string{
+				"team": "test",
+			},
+			DisableNameSuffixHash: true,
+		},
+	}
+	wantGeneratorOptions := &resources.GeneratorOptions{
+		Labels: map[string]string{
+			"team": "test",
+		},
+		DisableNameSuffixHash: true,
+	}
+	readGeneratorOptions := func(folder string) *resources.GeneratorOptions {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(folder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k.GeneratorOptions
+	}
+
+	err := Generate(fs, "/tmp/kustomize-generator-options", filepath.Join(componentFolder, "base"), options)
+	assertNoError(t, err)
+	assert.Equal(t, wantGeneratorOptions, readGeneratorOptions(filepath.Join(componentFolder, "base")))
+	err = GenerateOverlays(fs, "/tmp/kustomize-generator-options", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, wantGeneratorOptions, readGeneratorOptions(overlayFolder))
+
+	// The generatorOptions written by users are kept when they aren't set
+	kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+	assertNoError(t, err)
+	kustomizationBytes = bytes.Replace(kustomizationBytes, []byte("    team: test\n"), []byte("    team: test\n  annotations:\n    owner: test\n"), 1)
+	assertNoError(t, fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), kustomizationBytes, 0644))
+	err = GenerateOverlays(fs, "/tmp/kustomize-generator-options", overlayFolder, gitopsv1alpha1.GeneratorOptions{Name: componentName}, imageName, namespace, nil)
+	assertNoError(t, err)
+	wantGeneratorOptions.Annotations = map[string]string{
+		"owner": "test",
+	}
+	assert.Equal(t, wantGeneratorOptions, readGeneratorOptions(overlayFolder))
+}
+
+func TestGenerateWithPreserveUserResources(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	outputFolder := "/tmp/preserve-user-resources/components/test-component/base"
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:                  componentName,
+		TargetPort:            8080,
+		PreserveUserResources: true,
+	}
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(outputFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+
+	err := Generate(fs, "/tmp/preserve-user-resources", outputFolder, options)
+	assertNoError(t, err)
+	assert.Equal(t, "deployment.yaml,service.yaml", readKustomization().Metadata.Annotations[generatedResourcesAnnotation])
+
+	// Add a resource to the base, as users would
+	userConfigMap := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: user-config\n"
+	assertNoError(t, fs.WriteFile(filepath.Join(outputFolder, "configmap.yaml"), []byte(userConfigMap), 0644))
+	k := readKustomization()
+	k.AddResources("configmap.yaml")
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(filepath.Join(outputFolder, kustomizeFileName), kustomizationBytes, 0644))
+
+	// The user resource survives the regenerations, while a resource no longer generated is removed
+	err = Generate(fs, "/tmp/preserve-user-resources", outputFolder, options)
+	assertNoError(t, err)
+	options.TargetPort = 0
+	err = Generate(fs, "/tmp/preserve-user-resources", outputFolder, options)
+	assertNoError(t, err)
+
+	k = readKustomization()
+	assert.Equal(t, []string{"configmap.yaml", deploymentFileName}, k.Resources)
+	assert.Equal(t, deploymentFileName, k.Metadata.Annotations[generatedResourcesAnnotation])
+	content, err := fs.ReadFile(filepath.Join(outputFolder, "configmap.yaml"))
+	assertNoError(t, err)
+	assert.Equal(t, userConfigMap, string(content))
+	exists, err := fs.Exists(filepath.Join(outputFolder, serviceFileName))
+	assertNoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestGenerateWithCustomResourceDefinitions(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	outputFolder := "/tmp/custom-resource-definitions/components/test-component/base"
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		KubernetesResources: gitopsv1alpha1.KubernetesResources{
+			Others: []interface{}{
+				map[string]interface{}{
+					"apiVersion": "example.com/v1",
+					"kind":       "Widget",
+					"metadata": map[string]interface{}{
+						"name": "test",
+					},
+				},
+				map[string]interface{}{
+					"apiVersion": "apiextensions.k8s.io/v1",
+					"kind":       "CustomResourceDefinition",
+					"metadata": map[string]interface{}{
+						"name": "widgets.example.com",
+					},
+				},
+			},
+		},
+	}
+	crdFile := "crd-widgets.example.com.yaml"
+
+	readKustomization := func() resources.Kustomization {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(outputFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k
+	}
+
+	err := Generate(fs, "/tmp/custom-resource-definitions", outputFolder, options)
+	assertNoError(t, err)
+	k := readKustomization()
+	assert.Equal(t, []string{crdFile}, k.Crds)
+	// The CRD is applied before the custom resource
+	assert.Equal(t, crdFile, k.Resources[0])
+	assert.Contains(t, k.Resources, "widget-test.yaml")
+	var crd map[string]interface{}
+	crdBytes, err := fs.ReadFile(filepath.Join(outputFolder, crdFile))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(crdBytes, &crd))
+	assert.Equal(t, "CustomResourceDefinition", crd["kind"])
+
+	// The openapi field and the CRDs added by users are kept on regeneration
+	k.OpenAPI = map[string]string{"path": "schema.json"}
+	k.Crds = append(k.Crds, "crd-users.example.com.yaml")
+	kustomizationBytes, err := yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(filepath.Join(outputFolder, kustomizeFileName), kustomizationBytes, 0644))
+	err = Generate(fs, "/tmp/custom-resource-definitions", outputFolder, options)
+	assertNoError(t, err)
+	k = readKustomization()
+	assert.Equal(t, []string{"crd-users.example.com.yaml", crdFile}, k.Crds)
+	assert.Equal(t, map[string]string{"path": "schema.json"}, k.OpenAPI)
+	assert.Equal(t, crdFile, k.Resources[0])
+}
+
+func TestGenerateWithResourceOrder(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	outputFolder := "/tmp/resource-order/components/test-component/base"
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:       componentName,
+		TargetPort: 8080,
+	}
+
+	readResources := func() []string {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(outputFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k.Resources
+	}
+
+	// The resources are sorted by default
+	err := Generate(fs, "/tmp/resource-order", outputFolder, options)
+	assertNoError(t, err)
+	assert.Equal(t, []string{deploymentFileName, serviceFileName}, readResources())
+
+	// The listed resources come first, and the files that aren't generated are ignored
+	options.ResourceOrder = []string{serviceFileName, "namespace.yaml"}
+	err = Generate(fs, "/tmp/resource-order", outputFolder, options)
+	assertNoError(t, err)
+	assert.Equal(t, []string{serviceFileName, deploymentFileName}, readResources())
+}
+
+func TestGenerateOverlaysWithReplacements(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
+
+	componentFolder := filepath.Join("/tmp/replacements", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+		OverlayReplacements: []gitopsv1alpha1.Replacement{
+			{
+				Source: &gitopsv1alpha1.ReplacementSource{
+					Kind:      "Deployment",
+					Name:      componentName,
+					FieldPath: "spec.template.spec.containers.0.image",
+					Options: &gitopsv1alpha1.ReplacementFieldOptions{
+						Delimiter: "@",
+						Index:     1,
+					},
+				},
+				Targets: []gitopsv1alpha1.ReplacementTarget{
+					{
+						Select: &gitopsv1alpha1.ReplacementSelector{
+							Kind: "Deployment",
+							Name: componentName,
+						},
+						FieldPaths: []string{"metadata.annotations.[example.com/image-digest]"},
+						Options: &gitopsv1alpha1.ReplacementFieldOptions{
+							Create: true,
+						},
+					},
+				},
+			},
+		},
+	}
+	err := Generate(fs, "/tmp/replacements", filepath.Join(componentFolder, "base"), options)
+	assertNoError(t, err)
+
+	readReplacements := func() []resources.Replacement {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k.Replacements
+	}
+	generated := resources.Replacement{
+		Source: &resources.SourceSelector{
+			Kind:      "Deployment",
+			Name:      componentName,
+			FieldPath: "spec.template.spec.containers.0.image",
+			Options: &resources.FieldOptions{
+				Delimiter: "@",
+				Index:     1,
+			},
+		},
+		Targets: []resources.TargetSelector{
+			{
+				Select: &resources.Selector{
+					Kind: "Deployment",
+					Name: componentName,
+				},
+				FieldPaths: []string{"metadata.annotations.[example.com/image-digest]"},
+				Options: &resources.FieldOptions{
+					Create: true,
+				},
+			},
+		},
+	}
 
-					deployPatch := appsv1.Deployment{}
-					deploymentPatchBytes, err := tt.fs.ReadFile(deploymentPatchFilepath)
-					if err != nil {
-						t.Errorf("unexpected error reading deployment file")
-					}
-					err = yaml.Unmarshal(deploymentPatchBytes, &deployPatch)
-					if err != nil {
-						t.Errorf("unexpected error unmarshaling deployment patch file bytes")
-					}
-					if deployPatch.Spec.Template.Spec.Containers[0].Name != containerName {
-						t.Errorf("expected container name %v, got %v", containerName, deployPatch.Spec.Template.Spec.Containers[0].Name)
-					}
-				}
+	err = GenerateOverlays(fs, "/tmp/replacements", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, []resources.Replacement{generated}, readReplacements())
 
-				if tt.options.IsKubernetesCluster {
-					// Validate that the ingress.yaml got created successfully and contains the proper entries
-					ingressFilepath := filepath.Join(tt.outputFolder, ingressFileName)
-					exists, err = tt.fs.Exists(ingressFilepath)
-					if err != nil {
-						t.Errorf("unexpected error checking if ingress file exists %v", err)
-					}
-					if !exists && tt.options.TargetPort != 0 {
-						t.Errorf("ingress file does not exist at path %v", ingressFilepath)
-					} else if exists {
-						ingress := networkingv1.Ingress{}
-						ingressBytes, err := tt.fs.ReadFile(ingressFilepath)
-						if err != nil {
-							t.Errorf("unexpected error reading ingress file")
-						}
-						err = yaml.Unmarshal(ingressBytes, &ingress)
-						if err != nil {
-							t.Errorf("unexpected error unmarshaling ingress file bytes")
-						}
-						if len(tt.options.KubernetesResources.Ingresses) > 0 && ingress.Name != tt.options.KubernetesResources.Ingresses[0].Name {
-							t.Errorf("expected ingress name %v, got %v", tt.options.KubernetesResources.Ingresses[0].Name, ingress.Name)
-						} else if len(tt.options.KubernetesResources.Ingresses) == 0 && ingress.Name != tt.options.Name {
-							t.Errorf("expected ingress name %v, got %v", tt.options.Name, ingress.Name)
-						}
-					}
+	// The replacements added by users are kept, and the generated ones aren't duplicated on regeneration
+	kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+	assertNoError(t, err)
+	var k resources.Kustomization
+	assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+	userReplacement := resources.Replacement{Path: "replacements/route-host.yaml"}
+	k.Replacements = append([]resources.Replacement{userReplacement}, k.Replacements...)
+	kustomizationBytes, err = yaml.Marshal(k)
+	assertNoError(t, err)
+	assertNoError(t, fs.WriteFile(filepath.Join(overlayFolder, kustomizeFileName), kustomizationBytes, 0644))
 
-				} else {
-					// Validate that the route.yaml got created successfully and contains the proper entries
-					routeFilepath := filepath.Join(tt.outputFolder, routeFileName)
-					exists, err = tt.fs.Exists(routeFilepath)
-					if err != nil {
-						t.Errorf("unexpected error checking if route file exists %v", err)
-					}
-					if !exists && tt.options.TargetPort != 0 {
-						t.Errorf("route file does not exist at path %v", routeFilepath)
-					} else if exists {
-						route := routev1.Route{}
-						routeBytes, err := tt.fs.ReadFile(routeFilepath)
-						if err != nil {
-							t.Errorf("unexpected error reading route file")
-						}
-						err = yaml.Unmarshal(routeBytes, &route)
-						if err != nil {
-							t.Errorf("unexpected error unmarshaling route file bytes")
-						}
-						if len(tt.options.KubernetesResources.Routes) > 0 && route.Name != tt.options.KubernetesResources.Routes[0].Name {
-							t.Errorf("expected route name %v, got %v", tt.options.KubernetesResources.Routes[0].Name, route.Name)
-						} else if len(tt.options.KubernetesResources.Routes) == 0 && route.Name != tt.options.Name {
-							t.Errorf("expected route name %v, got %v", tt.options.Name, route.Name)
-						}
-					}
-				}
+	err = GenerateOverlays(fs, "/tmp/replacements", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Equal(t, []resources.Replacement{userReplacement, generated}, readReplacements())
+}
 
-				// Validate that the kustomization.yaml got created successfully and contains the proper entries
-				kustomizationFilepath := filepath.Join(tt.outputFolder, kustomizeFileName)
-				exists, err = tt.fs.Exists(kustomizationFilepath)
-				if err != nil {
-					t.Errorf("unexpected error checking if kustomize file exists %v", err)
-				}
-				if !exists {
-					t.Errorf("kustomize file does not exist at path %v", kustomizationFilepath)
-				}
+func TestGenerateOverlaysWithCreateNamespace(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+	imageName := "test-image"
+	namespace := "test-namespace"
 
-				// Read the kustomization.yaml and validate its entries
-				k := resources.Kustomization{}
-				kustomizationBytes, err := tt.fs.ReadFile(kustomizationFilepath)
-				if err != nil {
-					t.Errorf("unexpected error reading parent kustomize file")
-				}
-				err = yaml.Unmarshal(kustomizationBytes, &k)
-				if err != nil {
-					t.Errorf("unexpected error unmarshaling file")
-				}
-				// There match patch entries in the kustomization file
-				if len(k.Patches) != tt.expectPatchEntries {
-					t.Errorf("expected %v kustomization bases, got %v patches: %v", tt.expectPatchEntries, len(k.Patches), k.Patches)
-				}
+	componentFolder := filepath.Join("/tmp/create-namespace", "components", componentName)
+	overlayFolder := filepath.Join(componentFolder, "overlays", "prod")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:            componentName,
+		Application:     "test-application",
+		CreateNamespace: true,
+	}
+	err := Generate(fs, "/tmp/create-namespace", filepath.Join(componentFolder, "base"), options)
+	assertNoError(t, err)
 
-				// Validate that the APIVersion and Kind are set properly
-				if k.Kind != "Kustomization" {
-					t.Errorf("expected kustomize kind %v, got %v", "Kustomization", k.Kind)
-				}
-				if k.APIVersion != "kustomize.config.k8s.io/v1beta1" {
-					t.Errorf("expected kustomize apiversion %v, got %v", "kustomize.config.k8s.io/v1beta1", k.APIVersion)
-				}
+	readResources := func() []string {
+		var k resources.Kustomization
+		kustomizationBytes, err := fs.ReadFile(filepath.Join(overlayFolder, kustomizeFileName))
+		assertNoError(t, err)
+		assertNoError(t, yaml.Unmarshal(kustomizationBytes, &k))
+		return k.Resources
+	}
 
-			}
-		})
+	err = GenerateOverlays(fs, "/tmp/create-namespace", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.Contains(t, readResources(), namespaceFileName)
+	var ns corev1.Namespace
+	namespaceBytes, err := fs.ReadFile(filepath.Join(overlayFolder, namespaceFileName))
+	assertNoError(t, err)
+	assertNoError(t, yaml.Unmarshal(namespaceBytes, &ns))
+	assert.Equal(t, "Namespace", ns.Kind)
+	assert.Equal(t, "v1", ns.APIVersion)
+	assert.Equal(t, namespace, ns.Name)
+	assert.Equal(t, generateK8sLabels(options), ns.Labels)
+
+	// The namespace is removed once it's no longer created
+	options.CreateNamespace = false
+	err = GenerateOverlays(fs, "/tmp/create-namespace", overlayFolder, options, imageName, namespace, nil)
+	assertNoError(t, err)
+	assert.NotContains(t, readResources(), namespaceFileName)
+	exists, err := fs.Exists(filepath.Join(overlayFolder, namespaceFileName))
+	assertNoError(t, err)
+	assert.False(t, exists)
+
+	// A namespace isn't created without a name
+	options.CreateNamespace = true
+	err = GenerateOverlays(fs, "/tmp/create-namespace", overlayFolder, options, imageName, "", nil)
+	testutils.AssertErrorMatch(t, "the namespace is empty", err)
+}
+
+func TestGenerateOverlaysWithBaseCommand(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	componentName := "test-component"
+
+	baseDeployment := appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name: componentName,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "main",
+							Image:   "quay.io/test/test:latest",
+							Command: []string{"./passed-in"},
+						},
+					},
+				},
+			},
+		},
+	}
+	componentFolder := filepath.Join("/tmp/command", "components", componentName)
+	bytes, err := yaml.Marshal(baseDeployment)
+	assertNoError(t, err)
+	err = fs.WriteFile(filepath.Join(componentFolder, "base", deploymentFileName), bytes, 0755)
+	assertNoError(t, err)
+
+	outputFolder := filepath.Join(componentFolder, "overlays", "development")
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:    componentName,
+		Command: []string{"./from-options"},
+		Args:    []string{"--verbose"},
 	}
+	err = GenerateOverlays(fs, "/tmp/command", outputFolder, options, "test-image", "test-namespace", nil)
+	assertNoError(t, err)
+
+	deploymentPatchBytes, err := fs.ReadFile(filepath.Join(outputFolder, deploymentPatchFileName))
+	assertNoError(t, err)
+	deploymentPatch := appsv1.Deployment{}
+	err = yaml.Unmarshal(deploymentPatchBytes, &deploymentPatch)
+	assertNoError(t, err)
+
+	// the passed-in command wins, the args are not set in the base so they are still patched
+	assert.Nil(t, deploymentPatch.Spec.Template.Spec.Containers[0].Command)
+	assert.Equal(t, []string{"--verbose"}, deploymentPatch.Spec.Template.Spec.Containers[0].Args)
 }
 
 func TestGenerate(t *testing.T) {
@@ -1576,360 +7646,1175 @@ func TestGenerate(t *testing.T) {
 	componentName := "test-component"
 	namespace := "test-namespace"
 
-	deployment1 := appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "deployment1",
+	deployment1 := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "deployment1",
+		},
+	}
+	deployment2 := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "deployment2",
+		},
+	}
+
+	deploymentWithCommand := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "deployment-with-command",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "main",
+							Command: []string{"./passed-in"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	statefulSet1 := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "statefulset1",
+		},
+	}
+
+	statefulSet2 := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "statefulset2",
+		},
+	}
+
+	daemonSet1 := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "daemonset1",
+		},
+	}
+
+	daemonSet2 := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "daemonset2",
+		},
+	}
+
+	service1 := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "service1",
+		},
+	}
+	service2 := corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "service2",
+		},
+	}
+
+	route1 := routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "route1",
+		},
+	}
+	route2 := routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "route2",
+		},
+	}
+
+	ingress1 := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ingress1",
+		},
+	}
+	ingress2 := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ingress2",
+		},
+	}
+
+	pod1 := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod1",
+		},
+	}
+
+	others3 := []interface{}{
+		statefulSet2,
+		service2,
+		route2,
+	}
+
+	others4 := []interface{}{
+		daemonSet2,
+		service2,
+		route2,
+	}
+
+	maxSurge := intstr.FromInt(1)
+	pdbMinAvailable := intstr.FromInt(2)
+	hpaMinReplicas := int32(3)
+	knativeService := map[string]interface{}{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Service",
+		"metadata": map[string]interface{}{
+			"name": componentName,
+		},
+	}
+
+	storage := []gitopsv1alpha1.ComponentStorage{
+		{
+			Name:      "data",
+			Size:      "1Gi",
+			MountPath: "/var/lib/data",
+		},
+		{
+			Name:         "cache",
+			Size:         "500Mi",
+			MountPath:    "/var/cache",
+			StorageClass: "fast",
+		},
+	}
+
+	fs := ioutils.NewFilesystem()
+
+	tests := []struct {
+		name                  string
+		fs                    afero.Afero
+		component             gitopsv1alpha1.GeneratorOptions
+		outputFolder          string
+		isDeploymentGenerated bool
+		isServicetGenerated   bool
+		isRouteGenerated      bool
+		isSerializeRequired   bool // set to true if you are going to test KubernetesResources.Others
+		wantFiles             map[string]interface{}
+		wantErr               bool
+	}{
+		{
+			name: "Single deployment object provided only",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Deployments: []appsv1.Deployment{
+						deployment1,
+					},
+				},
+			},
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{deploymentFileName},
+				},
+				deploymentFileName: deployment1,
+			},
+		},
+		{
+			name: "Single statefulset object provided only",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					StatefulSets: []appsv1.StatefulSet{
+						statefulSet1,
+					},
+				},
+			},
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{statefulsetFileName},
+				},
+				statefulsetFileName: statefulSet1,
+			},
+		},
+		{
+			name: "Single daemonset object provided only",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					DaemonSets: []appsv1.DaemonSet{
+						daemonSet1,
+					},
+				},
+			},
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{daemonsetFileName},
+				},
+				daemonsetFileName: daemonSet1,
+			},
+		},
+		{
+			name: "Single svc object provided only",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Services: []corev1.Service{
+						service1,
+					},
+				},
+			},
+			isDeploymentGenerated: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{deploymentFileName, serviceFileName},
+				},
+				serviceFileName: service1,
+			},
+		},
+		{
+			name: "Single route object provided only",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Routes: []routev1.Route{
+						route1,
+					},
+				},
+			},
+			isDeploymentGenerated: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{deploymentFileName},
+				},
+			},
+		},
+		{
+			name: "Single deployment object provided only, with Target Port should generate svc",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Deployments: []appsv1.Deployment{
+						deployment1,
+					},
+				},
+				TargetPort: 1234,
+			},
+			isServicetGenerated: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{deploymentFileName, serviceFileName},
+				},
+				deploymentFileName: deployment1,
+			},
+		},
+		{
+			name: "Multiple deployment, service and route provided",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Deployments: []appsv1.Deployment{
+						deployment1,
+						deployment2,
+					},
+					Services: []corev1.Service{
+						service1,
+						service2,
+					},
+					Routes: []routev1.Route{
+						route1,
+						route2,
+					},
+				},
+				TargetPort: 1234,
+			},
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{"deployment-deployment2.yaml", deploymentFileName, "route-route2.yaml", "service-service2.yaml", serviceFileName},
+				},
+				deploymentFileName:            deployment1,
+				serviceFileName:               service1,
+				"deployment-deployment2.yaml": deployment2,
+				"route-route2.yaml":           route2,
+				"service-service2.yaml":       service2,
+			},
+		},
+		{
+			name: "Multiple statefulsets, service and route provided",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					StatefulSets: []appsv1.StatefulSet{
+						statefulSet1,
+						statefulSet2,
+					},
+					Services: []corev1.Service{
+						service1,
+						service2,
+					},
+					Routes: []routev1.Route{
+						route1,
+						route2,
+					},
+				},
+				TargetPort:               1234,
+				SingleOtherResourcesFile: true,
+			},
+			isSerializeRequired: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{otherFileName, serviceFileName, statefulsetFileName},
+				},
+				statefulsetFileName: statefulSet1,
+				serviceFileName:     service1,
+				otherFileName:       others3,
+			},
+		},
+		{
+			name: "Multiple daemonsets, service and route provided",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					DaemonSets: []appsv1.DaemonSet{
+						daemonSet1,
+						daemonSet2,
+					},
+					Services: []corev1.Service{
+						service1,
+						service2,
+					},
+					Routes: []routev1.Route{
+						route1,
+						route2,
+					},
+				},
+				TargetPort:               1234,
+				SingleOtherResourcesFile: true,
+			},
+			isSerializeRequired: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{daemonsetFileName, otherFileName, serviceFileName},
+				},
+				daemonsetFileName: daemonSet1,
+				serviceFileName:   service1,
+				otherFileName:     others4,
+			},
+		},
+		{
+			name: "Multiple deployments, ingresses and other multiple resources object provided only",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Deployments: []appsv1.Deployment{
+						deployment1,
+						deployment2,
+					},
+					Ingresses: []networkingv1.Ingress{
+						ingress1,
+						ingress2,
+					},
+					Others: []interface{}{
+						pod1,
+					},
+				},
+			},
+			isSerializeRequired: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{"deployment-deployment2.yaml", deploymentFileName, "ingress-ingress2.yaml", otherFileName},
+				},
+				deploymentFileName:            deployment1,
+				"deployment-deployment2.yaml": deployment2,
+				"ingress-ingress2.yaml":       ingress2,
+				otherFileName: []interface{}{
+					pod1,
+				},
+			},
 		},
-	}
-	deployment2 := appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "deployment2",
+		{
+			name: "Deployment with a command provided, the command option should be ignored",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				Command:     []string{"./from-options"},
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Deployments: []appsv1.Deployment{
+						deploymentWithCommand,
+					},
+				},
+			},
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{deploymentFileName},
+				},
+				deploymentFileName: deploymentWithCommand,
+			},
 		},
-	}
-
-	statefulSet1 := appsv1.StatefulSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "statefulset1",
+		{
+			name: "Service account creation enabled, should generate a service account",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                 componentName,
+				Namespace:            namespace,
+				Application:          applicationName,
+				CreateServiceAccount: true,
+			},
+			isDeploymentGenerated: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{deploymentFileName, serviceAccountFileName},
+				},
+				serviceAccountFileName: generateServiceAccount(gitopsv1alpha1.GeneratorOptions{Name: componentName, Namespace: namespace, Application: applicationName, CreateServiceAccount: true}),
+			},
 		},
-	}
-
-	statefulSet2 := appsv1.StatefulSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "statefulset2",
+		{
+			name: "Storage provided, should generate a pvc per entry",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				Storage:     storage,
+			},
+			isDeploymentGenerated: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{deploymentFileName, "pvc-cache.yaml", "pvc-data.yaml"},
+				},
+				"pvc-data.yaml":  generatePersistentVolumeClaim(gitopsv1alpha1.GeneratorOptions{Name: componentName, Namespace: namespace, Application: applicationName}, storage[0]),
+				"pvc-cache.yaml": generatePersistentVolumeClaim(gitopsv1alpha1.GeneratorOptions{Name: componentName, Namespace: namespace, Application: applicationName}, storage[1]),
+			},
 		},
-	}
-
-	daemonSet1 := appsv1.DaemonSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "daemonset1",
+		{
+			name: "StatefulSet workload type, should generate a statefulset with volume claim templates instead of pvcs",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				WorkloadType: gitopsv1alpha1.WorkloadTypeStatefulSet,
+				TargetPort:   8080,
+				Storage:      storage,
+			},
+			isServicetGenerated: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{serviceFileName, statefulsetFileName},
+				},
+				statefulsetFileName: generateStatefulSet(gitopsv1alpha1.GeneratorOptions{Name: componentName, Namespace: namespace, Application: applicationName, TargetPort: 8080, Storage: storage}),
+			},
+		},
+		{
+			name: "CronJob workload type, should not generate a service even if a target port is set",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				WorkloadType: gitopsv1alpha1.WorkloadTypeCronJob,
+				Schedule:     "0 0 * * *",
+				TargetPort:   8080,
+			},
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{cronjobFileName},
+				},
+				cronjobFileName: generateCronJob(gitopsv1alpha1.GeneratorOptions{Name: componentName, Namespace: namespace, Application: applicationName, Schedule: "0 0 * * *", TargetPort: 8080}),
+			},
+		},
+		{
+			name: "Error case with a CronJob workload type without a schedule",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				WorkloadType: gitopsv1alpha1.WorkloadTypeCronJob,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with a CronJob workload type and a route requested",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				WorkloadType: gitopsv1alpha1.WorkloadTypeCronJob,
+				Schedule:     "0 0 * * *",
+				Route:        "test-route.example.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Pod disruption budget provided, should generate a pdb",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				PodDisruptionBudget: &gitopsv1alpha1.PDBOptions{
+					MinAvailable: &pdbMinAvailable,
+				},
+			},
+			isDeploymentGenerated: true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{deploymentFileName, pdbFileName},
+				},
+				pdbFileName: &policyv1.PodDisruptionBudget{
+					TypeMeta: v1.TypeMeta{
+						APIVersion: "policy/v1",
+						Kind:       "PodDisruptionBudget",
+					},
+					ObjectMeta: v1.ObjectMeta{
+						Name:      componentName,
+						Namespace: namespace,
+						Labels: map[string]string{
+							"app.kubernetes.io/name":       componentName,
+							"app.kubernetes.io/instance":   componentName,
+							"app.kubernetes.io/part-of":    applicationName,
+							"app.kubernetes.io/managed-by": "kustomize",
+							"app.kubernetes.io/created-by": "application-service",
+						},
+					},
+					Spec: policyv1.PodDisruptionBudgetSpec{
+						MinAvailable: &pdbMinAvailable,
+						Selector: &v1.LabelSelector{
+							MatchLabels: map[string]string{
+								"app.kubernetes.io/instance": componentName,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Error case with both minAvailable and maxUnavailable set on the pod disruption budget",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				PodDisruptionBudget: &gitopsv1alpha1.PDBOptions{
+					MinAvailable:   &pdbMinAvailable,
+					MaxUnavailable: &pdbMinAvailable,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with autoscaling min replicas above the max replicas",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				Autoscaling: &gitopsv1alpha1.HPAOptions{
+					MinReplicas: &hpaMinReplicas,
+					MaxReplicas: 2,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with an invalid container name",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:          componentName,
+				Namespace:     namespace,
+				Application:   applicationName,
+				ContainerName: "Test_Container",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with a sidecar named after the container",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:          componentName,
+				Namespace:     namespace,
+				Application:   applicationName,
+				ContainerName: "app",
+				Sidecars: []corev1.Container{
+					{
+						Name:  "app",
+						Image: "envoyproxy/envoy:v1.25.0",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "KnativeService workload type, should generate a Knative service and no service",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				WorkloadType: gitopsv1alpha1.WorkloadTypeKnativeService,
+				TargetPort:   8080,
+			},
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{knativeServiceFileName},
+				},
+				knativeServiceFileName: generateKnativeService(gitopsv1alpha1.GeneratorOptions{Name: componentName, Namespace: namespace, Application: applicationName, TargetPort: 8080}),
+			},
+		},
+		{
+			name: "KnativeService workload type with a Knative service provided, should not generate one",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				WorkloadType: gitopsv1alpha1.WorkloadTypeKnativeService,
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Others: []interface{}{
+						knativeService,
+					},
+				},
+			},
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{"service-" + componentName + ".yaml"},
+				},
+				"service-" + componentName + ".yaml": knativeService,
+			},
+		},
+		{
+			name: "Error case with an incomplete secret env mapping",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				SecretEnv: []gitopsv1alpha1.SecretKeyMapping{
+					{
+						SecretName: "app-secret",
+						EnvName:    "DB_PASSWORD",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with a node port outside of the node port range",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  8080,
+				ServiceType: corev1.ServiceTypeNodePort,
+				NodePort:    8080,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with a node port on a ClusterIP service",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  8080,
+				NodePort:    30080,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with an unsupported service type",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  8080,
+				ServiceType: corev1.ServiceTypeExternalName,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with a headless service and a route",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  8080,
+				Headless:    true,
+				Route:       "test-route.example.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with a headless LoadBalancer service",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  8080,
+				Headless:    true,
+				ServiceType: corev1.ServiceTypeLoadBalancer,
+			},
+			wantErr: true,
 		},
-	}
-
-	daemonSet2 := appsv1.DaemonSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "daemonset2",
+		{
+			name: "Session affinity and external traffic policy provided, should be set on the service",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                  componentName,
+				Namespace:             namespace,
+				Application:           applicationName,
+				TargetPort:            8080,
+				ServiceType:           corev1.ServiceTypeNodePort,
+				SessionAffinity:       corev1.ServiceAffinityClientIP,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+			},
+			isDeploymentGenerated: true,
+			isServicetGenerated:   true,
+			wantFiles: map[string]interface{}{
+				kustomizeFileName: resources.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{deploymentFileName, serviceFileName},
+				},
+			},
 		},
-	}
-
-	service1 := corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "service1",
+		{
+			name: "Error case with an external traffic policy on a ClusterIP service",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                  componentName,
+				Namespace:             namespace,
+				Application:           applicationName,
+				TargetPort:            8080,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+			},
+			wantErr: true,
 		},
-	}
-	service2 := corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "service2",
+		{
+			name: "Error case with ingress TLS in auto mode without a route host",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  8080,
+				IngressTLS: &gitopsv1alpha1.IngressTLSOptions{
+					Auto: true,
+				},
+			},
+			wantErr: true,
 		},
-	}
-
-	route1 := routev1.Route{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "route1",
+		{
+			name: "Error case with an ingress path not starting with a slash",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				TargetPort:  5000,
+				Route:       componentName + ".example.com",
+				IngressPath: "api",
+			},
+			wantErr: true,
 		},
-	}
-	route2 := routev1.Route{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "route2",
+		{
+			name: "Error case with the ImplementationSpecific ingress path type not explicitly allowed",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:            componentName,
+				Namespace:       namespace,
+				Application:     applicationName,
+				TargetPort:      5000,
+				Route:           componentName + ".example.com",
+				IngressPathType: networkingv1.PathTypeImplementationSpecific,
+			},
+			wantErr: true,
 		},
-	}
-
-	ingress1 := networkingv1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "ingress1",
+		{
+			name: "Error case with an unsupported route TLS termination",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				RouteTLS: &gitopsv1alpha1.RouteTLSOptions{
+					Termination: "mtls",
+				},
+			},
+			wantErr: true,
 		},
-	}
-	ingress2 := networkingv1.Ingress{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "ingress2",
+		{
+			name: "Error case with a destination CA certificate on an edge terminated route",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				RouteTLS: &gitopsv1alpha1.RouteTLSOptions{
+					DestinationCACertificate: "-----BEGIN CERTIFICATE-----",
+				},
+			},
+			wantErr: true,
 		},
-	}
-
-	pod1 := corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "pod1",
+		{
+			name: "Error case with an unsupported expose mode",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				ExposeMode:  "gateway",
+			},
+			wantErr: true,
 		},
-	}
-
-	others1 := []interface{}{
-		deployment2,
-		service2,
-		route2,
-	}
-
-	others2 := []interface{}{
-		pod1,
-		deployment2,
-		ingress2,
-	}
-
-	others3 := []interface{}{
-		statefulSet2,
-		service2,
-		route2,
-	}
-
-	others4 := []interface{}{
-		daemonSet2,
-		service2,
-		route2,
-	}
-
-	fs := ioutils.NewFilesystem()
-
-	tests := []struct {
-		name                  string
-		fs                    afero.Afero
-		component             gitopsv1alpha1.GeneratorOptions
-		outputFolder          string
-		isDeploymentGenerated bool
-		isServicetGenerated   bool
-		isRouteGenerated      bool
-		isSerializeRequired   bool // set to true if you are going to test KubernetesResources.Others
-		wantFiles             map[string]interface{}
-		wantErr               bool
-	}{
 		{
-			name: "Single deployment object provided only",
+			name: "Error case with a route backend weight out of range",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Deployments: []appsv1.Deployment{
-						deployment1,
+				RouteBackends: []gitopsv1alpha1.RouteBackend{
+					{
+						ServiceName: componentName,
+						Weight:      257,
 					},
 				},
 			},
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{deploymentFileName},
+			wantErr: true,
+		},
+		{
+			name: "Error case with route backend weights adding up to more than 200",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				RouteBackends: []gitopsv1alpha1.RouteBackend{
+					{
+						ServiceName: componentName + "-blue",
+						Weight:      150,
+					},
+					{
+						ServiceName: componentName + "-green",
+						Weight:      100,
+					},
 				},
-				deploymentFileName: deployment1,
 			},
+			wantErr: true,
 		},
 		{
-			name: "Single statefulset object provided only",
+			name: "Error case with an invalid target port name",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					StatefulSets: []appsv1.StatefulSet{
-						statefulSet1,
+				Ports: []gitopsv1alpha1.ComponentPort{
+					{
+						ContainerPort:  8080,
+						TargetPortName: "not_a_valid_port_name",
 					},
 				},
 			},
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{statefulsetFileName},
+			wantErr: true,
+		},
+		{
+			name: "Error case with a TLS termination set on an insecure route",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:               componentName,
+				Namespace:          namespace,
+				Application:        applicationName,
+				RouteAllowInsecure: true,
+				RouteTLS: &gitopsv1alpha1.RouteTLSOptions{
+					Termination: routev1.TLSTerminationEdge,
 				},
-				statefulsetFileName: statefulSet1,
 			},
+			wantErr: true,
 		},
 		{
-			name: "Single daemonset object provided only",
+			name: "Error case with a ConfigMap key set as both a literal and a file",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					DaemonSets: []appsv1.DaemonSet{
-						daemonSet1,
+				ConfigMaps: []gitopsv1alpha1.ConfigMapOptions{
+					{
+						Name: "app-config",
+						Literals: map[string]string{
+							"app.properties": "a=b",
+						},
+						Files: map[string]string{
+							"app.properties": "a=b",
+						},
 					},
 				},
 			},
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{daemonsetFileName},
+			wantErr: true,
+		},
+		{
+			name: "Error case with an unsupported workload type",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:         componentName,
+				Namespace:    namespace,
+				Application:  applicationName,
+				WorkloadType: "DaemonSet",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with an invalid storage size",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				Storage: []gitopsv1alpha1.ComponentStorage{
+					{
+						Name:      "data",
+						Size:      "lots",
+						MountPath: "/data",
+					},
 				},
-				daemonsetFileName: daemonSet1,
 			},
+			wantErr: true,
 		},
 		{
-			name: "Single svc object provided only",
+			name: "Error case with an unsupported probe type",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Services: []corev1.Service{
-						service1,
+				TargetPort:  8080,
+				ReadinessProbe: &gitopsv1alpha1.ProbeOptions{
+					Type: "grpc",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with an exec probe without a command",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				LivenessProbe: &gitopsv1alpha1.ProbeOptions{
+					Type: gitopsv1alpha1.ProbeTypeExec,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with an unsupported image pull policy",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:            componentName,
+				Namespace:       namespace,
+				Application:     applicationName,
+				ImagePullPolicy: "Sometimes",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Error case with rolling update parameters on a recreate strategy",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				DeploymentStrategy: &appsv1.DeploymentStrategy{
+					Type: appsv1.RecreateDeploymentStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDeployment{
+						MaxSurge: &maxSurge,
 					},
 				},
 			},
-			isDeploymentGenerated: true,
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{deploymentFileName, serviceFileName},
+			wantErr: true,
+		},
+		{
+			name: "Error case with an unsupported deployment strategy",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				DeploymentStrategy: &appsv1.DeploymentStrategy{
+					Type: "BlueGreen",
 				},
-				serviceFileName: service1,
 			},
+			wantErr: true,
 		},
 		{
-			name: "Single route object provided only",
+			name: "Error case with more than one exposed port",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Routes: []routev1.Route{
-						route1,
+				Ports: []gitopsv1alpha1.ComponentPort{
+					{
+						Name:          "http",
+						ContainerPort: 8080,
+						Expose:        true,
+					},
+					{
+						Name:          "https",
+						ContainerPort: 8443,
+						Expose:        true,
 					},
 				},
 			},
-			isDeploymentGenerated: true,
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{deploymentFileName},
-				},
-			},
+			wantErr: true,
 		},
 		{
-			name: "Single deployment object provided only, with Target Port should generate svc",
+			name: "Error case with unnamed ports",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Deployments: []appsv1.Deployment{
-						deployment1,
+				Ports: []gitopsv1alpha1.ComponentPort{
+					{
+						ContainerPort: 8080,
+					},
+					{
+						ContainerPort: 9090,
 					},
 				},
-				TargetPort: 1234,
-			},
-			isServicetGenerated: true,
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{deploymentFileName, serviceFileName},
-				},
-				deploymentFileName: deployment1,
 			},
+			wantErr: true,
 		},
 		{
-			name: "Multiple deployment, service and route provided",
+			name: "Error case with a sidecar named after the component's container",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Deployments: []appsv1.Deployment{
-						deployment1,
-						deployment2,
-					},
-					Services: []corev1.Service{
-						service1,
-						service2,
-					},
-					Routes: []routev1.Route{
-						route1,
-						route2,
+				Sidecars: []corev1.Container{
+					{
+						Name:  "container-image",
+						Image: "envoyproxy/envoy:v1.25.0",
 					},
 				},
-				TargetPort: 1234,
-			},
-			isSerializeRequired: true,
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{deploymentFileName, otherFileName, serviceFileName},
-				},
-				deploymentFileName: deployment1,
-				serviceFileName:    service1,
-				otherFileName:      others1,
 			},
+			wantErr: true,
 		},
 		{
-			name: "Multiple statefulsets, service and route provided",
+			name: "Error case with a targeted patch without a patch or a path",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					StatefulSets: []appsv1.StatefulSet{
-						statefulSet1,
-						statefulSet2,
-					},
-					Services: []corev1.Service{
-						service1,
-						service2,
-					},
-					Routes: []routev1.Route{
-						route1,
-						route2,
+				OverlayTargetedPatches: []gitopsv1alpha1.TargetedPatch{
+					{
+						Target: gitopsv1alpha1.PatchTarget{
+							Kind: "Deployment",
+							Name: componentName,
+						},
 					},
 				},
-				TargetPort: 1234,
-			},
-			isSerializeRequired: true,
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{otherFileName, serviceFileName, statefulsetFileName},
-				},
-				statefulsetFileName: statefulSet1,
-				serviceFileName:     service1,
-				otherFileName:       others3,
 			},
+			wantErr: true,
 		},
 		{
-			name: "Multiple daemonsets, service and route provided",
+			name: "Error case with a replacement without targets",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
 				Namespace:   namespace,
 				Application: applicationName,
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					DaemonSets: []appsv1.DaemonSet{
-						daemonSet1,
-						daemonSet2,
-					},
-					Services: []corev1.Service{
-						service1,
-						service2,
-					},
-					Routes: []routev1.Route{
-						route1,
-						route2,
+				OverlayReplacements: []gitopsv1alpha1.Replacement{
+					{
+						Source: &gitopsv1alpha1.ReplacementSource{
+							Kind: "Deployment",
+							Name: componentName,
+						},
 					},
 				},
-				TargetPort: 1234,
 			},
-			isSerializeRequired: true,
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{daemonsetFileName, otherFileName, serviceFileName},
-				},
-				daemonsetFileName: daemonSet1,
-				serviceFileName:   service1,
-				otherFileName:     others4,
+			wantErr: true,
+		},
+		{
+			name: "Error case with an absolute base overlay",
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:        componentName,
+				Namespace:   namespace,
+				Application: applicationName,
+				BaseOverlay: "/overlays/common",
 			},
+			wantErr: true,
 		},
 		{
-			name: "Multiple deployments, ingresses and other multiple resources object provided only",
+			name: "Error case with the same passed in resource twice",
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name:        componentName,
@@ -1939,26 +8824,11 @@ func TestGenerate(t *testing.T) {
 					Deployments: []appsv1.Deployment{
 						deployment1,
 						deployment2,
+						deployment2,
 					},
-					Ingresses: []networkingv1.Ingress{
-						ingress1,
-						ingress2,
-					},
-					Others: []interface{}{
-						pod1,
-					},
-				},
-			},
-			isSerializeRequired: true,
-			wantFiles: map[string]interface{}{
-				kustomizeFileName: resources.Kustomization{
-					APIVersion: "kustomize.config.k8s.io/v1beta1",
-					Kind:       "Kustomization",
-					Resources:  []string{deploymentFileName, otherFileName},
 				},
-				deploymentFileName: deployment1,
-				otherFileName:      others2,
 			},
+			wantErr: true,
 		},
 		{
 			name:         "Error case with an invalid output path",
@@ -2039,6 +8909,259 @@ func TestGenerate(t *testing.T) {
 	}
 }
 
+func TestGenerateInitContainers(t *testing.T) {
+	initContainers := []corev1.Container{
+		{
+			Name:    "migrate",
+			Image:   "quay.io/test/migrate:latest",
+			Command: []string{"./migrate.sh"},
+			Args:    []string{"--to", "latest"},
+			Env: []corev1.EnvVar{
+				{
+					Name:  "DB_HOST",
+					Value: "postgres",
+				},
+			},
+		},
+		{
+			Name:  "wait-for-cache",
+			Image: "busybox",
+		},
+	}
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:           "test-component",
+		Namespace:      "test-namespace",
+		Application:    "test-application",
+		ContainerImage: "quay.io/test/test:latest",
+		InitContainers: initContainers,
+	}
+
+	path, cleanup := makeTempDir(t)
+	defer cleanup()
+	outputFolder := filepath.Join(path, "manifest", "gitops")
+
+	err := Generate(ioutils.NewFilesystem(), "", outputFolder, options)
+	assertNoError(t, err)
+
+	deploymentBytes, err := ioutil.ReadFile(filepath.Join(outputFolder, deploymentFileName))
+	assertNoError(t, err)
+	deployment := appsv1.Deployment{}
+	err = yaml.Unmarshal(deploymentBytes, &deployment)
+	assertNoError(t, err)
+
+	assert.Equal(t, initContainers, deployment.Spec.Template.Spec.InitContainers)
+	assert.Equal(t, 1, len(deployment.Spec.Template.Spec.Containers))
+	assert.Equal(t, "container-image", deployment.Spec.Template.Spec.Containers[0].Name)
+}
+
+func TestGenerateStartupProbe(t *testing.T) {
+	tests := []struct {
+		name             string
+		startupProbe     *gitopsv1alpha1.ProbeOptions
+		wantStartupProbe bool
+	}{
+		{
+			name:             "No startup probe configured",
+			wantStartupProbe: false,
+		},
+		{
+			name: "Startup probe configured",
+			startupProbe: &gitopsv1alpha1.ProbeOptions{
+				Type:             gitopsv1alpha1.ProbeTypeHTTP,
+				Path:             "/q/health/started",
+				PeriodSeconds:    5,
+				FailureThreshold: 60,
+			},
+			wantStartupProbe: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, cleanup := makeTempDir(t)
+			defer cleanup()
+			outputFolder := filepath.Join(path, "manifest", "gitops")
+
+			options := gitopsv1alpha1.GeneratorOptions{
+				Name:         "test-component",
+				Namespace:    "test-namespace",
+				Application:  "test-application",
+				TargetPort:   8080,
+				StartupProbe: tt.startupProbe,
+			}
+			err := Generate(ioutils.NewFilesystem(), "", outputFolder, options)
+			assertNoError(t, err)
+
+			deploymentBytes, err := ioutil.ReadFile(filepath.Join(outputFolder, deploymentFileName))
+			assertNoError(t, err)
+			assert.Equal(t, tt.wantStartupProbe, strings.Contains(string(deploymentBytes), "startupProbe:"))
+
+			if tt.wantStartupProbe {
+				deployment := appsv1.Deployment{}
+				err = yaml.Unmarshal(deploymentBytes, &deployment)
+				assertNoError(t, err)
+				startupProbe := deployment.Spec.Template.Spec.Containers[0].StartupProbe
+				assert.Equal(t, "/q/health/started", startupProbe.HTTPGet.Path)
+				assert.Equal(t, int32(5), startupProbe.PeriodSeconds)
+				assert.Equal(t, int32(60), startupProbe.FailureThreshold)
+			}
+		})
+	}
+}
+
+func TestGenerateDefaultResources(t *testing.T) {
+	tests := []struct {
+		name          string
+		component     gitopsv1alpha1.GeneratorOptions
+		wantResources corev1.ResourceRequirements
+	}{
+		{
+			name: "No resources and defaults not requested, should leave the resources empty",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+			},
+			wantResources: corev1.ResourceRequirements{},
+		},
+		{
+			name: "No resources, should apply the default requests",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                  "test-component",
+				ApplyDefaultResources: true,
+			},
+			wantResources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10m"),
+					corev1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+			},
+		},
+		{
+			name: "Partial resources, should only apply the missing default requests",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                  "test-component",
+				ApplyDefaultResources: true,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("250m"),
+					},
+				},
+			},
+			wantResources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+			},
+		},
+		{
+			name: "Partial resources with a limit, should not apply a default request for the limited resource",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                  "test-component",
+				ApplyDefaultResources: true,
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("32Mi"),
+					},
+				},
+			},
+			wantResources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("32Mi"),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("10m"),
+				},
+			},
+		},
+		{
+			name: "Complete resources, should be left untouched",
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                  "test-component",
+				ApplyDefaultResources: true,
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1"),
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+				},
+			},
+			wantResources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("512Mi"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedDeployment := generateDeployment(tt.component)
+
+			resources := generatedDeployment.Spec.Template.Spec.Containers[0].Resources
+			if !reflect.DeepEqual(resources, tt.wantResources) {
+				t.Errorf("TestGenerateDefaultResources() error: expected %v got %v", tt.wantResources, resources)
+			}
+		})
+	}
+}
+
+func TestGenerateLifecycle(t *testing.T) {
+	terminationGracePeriodSeconds := int64(60)
+	lifecycle := corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/sh", "-c", "sleep 10"},
+			},
+		},
+	}
+
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:                          "test-component",
+		Namespace:                     "test-namespace",
+		Application:                   "test-application",
+		Lifecycle:                     &lifecycle,
+		TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+	}
+
+	path, cleanup := makeTempDir(t)
+	defer cleanup()
+	outputFolder := filepath.Join(path, "manifest", "gitops")
+
+	err := Generate(ioutils.NewFilesystem(), "", outputFolder, options)
+	assertNoError(t, err)
+
+	deploymentBytes, err := ioutil.ReadFile(filepath.Join(outputFolder, deploymentFileName))
+	assertNoError(t, err)
+	deployment := appsv1.Deployment{}
+	err = yaml.Unmarshal(deploymentBytes, &deployment)
+	assertNoError(t, err)
+
+	assert.Equal(t, &lifecycle, deployment.Spec.Template.Spec.Containers[0].Lifecycle)
+	assert.Equal(t, &terminationGracePeriodSeconds, deployment.Spec.Template.Spec.TerminationGracePeriodSeconds)
+
+	// the overlay patch stays minimal unless the environment overrides the fields
+	deploymentPatch := generateDeploymentPatch(options, "image", "container-image", "test-namespace")
+	assert.Nil(t, deploymentPatch.Spec.Template.Spec.Containers[0].Lifecycle)
+	assert.Nil(t, deploymentPatch.Spec.Template.Spec.TerminationGracePeriodSeconds)
+
+	overlayTerminationGracePeriodSeconds := int64(120)
+	options.OverlayLifecycle = &lifecycle
+	options.OverlayTerminationGracePeriodSeconds = &overlayTerminationGracePeriodSeconds
+	deploymentPatch = generateDeploymentPatch(options, "image", "container-image", "test-namespace")
+	assert.Equal(t, &lifecycle, deploymentPatch.Spec.Template.Spec.Containers[0].Lifecycle)
+	assert.Equal(t, &overlayTerminationGracePeriodSeconds, deploymentPatch.Spec.Template.Spec.TerminationGracePeriodSeconds)
+}
+
 func makeTempDir(t *testing.T) (string, func()) {
 	t.Helper()
 	dir, err := ioutil.TempDir(os.TempDir(), "manifest")
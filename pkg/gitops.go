@@ -16,15 +16,27 @@
 package gitops
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/redhat-developer/gitops-generator/pkg/resources"
 	"github.com/redhat-developer/gitops-generator/pkg/util"
+	"github.com/redhat-developer/gitops-generator/pkg/util/ioutils"
+	"github.com/redhat-developer/gitops-generator/pkg/yaml"
 	"go.uber.org/zap/zapcore"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -35,7 +47,15 @@ import (
 	"github.com/spf13/afero"
 )
 
-const defaultRepoDescription = "Bootstrapped GitOps Repository based on Components"
+const (
+	defaultRepoDescription = "Bootstrapped GitOps Repository based on Components"
+	defaultAuthorName      = "GitOps Generator"
+	defaultAuthorEmail     = "gitops-generator@redhat.com"
+	defaultRemoteName      = "origin"
+
+	defaultPullRequestBranchTemplate = "gitops-gen/{{.Component}}-{{.Timestamp}}"
+	defaultTagNameTemplate           = "gitops/{{.Component}}/{{.Timestamp}}"
+)
 
 type CommandType string
 
@@ -53,6 +73,10 @@ type Generator interface {
 	GitRemoveComponent(outputPath string, remote string, componentName string, branch string, context string) error
 	CloneRepo(outputPath string, remote string, componentName string, branch string) error
 	GetCommitIDFromRepo(fs afero.Afero, repoPath string) (string, error)
+	CloneGenerateAndPushWithContext(ctx context.Context, outputPath string, remote string, options gitopsv1alpha1.GeneratorOptions, appFs afero.Afero, branch string, contextPath string, doPush bool) error
+	CommitAndPushWithContext(ctx context.Context, outputPath string, repoPathOverride string, remote string, componentName string, branch string, commitMessage string) error
+	GenerateOverlaysAndPushWithContext(ctx context.Context, outputPath string, clone bool, remote string, options gitopsv1alpha1.GeneratorOptions, applicationName, environmentName, imageName, namespace string, appFs afero.Afero, branch string, contextPath string, doPush bool, componentGeneratedResources map[string][]string) error
+	GitRemoveComponentWithContext(ctx context.Context, outputPath string, remote string, componentName string, appFs afero.Afero, branch string, contextPath string) error
 }
 
 // NewGitopsGen returns a Generator implementation
@@ -62,85 +86,464 @@ func NewGitopsGen() Gen {
 			Development: true,
 			TimeEncoder: zapcore.ISO8601TimeEncoder,
 		})),
+		execute: executeCommand,
+		locks:   newRepoLocks(),
 	}
 }
 
 func NewGitopsGenWithLogger(log logr.Logger) Gen {
 	return Gen{
-		Log: log,
+		Log:     log,
+		execute: executeCommand,
+		locks:   newRepoLocks(),
 	}
 }
 
 type Gen struct {
 	Log logr.Logger
+
+	// CloneDepth makes the clones of the GitOps repository shallow, with the given number of commits and without tags.
+	// Default is 0, a full clone
+	CloneDepth int
+
+	// SparseCheckout only checks out the folder of the component and the parent kustomization of the GitOps
+	// repository after cloning it, rather than the whole tree. Default is false
+	SparseCheckout bool
+
+	// CommandTimeout limits how long each git command may run for, e.g. so that a push to an unreachable remote
+	// doesn't block forever. Default is 0, no timeout
+	CommandTimeout time.Duration
+
+	// GitBinaryPath is the path of the git binary. Default is the git of the PATH
+	GitBinaryPath string
+
+	// Env are environment variables of the commands, e.g. GIT_SSL_CAINFO or HTTPS_PROXY, set over the environment of
+	// the process
+	Env map[string]string
+
+	// SSHKeyPath is the path of the private key authenticating to ssh remotes, e.g. git@github.com:org/repo.git.
+	// Default is the keys of the ssh configuration of the environment
+	SSHKeyPath string
+
+	// KnownHostsPath is the path of the known hosts file the host keys of ssh remotes are strictly checked against.
+	// Default is the known hosts of the ssh configuration of the environment
+	KnownHostsPath string
+
+	// AuthMode is how the token of https remotes is passed to git. Default is to embed it in the remote URL
+	AuthMode AuthMode
+
+	// CloneStrategy is whether the repositories are always cloned, or existing checkouts of the remote are reused.
+	// Default is to always clone
+	CloneStrategy CloneStrategy
+
+	// TagResult creates an annotated tag of the pushed commit and pushes it, e.g. so that environments can be pinned and
+	// rolled back by tag. Default is to not tag the commits
+	TagResult *TagOptions
+
+	// CleanBeforeGenerate discards the uncommitted changes and untracked files of the component before its resources are
+	// generated, e.g. left by a previous generation that failed before committing in a reused checkout. Only the folder
+	// of the component is cleaned. Default is to keep them
+	CleanBeforeGenerate bool
+
+	// StrictBranch fails with ErrBranchNotFound if the branch doesn't exist on the remote, e.g. because of a typo in its
+	// name, instead of creating it. Default is to create the branch
+	StrictBranch bool
+
+	// BranchFallbacks are the branches tried in order when the requested branch, one of them, doesn't exist, before
+	// creating it, e.g. ["main", "master"] for the repositories that only have one of them. The branch checked out is
+	// returned in the PushResult. Default is to create the requested branch
+	BranchFallbacks []string
+
+	// BaseBranch is the branch of the remote the branch is created from when it doesn't exist yet. Default is the branch
+	// checked out by the clone
+	BaseBranch string
+
+	// ForceClone deletes the destination of the clone if it exists and isn't a checkout of the remote. Default is to
+	// fail with ErrDestinationExists
+	ForceClone bool
+
+	// PushRetries is how many times a push rejected because the remote branch moved on, e.g. when components of the
+	// same application are generated concurrently, is retried after rebasing onto the remote branch. Default is 0,
+	// no retries
+	PushRetries int
+
+	// PushRetryBackoff is how long to wait before the first push retry, doubled for each subsequent retry
+	PushRetryBackoff time.Duration
+
+	// PushMode is how the branch is pushed. Default is a normal push, force-with-lease should be preferred to a force
+	// push to overwrite branches such as the preview environment ones
+	PushMode PushMode
+
+	// RemoteName is the name the remote is cloned, fetched and pushed as. Default is "origin"
+	RemoteName string
+
+	// AdditionalRemotes are pushed to once the push to the remote succeeds, e.g. to mirror the GitOps repository
+	AdditionalRemotes []Remote
+
+	// Author is the author and committer of the commits. Default is GitOps Generator <gitops-generator@redhat.com>, so
+	// that committing doesn't depend on the git config of the environment
+	Author Author
+
+	// SignCommits signs the commits with the signing key, e.g. for branches requiring signed commits. Default is nil,
+	// unsigned commits
+	SignCommits *SigningKey
+
+	// CommitMessageTemplate is the text/template of the commit messages, executed with the CommitMessageData of the
+	// operation, e.g. to reference a ticket. Default is a message describing the operation
+	CommitMessageTemplate string
+
+	// CommitTrailers are appended to the commit messages after a blank line, e.g. "Co-authored-by: Name <email>"
+	CommitTrailers []string
+
+	// DryRun generates the resources and stages them to return their diff, without committing nor pushing. The
+	// repositories cloned for a dry run are deleted once the diff is returned, while the existing checkouts that are
+	// reused are kept
+	DryRun bool
+
+	// PullRequest pushes the commits to a new branch and opens a pull request against the branch, instead of pushing
+	// to the branch. The additional remotes aren't pushed to. Default is to push to the branch
+	PullRequest *PullRequestOptions
+
+	// DisableRepoLocking doesn't serialize the concurrent calls of the generator against the same remote and branch,
+	// for the callers managing their own locking. Default is to serialize them, for the generators returned by
+	// NewGitopsGen and NewGitopsGenWithLogger and their copies
+	DisableRepoLocking bool
+
+	// execute executes the commands, replaced by the tests to mock them
+	execute executeFunc
+
+	// locks serializes the concurrent calls against the same remote and branch, shared by the copies of the generator
+	locks *repoLocks
+}
+
+// PushResult is the outcome of generating and pushing the GitOps resources
+type PushResult struct {
+	// Diff is the staged diff of a dry run, with the tokens removed
+	Diff string
+
+	// CommitSHA is the id of the pushed commit, empty if there was nothing to commit
+	CommitSHA string
+
+	// PullRequestNumber and PullRequestURL identify the pull request opened for the commit, if any
+	PullRequestNumber int
+	PullRequestURL    string
+
+	// Branch is the branch the changes are committed for, one of the BranchFallbacks if the requested branch doesn't
+	// exist
+	Branch string
+}
+
+// CommitInfo holds the metadata of a commit
+type CommitInfo struct {
+	SHA       string
+	ShortSHA  string
+	Author    string
+	Email     string
+	Timestamp time.Time
+	Subject   string
+}
+
+// commitInfoFormat is the git log format of the CommitInfo fields, separated by NUL characters, which commits can't
+// contain. The subject is last, so that it's parsed whole regardless of its content
+const commitInfoFormat = "--format=%H%x00%h%x00%an%x00%ae%x00%cI%x00%s"
+
+// PullRequestOptions configures the pull requests opened for the commits
+type PullRequestOptions struct {
+	// BranchTemplate is the template of the branch the pull request is opened from, executed with the PullRequestData.
+	// Default is "gitops-gen/{{.Component}}-{{.Timestamp}}"
+	BranchTemplate string
+
+	// TitleTemplate is the template of the title of the pull request, executed with the PullRequestData. Default is
+	// the first line of the commit message
+	TitleTemplate string
+
+	// BodyTemplate is the template of the body of the pull request, executed with the PullRequestData. Default is the
+	// commit message without its first line
+	BodyTemplate string
+}
+
+// TagOptions configures the tag of the pushed commit
+type TagOptions struct {
+	// NameTemplate is the template of the name of the tag, executed with the TagData. Default is
+	// "gitops/{{.Component}}/{{.Timestamp}}"
+	NameTemplate string
+
+	// MessageTemplate is the template of the message of the tag, executed with the TagData. Default is the commit
+	// message
+	MessageTemplate string
+}
+
+// TagData holds the fields available to the tag templates
+type TagData struct {
+	CommitMessageData
+
+	// Branch is the branch the tagged commit is pushed to
+	Branch string
+
+	// Timestamp is when the resources are generated, in UTC and of the form 20060102150405
+	Timestamp string
+}
+
+// gitTag is the annotated tag of the pushed commit
+type gitTag struct {
+	name    string
+	message string
 }
 
-// expose as a global variable for the purpose of running mock tests
+// PullRequestData holds the fields available to the pull request templates
+type PullRequestData struct {
+	CommitMessageData
+
+	// Branch is the branch the pull request is opened against
+	Branch string
+
+	// Timestamp is when the resources are generated, in UTC and of the form 20060102150405
+	Timestamp string
+}
+
+// pullRequest is the pull request opened for the commit, from the head branch
+type pullRequest struct {
+	head  string
+	title string
+	body  string
+}
+
+// Remote is a named git remote
+type Remote struct {
+	Name string
+
+	// URL is a string of the form https://$token@<domain>/<org>/<repo>, where <domain> is either github.com or gitlab.com and $token is optional
+	URL string
+}
+
+// CloneStrategy is how the repository is checked out before generating the resources
+type CloneStrategy string
+
+const (
+	// CloneStrategyClone always clones the repository
+	CloneStrategyClone CloneStrategy = "clone"
+	// CloneStrategyReuse resets an existing checkout of the remote to the branch, e.g. when generating the resources of
+	// the components of an application one after the other, and only clones the repository if there's none
+	CloneStrategyReuse CloneStrategy = "reuse"
+)
+
+// AuthMode is how the token of the remote is passed to git
+type AuthMode string
+
+const (
+	// AuthModeURL embeds the token in the remote URL, which is stored in the configuration of the cloned repository
+	AuthModeURL AuthMode = "url"
+	// AuthModeHeader passes the token to each git command as an http.extraHeader, so that the remote stored in the
+	// cloned repository is free of credentials
+	AuthModeHeader AuthMode = "header"
+)
+
+// PushMode is how the branch is pushed to the remote
+type PushMode string
+
+const (
+	// PushModeNormal fails the push if the remote branch has commits the local branch doesn't
+	PushModeNormal PushMode = "normal"
+	// PushModeForceWithLease overwrites the remote branch, unless it moved on since it was fetched
+	PushModeForceWithLease PushMode = "force-with-lease"
+	// PushModeForce overwrites the remote branch
+	PushModeForce PushMode = "force"
+)
+
+// Operation is what the commit of the GitOps resources does
+type Operation string
+
+const (
+	// OperationGenerateBase generates the base resources of a component
+	OperationGenerateBase Operation = "GenerateBase"
+	// OperationGenerateOverlays generates the overlays of a component for an environment
+	OperationGenerateOverlays Operation = "GenerateOverlays"
+	// OperationRemoveComponent removes a component
+	OperationRemoveComponent Operation = "RemoveComponent"
+)
+
+// CommitMessageData holds the fields available to the commit message template
+type CommitMessageData struct {
+	Component   string
+	Application string
+	Environment string
+	Operation   Operation
+}
+
+// SigningKey references the key commits are signed with
+type SigningKey struct {
+	// Format is the format of the key, either gpg or ssh. Default is gpg
+	Format string
+
+	// Key is the id of the gpg key, or the path of the ssh key. Default is the key of the git config of the environment
+	Key string
+}
+
+// Author identifies the author of commits
+type Author struct {
+	Name  string
+	Email string
+}
+
+// commandOptions are how the commands are executed
+type commandOptions struct {
+	gitBinaryPath string
+	env           map[string]string
+}
+
+// executeFunc executes the command in the base directory and returns its combined output
+type executeFunc func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error)
+
+// executeCommand is the executeFunc of the generators, replaced by the tests to mock the commands
 // only "git" and "rm" are supported
-/* #nosec G204 -- used internally to execute various gitops actions and eventual cleanup of artifacts.  Calling methods validate user input to ensure commands are used appropriately */
-var execute = func(baseDir string, cmd CommandType, args ...string) ([]byte, error) {
-	if cmd == GitCommand || cmd == RmCommand {
-		c := exec.Command(string(cmd), args...)
-		c.Dir = baseDir
-		output, err := c.CombinedOutput()
-		return output, err
+func executeCommand(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+	c, err := newCommand(ctx, options, baseDir, cmd, args...)
+	if err != nil {
+		return []byte(""), err
 	}
+	return c.CombinedOutput()
+}
 
-	return []byte(""), fmt.Errorf(unsupportedCmdMsg, string(cmd))
+// newCommand returns the command executed in the base directory, with the git binary path and the environment of the
+// options
+/* #nosec G204 -- used internally to execute various gitops actions and eventual cleanup of artifacts.  Calling methods validate user input to ensure commands are used appropriately */
+func newCommand(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) (*exec.Cmd, error) {
+	if cmd != GitCommand && cmd != RmCommand {
+		return nil, fmt.Errorf(unsupportedCmdMsg, string(cmd))
+	}
+	name := string(cmd)
+	if cmd == GitCommand && options.gitBinaryPath != "" {
+		name = options.gitBinaryPath
+	}
+	c := exec.CommandContext(ctx, name, args...)
+	c.Dir = baseDir
+	if len(options.env) > 0 {
+		keys := make([]string, 0, len(options.env))
+		for key := range options.env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		c.Env = os.Environ()
+		for _, key := range keys {
+			c.Env = append(c.Env, key+"="+options.env[key])
+		}
+	}
+	return c, nil
 }
 
+// expose as a global variable for the purpose of running mock tests with a fake client
+var newSCMClient = factory.FromRepoURL
+
 // CloneGenerateAndPush takes in the following args and generates the gitops resources for a given component
 // 1. outputPath: Where to output the gitops resources to
 // 2. remote: A string of the form https://$token@<domain>/<org>/<repo>, where <domain> is either github.com or gitlab.com and $token is optional. Corresponds to the component's gitops repository
 // 3. options: Options for resource generation
 // 4. The filesystem object used to create (either ioutils.NewFilesystem() or ioutils.NewMemoryFilesystem())
-// 5. The branch to push to
+// 5. The branch to push to. Empty is the default branch of the remote
 // 6. The path within the repository to generate the resources in
 // 7. The gitops config containing the build bundle;
 // Adapted from https://github.com/redhat-developer/kam/blob/master/pkg/pipelines/utils.go#L79
-func (s Gen) CloneGenerateAndPush(outputPath string, remote string, options gitopsv1alpha1.GeneratorOptions, appFs afero.Afero, branch string, context string, doPush bool) error {
+func (s Gen) CloneGenerateAndPush(outputPath string, remote string, options gitopsv1alpha1.GeneratorOptions, appFs afero.Afero, branch string, contextPath string, doPush bool) error {
+	return s.CloneGenerateAndPushWithContext(context.Background(), outputPath, remote, options, appFs, branch, contextPath, doPush)
+}
+
+// CloneGenerateAndPushWithContext is CloneGenerateAndPush, with the git commands interrupted once the context is done
+func (s Gen) CloneGenerateAndPushWithContext(ctx context.Context, outputPath string, remote string, options gitopsv1alpha1.GeneratorOptions, appFs afero.Afero, branch string, contextPath string, doPush bool) error {
+	_, err := s.CloneGenerateAndPushWithResult(ctx, outputPath, remote, options, appFs, branch, contextPath, doPush)
+	return err
+}
+
+// CloneGenerateAndPushWithResult is CloneGenerateAndPushWithContext, returning the result of the push
+func (s Gen) CloneGenerateAndPushWithResult(ctx context.Context, outputPath string, remote string, options gitopsv1alpha1.GeneratorOptions, appFs afero.Afero, branch string, contextPath string, doPush bool) (result PushResult, err error) {
 	componentName := options.Name
 
 	invalidRemoteErr := util.ValidateRemote(remote)
 	if invalidRemoteErr != nil {
-		return invalidRemoteErr
+		return result, invalidRemoteErr
+	}
+	// The default branch is resolved before locking, so that the calls without a branch are serialized with the ones
+	// pushing to the default branch
+	if branch == "" {
+		if branch, err = s.defaultBranch(ctx, outputPath, remote); err != nil {
+			return result, err
+		}
+	}
+	unlock, err := s.lockRepo(ctx, remote, branch)
+	if err != nil {
+		return result, err
+	}
+	defer unlock()
+
+	data := CommitMessageData{Component: componentName, Application: options.Application, Operation: OperationGenerateBase}
+	commitMessage, err := s.commitMessage(data, fmt.Sprintf("Generate GitOps base resources for component %s", componentName))
+	if err != nil {
+		return result, err
+	}
+	pr, tag, err := s.pullRequestAndTag(data, commitMessage, branch)
+	if err != nil {
+		return result, err
 	}
 
 	s.Log.V(6).Info("Cloning GitOps repository")
-	if out, err := execute(outputPath, GitCommand, "clone", remote, componentName); err != nil {
-		return &GitCmdError{path: outputPath, cmdResult: string(out), err: err, cmdType: cloneRepo}
+	checkoutExists, _ := appFs.DirExists(filepath.Join(outputPath, componentName, ".git"))
+	branchCloned, err := s.cloneOrReuseBranch(ctx, appFs, outputPath, remote, componentName, branch)
+	if err != nil {
+		return result, err
 	}
 	s.Log.V(6).Info("GitOps repository cloned")
+	if s.DryRun && !checkoutExists {
+		defer s.cleanUpDryRun(outputPath, componentName, &err)
+	}
 
 	repoPath := filepath.Join(outputPath, componentName)
-	gitopsFolder := filepath.Join(repoPath, context)
+	if err := s.sparseCheckout(ctx, repoPath, contextPath, componentName); err != nil {
+		return result, err
+	}
+	gitopsFolder := filepath.Join(repoPath, contextPath)
 	componentPath := filepath.Join(gitopsFolder, "components", componentName, "base")
 
-	// Checkout the specified branch
-	s.Log.V(6).Info(fmt.Sprintf("Checking out branch %s", branch))
-	if _, err := execute(repoPath, GitCommand, "switch", branch); err != nil {
-		if out, err := execute(repoPath, GitCommand, "checkout", "-b", branch); err != nil {
-			return &GitBranchError{branch: branch, repoPath: repoPath, cmdResult: string(out), err: err, cmdType: checkoutBranch}
+	// Checkout the specified branch, unless it was cloned
+	if !branchCloned {
+		s.Log.V(6).Info(fmt.Sprintf("Checking out branch %s", branch))
+		checkedOut, err := s.switchOrCreateBranch(ctx, repoPath, remote, branch)
+		if err != nil {
+			return result, err
+		}
+		if checkedOut != branch {
+			branch = checkedOut
+			if pr, tag, err = s.pullRequestAndTag(data, commitMessage, branch); err != nil {
+				return result, err
+			}
+		}
+		s.Log.V(6).Info(fmt.Sprintf("Branch %s checked out", branch))
+	}
+
+	if s.CleanBeforeGenerate {
+		componentDir := strings.TrimPrefix(filepath.ToSlash(filepath.Join(contextPath, "components", componentName)), "/")
+		if err := s.cleanComponent(ctx, repoPath, componentDir); err != nil {
+			return result, err
 		}
 	}
-	s.Log.V(6).Info(fmt.Sprintf("Branch %s checked out", branch))
 
-	if out, err := execute(repoPath, RmCommand, "-rf", filepath.Join("components", componentName, "base")); err != nil {
-		return &DeleteFolderError{componentPath: filepath.Join("components", componentName, "base"), repoPath: repoPath, cmdResult: string(out), err: err}
+	// The resources added by users to the base are kept, in which case the generator only replaces its own files
+	if !options.PreserveUserResources {
+		if out, err := s.executeContext(ctx, repoPath, RmCommand, "-rf", filepath.Join("components", componentName, "base")); err != nil {
+			return result, &DeleteFolderError{componentPath: filepath.Join("components", componentName, "base"), repoPath: repoPath, cmdResult: string(out), err: err}
+		}
 	}
 
 	// Generate the gitops resources and update the parent kustomize yaml file
 	s.Log.V(6).Info(fmt.Sprintf("Generating GitOps resources under %s", componentPath))
 	if err := Generate(appFs, gitopsFolder, componentPath, options); err != nil {
-		return &GitGenResourcesAndOverlaysError{path: componentPath, componentName: componentName, err: err}
+		return result, &GitGenResourcesAndOverlaysError{path: componentPath, componentName: componentName, err: err}
 	}
 	s.Log.V(6).Info(fmt.Sprintf("GitOps resources generated under %s", componentPath))
 
-	if doPush {
+	if doPush || s.DryRun {
 		s.Log.V(6).Info("Pushing GitOps resources to repository")
-		return s.CommitAndPush(outputPath, "", remote, componentName, branch, fmt.Sprintf("Generate GitOps base resources for component %s", componentName))
+		return s.commitAndPush(ctx, outputPath, "", remote, componentName, branch, commitMessage, pr, tag)
 	}
-	return nil
+	return result, nil
 }
 
 // CommitAndPush pushes any new changes to the GitOps repo.  The folder should already be cloned in the target output folder.
@@ -151,10 +554,58 @@ func (s Gen) CloneGenerateAndPush(outputPath string, remote string, options gito
 // 5. The branch to push to
 // 6. The path within the repository to generate the resources in
 func (s Gen) CommitAndPush(outputPath string, repoPathOverride string, remote string, componentName string, branch string, commitMessage string) error {
+	return s.CommitAndPushWithContext(context.Background(), outputPath, repoPathOverride, remote, componentName, branch, commitMessage)
+}
+
+// CommitAndPushWithContext is CommitAndPush, with the git commands interrupted once the context is done
+func (s Gen) CommitAndPushWithContext(ctx context.Context, outputPath string, repoPathOverride string, remote string, componentName string, branch string, commitMessage string) error {
+	_, err := s.CommitAndPushWithResult(ctx, outputPath, repoPathOverride, remote, componentName, branch, commitMessage)
+	return err
+}
+
+// CommitAndPushWithResult is CommitAndPushWithContext, returning the result of the push. The commit SHA is empty when
+// there's nothing to commit, and a dry run stops once the changes are staged, returning their diff
+func (s Gen) CommitAndPushWithResult(ctx context.Context, outputPath string, repoPathOverride string, remote string, componentName string, branch string, commitMessage string) (PushResult, error) {
+	unlock, err := s.lockRepo(ctx, remote, branch)
+	if err != nil {
+		return PushResult{}, err
+	}
+	defer unlock()
+
+	data := CommitMessageData{Component: componentName}
+	pr, tag, err := s.pullRequestAndTag(data, commitMessage, branch)
+	if err != nil {
+		return PushResult{}, err
+	}
+	return s.commitAndPush(ctx, outputPath, repoPathOverride, remote, componentName, branch, commitMessage, pr, tag)
+}
+
+// commitAndPush is CommitAndPushWithResult, opening the pull request for the commit if set
+func (s Gen) commitAndPush(ctx context.Context, outputPath string, repoPathOverride string, remote string, componentName string, branch string, commitMessage string, pr *pullRequest, tag *gitTag) (PushResult, error) {
+	result := PushResult{Branch: branch}
 
 	invalidRemoteErr := util.ValidateRemote(remote)
 	if invalidRemoteErr != nil {
-		return invalidRemoteErr
+		return result, invalidRemoteErr
+	}
+
+	for _, additionalRemote := range s.AdditionalRemotes {
+		if invalidRemoteErr := util.ValidateRemote(additionalRemote.URL); invalidRemoteErr != nil {
+			return result, invalidRemoteErr
+		}
+	}
+
+	pushBranch := branch
+	if pr != nil {
+		// The pull requests are opened with the token of the remote, which ssh remotes don't have
+		if util.IsSSHRemote(remote) {
+			return result, fmt.Errorf("failed to open a pull request, the ssh remote %q doesn't have a token", remote)
+		}
+		pushBranch = pr.head
+	}
+	pushArgs, err := s.pushArgs(s.remoteName(), pushBranch)
+	if err != nil {
+		return result, err
 	}
 
 	repoPath := filepath.Join(outputPath, componentName)
@@ -162,36 +613,384 @@ func (s Gen) CommitAndPush(outputPath string, repoPathOverride string, remote st
 		repoPath = filepath.Join(outputPath, repoPathOverride)
 	}
 
-	if out, err := execute(repoPath, GitCommand, "add", "."); err != nil {
-		return &GitAddFilesError{componentName: componentName, repoPath: repoPath, cmdResult: string(out), err: err}
+	// The index of a dry run is restored once the diff is captured, so that the changes staged in an existing checkout
+	// are left as they were
+	index := ""
+	if s.DryRun {
+		out, err := s.executeContext(ctx, repoPath, GitCommand, "write-tree")
+		if err != nil {
+			return result, &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: checkGitDiff}
+		}
+		index = strings.TrimSpace(string(out))
 	}
 
-	if out, err := execute(repoPath, GitCommand, "--no-pager", "diff", "--cached"); err != nil {
-		return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: checkGitDiff}
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, "add", "."); err != nil {
+		return result, &GitAddFilesError{componentName: componentName, repoPath: repoPath, cmdResult: string(out), err: err}
+	}
 
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, "--no-pager", "diff", "--cached"); err != nil {
+		return result, &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: checkGitDiff}
+
+	} else if s.DryRun {
+		result.Diff = util.SanitizeMessage(string(out))
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, "read-tree", index); err != nil {
+			return result, &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: resetRepo}
+		}
+		return result, nil
 	} else if string(out) != "" {
 		// Pull from remote if branch is present
-		if out, err := execute(repoPath, GitCommand, "ls-remote", "--heads", remote, branch); err != nil {
-			return &GitLsRemoteError{err: err, cmdResult: string(out), remote: remote}
+		authRemote, authArgs := s.remoteAuth(remote)
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, "ls-remote", "--heads", authRemote, branch)...); err != nil {
+			return result, &GitLsRemoteError{err: err, cmdResult: string(out), remote: remote}
 		} else if strings.Contains(string(out), "refs/heads/"+branch) {
 			// only if the git repository contains the branch, pull
-			if out, err := execute(repoPath, GitCommand, "pull"); err != nil {
-				return &GitPullError{err: err, cmdResult: string(out), remote: remote}
+			if out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, "pull")...); err != nil {
+				return result, &GitPullError{err: err, cmdResult: string(out), remote: remote}
+			}
+		}
+
+		// Commit the changes to the head branch of the pull request
+		if pr != nil {
+			if out, err := s.executeContext(ctx, repoPath, GitCommand, "checkout", "-b", pr.head); err != nil {
+				return result, &GitBranchError{branch: pr.head, repoPath: repoPath, cmdResult: string(out), err: err, cmdType: checkoutBranch}
 			}
 		}
 
 		// Commit the changes and push
-		if out, err := execute(repoPath, GitCommand, "commit", "-m", commitMessage); err != nil {
-			return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: commitFiles}
+		if len(s.CommitTrailers) > 0 {
+			commitMessage = strings.TrimRight(commitMessage, "\n") + "\n\n" + strings.Join(s.CommitTrailers, "\n")
 		}
-		if out, err := execute(repoPath, GitCommand, "push", "origin", branch); err != nil {
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, s.commitArgs(commitMessage)...); err != nil {
+			if s.SignCommits != nil && isSigningFailure(string(out)) {
+				return result, &GitSignCommitError{repoPath: repoPath, cmdResult: string(out), err: err}
+			}
+			return result, &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: commitFiles}
+		}
+		if err := s.push(ctx, repoPath, remote, pushBranch, pushArgs); err != nil {
+			return result, err
+		}
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, "rev-parse", "HEAD"); err != nil {
+			return result, &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: getCommitID}
+		} else {
+			result.CommitSHA = strings.TrimSpace(string(out))
+		}
+		// A commit that can't be tagged is still pushed, the tag error is only returned once everything else succeeded
+		tagErr := s.tagCommit(ctx, repoPath, remote, tag)
+		if pr != nil {
+			s.Log.V(6).Info(fmt.Sprintf("Opening a pull request from branch %s to branch %s", pr.head, branch))
+			opened, err := s.openPullRequest(ctx, remote, branch, pr)
+			if err != nil {
+				return result, err
+			}
+			result.PullRequestNumber, result.PullRequestURL = opened.Number, opened.Link
+			return result, tagErr
+		}
+		if err := s.pushAdditionalRemotes(ctx, repoPath, branch); err != nil {
+			return result, err
+		}
+		return result, tagErr
+	}
+
+	return result, nil
+}
+
+// push pushes the branch to the remote with the push arguments. A push from a shallow clone rejected because the remote
+// needs the missing history is retried once the clone is unshallowed, and a normal push rejected because the remote
+// branch moved on is retried after rebasing onto it, up to the push retries
+func (s Gen) push(ctx context.Context, repoPath string, remote string, branch string, pushArgs []string) error {
+	_, authArgs := s.remoteAuth(remote)
+	unshallowed := false
+	for retries := 0; ; {
+		out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, pushArgs...)...)
+		if err == nil {
+			return nil
+		}
+		switch {
+		case s.CloneDepth > 0 && !unshallowed && strings.Contains(string(out), "shallow"):
+			s.Log.V(6).Info("Unshallowing the GitOps repository")
+			if out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, "fetch", "--unshallow")...); err != nil {
+				return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: unshallowRepo}
+			}
+			unshallowed = true
+		case retries < s.PushRetries && (s.PushMode == "" || s.PushMode == PushModeNormal) && isPushRejected(string(out)):
+			backoff := s.PushRetryBackoff << retries
+			retries++
+			s.Log.V(6).Info(fmt.Sprintf("Push to branch %s rejected, rebasing and retrying in %s (%d/%d)", branch, backoff, retries, s.PushRetries))
+			select {
+			case <-ctx.Done():
+				return &GitCmdError{path: remote, cmdResult: string(out), err: ctx.Err(), cmdType: pushRemote}
+			case <-time.After(backoff):
+			}
+			if out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, "pull", "--rebase", s.remoteName(), branch)...); err != nil {
+				if !strings.Contains(string(out), "CONFLICT") {
+					return &GitPullError{err: err, cmdResult: string(out), remote: remote}
+				}
+				if abortOut, abortErr := s.executeContext(ctx, repoPath, GitCommand, "rebase", "--abort"); abortErr != nil {
+					return &GitCmdError{path: repoPath, cmdResult: string(abortOut), err: abortErr, cmdType: abortRebase}
+				}
+				return &GitRebaseConflictError{branch: branch, remote: remote, cmdResult: string(out), err: err}
+			}
+		default:
 			return &GitCmdError{path: remote, cmdResult: string(out), err: err, cmdType: pushRemote}
 		}
 	}
+}
+
+// pushAdditionalRemotes pushes the branch to the additional remotes, adding the remotes missing from the repository.
+// Every additional remote is pushed to, and the first failure is returned
+func (s Gen) pushAdditionalRemotes(ctx context.Context, repoPath string, branch string) error {
+	var pushErr error
+	for _, additionalRemote := range s.AdditionalRemotes {
+		if err := s.pushAdditionalRemote(ctx, repoPath, additionalRemote, branch); err != nil {
+			s.Log.Error(err, fmt.Sprintf("Failed to push to the additional remote %s", additionalRemote.Name))
+			if pushErr == nil {
+				pushErr = err
+			}
+		}
+	}
+	return pushErr
+}
+
+// pushAdditionalRemote adds the remote to the repository, or updates its URL if it already exists, and pushes the
+// branch to it
+func (s Gen) pushAdditionalRemote(ctx context.Context, repoPath string, additionalRemote Remote, branch string) error {
+	authRemote, authArgs := s.remoteAuth(additionalRemote.URL)
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, "remote", "add", additionalRemote.Name, authRemote); err != nil {
+		if !strings.Contains(string(out), "already exists") {
+			return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: addRemote}
+		}
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, "remote", "set-url", additionalRemote.Name, authRemote); err != nil {
+			return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: addRemote}
+		}
+	}
+	pushArgs, err := s.pushArgs(additionalRemote.Name, branch)
+	if err != nil {
+		return err
+	}
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, pushArgs...)...); err != nil {
+		return &GitPushRemoteError{remoteName: additionalRemote.Name, remote: additionalRemote.URL, cmdResult: string(out), err: err}
+	}
+	return nil
+}
+
+// remoteName returns the name of the remote to push to, or the default remote name if not set
+func (s Gen) remoteName() string {
+	if s.RemoteName == "" {
+		return defaultRemoteName
+	}
+	return s.RemoteName
+}
+
+// pushArgs returns the git arguments pushing the branch to the remote in the push mode
+func (s Gen) pushArgs(remoteName string, branch string) ([]string, error) {
+	switch s.PushMode {
+	case "", PushModeNormal:
+		return []string{"push", remoteName, branch}, nil
+	case PushModeForceWithLease:
+		return []string{"push", "--force-with-lease", remoteName, branch}, nil
+	case PushModeForce:
+		return []string{"push", "--force", remoteName, branch}, nil
+	}
+	return nil, fmt.Errorf("unsupported push mode %q", s.PushMode)
+}
+
+// commitMessage returns the commit message of the commit message template executed with the data, or the default
+// message if there's no template
+func (s Gen) commitMessage(data CommitMessageData, defaultMessage string) (string, error) {
+	if s.CommitMessageTemplate == "" {
+		return defaultMessage, nil
+	}
+	return executeTemplate("commit message", s.CommitMessageTemplate, data)
+}
+
+// pullRequest returns the pull request to open for the commit of the commit message against the branch, or nil if the
+// commits are pushed to the branch
+func (s Gen) pullRequest(data CommitMessageData, commitMessage string, branch string) (*pullRequest, error) {
+	if s.PullRequest == nil {
+		return nil, nil
+	}
+	prData := PullRequestData{CommitMessageData: data, Branch: branch, Timestamp: time.Now().UTC().Format("20060102150405")}
+
+	branchTemplate := s.PullRequest.BranchTemplate
+	if branchTemplate == "" {
+		branchTemplate = defaultPullRequestBranchTemplate
+	}
+	head, err := executeTemplate("pull request branch", branchTemplate, prData)
+	if err != nil {
+		return nil, err
+	}
+	pr := &pullRequest{head: strings.TrimSpace(head), title: commitMessage}
+	if i := strings.Index(commitMessage, "\n"); i >= 0 {
+		pr.title, pr.body = commitMessage[:i], strings.TrimSpace(commitMessage[i+1:])
+	}
+	if s.PullRequest.TitleTemplate != "" {
+		if pr.title, err = executeTemplate("pull request title", s.PullRequest.TitleTemplate, prData); err != nil {
+			return nil, err
+		}
+	}
+	if s.PullRequest.BodyTemplate != "" {
+		if pr.body, err = executeTemplate("pull request body", s.PullRequest.BodyTemplate, prData); err != nil {
+			return nil, err
+		}
+	}
+	return pr, nil
+}
+
+// pullRequestAndTag returns the pull request and the tag of the commit for the branch
+func (s Gen) pullRequestAndTag(data CommitMessageData, commitMessage string, branch string) (*pullRequest, *gitTag, error) {
+	pr, err := s.pullRequest(data, commitMessage, branch)
+	if err != nil {
+		return nil, nil, err
+	}
+	tag, err := s.resultTag(data, commitMessage, branch)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pr, tag, nil
+}
+
+// resultTag returns the tag of the pushed commit, or nil if the commits aren't tagged
+func (s Gen) resultTag(data CommitMessageData, commitMessage string, branch string) (*gitTag, error) {
+	if s.TagResult == nil {
+		return nil, nil
+	}
+	tagData := TagData{CommitMessageData: data, Branch: branch, Timestamp: time.Now().UTC().Format("20060102150405")}
+
+	nameTemplate := s.TagResult.NameTemplate
+	if nameTemplate == "" {
+		nameTemplate = defaultTagNameTemplate
+	}
+	name, err := executeTemplate("tag name", nameTemplate, tagData)
+	if err != nil {
+		return nil, err
+	}
+	tag := &gitTag{name: strings.TrimSpace(name), message: commitMessage}
+	if s.TagResult.MessageTemplate != "" {
+		if tag.message, err = executeTemplate("tag message", s.TagResult.MessageTemplate, tagData); err != nil {
+			return nil, err
+		}
+	}
+	return tag, nil
+}
 
+// tagCommit creates the annotated tag of the pushed commit and pushes it to the remote
+func (s Gen) tagCommit(ctx context.Context, repoPath string, remote string, tag *gitTag) error {
+	if tag == nil {
+		return nil
+	}
+	s.Log.V(6).Info(fmt.Sprintf("Tagging the commit with %s", tag.name))
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, append(s.identityArgs(), "tag", "-a", tag.name, "-m", tag.message)...); err != nil {
+		tagErr := &GitTagError{tag: tag.name, path: repoPath, cmdResult: string(out), err: err, cmdType: createTag}
+		s.Log.Error(tagErr, fmt.Sprintf("Failed to tag the commit with %s", tag.name))
+		return tagErr
+	}
+	_, authArgs := s.remoteAuth(remote)
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, "push", s.remoteName(), tag.name)...); err != nil {
+		tagErr := &GitTagError{tag: tag.name, path: remote, cmdResult: string(out), err: err, cmdType: pushTag}
+		s.Log.Error(tagErr, fmt.Sprintf("Failed to push the tag %s", tag.name))
+		return tagErr
+	}
 	return nil
 }
 
+// openPullRequest opens the pull request against the base branch of the remote, authenticated with the token of the
+// remote
+func (s Gen) openPullRequest(ctx context.Context, remote string, base string, pr *pullRequest) (*scm.PullRequest, error) {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return nil, &GitPullRequestError{remote: remote, head: pr.head, base: base, err: err}
+	}
+	u.User = url.UserPassword("", u.User.Username())
+	repo := strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), ".git")
+
+	client, err := newSCMClient(u.String())
+	if err != nil {
+		return nil, &GitPullRequestError{remote: remote, head: pr.head, base: base, err: err}
+	}
+	opened, _, err := client.PullRequests.Create(ctx, repo, &scm.PullRequestInput{
+		Title: pr.title,
+		Head:  pr.head,
+		Base:  base,
+		Body:  pr.body,
+	})
+	if err != nil {
+		return nil, &GitPullRequestError{remote: remote, head: pr.head, base: base, err: err}
+	}
+	return opened, nil
+}
+
+// executeTemplate returns the text template executed with the data, failing on missing keys
+func executeTemplate(name string, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the %s template: %v", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to execute the %s template: %v", name, err)
+	}
+	return out.String(), nil
+}
+
+// identityArgs returns the git arguments setting the identity of the commits and tags
+func (s Gen) identityArgs() []string {
+	name, email := s.Author.Name, s.Author.Email
+	if name == "" {
+		name = defaultAuthorName
+	}
+	if email == "" {
+		email = defaultAuthorEmail
+	}
+	return []string{"-c", "user.name=" + name, "-c", "user.email=" + email}
+}
+
+// commitArgs returns the git arguments committing with the message, as the author or the default author, and signed
+// with the signing key if set
+func (s Gen) commitArgs(message string) []string {
+	args := s.identityArgs()
+	if s.SignCommits == nil {
+		return append(args, "commit", "-m", message)
+	}
+	format := s.SignCommits.Format
+	if format == "" {
+		format = "gpg"
+	}
+	args = append(args, "-c", "gpg.format="+format)
+	if s.SignCommits.Key != "" {
+		args = append(args, "-c", "user.signingkey="+s.SignCommits.Key)
+	}
+	return append(args, "commit", "-S"+s.SignCommits.Key, "-m", message)
+}
+
+// isPushRejected returns whether the output of a push is a rejection because the remote branch has commits the local
+// branch doesn't
+func isPushRejected(out string) bool {
+	return strings.Contains(out, "non-fast-forward") || strings.Contains(out, "fetch first")
+}
+
+// signingFailures are the outputs of git, gpg and ssh-keygen when a commit can't be signed. The "failed to write commit
+// object" that git prints after them isn't one, as git prints it after any failure to write the commit, e.g. a full disk
+var signingFailures = []string{
+	"gpg failed to sign the data",
+	"ssh-keygen -Y sign is needed for ssh signing",
+	"failed to get the ssh fingerprint for key",
+	"either user.signingkey or gpg.ssh.defaultKeyCommand needs to be configured",
+	"gpg.ssh.defaultKeyCommand failed",
+	"No private key found for public key",
+	"Couldn't load public key",
+	"incorrect passphrase supplied to decrypt private key",
+}
+
+// isSigningFailure returns whether the output of a signed commit is a failure to sign it
+func isSigningFailure(out string) bool {
+	for _, failure := range signingFailures {
+		if strings.Contains(out, failure) {
+			return true
+		}
+	}
+	return false
+}
+
 // GenerateAndPush generates a new gitops folder with one component, and optionally pushes to Git. Note: this does not
 // clone an existing gitops repo.
 // 1. outputPath: Where the gitops resources are
@@ -278,22 +1077,23 @@ func (s Gen) GenerateAndPush(outputPath string, remote string, options gitopsv1a
 			return &GitCreateRepoError{repoName: repoName, org: org, err: err}
 		}
 
-		if out, err := execute(repoPath, GitCommand, "init", "."); err != nil {
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, "init", "."); err != nil {
 			return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: initializeGit}
 		}
-		if out, err := execute(repoPath, GitCommand, "add", "."); err != nil {
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, "add", "."); err != nil {
 			return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: addComponents}
 		}
-		if out, err := execute(repoPath, GitCommand, "commit", "-m", "Generate GitOps resources"); err != nil {
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, s.commitArgs("Generate GitOps resources")...); err != nil {
 			return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: commitFiles}
 		}
-		if out, err := execute(repoPath, GitCommand, "branch", "-m", branch); err != nil {
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, "branch", "-m", branch); err != nil {
 			return &GitBranchError{branch: branch, repoPath: repoPath, cmdResult: string(out), err: err, cmdType: switchBranch}
 		}
-		if out, err := execute(repoPath, GitCommand, "remote", "add", "origin", remote); err != nil {
-			return &GitAddFilesToRemoteError{componentName: componentName, remoteURL: remote, repoPath: repoPath, cmdResult: string(out), err: err}
+		authRemote, authArgs := s.remoteAuth(remote)
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, "remote", "add", s.remoteName(), authRemote); err != nil {
+			return &GitAddFilesToRemoteError{componentName: componentName, remoteName: s.remoteName(), remoteURL: remote, repoPath: repoPath, cmdResult: string(out), err: err}
 		}
-		if out, err := execute(repoPath, GitCommand, "push", "-u", "origin", branch); err != nil {
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, "push", "-u", s.remoteName(), branch)...); err != nil {
 			return &GitCmdError{path: remote, cmdResult: string(out), err: err, cmdType: pushRemote}
 		}
 	}
@@ -311,50 +1111,106 @@ func (s Gen) GenerateAndPush(outputPath string, remote string, options gitopsv1a
 // 7. imageName: The image name of the source
 // 8  namespace: The namespace of the component. This is used in as the namespace of the deployment yaml.
 // 9. The filesystem object used to create (either ioutils.NewFilesystem() or ioutils.NewMemoryFilesystem())
-// 10. The branch to push to
+// 10. The branch to push to. Empty is the default branch of the remote
 // 11. The path within the repository to generate the resources in
 // 12. Push the changes to the repository or not.
 // 13. The gitops config containing the build bundle;
-func (s Gen) GenerateOverlaysAndPush(outputPath string, clone bool, remote string, options gitopsv1alpha1.GeneratorOptions, applicationName, environmentName, imageName, namespace string, appFs afero.Afero, branch string, context string, doPush bool, componentGeneratedResources map[string][]string) error {
+func (s Gen) GenerateOverlaysAndPush(outputPath string, clone bool, remote string, options gitopsv1alpha1.GeneratorOptions, applicationName, environmentName, imageName, namespace string, appFs afero.Afero, branch string, contextPath string, doPush bool, componentGeneratedResources map[string][]string) error {
+	return s.GenerateOverlaysAndPushWithContext(context.Background(), outputPath, clone, remote, options, applicationName, environmentName, imageName, namespace, appFs, branch, contextPath, doPush, componentGeneratedResources)
+}
 
-	if clone || doPush {
+// GenerateOverlaysAndPushWithContext is GenerateOverlaysAndPush, with the git commands interrupted once the context is
+// done
+func (s Gen) GenerateOverlaysAndPushWithContext(ctx context.Context, outputPath string, clone bool, remote string, options gitopsv1alpha1.GeneratorOptions, applicationName, environmentName, imageName, namespace string, appFs afero.Afero, branch string, contextPath string, doPush bool, componentGeneratedResources map[string][]string) error {
+	_, err := s.GenerateOverlaysAndPushWithResult(ctx, outputPath, clone, remote, options, applicationName, environmentName, imageName, namespace, appFs, branch, contextPath, doPush, componentGeneratedResources)
+	return err
+}
+
+// GenerateOverlaysAndPushWithResult is GenerateOverlaysAndPushWithContext, returning the result of the push
+func (s Gen) GenerateOverlaysAndPushWithResult(ctx context.Context, outputPath string, clone bool, remote string, options gitopsv1alpha1.GeneratorOptions, applicationName, environmentName, imageName, namespace string, appFs afero.Afero, branch string, contextPath string, doPush bool, componentGeneratedResources map[string][]string) (result PushResult, err error) {
+
+	if clone || doPush || s.DryRun {
 		invalidRemoteErr := util.ValidateRemote(remote)
 		if invalidRemoteErr != nil {
-			return invalidRemoteErr
+			return result, invalidRemoteErr
 		}
+		if branch == "" {
+			if branch, err = s.defaultBranch(ctx, outputPath, remote); err != nil {
+				return result, err
+			}
+		}
+		unlock, err := s.lockRepo(ctx, remote, branch)
+		if err != nil {
+			return result, err
+		}
+		defer unlock()
 	}
 
 	componentName := options.Name
 	repoPath := filepath.Join(outputPath, applicationName)
 
+	data := CommitMessageData{Component: componentName, Application: applicationName, Environment: environmentName, Operation: OperationGenerateOverlays}
+	commitMessage, err := s.commitMessage(data, fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName))
+	if err != nil {
+		return result, err
+	}
+	pr, tag, err := s.pullRequestAndTag(data, commitMessage, branch)
+	if err != nil {
+		return result, err
+	}
+
 	if clone {
 		s.Log.V(6).Info("Cloning the GitOps repository")
-		if out, err := execute(outputPath, GitCommand, "clone", remote, applicationName); err != nil {
-			return &GitCmdError{path: outputPath, cmdResult: string(out), err: err, cmdType: cloneRepo}
+		checkoutExists, _ := appFs.DirExists(filepath.Join(repoPath, ".git"))
+		branchCloned, cloneErr := s.cloneOrReuseBranch(ctx, appFs, outputPath, remote, applicationName, branch)
+		if cloneErr != nil {
+			return result, cloneErr
+		}
+		if s.DryRun && !checkoutExists {
+			defer s.cleanUpDryRun(outputPath, applicationName, &err)
+		}
+		if err := s.sparseCheckout(ctx, repoPath, contextPath, componentName); err != nil {
+			return result, err
 		}
 
-		// Checkout the specified branch
-		if _, err := execute(repoPath, GitCommand, "switch", branch); err != nil {
-			if out, err := execute(repoPath, GitCommand, "checkout", "-b", branch); err != nil {
-				return &GitBranchError{branch: branch, repoPath: repoPath, cmdResult: string(out), err: err, cmdType: checkoutBranch}
+		// Checkout the specified branch, unless it was cloned
+		if !branchCloned {
+			checkedOut, err := s.switchOrCreateBranch(ctx, repoPath, remote, branch)
+			if err != nil {
+				return result, err
+			}
+			if checkedOut != branch {
+				branch = checkedOut
+				if pr, tag, err = s.pullRequestAndTag(data, commitMessage, branch); err != nil {
+					return result, err
+				}
 			}
 		}
 	}
 
 	// Generate the gitops resources and update the parent kustomize yaml file
-	gitopsFolder := filepath.Join(repoPath, context)
+	gitopsFolder := filepath.Join(repoPath, contextPath)
 	componentEnvOverlaysPath := filepath.Join(gitopsFolder, "components", componentName, "overlays", environmentName)
 
 	s.Log.V(6).Info("Generating the overlays resources")
 	if err := GenerateOverlays(appFs, gitopsFolder, componentEnvOverlaysPath, options, imageName, namespace, componentGeneratedResources); err != nil {
-		return &GitGenResourcesAndOverlaysError{path: componentEnvOverlaysPath, componentName: componentName, err: err, cmdType: genOverlays}
+		return result, &GitGenResourcesAndOverlaysError{path: componentEnvOverlaysPath, componentName: componentName, err: err, cmdType: genOverlays}
 	}
 
-	if doPush {
+	if doPush || s.DryRun {
 		s.Log.V(6).Info("Committing and pushing the overlays resources")
-		return s.CommitAndPush(outputPath, applicationName, remote, componentName, branch, fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName))
+		return s.commitAndPush(ctx, outputPath, applicationName, remote, componentName, branch, commitMessage, pr, tag)
+	}
+	return result, nil
+}
+
+// cleanUpDryRun deletes the repository cloned for a dry run, even once the context is done, setting the error if the
+// deletion fails and there's no error already
+func (s Gen) cleanUpDryRun(outputPath string, folder string, err *error) {
+	s.Log.V(6).Info(fmt.Sprintf("Deleting the dry run repository %s", folder))
+	if out, rmErr := s.executeContext(context.Background(), outputPath, RmCommand, "-rf", folder); rmErr != nil && *err == nil {
+		*err = &DeleteFolderError{componentPath: folder, repoPath: outputPath, cmdResult: string(out), err: rmErr}
 	}
-	return nil
 }
 
 // GitRemoveComponent clones the repo, removes the component, and pushes the changes back to the repository. It takes in the following args and updates the gitops resources by removing the given component
@@ -364,14 +1220,57 @@ func (s Gen) GenerateOverlaysAndPush(outputPath string, clone bool, remote strin
 // 4. The branch to push to
 // 5. The path within the repository to generate the resources in
 func (s Gen) GitRemoveComponent(outputPath string, remote string, componentName string, branch string, context string) error {
-	if cloneError := s.CloneRepo(outputPath, remote, componentName, branch); cloneError != nil {
-		return cloneError
+	return s.GitRemoveComponentWithFs(outputPath, remote, componentName, ioutils.NewFilesystem(), branch, context)
+}
+
+// GitRemoveComponentWithFs is GitRemoveComponent, with the parent kustomization updated in the filesystem (either
+// ioutils.NewFilesystem() or ioutils.NewMemoryFilesystem())
+func (s Gen) GitRemoveComponentWithFs(outputPath string, remote string, componentName string, appFs afero.Afero, branch string, contextPath string) error {
+	return s.GitRemoveComponentWithContext(context.Background(), outputPath, remote, componentName, appFs, branch, contextPath)
+}
+
+// GitRemoveComponentWithContext is GitRemoveComponentWithFs, with the git commands interrupted once the context is done
+func (s Gen) GitRemoveComponentWithContext(ctx context.Context, outputPath string, remote string, componentName string, appFs afero.Afero, branch string, contextPath string) error {
+	_, err := s.GitRemoveComponentWithResult(ctx, outputPath, remote, componentName, appFs, branch, contextPath)
+	return err
+}
+
+// GitRemoveComponentWithResult is GitRemoveComponentWithContext, returning the result of the push
+func (s Gen) GitRemoveComponentWithResult(ctx context.Context, outputPath string, remote string, componentName string, appFs afero.Afero, branch string, contextPath string) (PushResult, error) {
+	unlock, err := s.lockRepo(ctx, remote, branch)
+	if err != nil {
+		return PushResult{}, err
 	}
-	if removeComponentError := removeComponent(outputPath, componentName, context); removeComponentError != nil {
-		return removeComponentError
+	defer unlock()
+
+	data := CommitMessageData{Component: componentName, Operation: OperationRemoveComponent}
+	commitMessage, err := s.commitMessage(data, fmt.Sprintf("Removed component %s", componentName))
+	if err != nil {
+		return PushResult{}, err
+	}
+	pr, tag, err := s.pullRequestAndTag(data, commitMessage, branch)
+	if err != nil {
+		return PushResult{}, err
+	}
+	checkedOut, cloneError := s.cloneRepo(ctx, appFs, outputPath, remote, componentName, branch)
+	if cloneError != nil {
+		return PushResult{}, cloneError
+	}
+	if checkedOut != branch {
+		branch = checkedOut
+		if pr, tag, err = s.pullRequestAndTag(data, commitMessage, branch); err != nil {
+			return PushResult{}, err
+		}
+	}
+	if removeComponentError := s.removeComponent(ctx, outputPath, componentName, contextPath); removeComponentError != nil {
+		return PushResult{}, removeComponentError
+	}
+	gitopsFolder := filepath.Join(outputPath, componentName, contextPath)
+	if err := pruneParentKustomize(appFs, gitopsFolder, componentName); err != nil {
+		return PushResult{}, err
 	}
 
-	return s.CommitAndPush(outputPath, "", remote, componentName, branch, fmt.Sprintf("Removed component %s", componentName))
+	return s.commitAndPush(ctx, outputPath, "", remote, componentName, branch, commitMessage, pr, tag)
 }
 
 // CloneRepo clones the repo, and switches to the branch
@@ -380,23 +1279,429 @@ func (s Gen) GitRemoveComponent(outputPath string, remote string, componentName
 // 3. componentName: The component name corresponding to a single Component in an Application. eg. component.Name
 // 4. The branch to push to switch to
 func (s Gen) CloneRepo(outputPath string, remote string, componentName string, branch string) error {
+	unlock, err := s.lockRepo(context.Background(), remote, branch)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	_, err = s.cloneRepo(context.Background(), ioutils.NewFilesystem(), outputPath, remote, componentName, branch)
+	return err
+}
+
+// cloneRepo is CloneRepo, with the git commands interrupted once the context is done, and the existing checkouts looked
+// up in the filesystem. It returns the branch checked out
+func (s Gen) cloneRepo(ctx context.Context, appFs afero.Afero, outputPath string, remote string, componentName string, branch string) (string, error) {
 	invalidRemoteErr := util.ValidateRemote(remote)
 	if invalidRemoteErr != nil {
-		return invalidRemoteErr
+		return "", invalidRemoteErr
 	}
 
 	repoPath := filepath.Join(outputPath, componentName)
 
-	if out, err := execute(outputPath, GitCommand, "clone", remote, componentName); err != nil {
-		return &GitCmdError{path: outputPath, cmdResult: string(out), err: err, cmdType: cloneRepo}
+	branchCloned, err := s.cloneOrReuseBranch(ctx, appFs, outputPath, remote, componentName, branch)
+	if err != nil {
+		return "", err
+	}
+
+	// Checkout the specified branch, unless it was cloned
+	if !branchCloned {
+		return s.switchOrCreateBranch(ctx, repoPath, remote, branch)
+	}
+	return branch, nil
+}
+
+// executeContext executes the command, within the command timeout if set. The error of a command interrupted because
+// the context is done, or that isn't run as the context was already done, is the context error
+func (s Gen) executeContext(ctx context.Context, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cmdCtx := ctx
+	if s.CommandTimeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, s.CommandTimeout)
+		defer cancel()
+	}
+	execute := s.execute
+	if execute == nil {
+		execute = executeCommand
+	}
+	out, err := execute(cmdCtx, s.commandOptions(), baseDir, cmd, args...)
+	if err != nil && cmdCtx.Err() != nil {
+		return out, cmdCtx.Err()
+	}
+	return out, err
+}
+
+// repoLocks are the locks of the remotes and branches, held while a call of the generator clones, generates or pushes
+type repoLocks struct {
+	mu    sync.Mutex
+	locks map[string]*repoLock
+}
+
+// repoLock is held by sending to its channel, so that waiting for it can be interrupted. It's deleted once no call
+// holds nor waits for it
+type repoLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+func newRepoLocks() *repoLocks {
+	return &repoLocks{locks: map[string]*repoLock{}}
+}
+
+// lock waits for the lock of the key, until the context is done, and returns the function releasing it
+func (l *repoLocks) lock(ctx context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &repoLock{ch: make(chan struct{}, 1)}
+		l.locks[key] = lock
 	}
+	lock.refs++
+	l.mu.Unlock()
 
-	// Checkout the specified branch
-	if _, err := execute(repoPath, GitCommand, "switch", branch); err != nil {
-		if out, err := execute(repoPath, GitCommand, "checkout", "-b", branch); err != nil {
-			return &GitBranchError{branch: branch, repoPath: repoPath, cmdResult: string(out), err: err, cmdType: checkoutBranch}
+	release := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if lock.refs--; lock.refs == 0 {
+			delete(l.locks, key)
 		}
 	}
+	unlock := func() {
+		<-lock.ch
+		release()
+	}
+	// An available lock is taken even if the context is done, so that the calls fail the same with or without locking
+	select {
+	case lock.ch <- struct{}{}:
+		return unlock, nil
+	default:
+	}
+	select {
+	case lock.ch <- struct{}{}:
+		return unlock, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// lockRepo serializes the concurrent calls against the same remote and branch, unless the locking is disabled, and
+// returns the function releasing the lock
+func (s Gen) lockRepo(ctx context.Context, remote string, branch string) (func(), error) {
+	if s.locks == nil || s.DisableRepoLocking {
+		return func() {}, nil
+	}
+	return s.locks.lock(ctx, repoLockKey(remote, branch))
+}
+
+// repoLockKey returns the key of the lock of the remote and branch
+func repoLockKey(remote string, branch string) string {
+	return util.NormalizeRemote(remote) + "#" + branch
+}
+
+// commandOptions returns the options of the commands, exporting the ssh command using the ssh key and known hosts, if
+// set, as GIT_SSH_COMMAND
+func (s Gen) commandOptions() commandOptions {
+	options := commandOptions{gitBinaryPath: s.GitBinaryPath, env: s.Env}
+	if s.SSHKeyPath == "" && s.KnownHostsPath == "" {
+		return options
+	}
+
+	sshCommand := []string{"ssh"}
+	if s.SSHKeyPath != "" {
+		sshCommand = append(sshCommand, "-i", shellQuote(s.SSHKeyPath), "-o", "IdentitiesOnly=yes")
+	}
+	if s.KnownHostsPath != "" {
+		sshCommand = append(sshCommand, "-o", "UserKnownHostsFile="+shellQuote(s.KnownHostsPath), "-o", "StrictHostKeyChecking=yes")
+	}
+	options.env = make(map[string]string, len(s.Env)+1)
+	for key, value := range s.Env {
+		options.env[key] = value
+	}
+	options.env["GIT_SSH_COMMAND"] = strings.Join(sshCommand, " ")
+	return options
+}
+
+// shellQuote quotes the value for the shell that git runs GIT_SSH_COMMAND with
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// cloneBranch clones only the branch of the remote into the folder, and returns whether it did. If the branch is empty
+// or doesn't exist in the remote yet, the whole remote is cloned instead, so that the branch is switched to or created
+// once cloned
+func (s Gen) cloneBranch(ctx context.Context, outputPath string, remote string, folder string, branch string) (bool, error) {
+	if branch != "" {
+		out, err := s.executeContext(ctx, outputPath, GitCommand, s.cloneArgs(remote, folder, branch)...)
+		if err == nil {
+			return true, nil
+		}
+		if !strings.Contains(string(out), fmt.Sprintf("Remote branch %s not found", branch)) {
+			return false, &GitCmdError{path: outputPath, cmdResult: string(out), err: err, cmdType: cloneRepo}
+		}
+		s.Log.V(6).Info(fmt.Sprintf("Branch %s not found in the GitOps repository, cloning the whole repository", branch))
+	}
+	if out, err := s.executeContext(ctx, outputPath, GitCommand, s.cloneArgs(remote, folder, "")...); err != nil {
+		return false, &GitCmdError{path: outputPath, cmdResult: string(out), err: err, cmdType: cloneRepo}
+	}
+	return false, nil
+}
+
+// cleanComponent resets the folder of the component to the checked out commit, the equivalent of a git reset --hard
+// and git clean -fdx scoped to the folder, so that the files outside of it are left untouched
+func (s Gen) cleanComponent(ctx context.Context, repoPath string, componentDir string) error {
+	s.Log.V(6).Info(fmt.Sprintf("Cleaning %s", componentDir))
+	path := filepath.Join(repoPath, componentDir)
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, "reset", "--quiet", "HEAD", "--", componentDir); err != nil {
+		return &GitCmdError{path: path, cmdResult: string(out), err: err, cmdType: resetRepo}
+	}
+	// The component may not be committed yet, in which case there are no changes to discard
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, "checkout", "HEAD", "--", componentDir); err != nil && !strings.Contains(string(out), "did not match any file(s) known to git") {
+		return &GitCmdError{path: path, cmdResult: string(out), err: err, cmdType: resetRepo}
+	}
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, "clean", "-fdx", "--", componentDir); err != nil {
+		return &GitCmdError{path: path, cmdResult: string(out), err: err, cmdType: cleanRepo}
+	}
+	return nil
+}
+
+// GetDefaultBranch returns the default branch of the remote, the branch its HEAD points to, e.g. main or master
+func (s Gen) GetDefaultBranch(outputPath string, remote string) (string, error) {
+	if invalidRemoteErr := util.ValidateRemote(remote); invalidRemoteErr != nil {
+		return "", invalidRemoteErr
+	}
+	return s.defaultBranch(context.Background(), outputPath, remote)
+}
+
+// defaultBranch returns the default branch of the remote from the symbolic reference of its HEAD, listed as
+// "ref: refs/heads/<branch>\tHEAD"
+func (s Gen) defaultBranch(ctx context.Context, outputPath string, remote string) (string, error) {
+	authRemote, authArgs := s.remoteAuth(remote)
+	out, err := s.executeContext(ctx, outputPath, GitCommand, append(authArgs, "ls-remote", "--symref", authRemote, "HEAD")...)
+	if err != nil {
+		return "", &GitCmdError{path: remote, cmdResult: string(out), err: err, cmdType: headBranch}
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "ref:" && fields[2] == "HEAD" && strings.HasPrefix(fields[1], "refs/heads/") {
+			branch := strings.TrimPrefix(fields[1], "refs/heads/")
+			s.Log.V(6).Info(fmt.Sprintf("The default branch of the GitOps repository is %s", branch))
+			return branch, nil
+		}
+	}
+	return "", &GitCmdError{path: remote, cmdResult: string(out), err: errors.New("the HEAD of the remote isn't a branch"), cmdType: headBranch}
+}
+
+// switchOrCreateBranch switches to the branch, or else to the first of its fallbacks that exists, and returns the branch
+// switched to. The branch is created if none exist, unless the branch is strict, from the base branch of the remote if
+// set, fetching it first as the clone may not have it, or else from the checked out branch
+func (s Gen) switchOrCreateBranch(ctx context.Context, repoPath string, remote string, branch string) (string, error) {
+	candidates := s.branchCandidates(branch)
+	if s.StrictBranch {
+		_, authArgs := s.remoteAuth(remote)
+		out, err := s.executeContext(ctx, repoPath, GitCommand, append(append(authArgs, "ls-remote", "--heads", s.remoteName()), candidates...)...)
+		if err != nil {
+			return "", &GitLsRemoteError{err: err, cmdResult: string(out), remote: remote}
+		}
+		heads := map[string]bool{}
+		for _, line := range strings.Split(string(out), "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 {
+				heads[strings.TrimPrefix(fields[1], "refs/heads/")] = true
+			}
+		}
+		found := ""
+		for _, candidate := range candidates {
+			if heads[candidate] {
+				found = candidate
+				break
+			}
+		}
+		if found == "" {
+			return "", &BranchNotFoundError{branch: branch, remote: remote}
+		}
+		candidates = []string{found}
+	}
+	for _, candidate := range candidates {
+		if _, err := s.executeContext(ctx, repoPath, GitCommand, "switch", candidate); err == nil {
+			if candidate != branch {
+				s.Log.V(6).Info(fmt.Sprintf("Branch %s not found, switched to the fallback branch %s", branch, candidate))
+			}
+			return candidate, nil
+		}
+	}
+	branch = candidates[0]
+	args := []string{"checkout", "-b", branch}
+	if s.BaseBranch != "" {
+		_, authArgs := s.remoteAuth(remote)
+		refspec := fmt.Sprintf("%s:refs/remotes/%s/%s", s.BaseBranch, s.remoteName(), s.BaseBranch)
+		if out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, "fetch", s.remoteName(), refspec)...); err != nil {
+			return "", &GitBranchError{branch: s.BaseBranch, repoPath: repoPath, cmdResult: string(out), err: err, cmdType: fetchRepo}
+		}
+		args = append(args, s.remoteName()+"/"+s.BaseBranch)
+	}
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, args...); err != nil {
+		return "", &GitBranchError{branch: branch, repoPath: repoPath, cmdResult: string(out), err: err, cmdType: checkoutBranch}
+	}
+	return branch, nil
+}
+
+// branchCandidates returns the branch, followed by its fallbacks if it's one of them
+func (s Gen) branchCandidates(branch string) []string {
+	candidates := []string{branch}
+	isFallback := false
+	for _, fallback := range s.BranchFallbacks {
+		isFallback = isFallback || fallback == branch
+	}
+	if !isFallback {
+		return candidates
+	}
+	for _, fallback := range s.BranchFallbacks {
+		if fallback != branch {
+			candidates = append(candidates, fallback)
+		}
+	}
+	return candidates
+}
+
+// cloneOrReuseBranch is cloneBranch, unless the folder already exists, e.g. after a previous run crashed. A checkout of
+// the remote is fetched and reused, reset to the branch of the remote if the clone strategy reuses the checkouts. Any
+// other folder fails the clone, unless the clone is forced or the clone strategy reuses the checkouts, in which case it's
+// deleted and cloned again, as is a checkout of the remote that can't be switched to the branch
+func (s Gen) cloneOrReuseBranch(ctx context.Context, appFs afero.Afero, outputPath string, remote string, folder string, branch string) (bool, error) {
+	repoPath := filepath.Join(outputPath, folder)
+	force := s.ForceClone || s.CloneStrategy == CloneStrategyReuse
+	if exists, _ := appFs.DirExists(filepath.Join(repoPath, ".git")); exists {
+		if s.isCheckoutOf(ctx, repoPath, remote) {
+			if s.CloneStrategy != CloneStrategyReuse || branch == "" {
+				s.Log.V(6).Info(fmt.Sprintf("Reusing the checkout of the GitOps repository in %s", repoPath))
+				return false, s.fetch(ctx, repoPath, remote)
+			}
+			reused, err := s.reuseCheckout(ctx, repoPath, remote, branch)
+			if err != nil || reused {
+				return reused, err
+			}
+		} else if !force {
+			return false, &DestinationExistsError{repoPath: repoPath, remote: remote}
+		}
+		if err := s.deleteClone(ctx, outputPath, folder); err != nil {
+			return false, err
+		}
+	}
+
+	branchCloned, err := s.cloneBranch(ctx, outputPath, remote, folder, branch)
+	var cloneErr *GitCmdError
+	if err != nil && errors.As(err, &cloneErr) && strings.Contains(cloneErr.cmdResult, "already exists and is not an empty directory") {
+		if !force {
+			return false, &DestinationExistsError{repoPath: repoPath, remote: remote}
+		}
+		if err := s.deleteClone(ctx, outputPath, folder); err != nil {
+			return false, err
+		}
+		return s.cloneBranch(ctx, outputPath, remote, folder, branch)
+	}
+	return branchCloned, err
+}
+
+// deleteClone deletes the folder that can't be reused, so that it's cloned again
+func (s Gen) deleteClone(ctx context.Context, outputPath string, folder string) error {
+	s.Log.V(6).Info(fmt.Sprintf("The folder %s can't be reused, cloning the GitOps repository again", folder))
+	if out, err := s.executeContext(ctx, outputPath, RmCommand, "-rf", folder); err != nil {
+		return &DeleteFolderError{componentPath: folder, repoPath: outputPath, cmdResult: string(out), err: err}
+	}
+	return nil
+}
+
+// isCheckoutOf returns whether the checkout of the repository path is of the remote, whatever the form of its URL, e.g.
+// with another token or without the .git suffix
+func (s Gen) isCheckoutOf(ctx context.Context, repoPath string, remote string) bool {
+	out, err := s.executeContext(ctx, repoPath, GitCommand, "remote", "get-url", s.remoteName())
+	return err == nil && util.NormalizeRemote(strings.TrimSpace(string(out))) == util.NormalizeRemote(remote)
+}
+
+// fetch fetches the remote into the checkout
+func (s Gen) fetch(ctx context.Context, repoPath string, remote string) error {
+	_, authArgs := s.remoteAuth(remote)
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, append(authArgs, "fetch", s.remoteName())...); err != nil {
+		return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: fetchRepo}
+	}
+	return nil
+}
+
+// reuseCheckout resets the checkout of the remote to the branch of the remote, discarding any local change, and
+// returns whether it did. A checkout that can't be switched to the branch isn't reused
+func (s Gen) reuseCheckout(ctx context.Context, repoPath string, remote string, branch string) (bool, error) {
+	if err := s.fetch(ctx, repoPath, remote); err != nil {
+		return false, err
+	}
+	if _, err := s.executeContext(ctx, repoPath, GitCommand, "switch", branch); err != nil {
+		return false, nil
+	}
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, "reset", "--hard", s.remoteName()+"/"+branch); err != nil {
+		return false, &GitBranchError{branch: branch, repoPath: repoPath, cmdResult: string(out), err: err, cmdType: resetBranch}
+	}
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, "clean", "-fd"); err != nil {
+		return false, &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: cleanRepo}
+	}
+	return true, nil
+}
+
+// cloneArgs returns the git arguments cloning the remote into the folder, shallow if a clone depth is set, and only
+// cloning the branch if it's set
+func (s Gen) cloneArgs(remote string, folder string, branch string) []string {
+	remote, authArgs := s.remoteAuth(remote)
+	args := append(authArgs, "clone")
+	if s.CloneDepth > 0 {
+		args = append(args, "--depth", strconv.Itoa(s.CloneDepth), "--no-tags")
+	}
+	if branch != "" {
+		args = append(args, "--branch", branch, "--single-branch")
+	}
+	if s.RemoteName != "" {
+		args = append(args, "--origin", s.RemoteName)
+	}
+	return append(args, remote, folder)
+}
+
+// remoteAuth returns the remote and the git arguments authenticating to it. With the header authentication, the token
+// is removed from the remote and passed as an http.extraHeader instead
+func (s Gen) remoteAuth(remote string) (string, []string) {
+	if s.AuthMode != AuthModeHeader || util.IsSSHRemote(remote) {
+		return remote, nil
+	}
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil {
+		return remote, nil
+	}
+	token := u.User.Username()
+	if password, ok := u.User.Password(); ok {
+		token = password
+	}
+	u.User = nil
+	if token == "" {
+		return u.String(), nil
+	}
+
+	username := "x-access-token"
+	if u.Host == "gitlab.com" {
+		username = "oauth2"
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + token))
+	return u.String(), []string{"-c", "http.extraHeader=Authorization: Basic " + credentials}
+}
+
+// sparseCheckout limits the checkout of the cloned repository to the component and the parent kustomization, if the
+// sparse checkout is enabled. Only directories are set in the cone mode, whose checkout includes the files of the
+// parent directories of the component, the parent kustomization among them
+func (s Gen) sparseCheckout(ctx context.Context, repoPath string, contextPath string, componentName string) error {
+	if !s.SparseCheckout {
+		return nil
+	}
+	componentPath := filepath.ToSlash(filepath.Join(contextPath, "components", componentName))
+	if out, err := s.executeContext(ctx, repoPath, GitCommand, "sparse-checkout", "set", strings.TrimPrefix(componentPath, "/")); err != nil {
+		return &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: sparseCheckout}
+	}
 	return nil
 }
 
@@ -404,22 +1709,120 @@ func (s Gen) CloneRepo(outputPath string, remote string, componentName string, b
 // 1. outputPath: Where the gitops repo contents have been cloned
 // 2. componentName: The component name corresponding to a single Component in an Application. eg. component.Name
 // 3. The path within the repository to generate the resources in
-func removeComponent(outputPath string, componentName string, context string) error {
+func (s Gen) removeComponent(ctx context.Context, outputPath string, componentName string, contextPath string) error {
 	repoPath := filepath.Join(outputPath, componentName)
-	gitopsFolder := filepath.Join(repoPath, context)
+	gitopsFolder := filepath.Join(repoPath, contextPath)
 	componentPath := filepath.Join(gitopsFolder, "components", componentName)
-	if out, err := execute(repoPath, RmCommand, "-rf", componentPath); err != nil {
+	if out, err := s.executeContext(ctx, repoPath, RmCommand, "-rf", componentPath); err != nil {
 		return &DeleteFolderError{componentPath: componentPath, repoPath: repoPath, cmdResult: string(out), err: err}
 	}
 	return nil
 }
 
+// pruneParentKustomize removes the entries of the removed component from the kustomization in the gitops folder, so
+// that it doesn't reference a deleted directory. Nothing is written if the component isn't referenced
+func pruneParentKustomize(fs afero.Afero, gitopsFolder string, componentName string) error {
+	kustomizeFilePath := filepath.Join(gitopsFolder, kustomizeFileName)
+	exists, err := fs.Exists(kustomizeFilePath)
+	if err != nil || !exists {
+		return err
+	}
+	var k resources.Kustomization
+	if err := yaml.UnMarshalItemFromFile(fs, kustomizeFilePath, &k); err != nil {
+		return fmt.Errorf("failed to unmarshal items from %q: %v", kustomizeFilePath, err)
+	}
+
+	componentPath := filepath.Join("components", componentName)
+	pruned := false
+	prune := func(entries []string) []string {
+		var kept []string
+		for _, entry := range entries {
+			entryPath := filepath.Clean(entry)
+			if entryPath == componentPath || strings.HasPrefix(entryPath, componentPath+string(filepath.Separator)) {
+				pruned = true
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		return kept
+	}
+	k.Resources = prune(k.Resources)
+	k.Bases = prune(k.Bases)
+	k.Components = prune(k.Components)
+	if !pruned {
+		return nil
+	}
+	return yaml.MarshalItemToFile(fs, kustomizeFilePath, k)
+}
+
 // GetCommitIDFromRepo returns the commit ID for the given repository
 func (s Gen) GetCommitIDFromRepo(fs afero.Afero, repoPath string) (string, error) {
 	var out []byte
 	var err error
-	if out, err = execute(repoPath, GitCommand, "rev-parse", "HEAD"); err != nil {
+	if out, err = s.executeContext(context.Background(), repoPath, GitCommand, "rev-parse", "HEAD"); err != nil {
+		return "", &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: getCommitID}
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetCommitIDForRef returns the commit ID the reference resolves to in the given repository, e.g. a branch, a tag or
+// origin/<branch>. Annotated tags resolve to the commit they tag
+func (s Gen) GetCommitIDForRef(fs afero.Afero, repoPath string, ref string) (string, error) {
+	if err := util.ValidateRef(ref); err != nil {
+		return "", err
+	}
+	out, err := s.executeContext(context.Background(), repoPath, GitCommand, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
 		return "", &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: getCommitID}
 	}
-	return string(out), nil
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetCommitIDForRemoteBranch fetches the branch from the remote of the given repository, named RemoteName, and returns
+// the commit ID of <remote name>/<branch>
+func (s Gen) GetCommitIDForRemoteBranch(fs afero.Afero, repoPath string, branch string) (string, error) {
+	if err := util.ValidateRef(branch); err != nil {
+		return "", err
+	}
+	refspec := fmt.Sprintf("%s:refs/remotes/%s/%s", branch, s.remoteName(), branch)
+	if out, err := s.executeContext(context.Background(), repoPath, GitCommand, "fetch", s.remoteName(), refspec); err != nil {
+		return "", &GitBranchError{branch: branch, repoPath: repoPath, cmdResult: string(out), err: err, cmdType: fetchRepo}
+	}
+	return s.GetCommitIDForRef(fs, repoPath, s.remoteName()+"/"+branch)
+}
+
+// GetCommitInfo returns the metadata of the commit the reference resolves to in the given repository
+func (s Gen) GetCommitInfo(fs afero.Afero, repoPath string, ref string) (CommitInfo, error) {
+	if err := util.ValidateRef(ref); err != nil {
+		return CommitInfo{}, err
+	}
+	out, err := s.executeContext(context.Background(), repoPath, GitCommand, "log", "-1", commitInfoFormat, ref, "--")
+	if err != nil {
+		return CommitInfo{}, &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: getCommitInfo}
+	}
+	info, err := parseCommitInfo(string(out))
+	if err != nil {
+		return CommitInfo{}, &GitCmdError{path: repoPath, cmdResult: string(out), err: err, cmdType: getCommitInfo}
+	}
+	return info, nil
+}
+
+// parseCommitInfo parses the output of git log with the commitInfoFormat
+func parseCommitInfo(out string) (CommitInfo, error) {
+	fields := strings.SplitN(strings.TrimRight(out, "\n"), "\x00", 6)
+	if len(fields) != 6 || fields[0] == "" {
+		return CommitInfo{}, fmt.Errorf("unexpected git log output, expected 6 fields and got %d", len(fields))
+	}
+	timestamp, err := time.Parse(time.RFC3339, fields[4])
+	if err != nil {
+		return CommitInfo{}, fmt.Errorf("unexpected commit timestamp %q: %v", fields[4], err)
+	}
+	return CommitInfo{
+		SHA:       fields[0],
+		ShortSHA:  fields[1],
+		Author:    fields[2],
+		Email:     fields[3],
+		Timestamp: timestamp,
+		Subject:   fields[5],
+	}, nil
 }
@@ -16,18 +16,27 @@
 package gitops
 
 import (
+	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/driver/fake"
+	"github.com/jenkins-x/go-scm/scm/factory"
 	routev1 "github.com/openshift/api/route/v1"
 	gitopsv1alpha1 "github.com/redhat-developer/gitops-generator/api/v1alpha1"
+	"github.com/redhat-developer/gitops-generator/pkg/resources"
 	"github.com/redhat-developer/gitops-generator/pkg/testutils"
 	"github.com/redhat-developer/gitops-generator/pkg/util"
 	"github.com/redhat-developer/gitops-generator/pkg/util/ioutils"
+	"github.com/redhat-developer/gitops-generator/pkg/yaml"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
@@ -36,8 +45,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var originalExecute = execute
-
 func TestCloneGenerateAndPush(t *testing.T) {
 	repo := "https://github.com/testing/testing.git"
 	repoWithToken := "https://ghu_28lafsjdifouwej@github.com/testing/testing.git"
@@ -58,14 +65,17 @@ func TestCloneGenerateAndPush(t *testing.T) {
 	generator := NewGitopsGen()
 
 	tests := []struct {
-		name          string
-		repo          string
-		fs            afero.Afero
-		component     gitopsv1alpha1.GeneratorOptions
-		errors        *testutils.ErrorStack
-		outputs       [][]byte
-		want          []testutils.Execution
-		wantErrString string
+		name           string
+		repo           string
+		fs             afero.Afero
+		component      gitopsv1alpha1.GeneratorOptions
+		cloneDepth     int
+		sparseCheckout bool
+		baseBranch     string
+		errors         *testutils.ErrorStack
+		outputs        [][]byte
+		want           []testutils.Execution
+		wantErrString  string
 	}{
 		{
 			name:      "No errors",
@@ -82,18 +92,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -123,73 +127,27 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
-			name: "No errors with Kubernetes Resources provided",
-			repo: repo,
-			fs:   fs,
-			component: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Deployments: []appsv1.Deployment{
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "deployment1",
-							},
-						},
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "deployment2",
-							},
-						},
-					},
-					Services: []corev1.Service{
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "service1",
-							},
-						},
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "service2",
-							},
-						},
-					},
-					Routes: []routev1.Route{
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "route1",
-							},
-						},
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "route2",
-							},
-						},
-					},
-					Ingresses: []networkingv1.Ingress{
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "ingress1",
-							},
-						},
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "ingress2",
-							},
-						},
-					},
-				},
-			},
-			errors: &testutils.ErrorStack{},
+			name:       "No errors with a shallow clone",
+			repo:       repo,
+			fs:         fs,
+			component:  component,
+			cloneDepth: 1,
+			errors:     &testutils.ErrorStack{},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
@@ -199,18 +157,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--depth", "1", "--no-tags", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -240,38 +192,47 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
-			name: "No errors with Kubernetes Resource deployment provided",
-			repo: repo,
-			fs:   fs,
-			component: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Deployments: []appsv1.Deployment{
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "deployment1",
-							},
-						},
-					},
+			name:       "No errors with a shallow clone unshallowed after its push is rejected",
+			repo:       repo,
+			fs:         fs,
+			component:  component,
+			cloneDepth: 1,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					nil,
+					nil,
+					errors.New("exit status 1"),
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
 				},
 			},
-			errors: &testutils.ErrorStack{},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
+				[]byte("! [remote rejected] main -> main (shallow update not allowed)"),
 				[]byte("test output3"),
-				[]byte("test output4 refs/heads/main"),
-				[]byte("test output5"),
+				[]byte("test output4"),
+				[]byte("test output5 refs/heads/main"),
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
@@ -281,12 +242,7 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--depth", "1", "--no-tags", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -316,33 +272,37 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"fetch", "--unshallow"},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
-			name: "No errors with Kubernetes Resource svc and target port provided",
-			repo: repo,
-			fs:   fs,
-			component: gitopsv1alpha1.GeneratorOptions{
-				Name: "test-component",
-				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Services: []corev1.Service{
-						{
-							ObjectMeta: metav1.ObjectMeta{
-								Name: "service1",
-							},
-						},
-					},
-				},
-				TargetPort: 1234,
-			},
-			errors: &testutils.ErrorStack{},
+			name:           "No errors with a sparse checkout",
+			repo:           repo,
+			fs:             fs,
+			component:      component,
+			sparseCheckout: true,
+			errors:         &testutils.ErrorStack{},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
@@ -358,12 +318,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"sparse-checkout", "set", "components/test-component"},
 				},
 				{
 					BaseDir: repoPath,
@@ -393,28 +353,104 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
-			name: "No errors with Kubernetes Resource svc and target port not provided",
+			name:           "Sparse checkout failure",
+			repo:           repo,
+			fs:             fs,
+			component:      component,
+			sparseCheckout: true,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("Fatal error"),
+					nil,
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"sparse-checkout", "set", "components/test-component"},
+				},
+			},
+			wantErrString: "failed to sparse checkout repository \"/fake/path/test-component\"",
+		},
+		{
+			name: "No errors with Kubernetes Resources provided",
 			repo: repo,
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name: "test-component",
 				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Deployments: []appsv1.Deployment{
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "deployment1",
+							},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "deployment2",
+							},
+						},
+					},
 					Services: []corev1.Service{
 						{
 							ObjectMeta: metav1.ObjectMeta{
 								Name: "service1",
 							},
 						},
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "service2",
+							},
+						},
+					},
+					Routes: []routev1.Route{
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "route1",
+							},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "route2",
+							},
+						},
+					},
+					Ingresses: []networkingv1.Ingress{
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "ingress1",
+							},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "ingress2",
+							},
+						},
 					},
 				},
 			},
@@ -428,18 +464,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -469,31 +499,35 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
-			name: "No errors with Kubernetes Resource route and target port provided",
+			name: "No errors with Kubernetes Resource deployment provided",
 			repo: repo,
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name: "test-component",
 				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Routes: []routev1.Route{
+					Deployments: []appsv1.Deployment{
 						{
 							ObjectMeta: metav1.ObjectMeta{
-								Name: "route1",
+								Name: "deployment1",
 							},
 						},
 					},
 				},
-				TargetPort: 1234,
 			},
 			errors: &testutils.ErrorStack{},
 			outputs: [][]byte{
@@ -505,18 +539,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -546,30 +574,36 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
-			name: "No errors with Kubernetes Resource route and target port not provided",
+			name: "No errors with Kubernetes Resource svc and target port provided",
 			repo: repo,
 			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
 				Name: "test-component",
 				KubernetesResources: gitopsv1alpha1.KubernetesResources{
-					Routes: []routev1.Route{
+					Services: []corev1.Service{
 						{
 							ObjectMeta: metav1.ObjectMeta{
-								Name: "route1",
+								Name: "service1",
 							},
 						},
 					},
 				},
+				TargetPort: 1234,
 			},
 			errors: &testutils.ErrorStack{},
 			outputs: [][]byte{
@@ -581,18 +615,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -622,87 +650,188 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
-			name:      "Git clone failure",
-			repo:      repo,
-			fs:        fs,
-			component: component,
-			errors: &testutils.ErrorStack{
-				Errors: []error{
-					nil,
-					errors.New("test error"),
+			name: "No errors with Kubernetes Resource svc and target port not provided",
+			repo: repo,
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Services: []corev1.Service{
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "service1",
+							},
+						},
+					},
 				},
 			},
+			errors: &testutils.ErrorStack{},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("test output4 refs/heads/main"),
+				[]byte("test output5"),
+				[]byte("test output6"),
+				[]byte("test output7"),
+				[]byte("test output8"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "rm",
+					Args:    []string{"-rf", filepath.Join("components", componentName, "base")},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"add", "."},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"--no-pager", "diff", "--cached"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
 				},
 			},
-			wantErrString: "test error",
 		},
 		{
-			name:      "Git switch failure, git checkout failure",
-			repo:      repo,
-			fs:        fs,
-			component: component,
-			errors: &testutils.ErrorStack{
-				Errors: []error{
-					errors.New("Permission denied"),
-					errors.New("Fatal error"),
-					nil,
+			name: "No errors with Kubernetes Resource route and target port provided",
+			repo: repo,
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Routes: []routev1.Route{
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "route1",
+							},
+						},
+					},
 				},
+				TargetPort: 1234,
 			},
+			errors: &testutils.ErrorStack{},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
 				[]byte("test output3"),
+				[]byte("test output4 refs/heads/main"),
+				[]byte("test output5"),
+				[]byte("test output6"),
+				[]byte("test output7"),
+				[]byte("test output8"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "rm",
+					Args:    []string{"-rf", filepath.Join("components", componentName, "base")},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"add", "."},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"checkout", "-b", "main"},
+					Args:    []string{"--no-pager", "diff", "--cached"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
 				},
 			},
-			wantErrString: "failed to checkout branch \"main\" in repository \"/fake/path/test-component\" \"test output1\": Permission denied",
 		},
 		{
-			name:      "Git switch failure, git checkout success",
-			repo:      repo,
-			fs:        fs,
-			component: component,
-			errors: &testutils.ErrorStack{
-				Errors: []error{
-					nil,
-					errors.New("test error"),
-					nil,
+			name: "No errors with Kubernetes Resource route and target port not provided",
+			repo: repo,
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name: "test-component",
+				KubernetesResources: gitopsv1alpha1.KubernetesResources{
+					Routes: []routev1.Route{
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "route1",
+							},
+						},
+					},
 				},
 			},
+			errors: &testutils.ErrorStack{},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
@@ -712,24 +841,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
-				[]byte("test output10"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"checkout", "-b", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -759,34 +876,69 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
-			wantErrString: "",
 		},
 		{
-			name:      "rm -rf failure",
+			name:      "Git clone failure",
 			repo:      repo,
 			fs:        fs,
 			component: component,
 			errors: &testutils.ErrorStack{
 				Errors: []error{
-					errors.New("Permission Denied"),
 					nil,
+					errors.New("test error"),
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+			},
+			wantErrString: "test error",
+		},
+		{
+			name:      "Git switch failure, git checkout failure",
+			repo:      repo,
+			fs:        fs,
+			component: component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("Permission denied"),
+					errors.New("Fatal error"),
 					nil,
+					errors.New("exit status 128"),
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
 				[]byte("test output3"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
 			},
 			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
 				{
 					BaseDir: outputPath,
 					Command: "git",
@@ -799,67 +951,69 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				},
 				{
 					BaseDir: repoPath,
-					Command: "rm",
-					Args:    []string{"-rf", "components/test-component/base"},
+					Command: "git",
+					Args:    []string{"checkout", "-b", "main"},
 				},
 			},
-			wantErrString: "failed to delete \"components/test-component/base\" folder in repository in \"/fake/path/test-component\" \"test output1\": Permission Denied",
+			wantErrString: "failed to checkout branch \"main\" in repository \"/fake/path/test-component\" \"test output1\": Permission denied",
 		},
 		{
-			name:      "git add failure",
-			repo:      repo,
-			fs:        fs,
-			component: component,
+			name:       "Git switch failure, base branch fetch failure",
+			baseBranch: "develop",
+			repo:       repo,
+			fs:         fs,
+			component:  component,
 			errors: &testutils.ErrorStack{
 				Errors: []error{
+					errors.New("Permission denied"),
 					errors.New("Fatal error"),
 					nil,
-					nil,
-					nil,
+					errors.New("exit status 128"),
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
 				[]byte("test output3"),
-				[]byte("test output4"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
-					BaseDir: repoPath,
+					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
-					Command: "rm",
-					Args:    []string{"-rf", "components/test-component/base"},
+					Command: "git",
+					Args:    []string{"switch", "main"},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"add", "."},
+					Args:    []string{"fetch", "origin", "develop:refs/remotes/origin/develop"},
 				},
 			},
-			wantErrString: "failed to add files for component \"test-component\" to repository in \"/fake/path/test-component\" \"test output1\": Fatal error",
+			wantErrString: "failed to fetch branch \"develop\" in repository \"/fake/path/test-component\" \"test output1\": Permission denied",
 		},
 		{
-			name:      "git diff failure",
-			repo:      repo,
-			fs:        fs,
-			component: component,
+			name:       "Git switch failure, git checkout from the base branch failure",
+			baseBranch: "develop",
+			repo:       repo,
+			fs:         fs,
+			component:  component,
 			errors: &testutils.ErrorStack{
 				Errors: []error{
-					errors.New("Permission Denied"),
-					nil,
-					nil,
+					errors.New("Permission denied"),
 					nil,
+					errors.New("Fatal error"),
 					nil,
+					errors.New("exit status 128"),
 				},
 			},
 			outputs: [][]byte{
@@ -867,9 +1021,14 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output2"),
 				[]byte("test output3"),
 				[]byte("test output4"),
-				[]byte("test output5"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
 			},
 			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
 				{
 					BaseDir: outputPath,
 					Command: "git",
@@ -880,10 +1039,70 @@ func TestCloneGenerateAndPush(t *testing.T) {
 					Command: "git",
 					Args:    []string{"switch", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"fetch", "origin", "develop:refs/remotes/origin/develop"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"checkout", "-b", "main", "origin/develop"},
+				},
+			},
+			wantErrString: "failed to checkout branch \"main\" in repository \"/fake/path/test-component\" \"test output1\": Permission denied",
+		},
+		{
+			name:      "Git switch failure, git checkout success",
+			repo:      repo,
+			fs:        fs,
+			component: component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					nil,
+					errors.New("test error"),
+					nil,
+					errors.New("exit status 128"),
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("test output4 refs/heads/main"),
+				[]byte("test output5"),
+				[]byte("test output6"),
+				[]byte("test output7"),
+				[]byte("test output8"),
+				[]byte("test output9"),
+				[]byte("test output10"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"switch", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"checkout", "-b", "main"},
+				},
 				{
 					BaseDir: repoPath,
 					Command: "rm",
-					Args:    []string{"-rf", "components/test-component/base"},
+					Args:    []string{"-rf", filepath.Join("components", componentName, "base")},
 				},
 				{
 					BaseDir: repoPath,
@@ -895,19 +1114,107 @@ func TestCloneGenerateAndPush(t *testing.T) {
 					Command: "git",
 					Args:    []string{"--no-pager", "diff", "--cached"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
-			wantErrString: "failed to check git diff in repository \"/fake/path/test-component\" \"test output1\": Permission Denied",
+			wantErrString: "",
 		},
 		{
-			name:      "Git ls remote failure",
+			name:      "rm -rf failure",
 			repo:      repo,
 			fs:        fs,
 			component: component,
 			errors: &testutils.ErrorStack{
 				Errors: []error{
-					errors.New("ls error"),
+					errors.New("Permission Denied"),
+					nil,
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "rm",
+					Args:    []string{"-rf", "components/test-component/base"},
+				},
+			},
+			wantErrString: "failed to delete \"components/test-component/base\" folder in repository in \"/fake/path/test-component\" \"test output1\": Permission Denied",
+		},
+		{
+			name:      "git add failure",
+			repo:      repo,
+			fs:        fs,
+			component: component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("Fatal error"),
 					nil,
 					nil,
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "rm",
+					Args:    []string{"-rf", "components/test-component/base"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"add", "."},
+				},
+			},
+			wantErrString: "failed to add files for component \"test-component\" to repository in \"/fake/path/test-component\" \"test output1\": Fatal error",
+		},
+		{
+			name:      "git diff failure",
+			repo:      repo,
+			fs:        fs,
+			component: component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("Permission Denied"),
 					nil,
 					nil,
 					nil,
@@ -918,19 +1225,57 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output2"),
 				[]byte("test output3"),
 				[]byte("test output4"),
-				[]byte("test output5"),
-				[]byte("test output6"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "rm",
+					Args:    []string{"-rf", "components/test-component/base"},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"add", "."},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"--no-pager", "diff", "--cached"},
+				},
+			},
+			wantErrString: "failed to check git diff in repository \"/fake/path/test-component\" \"test output1\": Permission Denied",
+		},
+		{
+			name:      "Git ls remote failure",
+			repo:      repo,
+			fs:        fs,
+			component: component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("ls error"),
+					nil,
+					nil,
+					nil,
+					nil,
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("test output4"),
+				[]byte("test output5"),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -968,7 +1313,6 @@ func TestCloneGenerateAndPush(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -978,18 +1322,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output4"),
 				[]byte("test output5"),
 				[]byte("test output6"),
-				[]byte("test output6"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -1033,7 +1371,6 @@ func TestCloneGenerateAndPush(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -1044,18 +1381,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output5"),
 				[]byte("test output6"),
 				[]byte("test output7"),
-				[]byte("test output8"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -1085,7 +1416,7 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
 				},
 			},
 			wantErrString: "failed to commit files to repository \"/fake/path/test-component\" \"test output1\": Fatal error",
@@ -1105,7 +1436,6 @@ func TestCloneGenerateAndPush(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -1117,18 +1447,12 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repoWithToken, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repoWithToken, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -1158,7 +1482,7 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate GitOps base resources for component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
@@ -1178,12 +1502,7 @@ func TestCloneGenerateAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -1194,47 +1513,134 @@ func TestCloneGenerateAndPush(t *testing.T) {
 			wantErrString: "failed to generate the gitops resources in \"/fake/path/test-component/components/test-component/base\" for component \"test-component\"",
 		},
 		{
-			name: "gitops generate failure - image component",
+			name: "No errors, preserving the user resources of the base",
 			repo: repo,
-			fs:   readOnlyFs,
+			fs:   fs,
 			component: gitopsv1alpha1.GeneratorOptions{
-				Name:           "test-component",
-				ContainerImage: "quay.io/test/test",
-				TargetPort:     5000,
+				Name:                  "test-component",
+				ContainerImage:        "quay.io/test/test",
+				TargetPort:            5000,
+				PreserveUserResources: true,
 			},
 			errors: &testutils.ErrorStack{},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("test output4 refs/heads/main"),
+				[]byte("test output5"),
+				[]byte("test output6"),
+				[]byte("test output7"),
+			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, "test-component"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, "test-component"},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"add", "."},
 				},
 				{
 					BaseDir: repoPath,
-					Command: "rm",
-					Args:    []string{"-rf", "components/test-component/base"},
+					Command: "git",
+					Args:    []string{"--no-pager", "diff", "--cached"},
 				},
-			},
-			wantErrString: "failed to generate the gitops resources in \"/fake/path/test-component/components/test-component/base\" for component \"test-component\": failed to MkDirAll",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-
-			outputStack := testutils.NewOutputs(tt.outputs...)
-			executedCmds := []testutils.Execution{}
-
-			execute = newTestExecute(outputStack, tt.errors, &executedCmds)
-
-			err := generator.CloneGenerateAndPush(outputPath, tt.repo, tt.component, tt.fs, branch, "/", true)
-
-			if tt.wantErrString != "" {
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "Generate GitOps base resources for component test-component"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
+			},
+		},
+		{
+			name: "gitops generate failure - broken kustomize reference",
+			repo: repo,
+			fs:   fs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:                "test-component",
+				ContainerImage:      "quay.io/test/test",
+				TargetPort:          5000,
+				KustomizeComponents: []string{"../../../missing-component"},
+				ValidateKustomize:   true,
+			},
+			errors: &testutils.ErrorStack{},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, "test-component"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "rm",
+					Args:    []string{"-rf", "components/test-component/base"},
+				},
+			},
+			wantErrString: "failed to generate the gitops resources in \"/fake/path/test-component/components/test-component/base\" for component \"test-component\": failed to validate the kustomization in \"/fake/path/test-component/components/test-component/base\": the kustomization in \"/fake/path/test-component/components/test-component/base\" references \"../../../missing-component\", which doesn't exist",
+		},
+		{
+			name: "gitops generate failure - image component",
+			repo: repo,
+			fs:   readOnlyFs,
+			component: gitopsv1alpha1.GeneratorOptions{
+				Name:           "test-component",
+				ContainerImage: "quay.io/test/test",
+				TargetPort:     5000,
+			},
+			errors: &testutils.ErrorStack{},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, "test-component"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "rm",
+					Args:    []string{"-rf", "components/test-component/base"},
+				},
+			},
+			wantErrString: "failed to generate the gitops resources in \"/fake/path/test-component/components/test-component/base\" for component \"test-component\": failed to MkDirAll",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			outputStack := testutils.NewOutputs(tt.outputs...)
+			executedCmds := []testutils.Execution{}
+
+			generator := generator
+			generator.execute = newTestExecute(outputStack, tt.errors, &executedCmds)
+			generator.CloneDepth = tt.cloneDepth
+			generator.SparseCheckout = tt.sparseCheckout
+			generator.BaseBranch = tt.baseBranch
+			err := generator.CloneGenerateAndPush(outputPath, tt.repo, tt.component, tt.fs, branch, "/", true)
+
+			if tt.wantErrString != "" {
 				testutils.AssertErrorMatch(t, tt.wantErrString, err)
 			} else {
 				testutils.AssertNoError(t, err)
@@ -1243,9 +1649,6 @@ func TestCloneGenerateAndPush(t *testing.T) {
 			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
 		})
 	}
-
-	execute = originalExecute
-
 }
 
 func TestGenerateOverlaysAndPush(t *testing.T) {
@@ -1268,6 +1671,8 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 	generator := NewGitopsGen()
 	tests := []struct {
 		name            string
+		sparseCheckout  bool
+		baseBranch      string
 		fs              afero.Afero
 		component       gitopsv1alpha1.GeneratorOptions
 		errors          *testutils.ErrorStack
@@ -1284,6 +1689,68 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 			fs:        fs,
 			component: component,
 			errors:    &testutils.ErrorStack{},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("test output4 refs/heads/main"),
+				[]byte("test output5"),
+				[]byte("test output6"),
+				[]byte("test output7"),
+			},
+			applicationName: applicationName,
+			environmentName: environmentName,
+			imageName:       imageName,
+			namespace:       namespace,
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"add", "."},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"--no-pager", "diff", "--cached"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName)},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
+			},
+		},
+		{
+			name:           "No errors with a sparse checkout",
+			sparseCheckout: true,
+			fs:             fs,
+			component:      component,
+			errors:         &testutils.ErrorStack{},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
@@ -1302,12 +1769,12 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, applicationName},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"sparse-checkout", "set", "components/test-component"},
 				},
 				{
 					BaseDir: repoPath,
@@ -1332,13 +1799,18 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
@@ -1363,7 +1835,7 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, applicationName},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
 				},
 			},
 			wantErrString: "test error",
@@ -1377,18 +1849,25 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 					errors.New("Permission denied"),
 					errors.New("Fatal error"),
 					nil,
+					errors.New("exit status 128"),
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
 				[]byte("test output3"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
 			},
 			applicationName: applicationName,
 			environmentName: environmentName,
 			imageName:       imageName,
 			namespace:       namespace,
 			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
+				},
 				{
 					BaseDir: outputPath,
 					Command: "git",
@@ -1407,6 +1886,107 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 			},
 			wantErrString: "failed to checkout branch \"main\" in repository \"/fake/path/test-application\" \"test output1\": Permission denied",
 		},
+		{
+			name:       "Git switch failure, base branch fetch failure",
+			baseBranch: "develop",
+			fs:         fs,
+			component:  component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("Permission denied"),
+					errors.New("Fatal error"),
+					nil,
+					errors.New("exit status 128"),
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
+			},
+			applicationName: applicationName,
+			environmentName: environmentName,
+			imageName:       imageName,
+			namespace:       namespace,
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
+				},
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", repo, applicationName},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"switch", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"fetch", "origin", "develop:refs/remotes/origin/develop"},
+				},
+			},
+			wantErrString: "failed to fetch branch \"develop\" in repository \"/fake/path/test-application\" \"test output1\": Permission denied",
+		},
+		{
+			name:       "Git switch failure, git checkout from the base branch failure",
+			baseBranch: "develop",
+			fs:         fs,
+			component:  component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("Permission denied"),
+					nil,
+					errors.New("Fatal error"),
+					nil,
+					errors.New("exit status 128"),
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("test output4"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
+			},
+			applicationName: applicationName,
+			environmentName: environmentName,
+			imageName:       imageName,
+			namespace:       namespace,
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
+				},
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", repo, applicationName},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"switch", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"fetch", "origin", "develop:refs/remotes/origin/develop"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"checkout", "-b", "main", "origin/develop"},
+				},
+			},
+			wantErrString: "failed to checkout branch \"main\" in repository \"/fake/path/test-application\" \"test output1\": Permission denied",
+		},
 		{
 			name:      "Git switch failure, git checkout success",
 			fs:        fs,
@@ -1416,6 +1996,7 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 					nil,
 					errors.New("test error"),
 					nil,
+					errors.New("exit status 128"),
 				},
 			},
 			outputs: [][]byte{
@@ -1427,13 +2008,19 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output8"),
+				[]byte("test output9"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
 			},
 			applicationName: applicationName,
 			environmentName: environmentName,
 			imageName:       imageName,
 			namespace:       namespace,
 			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
+				},
 				{
 					BaseDir: outputPath,
 					Command: "git",
@@ -1472,13 +2059,18 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 			wantErrString: "",
 		},
@@ -1490,13 +2082,11 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				Errors: []error{
 					errors.New("Fatal error"),
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
-				[]byte("test output3"),
 			},
 			applicationName: applicationName,
 			environmentName: environmentName,
@@ -1506,12 +2096,7 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, applicationName},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
 				},
 				{
 					BaseDir: repoPath,
@@ -1530,14 +2115,12 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 					errors.New("Permission Denied"),
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
 				[]byte("test output3"),
-				[]byte("test output4"),
 			},
 			applicationName: applicationName,
 			environmentName: environmentName,
@@ -1547,12 +2130,7 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, applicationName},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
 				},
 				{
 					BaseDir: repoPath,
@@ -1577,7 +2155,6 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -1585,18 +2162,12 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				[]byte("test output2"),
 				[]byte("test output3"),
 				[]byte("test output4"),
-				[]byte("test output5"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, applicationName},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
 				},
 				{
 					BaseDir: repoPath,
@@ -1628,7 +2199,6 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -1637,18 +2207,12 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				[]byte("test output3"),
 				[]byte("test output4"),
 				[]byte("test output5"),
-				[]byte("test output6"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, applicationName},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
 				},
 				{
 					BaseDir: repoPath,
@@ -1686,7 +2250,6 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -1696,7 +2259,6 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				[]byte("test output4"),
 				[]byte("test output5"),
 				[]byte("test output6"),
-				[]byte("test output7"),
 			},
 			applicationName: applicationName,
 			environmentName: environmentName,
@@ -1706,12 +2268,7 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, applicationName},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
 				},
 				{
 					BaseDir: repoPath,
@@ -1736,7 +2293,7 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName)},
 				},
 			},
 			wantErrString: "failed to commit files to repository \"/fake/path/test-application\" \"test output1\": Fatal error",
@@ -1754,7 +2311,6 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -1765,7 +2321,6 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				[]byte("test output5"),
 				[]byte("test output6"),
 				[]byte("test output7"),
-				[]byte("test output7"),
 			},
 			applicationName: applicationName,
 			environmentName: environmentName,
@@ -1775,12 +2330,7 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, applicationName},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
 				},
 				{
 					BaseDir: repoPath,
@@ -1805,7 +2355,7 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Generate %s environment overlays for component %s", environmentName, componentName)},
 				},
 				{
 					BaseDir: repoPath,
@@ -1828,12 +2378,7 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, applicationName},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, applicationName},
 				},
 			},
 			wantErrString: "failed to generate the gitops resources in overlays dir \"/fake/path/test-application/components/test-component/overlays/environment\" for component \"test-component\"",
@@ -1846,8 +2391,10 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 			outputStack := testutils.NewOutputs(tt.outputs...)
 			executedCmds := []testutils.Execution{}
 
-			execute = newTestExecute(outputStack, tt.errors, &executedCmds)
-
+			generator := generator
+			generator.execute = newTestExecute(outputStack, tt.errors, &executedCmds)
+			generator.SparseCheckout = tt.sparseCheckout
+			generator.BaseBranch = tt.baseBranch
 			err := generator.GenerateOverlaysAndPush(outputPath, true, repo, tt.component, tt.applicationName, tt.environmentName, tt.imageName, tt.namespace, tt.fs, branch, "/", true, generatedResources)
 
 			if tt.wantErrString != "" {
@@ -1867,7 +2414,6 @@ func TestGenerateOverlaysAndPush(t *testing.T) {
 			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
 		})
 	}
-	execute = originalExecute
 }
 
 func TestGitRemoveComponent(t *testing.T) {
@@ -1892,6 +2438,7 @@ func TestGitRemoveComponent(t *testing.T) {
 		name          string
 		fs            afero.Afero
 		component     gitopsv1alpha1.GeneratorOptions
+		baseBranch    string
 		errors        *testutils.ErrorStack
 		outputs       [][]byte
 		want          []testutils.Execution
@@ -1911,18 +2458,12 @@ func TestGitRemoveComponent(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -1952,13 +2493,18 @@ func TestGitRemoveComponent(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
@@ -1979,7 +2525,7 @@ func TestGitRemoveComponent(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 			},
 			wantErrString: "test error",
@@ -1993,14 +2539,21 @@ func TestGitRemoveComponent(t *testing.T) {
 					errors.New("Permission denied"),
 					errors.New("Fatal error"),
 					nil,
+					errors.New("exit status 128"),
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
 				[]byte("test output3"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
 			},
 			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
 				{
 					BaseDir: outputPath,
 					Command: "git",
@@ -2019,6 +2572,99 @@ func TestGitRemoveComponent(t *testing.T) {
 			},
 			wantErrString: "failed to checkout branch \"main\" in repository \"/fake/path/test-component\" \"test output1\": Permission denied",
 		},
+		{
+			name:       "Git switch failure, base branch fetch failure",
+			baseBranch: "develop",
+			fs:         fs,
+			component:  component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("Permission denied"),
+					errors.New("Fatal error"),
+					nil,
+					errors.New("exit status 128"),
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"switch", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"fetch", "origin", "develop:refs/remotes/origin/develop"},
+				},
+			},
+			wantErrString: "failed to fetch branch \"develop\" in repository \"/fake/path/test-component\" \"test output1\": Permission denied",
+		},
+		{
+			name:       "Git switch failure, git checkout from the base branch failure",
+			baseBranch: "develop",
+			fs:         fs,
+			component:  component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("Permission denied"),
+					nil,
+					errors.New("Fatal error"),
+					nil,
+					errors.New("exit status 128"),
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("test output4"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"switch", "main"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"fetch", "origin", "develop:refs/remotes/origin/develop"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"checkout", "-b", "main", "origin/develop"},
+				},
+			},
+			wantErrString: "failed to checkout branch \"main\" in repository \"/fake/path/test-component\" \"test output1\": Permission denied",
+		},
 		{
 			name:      "Git switch failure, git checkout success",
 			fs:        fs,
@@ -2028,6 +2674,7 @@ func TestGitRemoveComponent(t *testing.T) {
 					nil,
 					errors.New("test error"),
 					nil,
+					errors.New("exit status 128"),
 				},
 			},
 			outputs: [][]byte{
@@ -2041,8 +2688,14 @@ func TestGitRemoveComponent(t *testing.T) {
 				[]byte("test output8"),
 				[]byte("test output9"),
 				[]byte("test output10"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
 			},
 			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
 				{
 					BaseDir: outputPath,
 					Command: "git",
@@ -2086,13 +2739,18 @@ func TestGitRemoveComponent(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 			wantErrString: "",
 		},
@@ -2104,24 +2762,17 @@ func TestGitRemoveComponent(t *testing.T) {
 				Errors: []error{
 					errors.New("Permission Denied"),
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
-				[]byte("test output3"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2140,25 +2791,18 @@ func TestGitRemoveComponent(t *testing.T) {
 					errors.New("Fatal error"),
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
 				[]byte("test output3"),
-				[]byte("test output4"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2183,7 +2827,6 @@ func TestGitRemoveComponent(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -2191,18 +2834,12 @@ func TestGitRemoveComponent(t *testing.T) {
 				[]byte("test output2"),
 				[]byte("test output3"),
 				[]byte("test output4"),
-				[]byte("test output5"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2233,7 +2870,6 @@ func TestGitRemoveComponent(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -2242,18 +2878,12 @@ func TestGitRemoveComponent(t *testing.T) {
 				[]byte("test output3"),
 				[]byte("test output4"),
 				[]byte("test output5"),
-				[]byte("test output6"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2290,7 +2920,6 @@ func TestGitRemoveComponent(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -2300,18 +2929,12 @@ func TestGitRemoveComponent(t *testing.T) {
 				[]byte("test output4"),
 				[]byte("test output5"),
 				[]byte("test output6"),
-				[]byte("test output7"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2354,7 +2977,6 @@ func TestGitRemoveComponent(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -2365,18 +2987,77 @@ func TestGitRemoveComponent(t *testing.T) {
 				[]byte("test output5"),
 				[]byte("test output6"),
 				[]byte("test output7"),
-				[]byte("test output8"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "rm",
+					Args:    []string{"-rf", componentPath},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"add", "."},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"--no-pager", "diff", "--cached"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
+				},
+			},
+			wantErrString: "failed to commit files to repository \"/fake/path/test-component\" \"test output1\": Fatal error",
+		},
+		{
+			name:      "git push failure",
+			fs:        fs,
+			component: component,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("Fatal error"),
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+				},
+			},
+			outputs: [][]byte{
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("test output4 refs/heads/main"),
+				[]byte("test output5"),
+				[]byte("test output6"),
+				[]byte("test output7"),
+				[]byte("test output8"),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2406,116 +3087,2705 @@ func TestGitRemoveComponent(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", "main"},
 				},
 			},
-			wantErrString: "failed to commit files to repository \"/fake/path/test-component\" \"test output1\": Fatal error",
+			wantErrString: fmt.Sprintf("failed to push remote to repository \"%s\" \"test output1\": Fatal error", repo),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			outputStack := testutils.NewOutputs(tt.outputs...)
+			executedCmds := []testutils.Execution{}
+
+			generator := generator
+			generator.execute = newTestExecute(outputStack, tt.errors, &executedCmds)
+
+			if err := Generate(fs, repoPath, componentBasePath, tt.component); err != nil {
+				t.Errorf("unexpected error %v", err)
+				return
+			}
+
+			generator.BaseBranch = tt.baseBranch
+			err := generator.GitRemoveComponentWithFs(outputPath, repo, tt.component.Name, tt.fs, branch, "/")
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+
+			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+		})
+	}
+}
+
+func TestGitRemoveComponentPrunesParentKustomize(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	repoPath := "/fake/path/test-component"
+	branch := "main"
+	generator := NewGitopsGen()
+
+	tests := []struct {
+		name          string
+		resources     []string
+		wantResources []string
+	}{
+		{
+			name:          "Removed component is pruned from the parent kustomization",
+			resources:     []string{"components/other-component/overlays/dev", "components/test-component/overlays/dev"},
+			wantResources: []string{"components/other-component/overlays/dev"},
+		},
+		{
+			name:          "Unreferenced component leaves the parent kustomization as is",
+			resources:     []string{"components/other-component/overlays/dev", "components/test-component-2/overlays/dev"},
+			wantResources: []string{"components/other-component/overlays/dev", "components/test-component-2/overlays/dev"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			for _, componentName := range []string{"test-component", "other-component"} {
+				err := Generate(fs, repoPath, filepath.Join(repoPath, "components", componentName, "base"), gitopsv1alpha1.GeneratorOptions{Name: componentName, TargetPort: 5000})
+				testutils.AssertNoError(t, err)
+			}
+			k := resources.Kustomization{Resources: tt.resources}
+			testutils.AssertNoError(t, yaml.MarshalItemToFile(fs, filepath.Join(repoPath, kustomizeFileName), k))
+
+			outputStack := testutils.NewOutputs(
+				[]byte("test output1"),
+				[]byte("test output2"),
+				[]byte("test output3"),
+				[]byte("test output4 refs/heads/main"),
+				[]byte("test output5"),
+				[]byte("test output6"),
+				[]byte("test output7"),
+				[]byte("test output8"),
+				[]byte("test output9"),
+			)
+			executedCmds := []testutils.Execution{}
+			generator.execute = newTestExecute(outputStack, &testutils.ErrorStack{}, &executedCmds)
+
+			err := generator.GitRemoveComponentWithFs(outputPath, repo, "test-component", fs, branch, "/")
+			testutils.AssertNoError(t, err)
+
+			var got resources.Kustomization
+			testutils.AssertNoError(t, yaml.UnMarshalItemFromFile(fs, filepath.Join(repoPath, kustomizeFileName), &got))
+			assert.Equal(t, tt.wantResources, got.Resources)
+		})
+	}
+}
+
+func TestCommitAndPushWithContext(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	componentName := "test-component"
+	branch := "main"
+
+	tests := []struct {
+		name           string
+		commandTimeout time.Duration
+		cancelOn       string
+		wantCmds       []string
+		wantErr        error
+		wantErrString  string
+	}{
+		{
+			name:           "Command timeout interrupts a hung push",
+			commandTimeout: 10 * time.Millisecond,
+			wantCmds:       []string{"add", "--no-pager", "ls-remote", "commit", "push"},
+			wantErr:        context.DeadlineExceeded,
+			wantErrString:  "failed to push remote to repository \"https://github.com/testing/testing.git\"",
+		},
+		{
+			name:          "Cancellation stops the remaining commands",
+			cancelOn:      "commit",
+			wantCmds:      []string{"add", "--no-pager", "ls-remote", "commit"},
+			wantErr:       context.Canceled,
+			wantErrString: "failed to push remote to repository \"https://github.com/testing/testing.git\"",
+		},
+		{
+			name:          "Cancelled context runs no commands",
+			cancelOn:      "start",
+			wantErr:       context.Canceled,
+			wantErrString: "failed to add files for component \"test-component\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelOn == "start" {
+				cancel()
+			}
+
+			var executedCmds []string
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				// Skip the config options of the command
+				for len(args) > 2 && args[0] == "-c" {
+					args = args[2:]
+				}
+				executedCmds = append(executedCmds, args[0])
+				switch args[0] {
+				case "--no-pager":
+					return []byte("test diff"), nil
+				case "push":
+					// The push hangs until it's interrupted
+					select {
+					case <-time.After(time.Second):
+						return nil, nil
+					case <-ctx.Done():
+						return []byte(""), errors.New("signal: killed")
+					}
+				}
+				if args[0] == tt.cancelOn {
+					cancel()
+				}
+				return []byte(""), nil
+			}
+
+			generator.CommandTimeout = tt.commandTimeout
+			err := generator.CommitAndPushWithContext(ctx, outputPath, "", repo, componentName, branch, "test commit")
+
+			testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			assert.True(t, errors.Is(err, tt.wantErr), "the error should wrap %v, got %v", tt.wantErr, err)
+			assert.Equal(t, tt.wantCmds, executedCmds, "command executed should be equal")
+		})
+	}
+}
+
+func TestCloneGenerateAndPushWithContextCancelled(t *testing.T) {
+	executedCmds := []testutils.Execution{}
+	generator := NewGitopsGen()
+	generator.execute = newTestExecute(testutils.NewOutputs(), testutils.NewErrors(), &executedCmds)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	options := gitopsv1alpha1.GeneratorOptions{Name: "test-component"}
+	err := generator.CloneGenerateAndPushWithContext(ctx, "/fake/path", "https://github.com/testing/testing.git", options, ioutils.NewMemoryFilesystem(), "main", "/", true)
+
+	testutils.AssertErrorMatch(t, "failed to clone git repository \"/fake/path\"", err)
+	assert.True(t, errors.Is(err, context.Canceled), "the error should wrap the context error, got %v", err)
+	assert.Empty(t, executedCmds, "no command should be executed")
+}
+
+func TestCommitAndPushRetries(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	repoPath := "/fake/path/test-component"
+	componentName := "test-component"
+	branch := "main"
+
+	tests := []struct {
+		name          string
+		pushRetries   int
+		errors        *testutils.ErrorStack
+		outputs       [][]byte
+		want          []testutils.Execution
+		wantErrString string
+	}{
+		{
+			name:        "Rejected push fails without retries",
+			pushRetries: 0,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("exit status 1"),
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+				},
+			},
+			outputs: [][]byte{
+				[]byte(" ! [rejected]        main -> main (fetch first)"),
+				[]byte(""),
+				[]byte(""),
+				[]byte("refs/heads/main"),
+				[]byte("test diff"),
+				[]byte(""),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"add", "."},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"--no-pager", "diff", "--cached"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "test commit"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", branch},
+				},
+			},
+			wantErrString: "failed to push remote to repository \"https://github.com/testing/testing.git\"",
+		},
+		{
+			name:        "Rejected push succeeds after rebasing",
+			pushRetries: 2,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					nil,
+					nil,
+					errors.New("exit status 1"),
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+				},
+			},
+			outputs: [][]byte{
+				[]byte(""),
+				[]byte(""),
+				[]byte(" ! [rejected]        main -> main (fetch first)"),
+				[]byte(""),
+				[]byte(""),
+				[]byte("refs/heads/main"),
+				[]byte("test diff"),
+				[]byte(""),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"add", "."},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"--no-pager", "diff", "--cached"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "test commit"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull", "--rebase", "origin", branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
+			},
+			wantErrString: "",
+		},
+		{
+			name:        "Rejected push fails once the retries are exhausted",
+			pushRetries: 1,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					errors.New("exit status 1"),
+					nil,
+					errors.New("exit status 1"),
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+				},
+			},
+			outputs: [][]byte{
+				[]byte(" ! [rejected]        main -> main (fetch first)"),
+				[]byte(""),
+				[]byte(" ! [rejected]        main -> main (fetch first)"),
+				[]byte(""),
+				[]byte(""),
+				[]byte("refs/heads/main"),
+				[]byte("test diff"),
+				[]byte(""),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"add", "."},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"--no-pager", "diff", "--cached"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "test commit"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull", "--rebase", "origin", branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", branch},
+				},
+			},
+			wantErrString: "failed to push remote to repository \"https://github.com/testing/testing.git\"",
+		},
+		{
+			name:        "Conflicting rebase is aborted",
+			pushRetries: 2,
+			errors: &testutils.ErrorStack{
+				Errors: []error{
+					nil,
+					errors.New("exit status 1"),
+					errors.New("exit status 1"),
+					nil,
+					nil,
+					nil,
+					nil,
+					nil,
+				},
+			},
+			outputs: [][]byte{
+				[]byte(""),
+				[]byte("CONFLICT (content): Merge conflict in components/test-component/base/deployment.yaml"),
+				[]byte(" ! [rejected]        main -> main (fetch first)"),
+				[]byte(""),
+				[]byte(""),
+				[]byte("refs/heads/main"),
+				[]byte("test diff"),
+				[]byte(""),
+			},
+			want: []testutils.Execution{
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"add", "."},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"--no-pager", "diff", "--cached"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"ls-remote", "--heads", repo, branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "test commit"},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"push", "origin", branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"pull", "--rebase", "origin", branch},
+				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rebase", "--abort"},
+				},
+			},
+			wantErrString: "failed to rebase onto branch \"main\" of remote \"https://github.com/testing/testing.git\" due to conflicts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputStack := testutils.NewOutputs(tt.outputs...)
+			executedCmds := []testutils.Execution{}
+
+			generator := NewGitopsGen()
+			generator.execute = newTestExecute(outputStack, tt.errors, &executedCmds)
+			generator.PushRetries = tt.pushRetries
+			err := generator.CommitAndPush(outputPath, "", repo, componentName, branch, "test commit")
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+
+			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+		})
+	}
+}
+
+func TestCommitAndPushAuthor(t *testing.T) {
+	tests := []struct {
+		name   string
+		author Author
+		want   []string
+	}{
+		{
+			name: "Default author",
+			want: []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "test commit"},
+		},
+		{
+			name: "Custom author",
+			author: Author{
+				Name:  "Test User",
+				Email: "test@test.org",
+			},
+			want: []string{"-c", "user.name=Test User", "-c", "user.email=test@test.org", "commit", "-m", "test commit"},
+		},
+		{
+			name: "Custom author without an email",
+			author: Author{
+				Name: "Test User",
+			},
+			want: []string{"-c", "user.name=Test User", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "test commit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputStack := testutils.NewOutputs([]byte(""), []byte(""), []byte(""), []byte(""), []byte("test diff"), []byte(""))
+			executedCmds := []testutils.Execution{}
+
+			generator := NewGitopsGen()
+			generator.execute = newTestExecute(outputStack, testutils.NewErrors(), &executedCmds)
+			generator.Author = tt.author
+			err := generator.CommitAndPush("/fake/path", "", "https://github.com/testing/testing.git", "test-component", "main", "test commit")
+
+			testutils.AssertNoError(t, err)
+			assert.Equal(t, tt.want, executedCmds[3].Args, "commit command should be equal")
+		})
+	}
+}
+
+func TestCommitAndPushSigned(t *testing.T) {
+	author := []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com"}
+	tests := []struct {
+		name          string
+		signCommits   *SigningKey
+		commitErr     error
+		commitOutput  string
+		want          []string
+		wantSignErr   bool
+		wantErrString string
+	}{
+		{
+			name:        "Signed with a gpg key",
+			signCommits: &SigningKey{Key: "3AA5C34371567BD2"},
+			want:        append(append([]string{}, author...), "-c", "gpg.format=gpg", "-c", "user.signingkey=3AA5C34371567BD2", "commit", "-S3AA5C34371567BD2", "-m", "test commit"),
+		},
+		{
+			name: "Signed with an ssh key",
+			signCommits: &SigningKey{
+				Format: "ssh",
+				Key:    "/home/user/.ssh/id_ed25519.pub",
+			},
+			want: append(append([]string{}, author...), "-c", "gpg.format=ssh", "-c", "user.signingkey=/home/user/.ssh/id_ed25519.pub", "commit", "-S/home/user/.ssh/id_ed25519.pub", "-m", "test commit"),
+		},
+		{
+			name:        "Signed with the default key",
+			signCommits: &SigningKey{},
+			want:        append(append([]string{}, author...), "-c", "gpg.format=gpg", "commit", "-S", "-m", "test commit"),
+		},
+		{
+			name:          "Signing failure",
+			signCommits:   &SigningKey{Key: "3AA5C34371567BD2"},
+			commitErr:     errors.New("exit status 128"),
+			commitOutput:  "error: gpg failed to sign the data\nfatal: failed to write commit object",
+			want:          append(append([]string{}, author...), "-c", "gpg.format=gpg", "-c", "user.signingkey=3AA5C34371567BD2", "commit", "-S3AA5C34371567BD2", "-m", "test commit"),
+			wantSignErr:   true,
+			wantErrString: "failed to sign the commit in repository \"/fake/path/test-component\"",
+		},
+		{
+			name: "ssh signing failure",
+			signCommits: &SigningKey{
+				Format: "ssh",
+				Key:    "/home/user/.ssh/id_ed25519.pub",
+			},
+			commitErr:     errors.New("exit status 128"),
+			commitOutput:  "Load key \"/home/user/.ssh/id_ed25519\": incorrect passphrase supplied to decrypt private key?\nfatal: failed to write commit object",
+			want:          append(append([]string{}, author...), "-c", "gpg.format=ssh", "-c", "user.signingkey=/home/user/.ssh/id_ed25519.pub", "commit", "-S/home/user/.ssh/id_ed25519.pub", "-m", "test commit"),
+			wantSignErr:   true,
+			wantErrString: "failed to sign the commit in repository \"/fake/path/test-component\"",
+		},
+		{
+			name:          "Signed commit failure unrelated to signing",
+			signCommits:   &SigningKey{Key: "3AA5C34371567BD2"},
+			commitErr:     errors.New("exit status 1"),
+			commitOutput:  "pre-commit hook: the design of the component is invalid, see the signature of the manifests",
+			want:          append(append([]string{}, author...), "-c", "gpg.format=gpg", "-c", "user.signingkey=3AA5C34371567BD2", "commit", "-S3AA5C34371567BD2", "-m", "test commit"),
+			wantErrString: "failed to commit files to repository \"/fake/path/test-component\"",
+		},
+		{
+			name:          "Signed commit failure writing the commit object",
+			signCommits:   &SigningKey{Key: "3AA5C34371567BD2"},
+			commitErr:     errors.New("exit status 128"),
+			commitOutput:  "error: insufficient permission for adding an object to repository database .git/objects\nfatal: failed to write commit object",
+			want:          append(append([]string{}, author...), "-c", "gpg.format=gpg", "-c", "user.signingkey=3AA5C34371567BD2", "commit", "-S3AA5C34371567BD2", "-m", "test commit"),
+			wantErrString: "failed to commit files to repository \"/fake/path/test-component\"",
+		},
+		{
+			name:          "Unsigned commit failure",
+			commitErr:     errors.New("exit status 1"),
+			commitOutput:  "error: unable to commit",
+			want:          append(append([]string{}, author...), "commit", "-m", "test commit"),
+			wantErrString: "failed to commit files to repository \"/fake/path/test-component\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputStack := testutils.NewOutputs([]byte(""), []byte(tt.commitOutput), []byte(""), []byte("test diff"), []byte(""))
+			errorStack := &testutils.ErrorStack{
+				Errors: []error{
+					nil,
+					tt.commitErr,
+					nil,
+					nil,
+					nil,
+				},
+			}
+			executedCmds := []testutils.Execution{}
+
+			generator := NewGitopsGen()
+			generator.execute = newTestExecute(outputStack, errorStack, &executedCmds)
+			generator.SignCommits = tt.signCommits
+			err := generator.CommitAndPush("/fake/path", "", "https://github.com/testing/testing.git", "test-component", "main", "test commit")
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			var signErr *GitSignCommitError
+			assert.Equal(t, tt.wantSignErr, errors.As(err, &signErr), "the error should be a signing error")
+			assert.Equal(t, tt.want, executedCmds[3].Args, "commit command should be equal")
+		})
+	}
+}
+
+func TestCommitMessageTemplate(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	componentName := "test-component"
+	applicationName := "test-application"
+	environmentName := "staging"
+	branch := "main"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name:        componentName,
+		Application: applicationName,
+	}
+	template := "[OPS-123] {{.Operation}} {{.Component}}{{with .Application}} of {{.}}{{end}}{{with .Environment}} in {{.}}{{end}}\n\nGenerated-By: gitops-generator"
+
+	tests := []struct {
+		name          string
+		template      string
+		operation     func(generator Gen) error
+		wantMessage   string
+		wantErrString string
+	}{
+		{
+			name:     "Base resources with the template",
+			template: template,
+			operation: func(generator Gen) error {
+				return generator.CloneGenerateAndPush(outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", true)
+			},
+			wantMessage: "[OPS-123] GenerateBase test-component of test-application\n\nGenerated-By: gitops-generator",
+		},
+		{
+			name:     "Overlays with the template",
+			template: template,
+			operation: func(generator Gen) error {
+				return generator.GenerateOverlaysAndPush(outputPath, true, repo, component, applicationName, environmentName, "image", "namespace", ioutils.NewMemoryFilesystem(), branch, "/", true, nil)
+			},
+			wantMessage: "[OPS-123] GenerateOverlays test-component of test-application in staging\n\nGenerated-By: gitops-generator",
+		},
+		{
+			name:     "Component removal with the template",
+			template: template,
+			operation: func(generator Gen) error {
+				return generator.GitRemoveComponent(outputPath, repo, componentName, branch, "/")
+			},
+			wantMessage: "[OPS-123] RemoveComponent test-component\n\nGenerated-By: gitops-generator",
+		},
+		{
+			name: "Overlays without a template",
+			operation: func(generator Gen) error {
+				return generator.GenerateOverlaysAndPush(outputPath, true, repo, component, applicationName, environmentName, "image", "namespace", ioutils.NewMemoryFilesystem(), branch, "/", true, nil)
+			},
+			wantMessage: "Generate staging environment overlays for component test-component",
+		},
+		{
+			name:     "Invalid template",
+			template: "{{.Component",
+			operation: func(generator Gen) error {
+				return generator.CloneGenerateAndPush(outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", true)
+			},
+			wantErrString: "failed to parse the commit message template",
+		},
+		{
+			name:     "Template with an unknown field",
+			template: "{{.Ticket}}",
+			operation: func(generator Gen) error {
+				return generator.GitRemoveComponent(outputPath, repo, componentName, branch, "/")
+			},
+			wantErrString: "failed to execute the commit message template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds [][]string
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, args)
+				if len(args) > 0 && args[0] == "--no-pager" {
+					return []byte("test diff"), nil
+				}
+				return []byte(""), nil
+			}
+
+			generator.CommitMessageTemplate = tt.template
+			err := tt.operation(generator)
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				assert.Empty(t, executedCmds, "no command should be executed")
+				return
+			}
+			testutils.AssertNoError(t, err)
+			var message string
+			for _, args := range executedCmds {
+				for i, arg := range args {
+					if arg == "-m" && i+1 < len(args) {
+						message = args[i+1]
+					}
+				}
+			}
+			assert.Equal(t, tt.wantMessage, message, "commit message should be equal")
+		})
+	}
+}
+
+func TestCommitTrailers(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	componentName := "test-component"
+	applicationName := "test-application"
+	branch := "main"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name:        componentName,
+		Application: applicationName,
+	}
+	trailers := []string{
+		"Co-authored-by: Jane Doe <jane@example.com>",
+		"Signed-off-by: John Doe <john@example.com>",
+	}
+
+	tests := []struct {
+		name        string
+		template    string
+		trailers    []string
+		operation   func(generator Gen) error
+		wantMessage string
+	}{
+		{
+			name:     "Commit with trailers",
+			trailers: trailers,
+			operation: func(generator Gen) error {
+				return generator.CommitAndPush(outputPath, "", repo, componentName, branch, "Update component")
+			},
+			wantMessage: "Update component\n\nCo-authored-by: Jane Doe <jane@example.com>\nSigned-off-by: John Doe <john@example.com>",
+		},
+		{
+			name:     "Templated commit with trailers",
+			template: "[OPS-123] {{.Operation}} {{.Component}}\n",
+			trailers: trailers,
+			operation: func(generator Gen) error {
+				return generator.CloneGenerateAndPush(outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", true)
+			},
+			wantMessage: "[OPS-123] GenerateBase test-component\n\nCo-authored-by: Jane Doe <jane@example.com>\nSigned-off-by: John Doe <john@example.com>",
+		},
+		{
+			name: "Commit without trailers",
+			operation: func(generator Gen) error {
+				return generator.CommitAndPush(outputPath, "", repo, componentName, branch, "Update component")
+			},
+			wantMessage: "Update component",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds [][]string
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, args)
+				if len(args) > 0 && args[0] == "--no-pager" {
+					return []byte("test diff"), nil
+				}
+				return []byte(""), nil
+			}
+
+			generator.CommitMessageTemplate = tt.template
+			generator.CommitTrailers = tt.trailers
+			err := tt.operation(generator)
+
+			testutils.AssertNoError(t, err)
+			var message string
+			for _, args := range executedCmds {
+				for i, arg := range args {
+					if arg == "-m" && i+1 < len(args) {
+						message = args[i+1]
+					}
+				}
+			}
+			assert.Equal(t, tt.wantMessage, message, "commit message should be equal")
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	componentName := "test-component"
+	applicationName := "test-application"
+	branch := "main"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name:        componentName,
+		Application: applicationName,
+	}
+	diff := "+  url: https://ghp_fj3492danj924@github.com/testing/testing.git"
+
+	tests := []struct {
+		name          string
+		operation     func(generator Gen, fs afero.Afero) (PushResult, error)
+		checkout      bool
+		rmErr         error
+		wantDiff      string
+		wantCleanUp   []string
+		wantErrString string
+	}{
+		{
+			name: "Base resources dry run",
+			operation: func(generator Gen, fs afero.Afero) (PushResult, error) {
+				return generator.CloneGenerateAndPushWithResult(context.Background(), outputPath, repo, component, fs, branch, "/", true)
+			},
+			wantDiff:    "+  url: https://<TOKEN>@github.com/testing/testing.git",
+			wantCleanUp: []string{"-rf", componentName},
+		},
+		{
+			name: "Overlays dry run without a push",
+			operation: func(generator Gen, fs afero.Afero) (PushResult, error) {
+				return generator.GenerateOverlaysAndPushWithResult(context.Background(), outputPath, true, repo, component, applicationName, "staging", "image", "namespace", fs, branch, "/", false, nil)
+			},
+			wantDiff:    "+  url: https://<TOKEN>@github.com/testing/testing.git",
+			wantCleanUp: []string{"-rf", applicationName},
+		},
+		{
+			name: "Base resources dry run reusing an existing checkout",
+			operation: func(generator Gen, fs afero.Afero) (PushResult, error) {
+				return generator.CloneGenerateAndPushWithResult(context.Background(), outputPath, repo, component, fs, branch, "/", true)
+			},
+			checkout: true,
+			wantDiff: "+  url: https://<TOKEN>@github.com/testing/testing.git",
+		},
+		{
+			name: "Overlays dry run reusing an existing checkout",
+			operation: func(generator Gen, fs afero.Afero) (PushResult, error) {
+				return generator.GenerateOverlaysAndPushWithResult(context.Background(), outputPath, true, repo, component, applicationName, "staging", "image", "namespace", fs, branch, "/", false, nil)
+			},
+			checkout: true,
+			wantDiff: "+  url: https://<TOKEN>@github.com/testing/testing.git",
+		},
+		{
+			name: "Overlays dry run of a repository that isn't cloned",
+			operation: func(generator Gen, fs afero.Afero) (PushResult, error) {
+				return generator.GenerateOverlaysAndPushWithResult(context.Background(), outputPath, false, repo, component, applicationName, "staging", "image", "namespace", fs, branch, "/", true, nil)
+			},
+			wantDiff: "+  url: https://<TOKEN>@github.com/testing/testing.git",
+		},
+		{
+			name: "Dry run failing to delete the repository",
+			operation: func(generator Gen, fs afero.Afero) (PushResult, error) {
+				return generator.CloneGenerateAndPushWithResult(context.Background(), outputPath, repo, component, fs, branch, "/", true)
+			},
+			rmErr:         errors.New("Permission denied"),
+			wantCleanUp:   []string{"-rf", componentName},
+			wantErrString: "failed to delete \"test-component\" folder in repository in \"/fake/path\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds [][]string
+			var cleanUp []string
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, args)
+				if cmd == RmCommand && baseDir == outputPath {
+					cleanUp = args
+					return []byte(""), tt.rmErr
+				}
+				if len(args) > 0 && args[0] == "--no-pager" {
+					return []byte(diff), nil
+				}
+				if len(args) > 0 && args[0] == "remote" {
+					return []byte(repo + "\n"), nil
+				}
+				if len(args) > 0 && args[0] == "write-tree" {
+					return []byte("4b825dc642cb6eb9a060e54bf8d69288fbee4904\n"), nil
+				}
+				return []byte(""), nil
+			}
+
+			fs := ioutils.NewMemoryFilesystem()
+			if tt.checkout {
+				for _, folder := range []string{componentName, applicationName} {
+					testutils.AssertNoError(t, fs.MkdirAll(filepath.Join(outputPath, folder, ".git"), 0750))
+				}
+			}
+			generator.DryRun = true
+			result, err := tt.operation(generator, fs)
+
+			for _, args := range executedCmds {
+				for _, arg := range args {
+					assert.NotEqual(t, "commit", arg, "no commit should be executed in a dry run")
+					assert.NotEqual(t, "push", arg, "no push should be executed in a dry run")
+				}
+			}
+			assert.Equal(t, tt.wantCleanUp, cleanUp, "the repository deletion should be equal")
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				return
+			}
+			testutils.AssertNoError(t, err)
+			assert.Equal(t, tt.wantDiff, result.Diff, "diff should be equal")
+			for i, args := range executedCmds {
+				if len(args) > 0 && args[0] == "--no-pager" {
+					assert.Equal(t, []string{"read-tree", "4b825dc642cb6eb9a060e54bf8d69288fbee4904"}, executedCmds[i+1], "the index should be restored once diffed")
+				}
+			}
+		})
+	}
+}
+
+func TestDryRunKeepsIndex(t *testing.T) {
+	fs := ioutils.NewFilesystem()
+	outputPath, err := fs.TempDir(os.TempDir(), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = fs.RemoveAll(outputPath)
+	}()
+	applicationName := "test-application"
+	repoPath := filepath.Join(outputPath, applicationName)
+	if err := fs.MkdirAll(repoPath, 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := createEmptyGitRepository(repoPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	git := func(args ...string) string {
+		out, err := executeCommand(context.Background(), commandOptions{}, repoPath, GitCommand, args...)
+		if err != nil {
+			t.Fatalf("unexpected error: %s %v", out, err)
+		}
+		return string(out)
+	}
+
+	// A file staged before the dry run stays staged, and the generated resources aren't
+	if err := fs.WriteFile(filepath.Join(repoPath, "staged.txt"), []byte("staged"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	git("add", "staged.txt")
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name:           "test-component",
+		Application:    applicationName,
+		ContainerImage: "quay.io/test/test-image:latest",
+	}
+	if err := Generate(fs, repoPath, filepath.Join(repoPath, "components", "test-component", "base"), component); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantIndex := git("ls-files", "--stage")
+
+	generator := NewGitopsGen()
+	generator.execute = executeCommand
+	generator.DryRun = true
+	result, err := generator.GenerateOverlaysAndPushWithResult(context.Background(), outputPath, false, "https://github.com/testing/testing.git", component, applicationName, "staging", "quay.io/test/test-image:v2", "namespace", fs, "main", "/", true, nil)
+
+	testutils.AssertNoError(t, err)
+	assert.Contains(t, result.Diff, "components/test-component/overlays/staging/kustomization.yaml", "the diff should contain the overlays")
+	assert.Equal(t, wantIndex, git("ls-files", "--stage"), "the index should be unchanged")
+	assert.Contains(t, git("status", "--porcelain"), "?? components/", "the generated resources should be left unstaged")
+}
+
+func TestCommitAndPushWithResult(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	componentName := "test-component"
+	applicationName := "test-application"
+	branch := "main"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name:        componentName,
+		Application: applicationName,
+	}
+	sha := "0e3ad5b1c2f9d7f8a3b4c5d6e7f8a9b0c1d2e3f4"
+
+	tests := []struct {
+		name          string
+		operation     func(generator Gen) (PushResult, error)
+		diff          string
+		revParseErr   error
+		wantSHA       string
+		wantRevParse  bool
+		wantErrString string
+	}{
+		{
+			name: "Commit SHA of the pushed commit",
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.CommitAndPushWithResult(context.Background(), outputPath, "", repo, componentName, branch, "test commit")
+			},
+			diff:         "test diff",
+			wantSHA:      sha,
+			wantRevParse: true,
+		},
+		{
+			name: "No commit SHA when there's nothing to commit",
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.CommitAndPushWithResult(context.Background(), outputPath, "", repo, componentName, branch, "test commit")
+			},
+		},
+		{
+			name: "Commit SHA of the base resources",
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.CloneGenerateAndPushWithResult(context.Background(), outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", true)
+			},
+			diff:         "test diff",
+			wantSHA:      sha,
+			wantRevParse: true,
+		},
+		{
+			name: "Commit SHA of the overlays",
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.GenerateOverlaysAndPushWithResult(context.Background(), outputPath, true, repo, component, applicationName, "staging", "image", "namespace", ioutils.NewMemoryFilesystem(), branch, "/", true, nil)
+			},
+			diff:         "test diff",
+			wantSHA:      sha,
+			wantRevParse: true,
+		},
+		{
+			name: "Failure retrieving the commit SHA",
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.CommitAndPushWithResult(context.Background(), outputPath, "", repo, componentName, branch, "test commit")
+			},
+			diff:          "test diff",
+			revParseErr:   errors.New("Permission denied"),
+			wantRevParse:  true,
+			wantErrString: "failed to retrieve commit id for repository \"/fake/path/test-component\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revParsed := false
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				if len(args) > 0 && args[0] == "--no-pager" {
+					return []byte(tt.diff), nil
+				}
+				if len(args) > 0 && args[0] == "rev-parse" {
+					revParsed = true
+					return []byte(sha + "\n"), tt.revParseErr
+				}
+				return []byte(""), nil
+			}
+
+			result, err := tt.operation(generator)
+			assert.Equal(t, tt.wantRevParse, revParsed, "whether the commit SHA is retrieved should be equal")
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				return
+			}
+			testutils.AssertNoError(t, err)
+			assert.Equal(t, tt.wantSHA, result.CommitSHA, "commit SHA should be equal")
+		})
+	}
+}
+
+func TestCommitAndPushModes(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	componentName := "test-component"
+	branch := "preview-pr-42"
+
+	tests := []struct {
+		name          string
+		pushMode      PushMode
+		wantPushArgs  []string
+		wantErrString string
+	}{
+		{
+			name:         "Default push mode",
+			wantPushArgs: []string{"push", "origin", branch},
+		},
+		{
+			name:         "Normal push",
+			pushMode:     PushModeNormal,
+			wantPushArgs: []string{"push", "origin", branch},
+		},
+		{
+			name:         "Force push with lease",
+			pushMode:     PushModeForceWithLease,
+			wantPushArgs: []string{"push", "--force-with-lease", "origin", branch},
+		},
+		{
+			name:         "Force push",
+			pushMode:     PushModeForce,
+			wantPushArgs: []string{"push", "--force", "origin", branch},
+		},
+		{
+			name:          "Unsupported push mode",
+			pushMode:      PushMode("mirror"),
+			wantErrString: "unsupported push mode \"mirror\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pushArgs []string
+			executed := false
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executed = true
+				if len(args) > 0 && args[0] == "--no-pager" {
+					return []byte("test diff"), nil
+				}
+				if len(args) > 0 && args[0] == "push" {
+					pushArgs = args
+				}
+				return []byte(""), nil
+			}
+
+			generator.PushMode = tt.pushMode
+			err := generator.CommitAndPush(outputPath, "", repo, componentName, branch, "test commit")
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				assert.False(t, executed, "no command should be executed")
+				return
+			}
+			testutils.AssertNoError(t, err)
+			assert.Equal(t, tt.wantPushArgs, pushArgs, "push arguments should be equal")
+		})
+	}
+}
+
+func TestCommitAndPushRemotes(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	mirror := "https://gitlab.com/testing/mirror.git"
+	backup := "https://github.com/testing/backup.git"
+	outputPath := "/fake/path"
+	repoPath := "/fake/path/test-component"
+	componentName := "test-component"
+	branch := "main"
+	sha := "0e3ad5b1c2f9d7f8a3b4c5d6e7f8a9b0c1d2e3f4"
+	commitArgs := []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "test commit"}
+
+	type response struct {
+		output string
+		err    error
+	}
+	tests := []struct {
+		name              string
+		remoteName        string
+		additionalRemotes []Remote
+		responses         map[string]response
+		want              []testutils.Execution
+		wantSHA           string
+		wantErrString     string
+	}{
+		{
+			name:       "Push to a named remote",
+			remoteName: "upstream",
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"add", "."}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"ls-remote", "--heads", repo, branch}},
+				{BaseDir: repoPath, Command: "git", Args: commitArgs},
+				{BaseDir: repoPath, Command: "git", Args: []string{"push", "upstream", branch}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"rev-parse", "HEAD"}},
+			},
+			wantSHA: sha,
+		},
+		{
+			name:              "Push to the additional remotes once the push to the remote succeeds",
+			additionalRemotes: []Remote{{Name: "mirror", URL: mirror}, {Name: "backup", URL: backup}},
+			responses: map[string]response{
+				"remote add backup " + backup: {output: "error: remote backup already exists.", err: errors.New("exit status 3")},
+			},
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"add", "."}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"ls-remote", "--heads", repo, branch}},
+				{BaseDir: repoPath, Command: "git", Args: commitArgs},
+				{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", branch}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"rev-parse", "HEAD"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "add", "mirror", mirror}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"push", "mirror", branch}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "add", "backup", backup}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "set-url", "backup", backup}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"push", "backup", branch}},
+			},
+			wantSHA: sha,
+		},
+		{
+			name:              "Failure pushing to an additional remote",
+			additionalRemotes: []Remote{{Name: "mirror", URL: mirror}, {Name: "backup", URL: backup}},
+			responses: map[string]response{
+				"push mirror " + branch: {output: "fatal: unable to access", err: errors.New("exit status 128")},
+			},
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"add", "."}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"ls-remote", "--heads", repo, branch}},
+				{BaseDir: repoPath, Command: "git", Args: commitArgs},
+				{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", branch}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"rev-parse", "HEAD"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "add", "mirror", mirror}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"push", "mirror", branch}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "add", "backup", backup}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"push", "backup", branch}},
+			},
+			wantSHA:       sha,
+			wantErrString: "failed to push to the additional remote \"mirror\"",
+		},
+		{
+			name:              "Failure pushing to the remote",
+			additionalRemotes: []Remote{{Name: "mirror", URL: mirror}},
+			responses: map[string]response{
+				"push origin " + branch: {output: "fatal: unable to access", err: errors.New("exit status 128")},
+			},
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"add", "."}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"ls-remote", "--heads", repo, branch}},
+				{BaseDir: repoPath, Command: "git", Args: commitArgs},
+				{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", branch}},
+			},
+			wantErrString: "failed to push remote to repository",
+		},
+		{
+			name:              "Invalid additional remote",
+			additionalRemotes: []Remote{{Name: "mirror", URL: "http://example.com/testing/mirror.git"}},
+			wantErrString:     "remote URL is invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds []testutils.Execution
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+				if r, ok := tt.responses[strings.Join(args, " ")]; ok {
+					return []byte(r.output), r.err
+				}
+				switch args[0] {
+				case "--no-pager":
+					return []byte("test diff"), nil
+				case "rev-parse":
+					return []byte(sha), nil
+				}
+				return []byte(""), nil
+			}
+
+			generator.RemoteName = tt.remoteName
+			generator.AdditionalRemotes = tt.additionalRemotes
+			result, err := generator.CommitAndPushWithResult(context.Background(), outputPath, "", repo, componentName, branch, "test commit")
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+			assert.Equal(t, tt.wantSHA, result.CommitSHA, "commit SHA should be equal")
+		})
+	}
+}
+
+func TestPullRequest(t *testing.T) {
+	repo := "https://ghp_fj3492danj924@github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	componentName := "test-component"
+	applicationName := "test-application"
+	branch := "main"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name:        componentName,
+		Application: applicationName,
+	}
+	commitArgs := func(message string) []string {
+		return []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", message}
+	}
+
+	tests := []struct {
+		name          string
+		options       PullRequestOptions
+		operation     func(generator Gen) (PushResult, error)
+		diff          string
+		clientErr     error
+		wantCmds      [][]string
+		wantHead      string
+		wantPR        *scm.PullRequestInput
+		wantErrString string
+	}{
+		{
+			name:    "Pull request of the base resources",
+			options: PullRequestOptions{BranchTemplate: "gitops-gen/{{.Component}}"},
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.CloneGenerateAndPushWithResult(context.Background(), outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", true)
+			},
+			diff: "test diff",
+			wantCmds: [][]string{
+				{"clone", "--branch", branch, "--single-branch", repo, componentName},
+				{"-rf", "components/test-component/base"},
+				{"add", "."},
+				{"--no-pager", "diff", "--cached"},
+				{"ls-remote", "--heads", repo, branch},
+				{"checkout", "-b", "gitops-gen/test-component"},
+				commitArgs("Generate GitOps base resources for component test-component"),
+				{"push", "origin", "gitops-gen/test-component"},
+				{"rev-parse", "HEAD"},
+			},
+			wantPR: &scm.PullRequestInput{
+				Title: "Generate GitOps base resources for component test-component",
+				Head:  "gitops-gen/test-component",
+				Base:  branch,
+			},
+		},
+		{
+			name: "Pull request of the overlays with templates",
+			options: PullRequestOptions{
+				BranchTemplate: "gitops-gen/{{.Component}}-{{.Environment}}",
+				TitleTemplate:  "Update {{.Component}} in {{.Environment}}",
+				BodyTemplate:   "Generated overlays of {{.Application}} against {{.Branch}}",
+			},
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.GenerateOverlaysAndPushWithResult(context.Background(), outputPath, false, repo, component, applicationName, "staging", "image", "namespace", ioutils.NewMemoryFilesystem(), branch, "/", true, nil)
+			},
+			diff: "test diff",
+			wantCmds: [][]string{
+				{"add", "."},
+				{"--no-pager", "diff", "--cached"},
+				{"ls-remote", "--heads", repo, branch},
+				{"checkout", "-b", "gitops-gen/test-component-staging"},
+				commitArgs("Generate staging environment overlays for component test-component"),
+				{"push", "origin", "gitops-gen/test-component-staging"},
+				{"rev-parse", "HEAD"},
+			},
+			wantPR: &scm.PullRequestInput{
+				Title: "Update test-component in staging",
+				Head:  "gitops-gen/test-component-staging",
+				Base:  branch,
+				Body:  "Generated overlays of test-application against main",
+			},
+		},
+		{
+			name: "Pull request of the component removal from the default branch",
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.GitRemoveComponentWithResult(context.Background(), outputPath, repo, componentName, ioutils.NewMemoryFilesystem(), branch, "/")
+			},
+			diff:     "test diff",
+			wantHead: `^gitops-gen/test-component-\d{14}$`,
+		},
+		{
+			name:    "No pull request when there's nothing to commit",
+			options: PullRequestOptions{BranchTemplate: "gitops-gen/{{.Component}}"},
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.CommitAndPushWithResult(context.Background(), outputPath, "", repo, componentName, branch, "test commit")
+			},
+			wantCmds: [][]string{
+				{"add", "."},
+				{"--no-pager", "diff", "--cached"},
+			},
+		},
+		{
+			name:    "Failure opening the pull request",
+			options: PullRequestOptions{BranchTemplate: "gitops-gen/{{.Component}}"},
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.CommitAndPushWithResult(context.Background(), outputPath, "", repo, componentName, branch, "test commit")
+			},
+			diff:          "test diff",
+			clientErr:     errors.New("401 Unauthorized"),
+			wantErrString: "failed to open a pull request from branch \"gitops-gen/test-component\" to branch \"main\" in repository \"https://<TOKEN>@github.com/testing/testing.git\": 401 Unauthorized",
+		},
+		{
+			name:    "Invalid branch template",
+			options: PullRequestOptions{BranchTemplate: "gitops-gen/{{.Ticket}}"},
+			operation: func(generator Gen) (PushResult, error) {
+				return generator.CloneGenerateAndPushWithResult(context.Background(), outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", true)
+			},
+			wantCmds:      [][]string{},
+			wantErrString: "failed to execute the pull request branch template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executedCmds := [][]string{}
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, args)
+				if len(args) > 0 && args[0] == "--no-pager" {
+					return []byte(tt.diff), nil
+				}
+				return []byte(""), nil
+			}
+			client, data := fake.NewDefault()
+			var clientURL string
+			newSCMClient = func(repoURL string) (*scm.Client, error) {
+				clientURL = repoURL
+				return client, tt.clientErr
+			}
+
+			generator.PullRequest = &tt.options
+			result, err := tt.operation(generator)
+
+			if tt.wantCmds != nil {
+				assert.Equal(t, tt.wantCmds, executedCmds, "command executed should be equal")
+			}
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				assert.NotContains(t, err.Error(), "ghp_fj3492danj924", "the token should be sanitized")
+				return
+			}
+			testutils.AssertNoError(t, err)
+			if tt.diff == "" {
+				assert.Empty(t, data.PullRequestsCreated, "no pull request should be opened")
+				assert.Zero(t, result.PullRequestNumber, "pull request number should be empty")
+				return
+			}
+			assert.Equal(t, "https://:ghp_fj3492danj924@github.com/testing/testing.git", clientURL, "client URL should be equal")
+			assert.Equal(t, 1, result.PullRequestNumber, "pull request number should be equal")
+			created := data.PullRequestsCreated[result.PullRequestNumber]
+			if tt.wantHead != "" {
+				assert.Regexp(t, tt.wantHead, created.Head, "pull request branch should match")
+				assert.Equal(t, "Removed component test-component", created.Title, "pull request title should be equal")
+				return
+			}
+			assert.Equal(t, tt.wantPR, created, "pull request should be equal")
+		})
+	}
+	newSCMClient = factory.FromRepoURL
+}
+
+func TestSSHRemote(t *testing.T) {
+	repo := "git@github.com:testing/testing.git"
+	outputPath := "/fake/path"
+	componentName := "test-component"
+	branch := "main"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+	}
+
+	tests := []struct {
+		name           string
+		sshKeyPath     string
+		knownHostsPath string
+		env            map[string]string
+		pullRequest    *PullRequestOptions
+		wantSSHCommand string
+		wantEnv        map[string]string
+		wantErrString  string
+	}{
+		{
+			name:           "Clone and push with the ssh key and known hosts",
+			sshKeyPath:     "/etc/keys/id_ed25519",
+			knownHostsPath: "/etc/ssh/known_hosts",
+			env:            map[string]string{"HTTPS_PROXY": "http://proxy:3128"},
+			wantSSHCommand: "ssh -i '/etc/keys/id_ed25519' -o IdentitiesOnly=yes -o UserKnownHostsFile='/etc/ssh/known_hosts' -o StrictHostKeyChecking=yes",
+			wantEnv:        map[string]string{"HTTPS_PROXY": "http://proxy:3128"},
+		},
+		{
+			name:           "Clone and push with the ssh key of a path to quote",
+			sshKeyPath:     "/etc/keys/it's key",
+			wantSSHCommand: "ssh -i '/etc/keys/it'\\''s key' -o IdentitiesOnly=yes",
+		},
+		{
+			name: "Clone and push with the ssh configuration of the environment",
+		},
+		{
+			name:          "Pull request from an ssh remote",
+			sshKeyPath:    "/etc/keys/id_ed25519",
+			pullRequest:   &PullRequestOptions{},
+			wantErrString: "failed to open a pull request, the ssh remote \"git@github.com:testing/testing.git\" doesn't have a token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executed []string
+			sshCommands := map[string]string{}
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executed = append(executed, args[0])
+				sshCommands[args[0]] = options.env["GIT_SSH_COMMAND"]
+				for key, value := range tt.wantEnv {
+					assert.Equal(t, value, options.env[key], "environment variable %s should be equal", key)
+				}
+				if args[0] == "--no-pager" {
+					return []byte("test diff"), nil
+				}
+				return []byte(""), nil
+			}
+
+			generator.SSHKeyPath = tt.sshKeyPath
+			generator.KnownHostsPath = tt.knownHostsPath
+			generator.Env = tt.env
+			generator.PullRequest = tt.pullRequest
+			err := generator.CloneGenerateAndPush(outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", true)
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				assert.NotContains(t, executed, "push", "no push should be executed")
+				return
+			}
+			testutils.AssertNoError(t, err)
+			assert.Contains(t, executed, "push", "push should be executed")
+			for _, command := range []string{"clone", "push"} {
+				assert.Equal(t, tt.wantSSHCommand, sshCommands[command], "GIT_SSH_COMMAND of the %s should be equal", command)
+			}
+			assert.NotContains(t, tt.env, "GIT_SSH_COMMAND", "the environment of the generator should be unchanged")
+		})
+	}
+}
+
+func TestAuthModeHeader(t *testing.T) {
+	token := "ghp_fj3492danj924"
+	repo := "https://" + token + "@github.com/testing/testing.git"
+	mirror := "https://" + token + "@gitlab.com/testing/mirror.git"
+	outputPath := "/fake/path"
+	repoPath := "/fake/path/test-component"
+	componentName := "test-component"
+	branch := "main"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+	}
+	githubHeader := "http.extraHeader=Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("x-access-token:"+token))
+	gitlabHeader := "http.extraHeader=Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("oauth2:"+token))
+
+	var executedCmds []testutils.Execution
+	generator := NewGitopsGen()
+	generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+		executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+		for _, arg := range args {
+			if arg == "--no-pager" {
+				return []byte("test diff"), nil
+			}
+			if arg == "ls-remote" {
+				return []byte("refs/heads/main"), nil
+			}
+		}
+		return []byte(""), nil
+	}
+
+	generator.AuthMode = AuthModeHeader
+	generator.AdditionalRemotes = []Remote{{Name: "mirror", URL: mirror}}
+	err := generator.CloneGenerateAndPush(outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", true)
+	testutils.AssertNoError(t, err)
+
+	want := []testutils.Execution{
+		{BaseDir: outputPath, Command: "git", Args: []string{"-c", githubHeader, "clone", "--branch", branch, "--single-branch", "https://github.com/testing/testing.git", componentName}},
+		{BaseDir: repoPath, Command: "rm", Args: []string{"-rf", "components/test-component/base"}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"add", "."}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"-c", githubHeader, "ls-remote", "--heads", "https://github.com/testing/testing.git", branch}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"-c", githubHeader, "pull"}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "Generate GitOps base resources for component test-component"}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"-c", githubHeader, "push", "origin", branch}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"rev-parse", "HEAD"}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"remote", "add", "mirror", "https://gitlab.com/testing/mirror.git"}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"-c", gitlabHeader, "push", "mirror", branch}},
+	}
+	assert.Equal(t, want, executedCmds, "command executed should be equal")
+	for _, executed := range executedCmds {
+		for _, arg := range executed.Args {
+			assert.NotContains(t, arg, token, "the token should not be in the command arguments")
+		}
+	}
+
+	// The header of a failing command is sanitized from the error
+	generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+		return []byte("fatal: " + strings.Join(args, " ")), errors.New("exit status 128")
+	}
+	err = generator.CloneGenerateAndPush(outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", true)
+	testutils.AssertErrorMatch(t, "Authorization: Basic <TOKEN>", err)
+	assert.NotContains(t, err.Error(), base64.StdEncoding.EncodeToString([]byte("x-access-token:"+token)), "the header should be sanitized")
+}
+
+func TestCloneStrategy(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	repoPath := "/fake/path/test-component"
+	componentName := "test-component"
+	branch := "main"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+	}
+
+	type response struct {
+		output string
+		err    error
+	}
+	tests := []struct {
+		name          string
+		strategy      CloneStrategy
+		checkout      bool
+		responses     map[string]response
+		want          []testutils.Execution
+		wantErrString string
+	}{
+		{
+			name:     "Reuse strategy without a checkout",
+			strategy: CloneStrategyReuse,
+			want: []testutils.Execution{
+				{BaseDir: outputPath, Command: "git", Args: []string{"clone", "--branch", branch, "--single-branch", repo, componentName}},
+				{BaseDir: repoPath, Command: "rm", Args: []string{"-rf", "components/test-component/base"}},
+			},
+		},
+		{
+			name:     "Reuse strategy with a checkout of the remote",
+			strategy: CloneStrategyReuse,
+			checkout: true,
+			responses: map[string]response{
+				"remote get-url origin": {output: repo + "\n"},
+			},
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "get-url", "origin"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"fetch", "origin"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"switch", branch}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"reset", "--hard", "origin/main"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"clean", "-fd"}},
+				{BaseDir: repoPath, Command: "rm", Args: []string{"-rf", "components/test-component/base"}},
+			},
+		},
+		{
+			name:     "Reuse strategy with a checkout of another remote",
+			strategy: CloneStrategyReuse,
+			checkout: true,
+			responses: map[string]response{
+				"remote get-url origin": {output: "https://github.com/testing/other.git\n"},
+			},
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "get-url", "origin"}},
+				{BaseDir: outputPath, Command: "rm", Args: []string{"-rf", componentName}},
+				{BaseDir: outputPath, Command: "git", Args: []string{"clone", "--branch", branch, "--single-branch", repo, componentName}},
+				{BaseDir: repoPath, Command: "rm", Args: []string{"-rf", "components/test-component/base"}},
+			},
+		},
+		{
+			name:     "Reuse strategy with a checkout that can't be switched to the branch",
+			strategy: CloneStrategyReuse,
+			checkout: true,
+			responses: map[string]response{
+				"remote get-url origin": {output: repo + "\n"},
+				"switch main":           {output: "fatal: invalid reference: main", err: errors.New("exit status 128")},
+			},
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "get-url", "origin"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"fetch", "origin"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"switch", branch}},
+				{BaseDir: outputPath, Command: "rm", Args: []string{"-rf", componentName}},
+				{BaseDir: outputPath, Command: "git", Args: []string{"clone", "--branch", branch, "--single-branch", repo, componentName}},
+				{BaseDir: repoPath, Command: "rm", Args: []string{"-rf", "components/test-component/base"}},
+			},
+		},
+		{
+			name:     "Reuse strategy failing to fetch the remote",
+			strategy: CloneStrategyReuse,
+			checkout: true,
+			responses: map[string]response{
+				"remote get-url origin": {output: repo + "\n"},
+				"fetch origin":          {output: "fatal: unable to access", err: errors.New("exit status 128")},
+			},
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "get-url", "origin"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"fetch", "origin"}},
+			},
+			wantErrString: "failed to fetch repository \"/fake/path/test-component\"",
+		},
+		{
+			name:     "Clone strategy with a checkout of the remote",
+			strategy: CloneStrategyClone,
+			checkout: true,
+			responses: map[string]response{
+				"remote get-url origin": {output: repo + "\n"},
+			},
+			want: []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"remote", "get-url", "origin"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"fetch", "origin"}},
+				{BaseDir: repoPath, Command: "git", Args: []string{"switch", branch}},
+				{BaseDir: repoPath, Command: "rm", Args: []string{"-rf", "components/test-component/base"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := ioutils.NewMemoryFilesystem()
+			if tt.checkout {
+				testutils.AssertNoError(t, fs.MkdirAll(filepath.Join(repoPath, ".git"), 0750))
+			}
+			var executedCmds []testutils.Execution
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+				if r, ok := tt.responses[strings.Join(args, " ")]; ok {
+					return []byte(r.output), r.err
+				}
+				return []byte(""), nil
+			}
+
+			generator.CloneStrategy = tt.strategy
+			err := generator.CloneGenerateAndPush(outputPath, repo, component, fs, branch, "/", false)
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+		})
+	}
+}
+
+func TestCloneRepoExistingDestination(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	componentName := "test-component"
+	branch := "main"
+	alreadyExists := "fatal: destination path 'test-component' already exists and is not an empty directory."
+
+	tests := []struct {
+		name          string
+		gitRepo       bool
+		remoteURL     string
+		forceClone    bool
+		want          [][]string
+		wantErrString string
+	}{
+		{
+			name:      "Checkout of the remote",
+			gitRepo:   true,
+			remoteURL: repo,
+			want: [][]string{
+				{"remote", "get-url", "origin"},
+				{"fetch", "origin"},
+				{"switch", branch},
+			},
+		},
+		{
+			name:      "Checkout of the remote with another form of its URL",
+			gitRepo:   true,
+			remoteURL: "https://ghp_token@github.com/Testing/testing",
+			want: [][]string{
+				{"remote", "get-url", "origin"},
+				{"fetch", "origin"},
+				{"switch", branch},
+			},
+		},
+		{
+			name:      "Checkout of another remote",
+			gitRepo:   true,
+			remoteURL: "https://github.com/testing/other.git",
+			want: [][]string{
+				{"remote", "get-url", "origin"},
+			},
+			wantErrString: "destination already exists",
+		},
+		{
+			name:       "Checkout of another remote, forcing the clone",
+			gitRepo:    true,
+			remoteURL:  "https://github.com/testing/other.git",
+			forceClone: true,
+			want: [][]string{
+				{"remote", "get-url", "origin"},
+				{"-rf", componentName},
+				{"clone", "--branch", branch, "--single-branch", repo, componentName},
+			},
+		},
+		{
+			name: "Folder that isn't a repository",
+			want: [][]string{
+				{"clone", "--branch", branch, "--single-branch", repo, componentName},
+			},
+			wantErrString: "destination already exists",
+		},
+		{
+			name:       "Folder that isn't a repository, forcing the clone",
+			forceClone: true,
+			want: [][]string{
+				{"clone", "--branch", branch, "--single-branch", repo, componentName},
+				{"-rf", componentName},
+				{"clone", "--branch", branch, "--single-branch", repo, componentName},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := t.TempDir()
+			repoPath := filepath.Join(outputPath, componentName)
+			testutils.AssertNoError(t, os.MkdirAll(repoPath, 0750))
+			testutils.AssertNoError(t, os.WriteFile(filepath.Join(repoPath, "kustomization.yaml"), []byte("resources: []"), 0600))
+			if tt.gitRepo {
+				testutils.AssertNoError(t, os.MkdirAll(filepath.Join(repoPath, ".git"), 0750))
+			}
+
+			var executedCmds [][]string
+			cloned := false
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, args)
+				switch args[0] {
+				case "remote":
+					return []byte(tt.remoteURL + "\n"), nil
+				case "-rf":
+					cloned = true
+				case "clone":
+					if !tt.gitRepo && !cloned {
+						return []byte(alreadyExists), errors.New("exit status 128")
+					}
+				}
+				return []byte(""), nil
+			}
+
+			generator.ForceClone = tt.forceClone
+			err := generator.CloneRepo(outputPath, repo, componentName, branch)
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				assert.ErrorIs(t, err, ErrDestinationExists, "error should be a destination exists error")
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+		})
+	}
+}
+
+func TestTagResult(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	mirror := "https://gitlab.com/testing/mirror.git"
+	outputPath := "/fake/path"
+	repoPath := "/fake/path/test-component"
+	componentName := "test-component"
+	branch := "main"
+	sha := "0e3ad5b1c2f9d7f8a3b4c5d6e7f8a9b0c1d2e3f4"
+	tag := "gitops/test-component/v1"
+	identityArgs := []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com"}
+	commitArgs := append(append([]string{}, identityArgs...), "commit", "-m", "test commit")
+	pushed := []testutils.Execution{
+		{BaseDir: repoPath, Command: "git", Args: []string{"add", "."}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"ls-remote", "--heads", repo, branch}},
+		{BaseDir: repoPath, Command: "git", Args: commitArgs},
+		{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", branch}},
+		{BaseDir: repoPath, Command: "git", Args: []string{"rev-parse", "HEAD"}},
+	}
+	tagArgs := func(message string) []string {
+		return append(append([]string{}, identityArgs...), "tag", "-a", tag, "-m", message)
+	}
+
+	type response struct {
+		output string
+		err    error
+	}
+	tests := []struct {
+		name              string
+		tagResult         *TagOptions
+		additionalRemotes []Remote
+		responses         map[string]response
+		want              []testutils.Execution
+		wantSHA           string
+		wantErrString     string
+		wantTagErr        bool
+	}{
+		{
+			name:      "Tag the pushed commit with the commit message",
+			tagResult: &TagOptions{NameTemplate: "gitops/{{.Component}}/v1"},
+			want: append(pushed[:len(pushed):len(pushed)],
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: tagArgs("test commit")},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", tag}},
+			),
+			wantSHA: sha,
+		},
+		{
+			name:      "Tag the pushed commit with a message template",
+			tagResult: &TagOptions{NameTemplate: "gitops/{{.Component}}/v1", MessageTemplate: "Generated {{.Component}} on {{.Branch}}"},
+			want: append(pushed[:len(pushed):len(pushed)],
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: tagArgs("Generated test-component on main")},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", tag}},
+			),
+			wantSHA: sha,
+		},
+		{
+			name:              "Tag the commit before pushing to the additional remotes",
+			tagResult:         &TagOptions{NameTemplate: "gitops/{{.Component}}/v1"},
+			additionalRemotes: []Remote{{Name: "mirror", URL: mirror}},
+			want: append(pushed[:len(pushed):len(pushed)],
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: tagArgs("test commit")},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", tag}},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"remote", "add", "mirror", mirror}},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"push", "mirror", branch}},
+			),
+			wantSHA: sha,
+		},
+		{
+			name:              "Failure creating the tag",
+			tagResult:         &TagOptions{NameTemplate: "gitops/{{.Component}}/v1"},
+			additionalRemotes: []Remote{{Name: "mirror", URL: mirror}},
+			responses: map[string]response{
+				strings.Join(tagArgs("test commit"), " "): {output: "fatal: tag 'gitops/test-component/v1' already exists", err: errors.New("exit status 128")},
+			},
+			want: append(pushed[:len(pushed):len(pushed)],
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: tagArgs("test commit")},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"remote", "add", "mirror", mirror}},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"push", "mirror", branch}},
+			),
+			wantSHA:       sha,
+			wantErrString: "failed to create tag \"gitops/test-component/v1\" in repository \"/fake/path/test-component\"",
+			wantTagErr:    true,
+		},
+		{
+			name:      "Failure pushing the tag",
+			tagResult: &TagOptions{NameTemplate: "gitops/{{.Component}}/v1"},
+			responses: map[string]response{
+				"push origin " + tag: {output: "fatal: unable to access", err: errors.New("exit status 128")},
+			},
+			want: append(pushed[:len(pushed):len(pushed)],
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: tagArgs("test commit")},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", tag}},
+			),
+			wantSHA:       sha,
+			wantErrString: fmt.Sprintf("failed to push tag \"gitops/test-component/v1\" in repository %q", repo),
+			wantTagErr:    true,
+		},
+		{
+			name:              "Failure pushing to an additional remote takes precedence over the tag failure",
+			tagResult:         &TagOptions{NameTemplate: "gitops/{{.Component}}/v1"},
+			additionalRemotes: []Remote{{Name: "mirror", URL: mirror}},
+			responses: map[string]response{
+				"push origin " + tag:    {output: "fatal: unable to access", err: errors.New("exit status 128")},
+				"push mirror " + branch: {output: "fatal: unable to access", err: errors.New("exit status 128")},
+			},
+			want: append(pushed[:len(pushed):len(pushed)],
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: tagArgs("test commit")},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", tag}},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"remote", "add", "mirror", mirror}},
+				testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"push", "mirror", branch}},
+			),
+			wantSHA:       sha,
+			wantErrString: "failed to push to the additional remote \"mirror\"",
+		},
+		{
+			name:      "No tag if the push fails",
+			tagResult: &TagOptions{NameTemplate: "gitops/{{.Component}}/v1"},
+			responses: map[string]response{
+				"push origin " + branch: {output: "fatal: unable to access", err: errors.New("exit status 128")},
+			},
+			want:          pushed[:len(pushed)-1],
+			wantErrString: "failed to push remote to repository",
+		},
+		{
+			name:          "Invalid tag name template",
+			tagResult:     &TagOptions{NameTemplate: "gitops/{{.Component"},
+			wantErrString: "failed to parse the tag name template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds []testutils.Execution
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+				if r, ok := tt.responses[strings.Join(args, " ")]; ok {
+					return []byte(r.output), r.err
+				}
+				switch args[0] {
+				case "--no-pager":
+					return []byte("test diff"), nil
+				case "rev-parse":
+					return []byte(sha), nil
+				}
+				return []byte(""), nil
+			}
+
+			generator.TagResult = tt.tagResult
+			generator.AdditionalRemotes = tt.additionalRemotes
+			result, err := generator.CommitAndPushWithResult(context.Background(), outputPath, "", repo, componentName, branch, "test commit")
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			var tagErr *GitTagError
+			assert.Equal(t, tt.wantTagErr, errors.As(err, &tagErr), "error should be a tag error")
+			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+			assert.Equal(t, tt.wantSHA, result.CommitSHA, "commit SHA should be equal")
+		})
+	}
+}
+
+func TestResultTagDefaultName(t *testing.T) {
+	generator := NewGitopsGen()
+	generator.TagResult = &TagOptions{}
+	tag, err := generator.resultTag(CommitMessageData{Component: "test-component"}, "test commit", "main")
+	testutils.AssertNoError(t, err)
+	assert.Regexp(t, `^gitops/test-component/\d{14}$`, tag.name, "tag name should be the default template")
+	assert.Equal(t, "test commit", tag.message, "tag message should be the commit message")
+
+	generator.TagResult = nil
+	tag, err = generator.resultTag(CommitMessageData{Component: "test-component"}, "test commit", "main")
+	testutils.AssertNoError(t, err)
+	assert.Nil(t, tag, "commits shouldn't be tagged")
+}
+
+func TestCleanBeforeGenerate(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	repoPath := "/fake/path/test-component"
+	componentName := "test-component"
+	branch := "main"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+	}
+	clone := testutils.Execution{BaseDir: outputPath, Command: "git", Args: []string{"clone", "--branch", branch, "--single-branch", repo, componentName}}
+	reset := testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"reset", "--quiet", "HEAD", "--", "components/test-component"}}
+	checkout := testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"checkout", "HEAD", "--", "components/test-component"}}
+	clean := testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"clean", "-fdx", "--", "components/test-component"}}
+	rmBase := testutils.Execution{BaseDir: repoPath, Command: "rm", Args: []string{"-rf", "components/test-component/base"}}
+	resetContext := testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"reset", "--quiet", "HEAD", "--", "gitops/components/test-component"}}
+	checkoutContext := testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"checkout", "HEAD", "--", "gitops/components/test-component"}}
+	cleanContext := testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"clean", "-fdx", "--", "gitops/components/test-component"}}
+
+	type response struct {
+		output string
+		err    error
+	}
+	tests := []struct {
+		name                string
+		cleanBeforeGenerate bool
+		context             string
+		responses           map[string]response
+		want                []testutils.Execution
+		wantErrString       string
+	}{
+		{
+			name: "No clean by default",
+			want: []testutils.Execution{clone, rmBase},
+		},
+		{
+			name:                "Clean the component before removing the base",
+			cleanBeforeGenerate: true,
+			want:                []testutils.Execution{clone, reset, checkout, clean, rmBase},
+		},
+		{
+			name:                "Clean the component under a non-root context",
+			cleanBeforeGenerate: true,
+			context:             "gitops",
+			want:                []testutils.Execution{clone, resetContext, checkoutContext, cleanContext, rmBase},
+		},
+		{
+			name:                "Clean a component that isn't committed yet",
+			cleanBeforeGenerate: true,
+			responses: map[string]response{
+				"checkout HEAD -- components/test-component": {output: "error: pathspec 'components/test-component' did not match any file(s) known to git", err: errors.New("exit status 1")},
+			},
+			want: []testutils.Execution{clone, reset, checkout, clean, rmBase},
+		},
+		{
+			name:                "Failure resetting the component",
+			cleanBeforeGenerate: true,
+			responses: map[string]response{
+				"checkout HEAD -- components/test-component": {output: "fatal: unable to write new index file", err: errors.New("exit status 128")},
+			},
+			want:          []testutils.Execution{clone, reset, checkout},
+			wantErrString: "failed to reset repository \"/fake/path/test-component/components/test-component\" \"fatal: unable to write new index file\": exit status 128",
+		},
+		{
+			name:                "Failure cleaning the component",
+			cleanBeforeGenerate: true,
+			responses: map[string]response{
+				"clean -fdx -- components/test-component": {output: "warning: failed to remove components/test-component/base", err: errors.New("exit status 1")},
+			},
+			want:          []testutils.Execution{clone, reset, checkout, clean},
+			wantErrString: "failed to clean repository \"/fake/path/test-component/components/test-component\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds []testutils.Execution
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+				if r, ok := tt.responses[strings.Join(args, " ")]; ok {
+					return []byte(r.output), r.err
+				}
+				return []byte(""), nil
+			}
+
+			generator.CleanBeforeGenerate = tt.cleanBeforeGenerate
+			context := tt.context
+			if context == "" {
+				context = "/"
+			}
+			err := generator.CloneGenerateAndPush(outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, context, false)
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+		})
+	}
+}
+
+func TestStrictBranch(t *testing.T) {
+	repo := "https://ghu_28lafsjdifouwej@github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	repoPath := "/fake/path/test-component"
+	componentName := "test-component"
+	branch := "mian"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+	}
+	cloneBranch := testutils.Execution{BaseDir: outputPath, Command: "git", Args: []string{"clone", "--branch", branch, "--single-branch", repo, componentName}}
+	clone := testutils.Execution{BaseDir: outputPath, Command: "git", Args: []string{"clone", repo, componentName}}
+	lsRemote := testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"ls-remote", "--heads", "origin", branch}}
+	switchBranch := testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"switch", branch}}
+	checkout := testutils.Execution{BaseDir: repoPath, Command: "git", Args: []string{"checkout", "-b", branch}}
+	rmBase := testutils.Execution{BaseDir: repoPath, Command: "rm", Args: []string{"-rf", "components/test-component/base"}}
+	branchNotCloned := "warning: Could not find remote branch mian to clone.\nfatal: Remote branch mian not found in upstream origin"
+
+	type response struct {
+		output string
+		err    error
+	}
+	tests := []struct {
+		name           string
+		strictBranch   bool
+		responses      map[string]response
+		want           []testutils.Execution
+		wantErrString  string
+		wantNotFoundIs bool
+	}{
+		{
+			name: "Permissive branch that doesn't exist",
+			responses: map[string]response{
+				strings.Join(cloneBranch.Args, " "): {output: branchNotCloned, err: errors.New("exit status 128")},
+				"switch " + branch:                  {output: "fatal: invalid reference: mian", err: errors.New("exit status 128")},
+			},
+			want: []testutils.Execution{cloneBranch, clone, switchBranch, checkout, rmBase},
+		},
+		{
+			name:         "Strict branch that doesn't exist",
+			strictBranch: true,
+			responses: map[string]response{
+				strings.Join(cloneBranch.Args, " "): {output: branchNotCloned, err: errors.New("exit status 128")},
+			},
+			want:           []testutils.Execution{cloneBranch, clone, lsRemote},
+			wantErrString:  "failed to switch to branch \"mian\" of remote \"https://<TOKEN>@github.com/testing/testing.git\": branch not found",
+			wantNotFoundIs: true,
+		},
+		{
+			name:         "Strict branch that exists",
+			strictBranch: true,
+			responses: map[string]response{
+				strings.Join(cloneBranch.Args, " "): {output: branchNotCloned, err: errors.New("exit status 128")},
+				strings.Join(lsRemote.Args, " "):    {output: "0e3ad5b1c2f9d7f8a3b4c5d6e7f8a9b0c1d2e3f4\trefs/heads/mian\n"},
+			},
+			want: []testutils.Execution{cloneBranch, clone, lsRemote, switchBranch, rmBase},
+		},
+		{
+			name:         "Strict branch that is cloned",
+			strictBranch: true,
+			want:         []testutils.Execution{cloneBranch, rmBase},
+		},
+		{
+			name:         "Failure listing the branches of the remote",
+			strictBranch: true,
+			responses: map[string]response{
+				strings.Join(cloneBranch.Args, " "): {output: branchNotCloned, err: errors.New("exit status 128")},
+				strings.Join(lsRemote.Args, " "):    {output: "fatal: unable to access", err: errors.New("exit status 128")},
+			},
+			want:          []testutils.Execution{cloneBranch, clone, lsRemote},
+			wantErrString: "failed to list git remotes for remote \"https://<TOKEN>@github.com/testing/testing.git\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds []testutils.Execution
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+				if r, ok := tt.responses[strings.Join(args, " ")]; ok {
+					return []byte(r.output), r.err
+				}
+				return []byte(""), nil
+			}
+
+			generator.StrictBranch = tt.strictBranch
+			err := generator.CloneGenerateAndPush(outputPath, repo, component, ioutils.NewMemoryFilesystem(), branch, "/", false)
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			assert.Equal(t, tt.wantNotFoundIs, errors.Is(err, ErrBranchNotFound), "error should be a branch not found error")
+			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+		})
+	}
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	repo := "https://ghu_28lafsjdifouwej@github.com/testing/testing.git"
+	outputPath := "/fake/path"
+
+	tests := []struct {
+		name          string
+		remote        string
+		output        string
+		err           error
+		want          string
+		wantErrString string
+	}{
+		{
+			name:   "Default branch main",
+			remote: repo,
+			output: "ref: refs/heads/main\tHEAD\nca82a6dff817ec66f44342007202690a93763949\tHEAD\n",
+			want:   "main",
+		},
+		{
+			name:   "Custom default branch",
+			remote: repo,
+			output: "ref: refs/heads/release/v1\tHEAD\nca82a6dff817ec66f44342007202690a93763949\tHEAD\n",
+			want:   "release/v1",
+		},
+		{
+			name:          "HEAD without a symbolic reference",
+			remote:        repo,
+			output:        "ca82a6dff817ec66f44342007202690a93763949\tHEAD\n",
+			wantErrString: "failed to detect the default branch of repository \"https://<TOKEN>@github.com/testing/testing.git\" \"ca82a6dff817ec66f44342007202690a93763949\\\\tHEAD\\\\n\": the HEAD of the remote isn't a branch",
+		},
+		{
+			name:          "Malformed output",
+			remote:        repo,
+			output:        "ref: refs/tags/v1 HEAD\n",
+			wantErrString: "the HEAD of the remote isn't a branch",
+		},
+		{
+			name:          "Failure listing the remote",
+			remote:        repo,
+			output:        "fatal: repository not found",
+			err:           errors.New("exit status 128"),
+			wantErrString: "failed to detect the default branch of repository \"https://<TOKEN>@github.com/testing/testing.git\" \"fatal: repository not found\": exit status 128",
+		},
+		{
+			name:          "Invalid remote",
+			remote:        "http://github.com/testing/testing.git",
+			wantErrString: "remote URL is invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds []testutils.Execution
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+				return []byte(tt.output), tt.err
+			}
+
+			branch, err := generator.GetDefaultBranch(outputPath, tt.remote)
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+				assert.Equal(t, []testutils.Execution{
+					{BaseDir: outputPath, Command: "git", Args: []string{"ls-remote", "--symref", repo, "HEAD"}},
+				}, executedCmds, "command executed should be equal")
+			}
+			assert.Equal(t, tt.want, branch, "branch should be equal")
+		})
+	}
+}
+
+func TestGenerateWithDefaultBranch(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	componentName := "test-component"
+	applicationName := "test-application"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+	}
+	symref := "ref: refs/heads/master\tHEAD\nca82a6dff817ec66f44342007202690a93763949\tHEAD\n"
+	lsRemote := testutils.Execution{BaseDir: outputPath, Command: "git", Args: []string{"ls-remote", "--symref", repo, "HEAD"}}
+
+	type response struct {
+		output string
+		err    error
+	}
+	tests := []struct {
+		name          string
+		overlays      bool
+		responses     map[string]response
+		want          []testutils.Execution
+		wantErrString string
+	}{
+		{
+			name: "Generate the base on the default branch",
+			responses: map[string]response{
+				"ls-remote --symref " + repo + " HEAD": {output: symref},
+			},
+			want: []testutils.Execution{
+				lsRemote,
+				{BaseDir: outputPath, Command: "git", Args: []string{"clone", "--branch", "master", "--single-branch", repo, componentName}},
+				{BaseDir: "/fake/path/test-component", Command: "rm", Args: []string{"-rf", "components/test-component/base"}},
+				{BaseDir: "/fake/path/test-component", Command: "git", Args: []string{"add", "."}},
+				{BaseDir: "/fake/path/test-component", Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+				{BaseDir: "/fake/path/test-component", Command: "git", Args: []string{"ls-remote", "--heads", repo, "master"}},
+				{BaseDir: "/fake/path/test-component", Command: "git", Args: []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "Generate GitOps base resources for component test-component"}},
+				{BaseDir: "/fake/path/test-component", Command: "git", Args: []string{"push", "origin", "master"}},
+				{BaseDir: "/fake/path/test-component", Command: "git", Args: []string{"rev-parse", "HEAD"}},
+			},
 		},
 		{
-			name:      "git push failure",
-			fs:        fs,
-			component: component,
-			errors: &testutils.ErrorStack{
-				Errors: []error{
-					errors.New("Fatal error"),
-					nil,
-					nil,
-					nil,
-					nil,
-					nil,
-					nil,
-					nil,
-					nil,
+			name:     "Generate the overlays on the default branch, switching to it",
+			overlays: true,
+			responses: map[string]response{
+				"ls-remote --symref " + repo + " HEAD": {output: symref},
+				"clone --branch master --single-branch " + repo + " " + applicationName: {
+					output: "warning: Could not find remote branch master to clone.\nfatal: Remote branch master not found in upstream origin",
+					err:    errors.New("exit status 128"),
 				},
 			},
-			outputs: [][]byte{
-				[]byte("test output1"),
-				[]byte("test output2"),
-				[]byte("test output3"),
-				[]byte("test output4 refs/heads/main"),
-				[]byte("test output5"),
-				[]byte("test output6"),
-				[]byte("test output7"),
-				[]byte("test output8"),
-				[]byte("test output8"),
-			},
 			want: []testutils.Execution{
-				{
-					BaseDir: outputPath,
-					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "rm",
-					Args:    []string{"-rf", componentPath},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"add", "."},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"--no-pager", "diff", "--cached"},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"ls-remote", "--heads", repo, branch},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"pull"},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"push", "origin", "main"},
-				},
+				lsRemote,
+				{BaseDir: outputPath, Command: "git", Args: []string{"clone", "--branch", "master", "--single-branch", repo, applicationName}},
+				{BaseDir: outputPath, Command: "git", Args: []string{"clone", repo, applicationName}},
+				{BaseDir: "/fake/path/test-application", Command: "git", Args: []string{"switch", "master"}},
 			},
-			wantErrString: fmt.Sprintf("failed to push remote to repository \"%s\" \"test output1\": Fatal error", repo),
+		},
+		{
+			name: "Failure detecting the default branch",
+			responses: map[string]response{
+				"ls-remote --symref " + repo + " HEAD": {output: "fatal: repository not found", err: errors.New("exit status 128")},
+			},
+			want:          []testutils.Execution{lsRemote},
+			wantErrString: "failed to detect the default branch of repository",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds []testutils.Execution
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+				if r, ok := tt.responses[strings.Join(args, " ")]; ok {
+					return []byte(r.output), r.err
+				}
+				if args[0] == "--no-pager" {
+					return []byte("test diff"), nil
+				}
+				return []byte(""), nil
+			}
 
-			outputStack := testutils.NewOutputs(tt.outputs...)
-			executedCmds := []testutils.Execution{}
+			var err error
+			if tt.overlays {
+				err = generator.GenerateOverlaysAndPush(outputPath, true, repo, component, applicationName, "environment", "image", "namespace", ioutils.NewMemoryFilesystem(), "", "/", false, nil)
+			} else {
+				err = generator.CloneGenerateAndPush(outputPath, repo, component, ioutils.NewMemoryFilesystem(), "", "/", true)
+			}
 
-			execute = newTestExecute(outputStack, tt.errors, &executedCmds)
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+		})
+	}
+}
 
-			if err := Generate(fs, repoPath, componentBasePath, tt.component); err != nil {
-				t.Errorf("unexpected error %v", err)
-				return
+func TestBranchFallbacks(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	repoPath := "/fake/path/test-component"
+	componentName := "test-component"
+	component := gitopsv1alpha1.GeneratorOptions{
+		Name: componentName,
+	}
+	branchNotCloned := func(branch string) string {
+		return fmt.Sprintf("warning: Could not find remote branch %s to clone.\nfatal: Remote branch %s not found in upstream origin", branch, branch)
+	}
+	pushed := func(branch string) []testutils.Execution {
+		return []testutils.Execution{
+			{BaseDir: repoPath, Command: "rm", Args: []string{"-rf", "components/test-component/base"}},
+			{BaseDir: repoPath, Command: "git", Args: []string{"add", "."}},
+			{BaseDir: repoPath, Command: "git", Args: []string{"--no-pager", "diff", "--cached"}},
+			{BaseDir: repoPath, Command: "git", Args: []string{"ls-remote", "--heads", repo, branch}},
+			{BaseDir: repoPath, Command: "git", Args: []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", "Generate GitOps base resources for component test-component"}},
+			{BaseDir: repoPath, Command: "git", Args: []string{"push", "origin", branch}},
+			{BaseDir: repoPath, Command: "git", Args: []string{"rev-parse", "HEAD"}},
+		}
+	}
+	cloned := func(branch string) []testutils.Execution {
+		return []testutils.Execution{
+			{BaseDir: outputPath, Command: "git", Args: []string{"clone", "--branch", branch, "--single-branch", repo, componentName}},
+			{BaseDir: outputPath, Command: "git", Args: []string{"clone", repo, componentName}},
+		}
+	}
+	git := func(args ...string) testutils.Execution {
+		return testutils.Execution{BaseDir: repoPath, Command: "git", Args: args}
+	}
+	concat := func(executions ...[]testutils.Execution) []testutils.Execution {
+		var all []testutils.Execution
+		for _, e := range executions {
+			all = append(all, e...)
+		}
+		return all
+	}
+	missing := errors.New("exit status 128")
+
+	type response struct {
+		output string
+		err    error
+	}
+	tests := []struct {
+		name          string
+		branch        string
+		fallbacks     []string
+		strictBranch  bool
+		tagResult     *TagOptions
+		responses     map[string]response
+		want          []testutils.Execution
+		wantBranch    string
+		wantErrString string
+	}{
+		{
+			name:   "No fallbacks",
+			branch: "main",
+			responses: map[string]response{
+				"clone --branch main --single-branch " + repo + " " + componentName: {output: branchNotCloned("main"), err: missing},
+				"switch main": {err: missing},
+			},
+			want:       concat(cloned("main"), []testutils.Execution{git("switch", "main"), git("checkout", "-b", "main")}, pushed("main")),
+			wantBranch: "main",
+		},
+		{
+			name:      "First choice present",
+			branch:    "main",
+			fallbacks: []string{"main", "master"},
+			want: concat([]testutils.Execution{
+				{BaseDir: outputPath, Command: "git", Args: []string{"clone", "--branch", "main", "--single-branch", repo, componentName}},
+			}, pushed("main")),
+			wantBranch: "main",
+		},
+		{
+			name:      "First choice missing, second present",
+			branch:    "main",
+			fallbacks: []string{"main", "master"},
+			tagResult: &TagOptions{NameTemplate: "gitops/{{.Branch}}"},
+			responses: map[string]response{
+				"clone --branch main --single-branch " + repo + " " + componentName: {output: branchNotCloned("main"), err: missing},
+				"switch main": {err: missing},
+			},
+			want: concat(cloned("main"), []testutils.Execution{git("switch", "main"), git("switch", "master")}, pushed("master"), []testutils.Execution{
+				git("-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "tag", "-a", "gitops/master", "-m", "Generate GitOps base resources for component test-component"),
+				git("push", "origin", "gitops/master"),
+			}),
+			wantBranch: "master",
+		},
+		{
+			name:      "No choice present",
+			branch:    "main",
+			fallbacks: []string{"main", "master"},
+			responses: map[string]response{
+				"clone --branch main --single-branch " + repo + " " + componentName: {output: branchNotCloned("main"), err: missing},
+				"switch main":   {err: missing},
+				"switch master": {err: missing},
+			},
+			want:       concat(cloned("main"), []testutils.Execution{git("switch", "main"), git("switch", "master"), git("checkout", "-b", "main")}, pushed("main")),
+			wantBranch: "main",
+		},
+		{
+			name:      "Branch that isn't one of the fallbacks",
+			branch:    "feature",
+			fallbacks: []string{"main", "master"},
+			responses: map[string]response{
+				"clone --branch feature --single-branch " + repo + " " + componentName: {output: branchNotCloned("feature"), err: missing},
+				"switch feature": {err: missing},
+			},
+			want:       concat(cloned("feature"), []testutils.Execution{git("switch", "feature"), git("checkout", "-b", "feature")}, pushed("feature")),
+			wantBranch: "feature",
+		},
+		{
+			name:         "Strict branch with the second choice on the remote",
+			branch:       "main",
+			fallbacks:    []string{"main", "master"},
+			strictBranch: true,
+			responses: map[string]response{
+				"clone --branch main --single-branch " + repo + " " + componentName: {output: branchNotCloned("main"), err: missing},
+				"ls-remote --heads origin main master":                              {output: "ca82a6dff817ec66f44342007202690a93763949\trefs/heads/master\n"},
+			},
+			want:       concat(cloned("main"), []testutils.Execution{git("ls-remote", "--heads", "origin", "main", "master"), git("switch", "master")}, pushed("master")),
+			wantBranch: "master",
+		},
+		{
+			name:         "Strict branch without any choice on the remote",
+			branch:       "main",
+			fallbacks:    []string{"main", "master"},
+			strictBranch: true,
+			responses: map[string]response{
+				"clone --branch main --single-branch " + repo + " " + componentName: {output: branchNotCloned("main"), err: missing},
+			},
+			want:          concat(cloned("main"), []testutils.Execution{git("ls-remote", "--heads", "origin", "main", "master")}),
+			wantErrString: "failed to switch to branch \"main\" of remote \"https://github.com/testing/testing.git\": branch not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds []testutils.Execution
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+				if r, ok := tt.responses[strings.Join(args, " ")]; ok {
+					return []byte(r.output), r.err
+				}
+				if args[0] == "--no-pager" {
+					return []byte("test diff"), nil
+				}
+				return []byte(""), nil
 			}
 
-			err := generator.GitRemoveComponent(outputPath, repo, tt.component.Name, branch, "/")
+			generator.BranchFallbacks = tt.fallbacks
+			generator.StrictBranch = tt.strictBranch
+			generator.TagResult = tt.tagResult
+			result, err := generator.CloneGenerateAndPushWithResult(context.Background(), outputPath, repo, component, ioutils.NewMemoryFilesystem(), tt.branch, "/", true)
 
 			if tt.wantErrString != "" {
 				testutils.AssertErrorMatch(t, tt.wantErrString, err)
 			} else {
 				testutils.AssertNoError(t, err)
 			}
-
 			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
+			assert.Equal(t, tt.wantBranch, result.Branch, "branch should be equal")
+		})
+	}
+}
+
+func TestRepoLocking(t *testing.T) {
+	repo := "https://github.com/testing/testing.git"
+	outputPath := "/fake/path"
+	branch := "main"
+	components := []string{"component-a", "component-b", "component-c", "component-d"}
+
+	tests := []struct {
+		name               string
+		disableRepoLocking bool
+		remotes            []string
+		branches           []string
+		wantMaxActive      int
+	}{
+		{
+			name:          "Generations against the same remote and branch are serialized",
+			remotes:       []string{repo, "https://ghu_28lafsjdifouwej@github.com/testing/testing.git", "https://github.com/Testing/testing", "git@github.com:testing/testing.git"},
+			branches:      []string{branch, branch, branch, branch},
+			wantMaxActive: 1,
+		},
+		{
+			name:          "Generations with and without the default branch are serialized",
+			remotes:       []string{repo, repo, repo, repo},
+			branches:      []string{"", branch, "", branch},
+			wantMaxActive: 1,
+		},
+		{
+			name:               "Generations run concurrently with the locking disabled",
+			disableRepoLocking: true,
+			remotes:            []string{repo, repo, repo, repo},
+			branches:           []string{branch, branch, branch, branch},
+			wantMaxActive:      len(components),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			active, maxActive, started := 0, 0, 0
+			allStarted := make(chan struct{})
+			generator := NewGitopsGen()
+			generator.DisableRepoLocking = tt.disableRepoLocking
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				switch args[0] {
+				case "clone":
+					mu.Lock()
+					active++
+					if active > maxActive {
+						maxActive = active
+					}
+					if started++; started == len(components) {
+						close(allStarted)
+					}
+					mu.Unlock()
+					// The clones wait for each other so that the generations overlap unless they're serialized, in which
+					// case they only wait briefly
+					timeout := 100 * time.Millisecond
+					if tt.disableRepoLocking {
+						timeout = 5 * time.Second
+					}
+					select {
+					case <-allStarted:
+					case <-time.After(timeout):
+					}
+				case "--no-pager":
+					return []byte("test diff"), nil
+				case "ls-remote":
+					if args[1] == "--symref" {
+						return []byte("ref: refs/heads/main\tHEAD\n"), nil
+					}
+				case "rev-parse":
+					mu.Lock()
+					active--
+					mu.Unlock()
+				}
+				return []byte(""), nil
+			}
+
+			fs := ioutils.NewMemoryFilesystem()
+			errs := make([]error, len(components))
+			var wg sync.WaitGroup
+			for i, componentName := range components {
+				wg.Add(1)
+				go func(i int, componentName string) {
+					defer wg.Done()
+					component := gitopsv1alpha1.GeneratorOptions{Name: componentName}
+					errs[i] = generator.CloneGenerateAndPush(outputPath, tt.remotes[i], component, fs, tt.branches[i], "/", true)
+				}(i, componentName)
+			}
+			wg.Wait()
+
+			for _, err := range errs {
+				testutils.AssertNoError(t, err)
+			}
+			assert.Equal(t, tt.wantMaxActive, maxActive, "the concurrent generations should be equal")
+			assert.Empty(t, generator.locks.locks, "the locks should be released")
 		})
 	}
+}
 
-	execute = originalExecute
+func TestRepoLocks(t *testing.T) {
+	locks := newRepoLocks()
+	unlock, err := locks.lock(context.Background(), "github.com/testing/testing#main")
+	testutils.AssertNoError(t, err)
+
+	// A lock of another key isn't held
+	unlockOther, err := locks.lock(context.Background(), "github.com/testing/testing#staging")
+	testutils.AssertNoError(t, err)
+	unlockOther()
+
+	// Waiting for a held lock is interrupted once the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = locks.lock(ctx, "github.com/testing/testing#main")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "waiting for the lock should be interrupted")
+
+	// A lock available is taken even if the context is done
+	unlock()
+	unlock, err = locks.lock(ctx, "github.com/testing/testing#main")
+	testutils.AssertNoError(t, err)
+	unlock()
+	assert.Empty(t, locks.locks, "the locks should be released")
 }
 
 func TestRemoveComponent(t *testing.T) {
@@ -2560,18 +5830,12 @@ func TestRemoveComponent(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2601,13 +5865,18 @@ func TestRemoveComponent(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 		},
 		{
@@ -2628,7 +5897,7 @@ func TestRemoveComponent(t *testing.T) {
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 			},
 			wantCloneErrString: "test error",
@@ -2642,14 +5911,21 @@ func TestRemoveComponent(t *testing.T) {
 					errors.New("Permission denied"),
 					errors.New("Fatal error"),
 					nil,
+					errors.New("exit status 128"),
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
 				[]byte("test output3"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
 			},
 			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
 				{
 					BaseDir: outputPath,
 					Command: "git",
@@ -2677,6 +5953,7 @@ func TestRemoveComponent(t *testing.T) {
 					nil,
 					errors.New("test error"),
 					nil,
+					errors.New("exit status 128"),
 				},
 			},
 			outputs: [][]byte{
@@ -2690,8 +5967,14 @@ func TestRemoveComponent(t *testing.T) {
 				[]byte("test output8"),
 				[]byte("test output9"),
 				[]byte("test output10"),
+				[]byte("warning: Could not find remote branch main to clone.\nfatal: Remote branch main not found in upstream origin"),
 			},
 			want: []testutils.Execution{
+				{
+					BaseDir: outputPath,
+					Command: "git",
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
+				},
 				{
 					BaseDir: outputPath,
 					Command: "git",
@@ -2735,13 +6018,18 @@ func TestRemoveComponent(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
 					Command: "git",
 					Args:    []string{"push", "origin", "main"},
 				},
+				{
+					BaseDir: repoPath,
+					Command: "git",
+					Args:    []string{"rev-parse", "HEAD"},
+				},
 			},
 			wantCloneErrString: "",
 		},
@@ -2753,24 +6041,17 @@ func TestRemoveComponent(t *testing.T) {
 				Errors: []error{
 					errors.New("Permission Denied"),
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
-				[]byte("test output3"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2789,25 +6070,18 @@ func TestRemoveComponent(t *testing.T) {
 					errors.New("Fatal error"),
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
 				[]byte("test output1"),
 				[]byte("test output2"),
 				[]byte("test output3"),
-				[]byte("test output4"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2832,7 +6106,6 @@ func TestRemoveComponent(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -2840,18 +6113,12 @@ func TestRemoveComponent(t *testing.T) {
 				[]byte("test output2"),
 				[]byte("test output3"),
 				[]byte("test output4"),
-				[]byte("test output5"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2882,7 +6149,6 @@ func TestRemoveComponent(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -2891,18 +6157,12 @@ func TestRemoveComponent(t *testing.T) {
 				[]byte("test output3"),
 				[]byte("test output4"),
 				[]byte("test output5"),
-				[]byte("test output6"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -2939,7 +6199,6 @@ func TestRemoveComponent(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -2949,18 +6208,12 @@ func TestRemoveComponent(t *testing.T) {
 				[]byte("test output4"),
 				[]byte("test output5"),
 				[]byte("test output6"),
-				[]byte("test output7"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -3003,7 +6256,6 @@ func TestRemoveComponent(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -3014,18 +6266,12 @@ func TestRemoveComponent(t *testing.T) {
 				[]byte("test output5"),
 				[]byte("test output6"),
 				[]byte("test output7"),
-				[]byte("test output8"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -3055,7 +6301,7 @@ func TestRemoveComponent(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
 				},
 			},
 			wantPushErrString: "failed to commit files to repository \"/fake/path/test-component\" \"test output1\": Fatal error",
@@ -3074,7 +6320,6 @@ func TestRemoveComponent(t *testing.T) {
 					nil,
 					nil,
 					nil,
-					nil,
 				},
 			},
 			outputs: [][]byte{
@@ -3086,18 +6331,12 @@ func TestRemoveComponent(t *testing.T) {
 				[]byte("test output6"),
 				[]byte("test output7"),
 				[]byte("test output8"),
-				[]byte("test output9"),
 			},
 			want: []testutils.Execution{
 				{
 					BaseDir: outputPath,
 					Command: "git",
-					Args:    []string{"clone", repo, component.Name},
-				},
-				{
-					BaseDir: repoPath,
-					Command: "git",
-					Args:    []string{"switch", "main"},
+					Args:    []string{"clone", "--branch", "main", "--single-branch", repo, component.Name},
 				},
 				{
 					BaseDir: repoPath,
@@ -3127,7 +6366,7 @@ func TestRemoveComponent(t *testing.T) {
 				{
 					BaseDir: repoPath,
 					Command: "git",
-					Args:    []string{"commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
+					Args:    []string{"-c", "user.name=GitOps Generator", "-c", "user.email=gitops-generator@redhat.com", "commit", "-m", fmt.Sprintf("Removed component %s", componentName)},
 				},
 				{
 					BaseDir: repoPath,
@@ -3144,7 +6383,7 @@ func TestRemoveComponent(t *testing.T) {
 			outputStack := testutils.NewOutputs(tt.outputs...)
 			executedCmds := []testutils.Execution{}
 
-			execute = newTestExecute(outputStack, tt.errors, &executedCmds)
+			generator.execute = newTestExecute(outputStack, tt.errors, &executedCmds)
 
 			if err := Generate(fs, repoPath, componentBasePath, tt.component); err != nil {
 				t.Errorf("unexpected error %v", err)
@@ -3161,7 +6400,7 @@ func TestRemoveComponent(t *testing.T) {
 
 			if tt.wantCloneErrString == "" {
 
-				err = removeComponent(outputPath, tt.component.Name, "/")
+				err = generator.removeComponent(context.Background(), outputPath, tt.component.Name, "/")
 
 				if tt.wantRemoveErrString != "" {
 					testutils.AssertErrorMatch(t, tt.wantRemoveErrString, err)
@@ -3186,7 +6425,6 @@ func TestRemoveComponent(t *testing.T) {
 
 		})
 	}
-	execute = originalExecute
 }
 
 func TestExecute(t *testing.T) {
@@ -3213,12 +6451,7 @@ func TestExecute(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			outputStack := testutils.NewOutputs()
-			executedCmds := []testutils.Execution{}
-
-			execute = newTestExecute(outputStack, testutils.NewErrors(), &executedCmds)
-
-			_, err := execute(tt.outputPath, tt.command, tt.args)
+			_, err := executeCommand(context.Background(), commandOptions{}, tt.outputPath, tt.command, tt.args)
 
 			if tt.wantErr != nil && err != nil {
 				if tt.wantErr.Error() != err.Error() {
@@ -3235,7 +6468,73 @@ func TestExecute(t *testing.T) {
 			}
 		})
 	}
-	execute = originalExecute
+}
+
+func TestNewCommand(t *testing.T) {
+	t.Setenv("GITOPS_GENERATOR_TEST", "ambient")
+
+	tests := []struct {
+		name          string
+		command       CommandType
+		options       commandOptions
+		wantPath      string
+		wantEnv       []string
+		wantErrString string
+	}{
+		{
+			name:     "Git command with the ambient environment",
+			command:  GitCommand,
+			wantPath: "git",
+		},
+		{
+			name:    "Git command with the git binary path and the environment",
+			command: GitCommand,
+			options: commandOptions{
+				gitBinaryPath: "/opt/git/bin/git",
+				env: map[string]string{
+					"HTTPS_PROXY":    "http://proxy:3128",
+					"GIT_SSL_CAINFO": "/etc/pki/ca.pem",
+				},
+			},
+			wantPath: "/opt/git/bin/git",
+			wantEnv:  []string{"GITOPS_GENERATOR_TEST=ambient", "GIT_SSL_CAINFO=/etc/pki/ca.pem", "HTTPS_PROXY=http://proxy:3128"},
+		},
+		{
+			name:     "Remove command ignoring the git binary path",
+			command:  RmCommand,
+			options:  commandOptions{gitBinaryPath: "/opt/git/bin/git"},
+			wantPath: "rm",
+		},
+		{
+			name:          "Unsupported command",
+			command:       "cd",
+			options:       commandOptions{gitBinaryPath: "/opt/git/bin/git"},
+			wantErrString: "Unsupported command \"cd\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newCommand(context.Background(), tt.options, "/fake/path", tt.command, "status")
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				return
+			}
+			testutils.AssertNoError(t, err)
+			assert.Equal(t, "/fake/path", c.Dir, "command directory should be equal")
+			assert.Equal(t, filepath.Base(tt.wantPath), filepath.Base(c.Path), "command binary should be equal")
+			assert.Equal(t, []string{"status"}, c.Args[1:], "command arguments should be equal")
+			if filepath.IsAbs(tt.wantPath) {
+				assert.Equal(t, tt.wantPath, c.Path, "command path should be equal")
+			}
+			if tt.wantEnv == nil {
+				assert.Nil(t, c.Env, "command should inherit the environment")
+				return
+			}
+			assert.Subset(t, c.Env, tt.wantEnv, "command environment should contain the variables")
+			assert.Equal(t, tt.wantEnv[1:], c.Env[len(c.Env)-2:], "variables should be set over the environment")
+		})
+	}
 }
 
 func TestGenerateAndPush(t *testing.T) {
@@ -3296,7 +6595,7 @@ func TestGenerateAndPush(t *testing.T) {
 			outputStack := testutils.NewOutputs(tt.outputs...)
 			executedCmds := []testutils.Execution{}
 			component.GitSource.URL = tt.repo
-			execute = newTestExecute(outputStack, tt.errors, &executedCmds)
+			generator.execute = newTestExecute(outputStack, tt.errors, &executedCmds)
 			err := generator.GenerateAndPush(outputPath, repo, tt.component, tt.fs, "main", tt.doPush, "KAM CLI")
 
 			if tt.wantErrString != "" {
@@ -3308,7 +6607,274 @@ func TestGenerateAndPush(t *testing.T) {
 			assert.Equal(t, tt.want, executedCmds, "command executed should be equal")
 		})
 	}
-	execute = originalExecute
+}
+
+func TestGetCommitIDForRef(t *testing.T) {
+	// Create a git repository with a tagged commit and a later commit, and a clone of it
+	fs := ioutils.NewFilesystem()
+	tempDir, err := fs.TempDir(os.TempDir(), "test")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = createEmptyGitRepository(tempDir)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	taggedCommitID, err := getCommitIDFromDotGit(tempDir)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	identity := []string{"-c", "user.name='Test User'", "-c", "user.email='test@test.org'"}
+	if out, err := executeCommand(context.Background(), commandOptions{}, tempDir, GitCommand, append(identity, "tag", "-a", "gitops/test-component/v1", "-m", "v1")...); err != nil {
+		t.Errorf("unexpected error: %s %v", out, err)
+	}
+	if out, err := executeCommand(context.Background(), commandOptions{}, tempDir, GitCommand, append(identity, "commit", "--allow-empty", "-m", "Second commit")...); err != nil {
+		t.Errorf("unexpected error: %s %v", out, err)
+	}
+	commitID, err := getCommitIDFromDotGit(tempDir)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	cloneDir, err := fs.TempDir(os.TempDir(), "test-clone")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if out, err := executeCommand(context.Background(), commandOptions{}, cloneDir, GitCommand, "clone", tempDir, "clone"); err != nil {
+		t.Errorf("unexpected error: %s %v", out, err)
+	}
+	clonePath := filepath.Join(cloneDir, "clone")
+	defer func() {
+		_ = fs.RemoveAll(tempDir)
+		_ = fs.RemoveAll(cloneDir)
+	}()
+
+	generator := NewGitopsGen()
+	tests := []struct {
+		name          string
+		repoPath      string
+		ref           string
+		remoteBranch  string
+		want          string
+		wantErrString string
+	}{
+		{
+			name:     "HEAD",
+			repoPath: tempDir,
+			ref:      "HEAD",
+			want:     commitID,
+		},
+		{
+			name:     "Branch",
+			repoPath: tempDir,
+			ref:      "master",
+			want:     commitID,
+		},
+		{
+			name:     "Annotated tag resolves to the tagged commit",
+			repoPath: tempDir,
+			ref:      "gitops/test-component/v1",
+			want:     taggedCommitID,
+		},
+		{
+			name:     "Remote branch",
+			repoPath: clonePath,
+			ref:      "origin/master",
+			want:     commitID,
+		},
+		{
+			name:          "Unknown reference",
+			repoPath:      tempDir,
+			ref:           "missing",
+			wantErrString: "failed to retrieve commit id for repository",
+		},
+		{
+			name:          "Invalid reference",
+			repoPath:      tempDir,
+			ref:           "--output=/tmp/file",
+			wantErrString: "git reference \"--output=/tmp/file\" is invalid",
+		},
+		{
+			name:         "Remote branch after a fetch",
+			repoPath:     clonePath,
+			remoteBranch: "master",
+			want:         commitID,
+		},
+		{
+			name:          "Unknown remote branch",
+			repoPath:      clonePath,
+			remoteBranch:  "missing",
+			wantErrString: "failed to fetch branch \"missing\"",
+		},
+		{
+			name:          "Invalid remote branch",
+			repoPath:      clonePath,
+			remoteBranch:  "main..feature",
+			wantErrString: "git reference \"main..feature\" is invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var err error
+			if tt.remoteBranch != "" {
+				got, err = generator.GetCommitIDForRemoteBranch(fs, tt.repoPath, tt.remoteBranch)
+			} else {
+				got, err = generator.GetCommitIDForRef(fs, tt.repoPath, tt.ref)
+			}
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			assert.Equal(t, tt.want, got, "commit ID should be equal")
+		})
+	}
+}
+
+func TestGetCommitInfo(t *testing.T) {
+	// Create a git repository with a commit to test with
+	fs := ioutils.NewFilesystem()
+	tempDir, err := fs.TempDir(os.TempDir(), "test")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = fs.RemoveAll(tempDir)
+	}()
+	if out, err := executeCommand(context.Background(), commandOptions{}, tempDir, GitCommand, "init"); err != nil {
+		t.Errorf("unexpected error: %s %v", out, err)
+	}
+	subject := "Generate GitOps base resources for component test-component | %x00 \"quoted\""
+	if out, err := executeCommand(context.Background(), commandOptions{}, tempDir, GitCommand, "-c", "user.name=Test User", "-c", "user.email=test@test.org", "commit", "--allow-empty", "-m", subject+"\n\nBody of the commit"); err != nil {
+		t.Errorf("unexpected error: %s %v", out, err)
+	}
+	commitID, err := getCommitIDFromDotGit(tempDir)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	generator := NewGitopsGen()
+	tests := []struct {
+		name          string
+		repoPath      string
+		ref           string
+		wantErrString string
+	}{
+		{
+			name:     "HEAD",
+			repoPath: tempDir,
+			ref:      "HEAD",
+		},
+		{
+			name:     "Commit ID",
+			repoPath: tempDir,
+			ref:      commitID,
+		},
+		{
+			name:          "Unknown reference",
+			repoPath:      tempDir,
+			ref:           "missing",
+			wantErrString: fmt.Sprintf("failed to retrieve commit info for repository %q", tempDir),
+		},
+		{
+			name:          "Invalid reference",
+			repoPath:      tempDir,
+			ref:           "HEAD~1",
+			wantErrString: "git reference \"HEAD~1\" is invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := generator.GetCommitInfo(fs, tt.repoPath, tt.ref)
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+				assert.Equal(t, CommitInfo{}, info, "commit info should be empty")
+				return
+			}
+			testutils.AssertNoError(t, err)
+			assert.Equal(t, commitID, info.SHA, "SHA should be equal")
+			assert.True(t, len(info.ShortSHA) >= 7 && strings.HasPrefix(commitID, info.ShortSHA), "short SHA should abbreviate the SHA")
+			assert.Equal(t, "Test User", info.Author, "author should be equal")
+			assert.Equal(t, "test@test.org", info.Email, "email should be equal")
+			assert.WithinDuration(t, time.Now(), info.Timestamp, time.Minute, "timestamp should be the time of the commit")
+			assert.Equal(t, subject, info.Subject, "subject should be equal")
+		})
+	}
+}
+
+func TestGetCommitInfoParsing(t *testing.T) {
+	repoPath := "/fake/path/test-component"
+	sha := "ca82a6dff817ec66f44342007202690a93763949"
+
+	tests := []struct {
+		name          string
+		output        string
+		want          CommitInfo
+		wantErrString string
+	}{
+		{
+			name:   "Subject with separators",
+			output: sha + "\x00ca82a6d\x00GitOps Generator\x00gitops-generator@redhat.com\x002022-10-10T12:00:00+02:00\x00Update a\x00b | c %x00\n",
+			want: CommitInfo{
+				SHA:       sha,
+				ShortSHA:  "ca82a6d",
+				Author:    "GitOps Generator",
+				Email:     "gitops-generator@redhat.com",
+				Timestamp: time.Date(2022, 10, 10, 10, 0, 0, 0, time.UTC),
+				Subject:   "Update a\x00b | c %x00",
+			},
+		},
+		{
+			name:   "Empty subject",
+			output: sha + "\x00ca82a6d\x00GitOps Generator\x00gitops-generator@redhat.com\x002022-10-10T12:00:00Z\x00\n",
+			want: CommitInfo{
+				SHA:       sha,
+				ShortSHA:  "ca82a6d",
+				Author:    "GitOps Generator",
+				Email:     "gitops-generator@redhat.com",
+				Timestamp: time.Date(2022, 10, 10, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:          "Missing fields",
+			output:        sha + "\x00ca82a6d\n",
+			wantErrString: "unexpected git log output, expected 6 fields and got 2",
+		},
+		{
+			name:          "Invalid timestamp",
+			output:        sha + "\x00ca82a6d\x00GitOps Generator\x00gitops-generator@redhat.com\x00yesterday\x00Subject\n",
+			wantErrString: "unexpected commit timestamp \"yesterday\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executedCmds []testutils.Execution
+			generator := NewGitopsGen()
+			generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+				executedCmds = append(executedCmds, testutils.Execution{BaseDir: baseDir, Command: string(cmd), Args: args})
+				return []byte(tt.output), nil
+			}
+
+			info, err := generator.GetCommitInfo(ioutils.NewMemoryFilesystem(), repoPath, "HEAD")
+
+			if tt.wantErrString != "" {
+				testutils.AssertErrorMatch(t, tt.wantErrString, err)
+			} else {
+				testutils.AssertNoError(t, err)
+			}
+			assert.True(t, tt.want.Timestamp.Equal(info.Timestamp), "timestamp should be equal")
+			info.Timestamp = tt.want.Timestamp
+			assert.Equal(t, tt.want, info, "commit info should be equal")
+			assert.Equal(t, []testutils.Execution{
+				{BaseDir: repoPath, Command: "git", Args: []string{"log", "-1", "--format=%H%x00%h%x00%an%x00%ae%x00%cI%x00%s", "HEAD", "--"}},
+			}, executedCmds, "command executed should be equal")
+		})
+	}
 }
 
 func TestGetCommitIDFromRepo(t *testing.T) {
@@ -3366,7 +6932,7 @@ func TestGetCommitIDFromRepo(t *testing.T) {
 				outputStack := testutils.NewOutputs()
 				executedCmds := []testutils.Execution{}
 
-				execute = newTestExecute(outputStack, testutils.NewErrors(), &executedCmds)
+				generator.execute = newTestExecute(outputStack, testutils.NewErrors(), &executedCmds)
 			}
 
 			commitID, err := generator.GetCommitIDFromRepo(fs, tt.repoPath)
@@ -3382,18 +6948,232 @@ func TestGetCommitIDFromRepo(t *testing.T) {
 			}
 		})
 	}
-	execute = originalExecute
+}
+
+func TestCloneGenerateAndPushWithRepository(t *testing.T) {
+	testCloneGenerateAndPushWithRepository(t, "")
+}
+
+func TestCloneGenerateAndPushWithRepositoryRemoteName(t *testing.T) {
+	testCloneGenerateAndPushWithRepository(t, "upstream")
+}
+
+// testCloneGenerateAndPushWithRepository generates and pushes the resources of a component to a local repository with
+// the remote name, the remote being replaced by the path of the repository
+func testCloneGenerateAndPushWithRepository(t *testing.T, remoteName string) {
+	fs := ioutils.NewFilesystem()
+	tempDir, err := fs.TempDir(os.TempDir(), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = fs.RemoveAll(tempDir)
+	}()
+
+	// Create a bare repository with a main branch, its default branch
+	repoPath := filepath.Join(tempDir, "repository.git")
+	seedPath := filepath.Join(tempDir, "seed")
+	identity := []string{"-c", "user.name=Test User", "-c", "user.email=test@test.org"}
+	for _, command := range []struct {
+		baseDir string
+		args    []string
+	}{
+		{tempDir, []string{"init", "--bare", repoPath}},
+		{repoPath, []string{"symbolic-ref", "HEAD", "refs/heads/main"}},
+		{tempDir, []string{"clone", repoPath, seedPath}},
+		{seedPath, append(identity, "commit", "--allow-empty", "-m", "Initial commit")},
+		{seedPath, []string{"push", "origin", "HEAD:main"}},
+	} {
+		if out, err := executeCommand(context.Background(), commandOptions{}, command.baseDir, GitCommand, command.args...); err != nil {
+			t.Fatalf("unexpected error: %s %v", out, err)
+		}
+	}
+	revParse := func(ref string) string {
+		out, err := executeCommand(context.Background(), commandOptions{}, repoPath, GitCommand, "rev-parse", "--verify", "--quiet", ref)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	remote := "https://github.com/testing/testing.git"
+	generator := NewGitopsGen()
+	generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+		args = append([]string{}, args...)
+		for i, arg := range args {
+			if arg == remote {
+				args[i] = repoPath
+			}
+		}
+		return executeCommand(ctx, options, baseDir, cmd, args...)
+	}
+	generator.RemoteName = remoteName
+	wantRemotes := "origin\n"
+	if remoteName != "" {
+		wantRemotes = remoteName + "\n"
+	}
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:           "test-component",
+		ContainerImage: "quay.io/test/test-image:latest",
+		Replicas:       1,
+	}
+
+	tests := []struct {
+		name       string
+		branch     string
+		replicas   int
+		remove     bool
+		wantBranch string
+		wantCommit bool
+	}{
+		{
+			name:       "Generates and pushes the resources to the branch",
+			branch:     "main",
+			replicas:   1,
+			wantBranch: "main",
+			wantCommit: true,
+		},
+		{
+			name:       "Skips the commit when the resources don't change",
+			branch:     "main",
+			replicas:   1,
+			wantBranch: "main",
+		},
+		{
+			name:       "Creates the branch missing from the remote",
+			branch:     "feature",
+			replicas:   2,
+			wantBranch: "feature",
+			wantCommit: true,
+		},
+		{
+			name:       "Pushes to the default branch without a branch",
+			replicas:   3,
+			wantBranch: "main",
+			wantCommit: true,
+		},
+		{
+			name:       "Removes the component",
+			branch:     "main",
+			remove:     true,
+			wantBranch: "main",
+			wantCommit: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(tempDir, fmt.Sprintf("output-%d", i))
+			testutils.AssertNoError(t, fs.MkdirAll(outputPath, 0755))
+			previousCommit := revParse(tt.wantBranch)
+			options.Replicas = tt.replicas
+			var result PushResult
+			if tt.remove {
+				result, err = generator.GitRemoveComponentWithResult(context.Background(), outputPath, remote, options.Name, fs, tt.branch, "/")
+			} else {
+				result, err = generator.CloneGenerateAndPushWithResult(context.Background(), outputPath, remote, options, fs, tt.branch, "/", true)
+			}
+			testutils.AssertNoError(t, err)
+
+			assert.Equal(t, tt.wantBranch, result.Branch)
+			if tt.wantCommit {
+				assert.NotEmpty(t, result.CommitSHA)
+				assert.Equal(t, revParse(tt.wantBranch), result.CommitSHA)
+			} else {
+				assert.Empty(t, result.CommitSHA)
+				assert.Equal(t, previousCommit, revParse(tt.wantBranch))
+			}
+			componentTree := revParse(tt.wantBranch + ":components/test-component/base")
+			assert.Equal(t, tt.remove, componentTree == "", "the component should only be missing once removed")
+			remotes, err := executeCommand(context.Background(), commandOptions{}, filepath.Join(outputPath, options.Name), GitCommand, "remote")
+			testutils.AssertNoError(t, err)
+			assert.Equal(t, wantRemotes, string(remotes), "the remote should be named after the remote name")
+		})
+	}
+}
+
+func TestSparseCheckoutWithRepository(t *testing.T) {
+	fs := ioutils.NewFilesystem()
+	tempDir, err := fs.TempDir(os.TempDir(), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = fs.RemoveAll(tempDir)
+	}()
+
+	// Create a bare repository with the parent kustomization and another component under the context path
+	repoPath := filepath.Join(tempDir, "repository.git")
+	seedPath := filepath.Join(tempDir, "seed")
+	for _, file := range []string{"README.md", "gitops/kustomization.yaml", "gitops/components/other-component/base/kustomization.yaml"} {
+		testutils.AssertNoError(t, fs.MkdirAll(filepath.Dir(filepath.Join(seedPath, file)), 0755))
+		testutils.AssertNoError(t, fs.WriteFile(filepath.Join(seedPath, file), []byte("# "+file+"\n"), 0644))
+	}
+	for _, command := range []struct {
+		baseDir string
+		args    []string
+	}{
+		{tempDir, []string{"init", "--bare", repoPath}},
+		{repoPath, []string{"symbolic-ref", "HEAD", "refs/heads/main"}},
+		{seedPath, []string{"init"}},
+		{seedPath, []string{"add", "."}},
+		{seedPath, []string{"-c", "user.name=Test User", "-c", "user.email=test@test.org", "commit", "-m", "Initial commit"}},
+		{seedPath, []string{"push", repoPath, "HEAD:main"}},
+	} {
+		if out, err := executeCommand(context.Background(), commandOptions{}, command.baseDir, GitCommand, command.args...); err != nil {
+			t.Fatalf("unexpected error: %s %v", out, err)
+		}
+	}
+
+	remote := "https://github.com/testing/testing.git"
+	generator := NewGitopsGen()
+	generator.execute = func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+		args = append([]string{}, args...)
+		for i, arg := range args {
+			if arg == remote {
+				args[i] = repoPath
+			}
+		}
+		return executeCommand(ctx, options, baseDir, cmd, args...)
+	}
+	generator.SparseCheckout = true
+	options := gitopsv1alpha1.GeneratorOptions{
+		Name:           "test-component",
+		ContainerImage: "quay.io/test/test-image:latest",
+	}
+	outputPath := filepath.Join(tempDir, "output")
+	testutils.AssertNoError(t, fs.MkdirAll(outputPath, 0755))
+
+	result, err := generator.CloneGenerateAndPushWithResult(context.Background(), outputPath, remote, options, fs, "main", "gitops", true)
+
+	testutils.AssertNoError(t, err)
+	assert.NotEmpty(t, result.CommitSHA, "the resources should be pushed")
+	checkoutPath := filepath.Join(outputPath, options.Name)
+	for file, wantExists := range map[string]bool{
+		"README.md":                         true,
+		"gitops/kustomization.yaml":         true,
+		"gitops/components/test-component":  true,
+		"gitops/components/other-component": false,
+	} {
+		exists, err := fs.Exists(filepath.Join(checkoutPath, file))
+		testutils.AssertNoError(t, err)
+		assert.Equal(t, wantExists, exists, "the checkout of %s should be equal", file)
+	}
+	out, err := executeCommand(context.Background(), commandOptions{}, repoPath, GitCommand, "ls-tree", "-r", "--name-only", "main")
+	testutils.AssertNoError(t, err)
+	assert.Contains(t, string(out), "gitops/components/other-component/base/kustomization.yaml", "the other component should be kept")
+	assert.Contains(t, string(out), "gitops/components/test-component/base/kustomization.yaml", "the component should be pushed")
 }
 
 // createEmptyGitRepository generates an empty git repository under the specified folder
 func createEmptyGitRepository(repoPath string) error {
 	// Initialize the Git repository
-	if out, err := execute(repoPath, GitCommand, "init"); err != nil {
+	if out, err := executeCommand(context.Background(), commandOptions{}, repoPath, GitCommand, "init"); err != nil {
 		return fmt.Errorf("Unable to intialize git repository in %q %q: %s", repoPath, out, err)
 	}
 
 	// Create an empty commit
-	if out, err := execute(repoPath, GitCommand, "-c", "user.name='Test User'", "-c", "user.email='test@test.org'", "commit", "--allow-empty", "-m", "\"Empty commit\""); err != nil {
+	if out, err := executeCommand(context.Background(), commandOptions{}, repoPath, GitCommand, "-c", "user.name='Test User'", "-c", "user.email='test@test.org'", "commit", "--allow-empty", "-m", "\"Empty commit\""); err != nil {
 		return fmt.Errorf("Unable to create empty commit in %q %q: %s", repoPath, out, err)
 	}
 	return nil
@@ -3407,7 +7187,7 @@ func getCommitIDFromDotGit(repoPath string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return string(fileBytes), nil
+	return strings.TrimSpace(string(fileBytes)), nil
 }
 
 func mockExecute(outputStack *testutils.OutputStack, errorStack *testutils.ErrorStack, executedCmds *[]testutils.Execution, baseDir string, cmd CommandType, args ...string) ([]byte, error, *[]testutils.Execution) {
@@ -3427,8 +7207,8 @@ func mockExecute(outputStack *testutils.OutputStack, errorStack *testutils.Error
 	return []byte(""), fmt.Errorf("Unsupported command \"%s\" ", string(cmd)), executedCmds
 }
 
-func newTestExecute(outputStack *testutils.OutputStack, errorStack *testutils.ErrorStack, executedCmds *[]testutils.Execution) func(baseDir string, cmd CommandType, args ...string) ([]byte, error) {
-	return func(baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+func newTestExecute(outputStack *testutils.OutputStack, errorStack *testutils.ErrorStack, executedCmds *[]testutils.Execution) func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
+	return func(ctx context.Context, options commandOptions, baseDir string, cmd CommandType, args ...string) ([]byte, error) {
 		var output []byte
 		var execErr error
 		output, execErr, executedCmds = mockExecute(outputStack, errorStack, executedCmds, baseDir, cmd, args...)
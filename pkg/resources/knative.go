@@ -0,0 +1,48 @@
+//
+// Copyright 2021-2022 Red Hat, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// KnativeServiceAPIVersion is the API version of Knative services
+	KnativeServiceAPIVersion = "serving.knative.dev/v1"
+	// KnativeServiceKind is the kind of Knative services
+	KnativeServiceKind = "Service"
+)
+
+// KnativeService is a structural representation of a Knative serving Service, limited to the fields that are generated.
+type KnativeService struct {
+	v1.TypeMeta   `json:",inline"`
+	v1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KnativeServiceSpec `json:"spec,omitempty"`
+}
+
+// KnativeServiceSpec holds the template of the revisions created by the Knative service
+type KnativeServiceSpec struct {
+	Template KnativeRevisionTemplateSpec `json:"template"`
+}
+
+// KnativeRevisionTemplateSpec describes the revisions created by the Knative service
+type KnativeRevisionTemplateSpec struct {
+	v1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec corev1.PodSpec `json:"spec,omitempty"`
+}
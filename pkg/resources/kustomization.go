@@ -18,35 +18,332 @@
 package resources
 
 import (
+	"encoding/json"
+	"reflect"
 	"sort"
+	"strings"
 )
 
 // Kustomization is a structural representation of the Kustomize file format.
 type Kustomization struct {
-	APIVersion   string            `json:"apiVersion,omitempty"`
-	Kind         string            `json:"kind,omitempty"`
-	Resources    []string          `json:"resources,omitempty"`
-	Bases        []string          `json:"bases,omitempty"`
-	Patches      []Patch           `json:"patches,omitempty"`
-	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	APIVersion         string            `json:"apiVersion,omitempty"`
+	Kind               string            `json:"kind,omitempty"`
+	Metadata           *ObjectMeta       `json:"metadata,omitempty"`
+	Resources          []string          `json:"resources,omitempty"`
+	Bases              []string          `json:"bases,omitempty"`
+	Components         []string          `json:"components,omitempty"`
+	Crds               []string          `json:"crds,omitempty"`
+	Patches            []Patch           `json:"patches,omitempty"`
+	CommonLabels       map[string]string `json:"commonLabels,omitempty"`
+	Labels             []Label           `json:"labels,omitempty"`
+	CommonAnnotations  map[string]string `json:"commonAnnotations,omitempty"`
+	NamePrefix         string            `json:"namePrefix,omitempty"`
+	NameSuffix         string            `json:"nameSuffix,omitempty"`
+	Namespace          string            `json:"namespace,omitempty"`
+	Images             []Image           `json:"images,omitempty"`
+	Replicas           []Replica         `json:"replicas,omitempty"`
+	Replacements       []Replacement     `json:"replacements,omitempty"`
+	ConfigMapGenerator []ConfigMapArgs   `json:"configMapGenerator,omitempty"`
+	SecretGenerator    []SecretArgs      `json:"secretGenerator,omitempty"`
+	GeneratorOptions   *GeneratorOptions `json:"generatorOptions,omitempty"`
+	SortOptions        *SortOptions      `json:"sortOptions,omitempty"`
+	BuildMetadata      []string          `json:"buildMetadata,omitempty"`
+	OpenAPI            map[string]string `json:"openapi,omitempty"`
+
+	// UnknownFields holds the fields of the kustomization that aren't managed by the generator,
+	// so that they're written back untouched
+	UnknownFields map[string]interface{} `json:"-"`
+}
+
+// kustomization has the fields of Kustomization, without its custom marshalling
+type kustomization Kustomization
+
+// UnmarshalJSON unmarshals the kustomization, keeping the fields it doesn't know about in UnknownFields
+func (k *Kustomization) UnmarshalJSON(data []byte) error {
+	*k = Kustomization{}
+	known := (*kustomization)(k)
+	// The known fields are unmarshalled through a type named after the kustomization, so that the unmarshalling
+	// errors still refer to the Kustomization type
+	type Kustomization kustomization
+	if err := json.Unmarshal(data, (*Kustomization)(known)); err != nil {
+		return err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, name := range knownFieldNames() {
+		delete(fields, name)
+	}
+	if len(fields) > 0 {
+		known.UnknownFields = fields
+	}
+	return nil
+}
+
+// MarshalJSON marshals the kustomization along with its UnknownFields. The fields managed by the
+// generator take precedence over unknown fields of the same name.
+func (k Kustomization) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(kustomization(k))
+	if err != nil || len(k.UnknownFields) == 0 {
+		return data, err
+	}
+	fields := make(map[string]interface{})
+	for name, value := range k.UnknownFields {
+		fields[name] = value
+	}
+	var known map[string]json.RawMessage
+	if err := json.Unmarshal(data, &known); err != nil {
+		return nil, err
+	}
+	for name, value := range known {
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+// knownFieldNames returns the JSON names of the fields of the kustomization
+func knownFieldNames() []string {
+	var names []string
+	t := reflect.TypeOf(kustomization{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ObjectMeta holds the metadata of the kustomization
+type ObjectMeta struct {
+	Name        string            `json:"name,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Label holds labels to add to all the resources, and whether they're added to the selectors and pod templates too
+type Label struct {
+	Pairs            map[string]string `json:"pairs,omitempty"`
+	IncludeSelectors bool              `json:"includeSelectors,omitempty"`
+	IncludeTemplates bool              `json:"includeTemplates,omitempty"`
+}
+
+// SortOptions holds the order the resources are output in
+type SortOptions struct {
+	Order             string             `json:"order,omitempty"`
+	LegacySortOptions *LegacySortOptions `json:"legacySortOptions,omitempty"`
+}
+
+// LegacySortOptions holds the kinds output first and last with the legacy order
+type LegacySortOptions struct {
+	OrderFirst []string `json:"orderFirst,omitempty"`
+	OrderLast  []string `json:"orderLast,omitempty"`
+}
+
+// Image holds the image override information
+type Image struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName,omitempty"`
+	NewTag  string `json:"newTag,omitempty"`
+	Digest  string `json:"digest,omitempty"`
+}
+
+// Replica holds the replica count override information
+type Replica struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// Replacement holds a kustomize replacement, which copies a field of the source resource into fields of the targets.
+// A replacement is either inline, with a source and targets, or read from the file at Path
+type Replacement struct {
+	Path    string           `json:"path,omitempty"`
+	Source  *SourceSelector  `json:"source,omitempty"`
+	Targets []TargetSelector `json:"targets,omitempty"`
+}
+
+// SourceSelector selects the resource and field a replacement value is copied from
+type SourceSelector struct {
+	Group     string        `json:"group,omitempty"`
+	Version   string        `json:"version,omitempty"`
+	Kind      string        `json:"kind,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	Namespace string        `json:"namespace,omitempty"`
+	FieldPath string        `json:"fieldPath,omitempty"`
+	Options   *FieldOptions `json:"options,omitempty"`
+}
+
+// TargetSelector selects the resources and fields a replacement value is copied to
+type TargetSelector struct {
+	Select     *Selector     `json:"select,omitempty"`
+	Reject     []Selector    `json:"reject,omitempty"`
+	FieldPaths []string      `json:"fieldPaths,omitempty"`
+	Options    *FieldOptions `json:"options,omitempty"`
+}
+
+// Selector selects resources by their id, labels and annotations
+type Selector struct {
+	Group              string `json:"group,omitempty"`
+	Version            string `json:"version,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+	Name               string `json:"name,omitempty"`
+	Namespace          string `json:"namespace,omitempty"`
+	AnnotationSelector string `json:"annotationSelector,omitempty"`
+	LabelSelector      string `json:"labelSelector,omitempty"`
+}
+
+// FieldOptions select the part of a field value a replacement reads or writes
+type FieldOptions struct {
+	Delimiter string `json:"delimiter,omitempty"`
+	Index     int    `json:"index,omitempty"`
+	Encoding  string `json:"encoding,omitempty"`
+	Create    bool   `json:"create,omitempty"`
+}
+
+// GeneratorArgs holds the arguments of a ConfigMap or Secret generator
+type GeneratorArgs struct {
+	Name     string            `json:"name,omitempty"`
+	Behavior string            `json:"behavior,omitempty"`
+	Literals []string          `json:"literals,omitempty"`
+	Files    []string          `json:"files,omitempty"`
+	Envs     []string          `json:"envs,omitempty"`
+	Options  *GeneratorOptions `json:"options,omitempty"`
+}
+
+// GeneratorOptions holds the options of a ConfigMap or Secret generator, or of all the generators of a kustomization
+type GeneratorOptions struct {
+	Labels                map[string]string `json:"labels,omitempty"`
+	Annotations           map[string]string `json:"annotations,omitempty"`
+	DisableNameSuffixHash bool              `json:"disableNameSuffixHash,omitempty"`
+}
+
+// ConfigMapArgs holds the ConfigMap generator information
+type ConfigMapArgs struct {
+	GeneratorArgs
+}
+
+// SecretArgs holds the Secret generator information
+type SecretArgs struct {
+	GeneratorArgs
+	Type string `json:"type,omitempty"`
 }
 
-// Patch holds the patch information
+// Patch holds the patch information. A patch is either a file with a strategic merge patch, or a JSON 6902 or
+// strategic merge patch, inline or in a file, applied to the resources selected by its target
 type Patch struct {
-	Path string `json:"path"`
+	Path   string       `json:"path,omitempty"`
+	Patch  string       `json:"patch,omitempty"`
+	Target *PatchTarget `json:"target,omitempty"`
+}
+
+// PatchTarget holds the selector of the resources a patch applies to
+type PatchTarget struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
 }
 
+// AddResources adds the resources to the kustomization, which are kept sorted and without duplicates
 func (k *Kustomization) AddResources(s ...string) {
 	k.Resources = removeDuplicatesAndSort(append(k.Resources, s...))
 }
 
+// AddResourcesOrdered adds the resources to the kustomization after the existing ones, in the given order and without
+// duplicates. Adding resources with AddResources afterwards sorts all of them again
+func (k *Kustomization) AddResourcesOrdered(s ...string) {
+	for _, resource := range s {
+		if !containsResource(k.Resources, resource) {
+			k.Resources = append(k.Resources, resource)
+		}
+	}
+}
+
+// PrependResources moves the resources to the start of the kustomization resources, in the given order, so that
+// they're applied before the others
+func (k *Kustomization) PrependResources(s ...string) {
+	var resources []string
+	for _, resource := range s {
+		if !containsResource(resources, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	for _, resource := range k.Resources {
+		if !containsResource(resources, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	k.Resources = resources
+}
+
+// AddCrds adds the CRD files to the kustomization, which are kept sorted and without duplicates
+func (k *Kustomization) AddCrds(s ...string) {
+	k.Crds = removeDuplicatesAndSort(append(k.Crds, s...))
+}
+
+// AddBases adds the bases to the kustomization, which are kept sorted and without duplicates
 func (k *Kustomization) AddBases(s ...string) {
 	k.Bases = removeDuplicatesAndSort(append(k.Bases, s...))
 }
 
+// AddComponents adds the components to the kustomization, which are kept sorted and without duplicates
+func (k *Kustomization) AddComponents(s ...string) {
+	k.Components = removeDuplicatesAndSort(append(k.Components, s...))
+}
+
+// AddPatches adds the patch files to the kustomization, which are kept sorted and without duplicates. The targets of
+// the existing patches are kept, and the inline patches stay after the patch files
 func (k *Kustomization) AddPatches(s ...string) {
-	files := removeDuplicatesAndSort(append(getPatchFiles(k.Patches), s...))
-	k.Patches = addFilestoPatches(files)
+	existing := make(map[string]Patch)
+	var inline []Patch
+	for _, patch := range k.Patches {
+		if patch.Path == "" {
+			inline = append(inline, patch)
+		} else if _, ok := existing[patch.Path]; !ok {
+			existing[patch.Path] = patch
+		}
+	}
+	var patches []Patch
+	for _, file := range removeDuplicatesAndSort(append(getPatchFiles(k.Patches), s...)) {
+		if file == "" {
+			continue
+		}
+		patch, ok := existing[file]
+		if !ok {
+			patch = Patch{Path: file}
+		}
+		patches = append(patches, patch)
+	}
+	k.Patches = append(patches, inline...)
+}
+
+// AddBuildMetadata adds the build metadata options to the kustomization, after the existing ones
+func (k *Kustomization) AddBuildMetadata(s ...string) {
+	for _, option := range s {
+		exists := false
+		for _, existing := range k.BuildMetadata {
+			if existing == option {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			k.BuildMetadata = append(k.BuildMetadata, option)
+		}
+	}
+}
+
+// SetPatchTarget sets the target of the patches of the given file that don't have one yet
+func (k *Kustomization) SetPatchTarget(file string, target PatchTarget) {
+	for i := range k.Patches {
+		if k.Patches[i].Path == file && k.Patches[i].Target == nil {
+			patchTarget := target
+			k.Patches[i].Target = &patchTarget
+		}
+	}
 }
 
 func removeDuplicatesAndSort(s []string) []string {
@@ -62,6 +359,8 @@ func removeDuplicatesAndSort(s []string) []string {
 	return out
 }
 
+// CompareDifferenceAndAddCustomPatches sets the patches to the generated patch files that aren't part of the
+// original patches, followed by the original patches in the order the user authored them
 func (k *Kustomization) CompareDifferenceAndAddCustomPatches(original []Patch, generated []string) {
 	newGeneratedFiles := []string{}
 	originalPatches := make(map[string]bool)
@@ -70,13 +369,48 @@ func (k *Kustomization) CompareDifferenceAndAddCustomPatches(original []Patch, g
 	}
 	for _, generatedElement := range generated {
 		if _, ok := originalPatches[generatedElement]; !ok {
-			// preserve the newGeneratedFiles order
+			// preserve the newGeneratedFiles order, skipping the files generated more than once
 			newGeneratedFiles = append(newGeneratedFiles, generatedElement)
+			originalPatches[generatedElement] = true
+		}
+	}
+	// new generated files should add to the top of the patch list, and the original patches are kept as is, along
+	// with their targets
+	k.Patches = append(addFilestoPatches(newGeneratedFiles), original...)
+}
+
+// AddReplacements adds the replacements to the kustomization after the existing ones, unless the same replacement is
+// already there
+func (k *Kustomization) AddReplacements(replacements ...Replacement) {
+	for _, replacement := range replacements {
+		exists := false
+		for _, existing := range k.Replacements {
+			if reflect.DeepEqual(existing, replacement) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			k.Replacements = append(k.Replacements, replacement)
+		}
+	}
+}
+
+// AddTargetedPatches adds the patches with a target to the end of the patch list, replacing any patch with the same target
+func (k *Kustomization) AddTargetedPatches(patches ...Patch) {
+	for _, patch := range patches {
+		replaced := false
+		for i := range k.Patches {
+			if k.Patches[i].Target != nil && patch.Target != nil && *k.Patches[i].Target == *patch.Target {
+				k.Patches[i] = patch
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			k.Patches = append(k.Patches, patch)
 		}
 	}
-	// new generated files should add to the top of the patch list
-	newPatchesList := append(newGeneratedFiles, getPatchFiles(original)...)
-	k.Patches = addFilestoPatches(newPatchesList)
 }
 
 // gets the files from Patch
@@ -96,3 +430,13 @@ func addFilestoPatches(files []string) []Patch {
 	}
 	return patches
 }
+
+// checks whether the resources contain the resource
+func containsResource(resources []string, resource string) bool {
+	for _, r := range resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
@@ -17,9 +17,11 @@
 package resources
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
 )
 
 func Test_AddResource(t *testing.T) {
@@ -40,6 +42,16 @@ func Test_AddBases(t *testing.T) {
 	}
 }
 
+func Test_AddComponents(t *testing.T) {
+	k := Kustomization{}
+	k.AddComponents("../components/monitoring", "../components/add-istio-sidecar", "../components/monitoring")
+	k.AddComponents("../components/add-istio-sidecar")
+
+	if diff := cmp.Diff([]string{"../components/add-istio-sidecar", "../components/monitoring"}, k.Components); diff != "" {
+		t.Fatalf("failed to add components:\n%s", diff)
+	}
+}
+
 func Test_AddPatches(t *testing.T) {
 	k := Kustomization{}
 	k.AddPatches("testing.yaml", "testing2.yaml")
@@ -84,7 +96,7 @@ func Test_CompareDifferenceAndAddCustomizedPatches(t *testing.T) {
 			Path: "custom2.yaml",
 		},
 	}
-	generated := []string{"testing.yaml", "testing2.yaml", "testing3.yaml"}
+	generated := []string{"testing.yaml", "testing2.yaml", "testing3.yaml", "testing3.yaml"}
 	k.CompareDifferenceAndAddCustomPatches(original, generated)
 	if diff := cmp.Diff([]string{"testing3.yaml", "testing.yaml", "testing2.yaml", "custom.yaml", "custom2.yaml"}, getPatchFiles(k.Patches)); diff != "" {
 		t.Fatalf("failed to add customized patches:\n%s", diff)
@@ -136,3 +148,567 @@ func Test_AddFilestoPatches(t *testing.T) {
 		t.Fatalf("failed to add patch files:\n%s", diff)
 	}
 }
+
+func Test_GeneratorsRoundTrip(t *testing.T) {
+	k := Kustomization{
+		ConfigMapGenerator: []ConfigMapArgs{
+			{
+				GeneratorArgs: GeneratorArgs{
+					Name:     "app-config",
+					Behavior: "merge",
+					Literals: []string{"LOG_LEVEL=info"},
+					Files:    []string{"app.properties"},
+				},
+			},
+		},
+		SecretGenerator: []SecretArgs{
+			{
+				GeneratorArgs: GeneratorArgs{
+					Name:  "app-tls",
+					Files: []string{"tls.crt=certs/server.crt", "tls.key=certs/server.key"},
+					Options: &GeneratorOptions{
+						DisableNameSuffixHash: true,
+					},
+				},
+				Type: "kubernetes.io/tls",
+			},
+		},
+	}
+	want := `configMapGenerator:
+- behavior: merge
+  files:
+  - app.properties
+  literals:
+  - LOG_LEVEL=info
+  name: app-config
+secretGenerator:
+- files:
+  - tls.crt=certs/server.crt
+  - tls.key=certs/server.key
+  name: app-tls
+  options:
+    disableNameSuffixHash: true
+  type: kubernetes.io/tls
+`
+
+	data, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Fatalf("failed to marshal the generators:\n%s", diff)
+	}
+
+	var got Kustomization
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to unmarshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(k, got); diff != "" {
+		t.Fatalf("failed to unmarshal the generators:\n%s", diff)
+	}
+}
+
+func Test_ReplicasMarshal(t *testing.T) {
+	k := Kustomization{
+		Replicas: []Replica{
+			{
+				Name:  "test-component",
+				Count: 3,
+			},
+		},
+	}
+	want := `replicas:
+- count: 3
+  name: test-component
+`
+
+	data, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Fatalf("failed to marshal the replicas:\n%s", diff)
+	}
+}
+
+func Test_MixedPatchesMarshal(t *testing.T) {
+	k := Kustomization{}
+	k.AddPatches("deployment-patch.yaml")
+	k.AddTargetedPatches(Patch{
+		Patch: "- op: remove\n  path: /spec/template/spec/containers/0/ports/1\n",
+		Target: &PatchTarget{
+			Group:   "apps",
+			Version: "v1",
+			Kind:    "Deployment",
+			Name:    "test-component",
+		},
+	})
+	want := `patches:
+- path: deployment-patch.yaml
+- patch: |
+    - op: remove
+      path: /spec/template/spec/containers/0/ports/1
+  target:
+    group: apps
+    kind: Deployment
+    name: test-component
+    version: v1
+`
+
+	data, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Fatalf("failed to marshal the patches:\n%s", diff)
+	}
+
+	// Adding a patch with the same target replaces it, and the original patches keep their targets
+	k.AddTargetedPatches(Patch{
+		Path: "remove-port.yaml",
+		Target: &PatchTarget{
+			Group:   "apps",
+			Version: "v1",
+			Kind:    "Deployment",
+			Name:    "test-component",
+		},
+	})
+	original := k.Patches
+	k = Kustomization{}
+	k.CompareDifferenceAndAddCustomPatches(original, []string{"deployment-patch.yaml", "pdb-patch.yaml"})
+	wantPatches := []Patch{
+		{
+			Path: "pdb-patch.yaml",
+		},
+		{
+			Path: "deployment-patch.yaml",
+		},
+		{
+			Path: "remove-port.yaml",
+			Target: &PatchTarget{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+				Name:    "test-component",
+			},
+		},
+	}
+	if diff := cmp.Diff(wantPatches, k.Patches); diff != "" {
+		t.Fatalf("failed to keep the targeted patches:\n%s", diff)
+	}
+}
+
+func Test_UnknownFieldsRoundTrip(t *testing.T) {
+	data := []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+transformers:
+- transformer.yaml
+validators:
+- validator.yaml
+`)
+
+	var k Kustomization
+	if err := yaml.Unmarshal(data, &k); err != nil {
+		t.Fatalf("failed to unmarshal the kustomization: %v", err)
+	}
+	wantUnknownFields := map[string]interface{}{
+		"transformers": []interface{}{"transformer.yaml"},
+		"validators":   []interface{}{"validator.yaml"},
+	}
+	if diff := cmp.Diff(wantUnknownFields, k.UnknownFields); diff != "" {
+		t.Fatalf("failed to keep the unknown fields:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"deployment.yaml"}, k.Resources); diff != "" {
+		t.Fatalf("failed to unmarshal the resources:\n%s", diff)
+	}
+
+	got, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(string(data), string(got)); diff != "" {
+		t.Fatalf("failed to round trip the kustomization:\n%s", diff)
+	}
+}
+
+func Test_AddPatchesKeepsTargets(t *testing.T) {
+	k := Kustomization{
+		Patches: []Patch{
+			{
+				Patch: "- op: remove\n  path: /spec/replicas\n",
+				Target: &PatchTarget{
+					Kind: "Deployment",
+				},
+			},
+			{
+				Path: "deployment-patch.yaml",
+				Target: &PatchTarget{
+					Kind: "Deployment",
+					Name: "test-component",
+				},
+			},
+		},
+	}
+	k.AddPatches("worker-deployment-patch.yaml", "deployment-patch.yaml")
+	k.SetPatchTarget("worker-deployment-patch.yaml", PatchTarget{Kind: "Deployment", Name: "worker"})
+	k.SetPatchTarget("deployment-patch.yaml", PatchTarget{Kind: "Deployment", Name: "other"})
+
+	want := []Patch{
+		{
+			Path: "deployment-patch.yaml",
+			Target: &PatchTarget{
+				Kind: "Deployment",
+				Name: "test-component",
+			},
+		},
+		{
+			Path: "worker-deployment-patch.yaml",
+			Target: &PatchTarget{
+				Kind: "Deployment",
+				Name: "worker",
+			},
+		},
+		{
+			Patch: "- op: remove\n  path: /spec/replicas\n",
+			Target: &PatchTarget{
+				Kind: "Deployment",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, k.Patches); diff != "" {
+		t.Fatalf("failed to keep the patch targets:\n%s", diff)
+	}
+
+	// A patch without a target is marshalled as a path only
+	data, err := yaml.Marshal(Kustomization{Patches: []Patch{{Path: "deployment-patch.yaml"}}})
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff("patches:\n- path: deployment-patch.yaml\n", string(data)); diff != "" {
+		t.Fatalf("failed to marshal the patches:\n%s", diff)
+	}
+}
+
+func Test_LabelsRoundTrip(t *testing.T) {
+	data := `labels:
+- includeTemplates: true
+  pairs:
+    app.kubernetes.io/name: test-component
+    team: test
+- includeSelectors: true
+  pairs:
+    app: test-component
+`
+
+	var k Kustomization
+	if err := yaml.Unmarshal([]byte(data), &k); err != nil {
+		t.Fatalf("failed to unmarshal the kustomization: %v", err)
+	}
+	want := []Label{
+		{
+			Pairs: map[string]string{
+				"app.kubernetes.io/name": "test-component",
+				"team":                   "test",
+			},
+			IncludeTemplates: true,
+		},
+		{
+			Pairs: map[string]string{
+				"app": "test-component",
+			},
+			IncludeSelectors: true,
+		},
+	}
+	if diff := cmp.Diff(want, k.Labels); diff != "" {
+		t.Fatalf("failed to unmarshal the labels:\n%s", diff)
+	}
+
+	got, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(data, string(got)); diff != "" {
+		t.Fatalf("failed to round trip the labels:\n%s", diff)
+	}
+}
+
+func Test_SortOptionsAndBuildMetadataRoundTrip(t *testing.T) {
+	data := `buildMetadata:
+- managedByLabel
+- originAnnotations
+sortOptions:
+  legacySortOptions:
+    orderFirst:
+    - Namespace
+    orderLast:
+    - ValidatingWebhookConfiguration
+  order: legacy
+`
+
+	var k Kustomization
+	if err := yaml.Unmarshal([]byte(data), &k); err != nil {
+		t.Fatalf("failed to unmarshal the kustomization: %v", err)
+	}
+	want := Kustomization{
+		BuildMetadata: []string{"managedByLabel", "originAnnotations"},
+		SortOptions: &SortOptions{
+			Order: "legacy",
+			LegacySortOptions: &LegacySortOptions{
+				OrderFirst: []string{"Namespace"},
+				OrderLast:  []string{"ValidatingWebhookConfiguration"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, k); diff != "" {
+		t.Fatalf("failed to unmarshal the kustomization:\n%s", diff)
+	}
+
+	k.AddBuildMetadata("managedByLabel", "transformerAnnotations")
+	got, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	wantData := strings.Replace(data, "- originAnnotations\n", "- originAnnotations\n- transformerAnnotations\n", 1)
+	if diff := cmp.Diff(wantData, string(got)); diff != "" {
+		t.Fatalf("failed to marshal the kustomization:\n%s", diff)
+	}
+}
+
+func Test_GeneratorOptionsMarshal(t *testing.T) {
+	k := Kustomization{
+		ConfigMapGenerator: []ConfigMapArgs{
+			{
+				GeneratorArgs: GeneratorArgs{
+					Name:     "test-config",
+					Literals: []string{"key=value"},
+					Options: &GeneratorOptions{
+						DisableNameSuffixHash: true,
+					},
+				},
+			},
+		},
+		GeneratorOptions: &GeneratorOptions{
+			Labels: map[string]string{
+				"app.kubernetes.io/part-of": "test-application",
+			},
+			Annotations: map[string]string{
+				"argocd.argoproj.io/compare-options": "IgnoreExtraneous",
+			},
+			DisableNameSuffixHash: true,
+		},
+	}
+	// The generatorOptions of the kustomization are a top-level block, separate from the options of each generator
+	want := `configMapGenerator:
+- literals:
+  - key=value
+  name: test-config
+  options:
+    disableNameSuffixHash: true
+generatorOptions:
+  annotations:
+    argocd.argoproj.io/compare-options: IgnoreExtraneous
+  disableNameSuffixHash: true
+  labels:
+    app.kubernetes.io/part-of: test-application
+`
+
+	data, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Fatalf("failed to marshal the generator options:\n%s", diff)
+	}
+
+	var got Kustomization
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to unmarshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(k, got); diff != "" {
+		t.Fatalf("failed to round trip the generator options:\n%s", diff)
+	}
+}
+
+func Test_CrdsAndPrependResources(t *testing.T) {
+	k := Kustomization{}
+	k.AddResources("deployment.yaml", "widget-test.yaml", "crd-widgets.example.com.yaml")
+	k.AddCrds("crd-widgets.example.com.yaml", "crd-gadgets.example.com.yaml", "crd-widgets.example.com.yaml")
+	k.OpenAPI = map[string]string{"path": "schema.json"}
+	k.PrependResources("crd-widgets.example.com.yaml")
+
+	want := `crds:
+- crd-gadgets.example.com.yaml
+- crd-widgets.example.com.yaml
+openapi:
+  path: schema.json
+resources:
+- crd-widgets.example.com.yaml
+- deployment.yaml
+- widget-test.yaml
+`
+	data, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Fatalf("failed to marshal the crds:\n%s", diff)
+	}
+
+	var got Kustomization
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to unmarshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(k, got); diff != "" {
+		t.Fatalf("failed to round trip the crds:\n%s", diff)
+	}
+}
+
+func Test_AddResourcesOrdered(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(k *Kustomization)
+		want []string
+	}{
+		{
+			name: "Resources keep their insertion order without duplicates",
+			add: func(k *Kustomization) {
+				k.AddResourcesOrdered("namespace.yaml", "rbac.yaml", "namespace.yaml")
+				k.AddResourcesOrdered("deployment.yaml", "rbac.yaml")
+			},
+			want: []string{"namespace.yaml", "rbac.yaml", "deployment.yaml"},
+		},
+		{
+			name: "Ordered resources are added after the sorted ones",
+			add: func(k *Kustomization) {
+				k.AddResources("service.yaml", "deployment.yaml")
+				k.AddResourcesOrdered("namespace.yaml", "deployment.yaml")
+			},
+			want: []string{"deployment.yaml", "service.yaml", "namespace.yaml"},
+		},
+		{
+			name: "Sorted resources sort the ordered ones again",
+			add: func(k *Kustomization) {
+				k.AddResourcesOrdered("namespace.yaml", "deployment.yaml")
+				k.AddResources("service.yaml", "namespace.yaml")
+			},
+			want: []string{"deployment.yaml", "namespace.yaml", "service.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := Kustomization{}
+			tt.add(&k)
+			if diff := cmp.Diff(tt.want, k.Resources); diff != "" {
+				t.Fatalf("unexpected resources:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ReplacementsRoundTrip(t *testing.T) {
+	data := `replacements:
+- source:
+    fieldPath: spec.template.spec.containers.0.image
+    kind: Deployment
+    name: test-component
+    options:
+      delimiter: '@'
+      index: 1
+  targets:
+  - fieldPaths:
+    - metadata.annotations.[example.com/image-digest]
+    - spec.template.metadata.annotations.[example.com/image-digest]
+    options:
+      create: true
+    reject:
+    - name: test-worker
+    - labelSelector: app=legacy
+    select:
+      kind: Deployment
+- path: replacements/route-host.yaml
+`
+
+	var k Kustomization
+	if err := yaml.Unmarshal([]byte(data), &k); err != nil {
+		t.Fatalf("failed to unmarshal the kustomization: %v", err)
+	}
+	want := Kustomization{
+		Replacements: []Replacement{
+			{
+				Source: &SourceSelector{
+					Kind:      "Deployment",
+					Name:      "test-component",
+					FieldPath: "spec.template.spec.containers.0.image",
+					Options: &FieldOptions{
+						Delimiter: "@",
+						Index:     1,
+					},
+				},
+				Targets: []TargetSelector{
+					{
+						Select: &Selector{
+							Kind: "Deployment",
+						},
+						Reject: []Selector{
+							{
+								Name: "test-worker",
+							},
+							{
+								LabelSelector: "app=legacy",
+							},
+						},
+						FieldPaths: []string{
+							"metadata.annotations.[example.com/image-digest]",
+							"spec.template.metadata.annotations.[example.com/image-digest]",
+						},
+						Options: &FieldOptions{
+							Create: true,
+						},
+					},
+				},
+			},
+			{
+				Path: "replacements/route-host.yaml",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, k); diff != "" {
+		t.Fatalf("failed to unmarshal the replacements:\n%s", diff)
+	}
+
+	got, err := yaml.Marshal(k)
+	if err != nil {
+		t.Fatalf("failed to marshal the kustomization: %v", err)
+	}
+	if diff := cmp.Diff(data, string(got)); diff != "" {
+		t.Fatalf("failed to marshal the replacements:\n%s", diff)
+	}
+}
+
+func Test_AddReplacements(t *testing.T) {
+	existing := Replacement{Path: "replacements/route-host.yaml"}
+	added := Replacement{
+		Source: &SourceSelector{
+			Kind:      "Deployment",
+			Name:      "test-component",
+			FieldPath: "spec.template.spec.containers.0.image",
+		},
+		Targets: []TargetSelector{
+			{
+				Select:     &Selector{Kind: "Deployment"},
+				FieldPaths: []string{"metadata.annotations.[example.com/image]"},
+			},
+		},
+	}
+	k := Kustomization{Replacements: []Replacement{existing}}
+
+	// Adding the same replacement again, e.g. when regenerating, doesn't duplicate it
+	k.AddReplacements(added)
+	k.AddReplacements(added, existing)
+	if diff := cmp.Diff([]Replacement{existing, added}, k.Replacements); diff != "" {
+		t.Fatalf("unexpected replacements:\n%s", diff)
+	}
+}
@@ -17,49 +17,122 @@ package util
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
 	"net/url"
 	"regexp"
 	"strings"
 )
 
-var invalidRemoteMsg = errors.New("remote URL is invalid or missing the https scheme and/or supported github.com or gitlab.com hosts")
+var invalidRemoteMsg = errors.New("remote URL is invalid or missing the https or ssh scheme and/or supported github.com or gitlab.com hosts")
 
-// ValidateRemote minimally validates the remote gitops URL to ensure it contains the "https" scheme and supported "github.com" and "gitlab.com" hosts
+// scpRemoteRegex matches the scp-like syntax of ssh remotes, e.g. git@github.com:org/repo.git
+var scpRemoteRegex = regexp.MustCompile(`^[\w.-]+@([\w.-]+):([^/].*)$`)
+
+// ValidateRemote minimally validates the remote gitops URL to ensure it contains the "https" or "ssh" scheme, or is an
+// scp-like ssh remote, and supported "github.com" and "gitlab.com" hosts
 func ValidateRemote(remote string) error {
+	if matches := scpRemoteRegex.FindStringSubmatch(remote); matches != nil {
+		if isSupportedHost(matches[1]) {
+			return nil
+		}
+		return invalidRemoteMsg
+	}
+
 	remoteURL, parseErr := url.Parse(remote)
 	if parseErr != nil {
 		return invalidRemoteMsg
 	}
 
-	if remoteURL.Scheme == "https" && (remoteURL.Host == "github.com" || remoteURL.Host == "gitlab.com") {
+	if remoteURL.Scheme == "https" && isSupportedHost(remoteURL.Host) {
+		return nil
+	}
+	if remoteURL.Scheme == "ssh" && isSupportedHost(remoteURL.Hostname()) {
 		return nil
 	}
 
 	return invalidRemoteMsg
 }
 
+// IsSSHRemote returns whether the remote is an ssh remote, either of the "ssh" scheme or scp-like, which is
+// authenticated with ssh keys rather than a token
+func IsSSHRemote(remote string) bool {
+	return strings.HasPrefix(remote, "ssh://") || scpRemoteRegex.MatchString(remote)
+}
+
+// NormalizeRemote returns the host and path of the repository of the remote, e.g. github.com/org/repo for both
+// https://$token@github.com/org/repo.git and git@github.com:org/repo.git, so that the forms of a remote compare equal
+func NormalizeRemote(remote string) string {
+	repo := remote
+	if matches := scpRemoteRegex.FindStringSubmatch(remote); matches != nil {
+		repo = matches[1] + "/" + matches[2]
+	} else if remoteURL, err := url.Parse(remote); err == nil && remoteURL.Host != "" {
+		repo = remoteURL.Hostname() + remoteURL.Path
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(repo), "/"), ".git")
+}
+
+// ValidateRef validates the git reference, e.g. a branch, a tag or a commit ID, against the rules of git
+// check-ref-format. References starting with "-" are rejected too, as git would parse them as options
+func ValidateRef(ref string) error {
+	if ref == "" || ref == "@" || strings.HasPrefix(ref, "-") || strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") ||
+		strings.HasSuffix(ref, ".") || strings.HasSuffix(ref, ".lock") || strings.Contains(ref, "..") ||
+		strings.Contains(ref, "//") || strings.Contains(ref, "@{") || strings.ContainsAny(ref, " ~^:?*[\\") {
+		return fmt.Errorf("git reference %q is invalid", ref)
+	}
+	for _, component := range strings.Split(ref, "/") {
+		if strings.HasPrefix(component, ".") {
+			return fmt.Errorf("git reference %q is invalid", ref)
+		}
+	}
+	for _, r := range ref {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("git reference %q is invalid", ref)
+		}
+	}
+	return nil
+}
+
+func isSupportedHost(host string) bool {
+	return host == "github.com" || host == "gitlab.com"
+}
+
 /* #nosec G101 -- regex for remote url segment that can contain a token.  This is not a hardcoded token*/
 const (
 	tokenRegex  = `(https:\/\/)(\w+)@`
+	headerRegex = `(Authorization: (?:Basic|Bearer) )([\w+/=.-]+)`
+	keyRegex    = `(ssh -i |[Ii]dentity file |[Ll]oad key |UserKnownHostsFile=)["']?([^\s"']+)`
 	schemaBytes = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
 )
 
 // SanitizeErrorMessage takes in a given error message and returns a new, sanitized error with things like tokens removed
 func SanitizeErrorMessage(err error) error {
+	return errors.New(SanitizeMessage(err.Error()))
+}
+
+// SanitizeMessage takes in a given message, such as the output of a git command, and returns it with things like tokens removed
+func SanitizeMessage(msg string) string {
 	reg := regexp.MustCompile(tokenRegex)
-	matches := reg.FindAllStringSubmatch(err.Error(), -1)
-	newErrMsg := err.Error()
+	matches := reg.FindAllStringSubmatch(msg, -1)
+	newMsg := msg
 
 	for _, v := range matches {
 		// check for length of 3 because this includes the string match for the entire regex and sub-matches to the two capturing groups
 		if len(v) == 3 {
-			// use newErrMsg in subsequent iterations to ensure multiple tokens in a message get redacted
-			newErrMsg = strings.Replace(newErrMsg, v[2], "<TOKEN>", 1)
+			// use newMsg in subsequent iterations to ensure multiple tokens in a message get redacted
+			newMsg = strings.Replace(newMsg, v[2], "<TOKEN>", 1)
 		}
 	}
 
-	return errors.New(newErrMsg)
+	// the tokens of the authorization headers passed to git are redacted too
+	newMsg = regexp.MustCompile(headerRegex).ReplaceAllString(newMsg, "${1}<TOKEN>")
+
+	// the paths of the ssh keys and known hosts are redacted too, e.g. from the ssh command or its warnings
+	for _, v := range regexp.MustCompile(keyRegex).FindAllStringSubmatch(newMsg, -1) {
+		newMsg = strings.Replace(newMsg, v[2], "<KEY_PATH>", 1)
+	}
+
+	return newMsg
 }
 
 // GetRandomString returns a random string which is n characters long.
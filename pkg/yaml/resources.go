@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/afero"
@@ -32,19 +33,23 @@ import (
 // marshal the values to the filenames as YAML resources, joining the prefix to
 // the filenames before writing.
 //
-// It returns the list of filenames written out.
+// It returns the sorted list of filenames written out.
 func WriteResources(fs afero.Fs, path string, files map[string]interface{}) ([]string, error) {
 	path, err := homedir.Expand(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve path to file: %v", err)
 	}
-	filenames := make([]string, 0)
-	for filename, item := range files {
-		err := MarshalItemToFile(fs, filepath.Join(path, filename), item)
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	// the files are written in order, so that the output doesn't depend on the map iteration order
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		err := MarshalItemToFile(fs, filepath.Join(path, filename), files[filename])
 		if err != nil {
 			return nil, err
 		}
-		filenames = append(filenames, filename)
 	}
 	return filenames, nil
 }
@@ -99,6 +99,21 @@ func TestWriteResources(t *testing.T) {
 	}
 }
 
+func TestWriteResourcesSortsFilenames(t *testing.T) {
+	fs := ioutils.NewMemoryFilesystem()
+	r := Resources{
+		"statefulset.yaml":   appsv1.StatefulSet{},
+		"deployment.yaml":    appsv1.Deployment{},
+		"kustomization.yaml": resources.Kustomization{},
+	}
+
+	filenames, err := WriteResources(fs, "/tmp/sorted", r)
+	assertNoError(t, err)
+	if diff := cmp.Diff([]string{"deployment.yaml", "kustomization.yaml", "statefulset.yaml"}, filenames); diff != "" {
+		t.Fatalf("filenames mismatch:\n%s", diff)
+	}
+}
+
 func TestMarshalItemToFileAndUnMarshalItemFromFile(t *testing.T) {
 	fs := ioutils.NewFilesystem()
 	readOnlyFs := ioutils.NewReadOnlyFs()